@@ -23,8 +23,10 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/actions"
+	cpaws "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/config"
 
 	ciqLogger "github.com/RHEcosystemAppEng/cluster-iq/internal/logger"
@@ -258,6 +260,15 @@ func main() {
 		logger.Fatal("Failed to load Agent config", zap.Error(err))
 	}
 
+	// Retry policy shared by every AWS connection used by the executors
+	if err := cpaws.SetAWSRetryPolicy(cpaws.AWSRetryPolicy{
+		MaxRetries: cfg.AWSRetry.AWSMaxRetries,
+		MinDelay:   time.Duration(cfg.AWSRetry.AWSRetryMinDelayMilis) * time.Millisecond,
+		MaxDelay:   time.Duration(cfg.AWSRetry.AWSRetryMaxDelayMilis) * time.Millisecond,
+	}); err != nil {
+		logger.Fatal("Invalid AWS retry configuration", zap.Error(err))
+	}
+
 	// Creating AgentService with the specified configuration
 	agent, err := NewAgent(cfg, logger)
 	if err != nil {
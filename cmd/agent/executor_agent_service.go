@@ -2,11 +2,18 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"fmt"
+	"maps"
 	"net/http"
+	"os"
+	"reflect"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/actions"
 	cexec "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_executors"
@@ -23,6 +30,9 @@ type ExecutorAgentService struct {
 	cfg *config.ExecutorAgentServiceConfig
 	AgentService
 	executors      map[string]cexec.CloudExecutor
+	accountConfigs map[string]credentials.AccountConfig // Account configs used for creating the current executors
+	credsFileHash  []byte                               // Checksum of the credentials file used for creating the current executors
+	mutex          sync.RWMutex                         // Protects the executors map during the credentials reload
 	actionsChannel <-chan actions.Action
 	client         http.Client          // HTTP Client for retrieving the schedule from API
 	eventService   *events.EventService // Service for handling audit logs
@@ -59,6 +69,7 @@ func NewExecutorAgentService(cfg *config.ExecutorAgentServiceConfig, actionsChan
 	eas := ExecutorAgentService{
 		cfg:            cfg,
 		executors:      make(map[string]cexec.CloudExecutor),
+		accountConfigs: make(map[string]credentials.AccountConfig),
 		actionsChannel: actionsChannel,
 		AgentService: AgentService{
 			logger: logger,
@@ -79,7 +90,9 @@ func NewExecutorAgentService(cfg *config.ExecutorAgentServiceConfig, actionsChan
 	return &eas
 }
 
-// readCloudProviderAccounts reads cloud provider account configurations from the credentials file.
+// readCloudProviderAccounts reads cloud provider account configurations from
+// the credentials file. If the discovery of an AWS Organization fails, its
+// previously discovered members are kept, so their executors aren't dropped.
 //
 // Returns:
 //   - []credentials.AccountConfig: A slice of account configurations.
@@ -90,10 +103,18 @@ func (e *ExecutorAgentService) readCloudProviderAccounts() ([]credentials.Accoun
 		return nil, err
 	}
 
-	return accounts, nil
+	// Adding the member accounts of the AWS Organizations with discovery enabled
+	expanded, err := credentials.ExpandAWSOrganizations(accounts)
+	if err != nil {
+		e.logger.Error("AWS Organizations discovery failed; keeping the previously discovered members", zap.Error(err))
+		expanded = credentials.KeepFailedOrganizations(expanded, slices.Collect(maps.Values(e.accountConfigs)), err)
+	}
+
+	return expanded, nil
 }
 
-// AddExecutor adds a new CloudExecutor to the AgentService.
+// AddExecutor adds a new CloudExecutor to the AgentService. If there's
+// already an executor for the same account, it's replaced.
 //
 // Parameters:
 //   - exec: CloudExecutor instance to add.
@@ -105,16 +126,33 @@ func (e *ExecutorAgentService) AddExecutor(exec cexec.CloudExecutor) error {
 		return fmt.Errorf("cannot add a nil Executor")
 	}
 
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
 	e.executors[exec.GetAccountName()] = exec
 
 	return nil
 }
 
+// RemoveExecutor removes the CloudExecutor of an account from the AgentService.
+//
+// Parameters:
+//   - accountName: The name of the account whose executor is removed.
+func (e *ExecutorAgentService) RemoveExecutor(accountName string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.executors, accountName)
+}
+
 // createExecutors initializes CloudExecutors for all configured cloud provider accounts.
 //
 // Returns:
 //   - error: An error if any executor initialization fails.
 func (e *ExecutorAgentService) createExecutors() error {
+	hash, err := credentialsFileHash(e.cfg.Credentials.CredentialsFile)
+	if err != nil {
+		return err
+	}
+
 	accounts, err := e.readCloudProviderAccounts()
 	if err != nil {
 		return err
@@ -122,57 +160,277 @@ func (e *ExecutorAgentService) createExecutors() error {
 
 	// Generating a CloudExecutor by account. The creation of the CloudExecutor depends on the Cloud Provider
 	for _, account := range accounts {
-		switch account.Provider {
-		case inventory.AWSProvider: // AWS
-			e.logger.Info("Creating Executor for AWS account", zap.String("account_name", account.Name))
-			exec := cexec.NewAWSExecutor(
-				inventory.NewAccount("", account.Name, account.Provider, account.User, account.Key),
-				e.actionsChannel,
-				logger,
-			)
-			err := e.AddExecutor(exec)
+		exec, err := e.newExecutor(account)
+		if err != nil {
+			e.logger.Error("Cannot create an Executor for account", zap.String("account_name", account.Name), zap.Error(err))
+			return err
+		}
+
+		// Unsupported providers don't have executor
+		if exec == nil {
+			continue
+		}
+
+		if err := e.AddExecutor(exec); err != nil {
+			e.logger.Error("Cannot add the Executor for account", zap.String("account_name", account.Name), zap.Error(err))
+			return err
+		}
+		e.accountConfigs[account.Name] = account
+	}
+
+	e.credsFileHash = hash
+	return nil
+}
+
+// newExecutor creates the CloudExecutor for an account. The creation of the
+// CloudExecutor depends on the Cloud Provider.
+//
+// Parameters:
+//   - account: Account config read from the credentials file.
+//
+// Returns:
+//   - cexec.CloudExecutor: The new executor, or nil if the provider is not supported.
+//   - error: An error if the executor can't be created.
+func (e *ExecutorAgentService) newExecutor(account credentials.AccountConfig) (cexec.CloudExecutor, error) {
+	invAccount := inventory.NewAccount("", account.Name, account.Provider, account.User, account.Key)
+	invAccount.SetRegionFilter(account.Regions, account.ExcludeRegions)
+
+	// A nil executor pointer must be checked before converting it into a CloudExecutor
+	switch account.Provider {
+	case inventory.AWSProvider: // AWS
+		e.logger.Info("Creating Executor for AWS account", zap.String("account_name", account.Name))
+		exec := cexec.NewAWSExecutor(
+			invAccount,
+			account.AWSAssumeRoleConfig(),
+			e.actionsChannel,
+			logger,
+		)
+		if exec == nil {
+			return nil, fmt.Errorf("cannot create an AWSExecutor for account %s", account.Name)
+		}
+		return exec, nil
+
+	case inventory.GCPProvider: // GCP
+		e.logger.Info("Creating Executor for GCP account", zap.String("account_name", account.Name))
+		exec := cexec.NewGCPExecutor(
+			invAccount,
+			e.actionsChannel,
+			logger,
+		)
+		if exec == nil {
+			return nil, fmt.Errorf("cannot create a GCPExecutor for account %s", account.Name)
+		}
+		return exec, nil
+
+	case inventory.AzureProvider: // Azure
+		e.logger.Info("Creating Executor for Azure account", zap.String("account_name", account.Name))
+		exec := cexec.NewAzureExecutor(
+			invAccount,
+			account.TenantID,
+			e.actionsChannel,
+			logger,
+		)
+		if exec == nil {
+			return nil, fmt.Errorf("cannot create an AzureExecutor for account %s", account.Name)
+		}
+		return exec, nil
+
+	default:
+		e.logger.Warn("Unsupported cloud provider, skipping account",
+			zap.String("account_name", account.Name),
+			zap.String("provider", string(account.Provider)))
+		return nil, nil
+	}
+}
+
+// watchCredentialsFile checks periodically if the credentials file has
+// changed, and reloads the executors when it does. The AWS Organizations are
+// discovered again on their own interval, as their members can change without
+// modifying the credentials file. It runs until the done channel is closed.
+//
+// Parameters:
+//   - done: Channel closed when the ExecutorAgentService stops.
+func (e *ExecutorAgentService) watchCredentialsFile(done <-chan struct{}) {
+	// A nil channel never receives, so the disabled checks are never selected
+	var credsTicks, discoveryTicks <-chan time.Time
+	if e.cfg.CredsReloadInterval > 0 {
+		ticker := time.NewTicker(time.Duration(e.cfg.CredsReloadInterval) * time.Second)
+		defer ticker.Stop()
+		credsTicks = ticker.C
+	} else {
+		e.logger.Info("Credentials file hot reload disabled")
+	}
+	if e.cfg.OrgDiscoveryInterval > 0 {
+		ticker := time.NewTicker(time.Duration(e.cfg.OrgDiscoveryInterval) * time.Second)
+		defer ticker.Stop()
+		discoveryTicks = ticker.C
+	} else {
+		e.logger.Info("AWS Organizations periodic discovery disabled")
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-credsTicks:
+			hash, err := credentialsFileHash(e.cfg.Credentials.CredentialsFile)
 			if err != nil {
-				e.logger.Error("Cannot create an AWSEexecutor for account", zap.String("account_name", account.Name), zap.Error(err))
-				return err
+				e.logger.Error("Cannot read credentials file", zap.Error(err))
+				continue
 			}
 
-		case inventory.GCPProvider: // GCP
-			e.logger.Warn("Failed to create Executor for GCP account",
-				zap.String("account", account.Name),
-				zap.String("reason", "not implemented"),
-			)
+			if bytes.Equal(hash, e.credsFileHash) {
+				continue
+			}
+
+			e.logger.Info("Credentials file changed. Reloading executors")
+			e.reloadCredentials(hash)
+		case <-discoveryTicks:
+			if !e.hasOrganizationDiscovery() {
+				continue
+			}
 
-		case inventory.AzureProvider: // Azure
-			e.logger.Warn("Failed to create Executor for Azure account",
-				zap.String("account", account.Name),
-				zap.String("reason", "not implemented"),
-			)
+			hash, err := credentialsFileHash(e.cfg.Credentials.CredentialsFile)
+			if err != nil {
+				e.logger.Error("Cannot read credentials file", zap.Error(err))
+				continue
+			}
 
+			e.logger.Info("Discovering AWS Organizations members. Reloading executors")
+			e.reloadCredentials(hash)
 		}
 	}
+}
+
+// reloadCredentials reloads the executors, and records the checksum of the
+// credentials file used for them if the reload succeeds
+func (e *ExecutorAgentService) reloadCredentials(hash []byte) {
+	if err := e.reloadExecutors(); err != nil {
+		e.logger.Error("Cannot reload executors", zap.Error(err))
+		return
+	}
+	e.credsFileHash = hash
+}
+
+// hasOrganizationDiscovery returns true if any of the current accounts is an
+// AWS Organization management account with discovery enabled
+func (e *ExecutorAgentService) hasOrganizationDiscovery() bool {
+	for _, account := range e.accountConfigs {
+		if account.Provider == inventory.AWSProvider && account.OrganizationDiscovery {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadExecutors reads again the credentials file and rebuilds only the
+// executors of the accounts added or modified (e.g. rotated keys) since the
+// last read. Executors of removed accounts are dropped. An audit event is
+// generated for every affected account.
+//
+// Returns:
+//   - error: An error if the credentials file can't be read. Executors remain unchanged in that case.
+func (e *ExecutorAgentService) reloadExecutors() error {
+	accounts, err := e.readCloudProviderAccounts()
+	if err != nil {
+		return err
+	}
+
+	newConfigs := make(map[string]credentials.AccountConfig, len(accounts))
+	for _, account := range accounts {
+		newConfigs[account.Name] = account
+
+		action := inventory.AccountAddedAction
+		previous, exists := e.accountConfigs[account.Name]
+		if exists {
+			if reflect.DeepEqual(previous, account) {
+				continue
+			}
+			action = inventory.AccountRotatedAction
+		}
+
+		exec, err := e.newExecutor(account)
+		if err == nil && exec != nil {
+			err = e.AddExecutor(exec)
+		}
+		if err != nil || exec == nil {
+			e.logger.Error("Cannot reload the Executor for account", zap.String("account_name", account.Name), zap.Error(err))
+			e.logAccountEvent(action, account.Name, events.ResultFailed, events.SeverityError)
+			// Keeping the previous config (and executor) if there was one
+			if exists {
+				newConfigs[account.Name] = previous
+			} else {
+				delete(newConfigs, account.Name)
+			}
+			continue
+		}
+
+		e.logger.Info("Executor reloaded", zap.String("account_name", account.Name), zap.String("action", action))
+		e.logAccountEvent(action, account.Name, events.ResultSuccess, events.SeverityInfo)
+	}
+
+	for accountName := range e.accountConfigs {
+		if _, ok := newConfigs[accountName]; ok {
+			continue
+		}
+		e.RemoveExecutor(accountName)
+		e.logger.Info("Executor removed", zap.String("account_name", accountName))
+		e.logAccountEvent(inventory.AccountRemovedAction, accountName, events.ResultSuccess, events.SeverityWarning)
+	}
+
+	e.accountConfigs = newConfigs
 	return nil
 }
 
+// logAccountEvent generates an audit event about a change on the configured accounts
+func (e *ExecutorAgentService) logAccountEvent(action string, accountName string, result string, severity string) {
+	description := "Credentials file reload"
+	_, err := e.eventService.LogEvent(events.EventOptions{
+		Action:       actions.ActionOperation(action),
+		Description:  &description,
+		ResourceID:   accountName,
+		ResourceType: inventory.AccountResourceType,
+		Result:       result,
+		Severity:     severity,
+		TriggeredBy:  "ClusterIQ Agent",
+	})
+	if err != nil {
+		e.logger.Error("Cannot log account event", zap.String("account_name", accountName), zap.Error(err))
+	}
+}
+
+// credentialsFileHash returns the SHA256 checksum of the credentials file content
+func credentialsFileHash(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(content)
+	return hash[:], nil
+}
+
 // GetExecutor retrieves the CloudExecutor associated with a given account name.
 //
 // Parameters:
 // - accountName: The name of the account for which the executor is requested.
 //
 // Returns:
-// - cexec.CloudExecutor: The executor for the specified account.
-// - error: An error if no executor is found for the given account.
-func (e *ExecutorAgentService) GetExecutor(accountName string) *cexec.CloudExecutor {
-	exec, ok := e.executors[accountName]
-	if !ok {
-		return nil
-	}
-	return &exec
+// - cexec.CloudExecutor: The executor for the specified account, or nil if there's no executor for the account.
+func (e *ExecutorAgentService) GetExecutor(accountName string) cexec.CloudExecutor {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.executors[accountName]
 }
 
 func (e *ExecutorAgentService) Start() error {
 	e.logger.Debug("Starting ExecutorAgentService")
 	var actionStatus string
 
+	// Watching the credentials file until the actions channel is closed
+	done := make(chan struct{})
+	defer close(done)
+	go e.watchCredentialsFile(done)
+
 	// Reading actions from channel to prepare its execution
 	for newAction := range e.actionsChannel {
 		e.logger.Debug("New action arrived to ExecutorAgentService",
@@ -201,11 +459,15 @@ func (e *ExecutorAgentService) Start() error {
 		})
 
 		target := newAction.GetTarget()
-		cexec := *(e.GetExecutor(target.GetAccountName()))
+		cexec := e.GetExecutor(target.GetAccountName())
 		if cexec == nil {
-			return fmt.Errorf("there's no Executor available for the requested account")
-		}
-		if err := cexec.ProcessAction(newAction); err != nil {
+			// The account could be removed from the credentials file after the action was scheduled
+			e.logger.Error("There's no Executor available for the requested account",
+				zap.String("action_id", newAction.GetID()),
+				zap.String("account_name", target.GetAccountName()))
+			actionStatus = "Failed"
+			tracker.Failed()
+		} else if err := cexec.ProcessAction(newAction); err != nil {
 			e.logger.Error("Error while processing action", zap.String("action_id", newAction.GetID()))
 			actionStatus = "Failed"
 			tracker.Failed()
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/actions"
+	cexec "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_executors"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/config"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/credentials"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/events"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/models"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeEventClient records the audit events instead of writing them on the DB
+type fakeEventClient struct {
+	mutex   sync.Mutex
+	events  []models.AuditLog
+	results []string
+}
+
+func (f *fakeEventClient) AddEvent(event models.AuditLog) (int64, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.events = append(f.events, event)
+	return int64(len(f.events)), nil
+}
+
+func (f *fakeEventClient) UpdateEventStatus(_ int64, result string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.results = append(f.results, result)
+	return nil
+}
+
+// blockingExecutor is a CloudExecutor whose actions don't finish until release is closed
+type blockingExecutor struct {
+	accountName string
+	started     chan struct{}
+	release     chan struct{}
+	processed   int
+}
+
+func newBlockingExecutor(accountName string) *blockingExecutor {
+	return &blockingExecutor{accountName: accountName, started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (b *blockingExecutor) Connect() error         { return nil }
+func (b *blockingExecutor) SetRegion(string) error { return nil }
+func (b *blockingExecutor) GetAccountName() string { return b.accountName }
+func (b *blockingExecutor) ProcessAction(actions.Action) error {
+	close(b.started)
+	<-b.release
+	b.processed++
+	return nil
+}
+
+// newTestExecutorAgentService returns an ExecutorAgentService reading the
+// specified credentials file content, without any executor
+func newTestExecutorAgentService(t *testing.T, credentialsFile string, actionsChannel <-chan actions.Action) (*ExecutorAgentService, *fakeEventClient) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	assert.Nil(t, os.WriteFile(path, []byte(credentialsFile), 0o600))
+
+	eventClient := &fakeEventClient{}
+	e := &ExecutorAgentService{
+		cfg:            &config.ExecutorAgentServiceConfig{Credentials: config.CloudCredentialsConfig{CredentialsFile: path}},
+		AgentService:   AgentService{logger: zap.NewNop()},
+		executors:      make(map[string]cexec.CloudExecutor),
+		accountConfigs: make(map[string]credentials.AccountConfig),
+		actionsChannel: actionsChannel,
+		eventService:   events.NewEventService(eventClient, zap.NewNop()),
+	}
+	return e, eventClient
+}
+
+// addTestExecutor adds an executor as if it was created from the account config
+func addTestExecutor(t *testing.T, e *ExecutorAgentService, exec cexec.CloudExecutor, account credentials.AccountConfig) {
+	assert.Nil(t, e.AddExecutor(exec))
+	e.accountConfigs[account.Name] = account
+}
+
+// TestReloadExecutorsKeepsInFlightActions verifies the executors of the rotated accounts are replaced while an action is running on them, and the running action finishes on the previous executor
+func TestReloadExecutorsKeepsInFlightActions(t *testing.T) {
+	actionsChannel := make(chan actions.Action)
+	e, eventClient := newTestExecutorAgentService(t, `
+[rotated]
+provider = aws
+user = AKIANEWKEY
+key = new-secret
+`, actionsChannel)
+
+	previous := newBlockingExecutor("rotated")
+	addTestExecutor(t, e, previous, credentials.AccountConfig{Name: "rotated", Provider: inventory.AWSProvider, User: "AKIAOLDKEY", Key: "old-secret"})
+	removed := newBlockingExecutor("removed")
+	addTestExecutor(t, e, removed, credentials.AccountConfig{Name: "removed", Provider: inventory.AWSProvider})
+
+	done := make(chan error)
+	go func() { done <- e.Start() }()
+
+	target := actions.NewActionTarget("rotated", "eu-west-1", "ocp-a1b2c-rotated", nil)
+	actionsChannel <- actions.NewInstantAction(actions.PowerOffCluster, *target, "Pending", true)
+	<-previous.started
+
+	assert.Nil(t, e.reloadExecutors())
+	assert.IsType(t, &cexec.AWSExecutor{}, e.GetExecutor("rotated"))
+	assert.Nil(t, e.GetExecutor("removed"))
+	assert.Equal(t, "AKIANEWKEY", e.accountConfigs["rotated"].User)
+
+	close(previous.release)
+	close(actionsChannel)
+	assert.Nil(t, <-done)
+
+	assert.Equal(t, 1, previous.processed)
+	assert.Equal(t, 0, removed.processed)
+	assert.Equal(t, []string{events.ResultSuccess}, eventClient.results)
+
+	var accountEvents []string
+	for _, event := range eventClient.events {
+		if event.ResourceType == inventory.AccountResourceType {
+			accountEvents = append(accountEvents, event.ResourceID+":"+string(event.ActionName))
+		}
+	}
+	assert.ElementsMatch(t, []string{"rotated:" + inventory.AccountRotatedAction, "removed:" + inventory.AccountRemovedAction}, accountEvents)
+}
+
+// TestReloadExecutorsUnchangedAccounts verifies the executors of the accounts without changes are kept, and the unreadable credentials files don't modify the executors
+func TestReloadExecutorsUnchangedAccounts(t *testing.T) {
+	e, eventClient := newTestExecutorAgentService(t, `
+[unchanged]
+provider = aws
+user = AKIAKEY
+key = secret
+`, nil)
+
+	accounts, err := e.readCloudProviderAccounts()
+	assert.Nil(t, err)
+	executor := newBlockingExecutor("unchanged")
+	addTestExecutor(t, e, executor, accounts[0])
+
+	assert.Nil(t, e.reloadExecutors())
+	assert.Same(t, executor, e.GetExecutor("unchanged"))
+	assert.Empty(t, eventClient.events)
+
+	assert.Nil(t, os.Remove(e.cfg.Credentials.CredentialsFile))
+	assert.NotNil(t, e.reloadExecutors())
+	assert.Same(t, executor, e.GetExecutor("unchanged"))
+}
+
+// TestReloadExecutorsKeepsMembersOnDiscoveryError verifies the executors of the discovered member accounts are kept when the discovery of their organization fails
+func TestReloadExecutorsKeepsMembersOnDiscoveryError(t *testing.T) {
+	// The member role template without AccountID placeholder makes the discovery fail
+	e, eventClient := newTestExecutorAgentService(t, `
+[management]
+provider = aws
+user = AKIAKEY
+key = secret
+organization_discovery = true
+member_role_arn = arn:aws:iam::123456789012:role/ClusterIQReadOnly
+`, nil)
+
+	accounts, err := e.readCloudProviderAccounts()
+	assert.Nil(t, err)
+	assert.Len(t, accounts, 1)
+	management := newBlockingExecutor("management")
+	addTestExecutor(t, e, management, accounts[0])
+	member := newBlockingExecutor("sandbox-01")
+	addTestExecutor(t, e, member, credentials.AccountConfig{Name: "sandbox-01", Provider: inventory.AWSProvider, ManagementAccount: "management"})
+	assert.True(t, e.hasOrganizationDiscovery())
+
+	assert.Nil(t, e.reloadExecutors())
+	assert.Same(t, management, e.GetExecutor("management"))
+	assert.Same(t, member, e.GetExecutor("sandbox-01"))
+	assert.Contains(t, e.accountConfigs, "sandbox-01")
+	assert.Empty(t, eventClient.events)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// estimateCosts estimates the expenses of the instances whose accounts don't
+// have billing information, based on their on-demand price and the running
+// hours recorded by the scans since the beginning of the previous month (the
+// same period covered by the billing stockers)
+func (a APIServer) estimateCosts() error {
+	now := time.Now().UTC()
+	since := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+
+	samples, err := a.sql.GetUnbilledInstancesSamples(since)
+	if err != nil {
+		return fmt.Errorf("failed to get the instances samples: %w", err)
+	}
+
+	estimation := a.prices.EstimateExpenses(samples, since, now)
+	if len(estimation.UnknownInstanceTypes) > 0 {
+		a.logger.Warn("Instance types without price, their costs can't be estimated",
+			zap.Strings("instance_types", estimation.UnknownInstanceTypes))
+	}
+
+	if err := a.sql.WriteEstimatedExpenses(estimation.Expenses); err != nil {
+		return fmt.Errorf("failed to write the estimated expenses: %w", err)
+	}
+
+	a.logger.Debug("Instances costs estimated",
+		zap.Int("samples", len(samples)),
+		zap.Int("expenses", len(estimation.Expenses)))
+	return nil
+}
@@ -1,15 +1,23 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/actions"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/events"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/forecast"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/models"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/scan"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -410,13 +418,26 @@ func (a APIServer) HandlerPostExpense(c *gin.Context) {
 //	@Tags			Instances
 //	@Accept			json
 //	@Produce		json
+//	@Param			at	query		string	false	"Point in time (RFC3339 timestamp or YYYY-MM-DD date) for obtaining the Instances as they were at that moment"
 //	@Success		200	{object}	InstanceListResponse
+//	@Failure		400	{object}	GenericErrorResponse
 //	@Failure		500	{object}	GenericErrorResponse
 //	@Router			/instances [get]
 func (a APIServer) HandlerGetInstances(c *gin.Context) {
-	a.logger.Debug("Retrieving complete instance inventory")
+	at, err := parseAtQuery(c)
+	if err != nil {
+		c.PureJSON(http.StatusBadRequest, NewGenericErrorResponse(err.Error()))
+		return
+	}
 
-	instances, err := a.sql.GetInstances()
+	var instances []inventory.Instance
+	if at != nil {
+		a.logger.Debug("Retrieving instance inventory at a point in time", zap.Time("at", *at))
+		instances, err = a.sql.GetInstancesAt(*at)
+	} else {
+		a.logger.Debug("Retrieving complete instance inventory")
+		instances, err = a.sql.GetInstances()
+	}
 	if err != nil {
 		a.logger.Error("Can't retrieve Instances list", zap.Error(err))
 		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
@@ -558,6 +579,214 @@ func (a APIServer) HandlerPatchInstance(c *gin.Context) {
 	c.PureJSON(http.StatusNotImplemented, nil)
 }
 
+// ==================== Resources     Handlers ====================
+
+// HandlerGetResources handles the request for obtain the entire non-compute Resources list
+//
+//	@Summary		Obtain every Resource
+//	@Description	Returns a list of every non-compute Resource (volumes, snapshots, IPs...) in the inventory
+//	@Tags			Resources
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	ResourceListResponse
+//	@Failure		500	{object}	GenericErrorResponse
+//	@Router			/resources [get]
+func (a APIServer) HandlerGetResources(c *gin.Context) {
+	a.logger.Debug("Retrieving complete resources inventory")
+
+	resources, err := a.sql.GetResources()
+	if err != nil {
+		a.logger.Error("Can't retrieve Resources list", zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	c.PureJSON(http.StatusOK, NewResourceListResponse(resources))
+}
+
+// HandlerPostResource handles the request for writing new non-compute Resources in the inventory
+//
+//	@Summary		Creates new Resources in the inventory
+//	@Description	Receives and write into the DB the information for new non-compute Resources
+//	@Tags			Resources
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource	body		[]inventory.Resource	true	"New Resources to be added"
+//	@Success		200			{object}	nil
+//	@Failure		400			{object}	GenericErrorResponse
+//	@Failure		500			{object}	GenericErrorResponse
+//	@Router			/resources [post]
+func (a APIServer) HandlerPostResource(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		a.logger.Error("Can't get body from request", zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	var resources []inventory.Resource
+	err = json.Unmarshal(body, &resources)
+	if err != nil {
+		a.logger.Error("Can't obtain data from body request", zap.Error(err))
+		c.PureJSON(http.StatusBadRequest, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	if len(resources) == 0 {
+		c.PureJSON(http.StatusOK, nil)
+		return
+	}
+
+	a.logger.Debug("Writing new Resources", zap.Int("resources", len(resources)))
+	err = a.sql.WriteResources(resources)
+	if err != nil {
+		a.logger.Error("Can't write new resources into DB", zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+	c.PureJSON(http.StatusOK, nil)
+}
+
+// ==================== Orphans       Handlers ====================
+
+// HandlerGetOrphans handles the request for obtain the list of orphaned Resources
+//
+//	@Summary		Obtain every orphaned Resource
+//	@Description	Returns a list of the Resources (load balancers, volumes, security groups, hosted zones...) that remain on the cloud accounts after their clusters were terminated
+//	@Tags			Orphans
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	ResourceListResponse
+//	@Failure		500	{object}	GenericErrorResponse
+//	@Router			/orphans [get]
+func (a APIServer) HandlerGetOrphans(c *gin.Context) {
+	a.logger.Debug("Retrieving orphaned resources")
+
+	orphans, err := a.sql.GetOrphanResources()
+	if err != nil {
+		a.logger.Error("Can't retrieve orphaned Resources list", zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	c.PureJSON(http.StatusOK, NewResourceListResponse(orphans))
+}
+
+// HandlerPostOrphans handles the request for writing the orphaned Resources found by the scanner
+//
+//	@Summary		Registers orphaned Resources in the inventory
+//	@Description	Receives and write into the DB the Resources that remain after their clusters were terminated. The known orphaned Resources of the checked clusters that were not found anymore are removed as resolved. An audit event is generated for every cluster with new or resolved orphaned Resources
+//	@Tags			Orphans
+//	@Accept			json
+//	@Produce		json
+//	@Param			orphans	body		scan.OrphansRequest	true	"Checked clusters and their orphaned Resources"
+//	@Success		200		{object}	nil
+//	@Failure		400		{object}	GenericErrorResponse
+//	@Failure		500		{object}	GenericErrorResponse
+//	@Router			/orphans [post]
+func (a APIServer) HandlerPostOrphans(c *gin.Context) {
+	var request scan.OrphansRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		a.logger.Error("Can't obtain data from body request", zap.Error(err))
+		c.PureJSON(http.StatusBadRequest, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	if len(request.ClusterIDs) == 0 && len(request.Orphans) == 0 {
+		c.PureJSON(http.StatusOK, nil)
+		return
+	}
+
+	// Getting the already known orphans for notifying only the changes
+	knownOrphans, err := a.sql.GetOrphanResources()
+	if err != nil {
+		a.logger.Error("Can't retrieve orphaned Resources list", zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	resolved := resolvedOrphans(knownOrphans, request)
+	resolvedIDs := make([]string, 0, len(resolved))
+	for _, orphan := range resolved {
+		resolvedIDs = append(resolvedIDs, orphan.ID)
+	}
+
+	a.logger.Debug("Writing orphaned Resources", zap.Int("resources", len(request.Orphans)), zap.Int("resolved", len(resolved)))
+	if err := a.sql.WriteOrphanResources(request.Orphans, resolvedIDs); err != nil {
+		a.logger.Error("Can't write orphaned resources into DB", zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	a.logOrphanEvents(knownOrphans, request.Orphans, resolved)
+
+	c.PureJSON(http.StatusOK, nil)
+}
+
+// resolvedOrphans returns the known orphaned resources of the checked
+// clusters that were not found anymore. Only the resource types looked for by
+// the scanner are considered, as the rest can't be found again
+func resolvedOrphans(knownOrphans []inventory.Resource, request scan.OrphansRequest) []inventory.Resource {
+	found := make(map[string]bool, len(request.Orphans))
+	for _, orphan := range request.Orphans {
+		found[orphan.ID] = true
+	}
+
+	var resolved []inventory.Resource
+	for _, orphan := range knownOrphans {
+		if found[orphan.ID] || !slices.Contains(request.ClusterIDs, orphan.ClusterID) || !slices.Contains(inventory.OrphanResourceTypes, orphan.Type) {
+			continue
+		}
+		resolved = append(resolved, orphan)
+	}
+	return resolved
+}
+
+// logOrphanEvents generates a warning audit event for every cluster with
+// orphaned resources that were not already on the knownOrphans list, and an
+// info audit event for every cluster with resolved orphaned resources
+func (a APIServer) logOrphanEvents(knownOrphans []inventory.Resource, orphans []inventory.Resource, resolved []inventory.Resource) {
+	known := make(map[string]bool, len(knownOrphans))
+	for _, orphan := range knownOrphans {
+		known[orphan.ID] = true
+	}
+
+	var newOrphans []inventory.Resource
+	for _, orphan := range orphans {
+		if !known[orphan.ID] {
+			newOrphans = append(newOrphans, orphan)
+		}
+	}
+
+	a.logClustersOrphansEvents(inventory.ClusterOrphanedResourcesAction, "Orphaned resources detected", events.SeverityWarning, newOrphans)
+	a.logClustersOrphansEvents(inventory.ClusterOrphanedResourcesResolvedAction, "Orphaned resources removed", events.SeverityInfo, resolved)
+}
+
+// logClustersOrphansEvents generates an audit event for every cluster of the orphaned resources, listing them
+func (a APIServer) logClustersOrphansEvents(action string, message string, severity string, orphans []inventory.Resource) {
+	// Grouping the orphans by cluster
+	clustersOrphans := make(map[string][]string)
+	for _, orphan := range orphans {
+		clustersOrphans[orphan.ClusterID] = append(clustersOrphans[orphan.ClusterID], fmt.Sprintf("%s(%s)", orphan.Type, orphan.ID))
+	}
+
+	for clusterID, resources := range clustersOrphans {
+		description := fmt.Sprintf("%s: %s", message, strings.Join(resources, ", "))
+		_, err := a.eventService.LogEvent(events.EventOptions{
+			Action:       actions.ActionOperation(action),
+			Description:  &description,
+			ResourceID:   clusterID,
+			ResourceType: inventory.ClusterResourceType,
+			Result:       events.ResultSuccess,
+			Severity:     severity,
+			TriggeredBy:  "ClusterIQ Scanner",
+		})
+		if err != nil {
+			a.logger.Error("Can't log orphaned resources event", zap.String("cluster_id", clusterID), zap.Error(err))
+		}
+	}
+}
+
 // ==================== Clusters      Handlers ====================
 
 // HandlerGetClusters handles the request for obtaining the entire Cluster list
@@ -567,13 +796,26 @@ func (a APIServer) HandlerPatchInstance(c *gin.Context) {
 //	@Tags			Clusters
 //	@Accept			json
 //	@Produce		json
+//	@Param			at	query		string	false	"Point in time (RFC3339 timestamp or YYYY-MM-DD date) for obtaining the Clusters as they were at that moment"
 //	@Success		200	{object}	ClusterListResponse
+//	@Failure		400	{object}	GenericErrorResponse
 //	@Failure		500	{object}	GenericErrorResponse
 //	@Router			/clusters [get]
 func (a APIServer) HandlerGetClusters(c *gin.Context) {
-	a.logger.Debug("Retrieving complete clusters inventory")
+	at, err := parseAtQuery(c)
+	if err != nil {
+		c.PureJSON(http.StatusBadRequest, NewGenericErrorResponse(err.Error()))
+		return
+	}
 
-	clusters, err := a.sql.GetClusters()
+	var clusters []inventory.Cluster
+	if at != nil {
+		a.logger.Debug("Retrieving clusters inventory at a point in time", zap.Time("at", *at))
+		clusters, err = a.sql.GetClustersAt(*at)
+	} else {
+		a.logger.Debug("Retrieving complete clusters inventory")
+		clusters, err = a.sql.GetClusters()
+	}
 	if err != nil {
 		a.logger.Error("Can't retrieve Clusters list", zap.Error(err))
 		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
@@ -633,6 +875,31 @@ func (a APIServer) HandlerGetInstancesOnCluster(c *gin.Context) {
 	c.PureJSON(http.StatusOK, NewInstanceListResponse(instances))
 }
 
+// HandlerGetResourcesOnCluster handles the request for obtain the list of non-compute Resources belonging to a specific Cluster
+//
+//	@Summary		Obtain Resources list belonging to a Cluster
+//	@Description	Returns a list of non-compute Resources (volumes, snapshots, IPs...) belonging to a Cluster given by ID
+//	@Tags			Clusters
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_id	path		string	true	"Cluster ID"
+//	@Success		200			{object}	ResourceListResponse
+//	@Failure		500			{object}	GenericErrorResponse
+//	@Router			/clusters/{cluster_id}/resources [get]
+func (a APIServer) HandlerGetResourcesOnCluster(c *gin.Context) {
+	clusterID := c.Param("cluster_id")
+	a.logger.Debug("Retrieving Cluster's Resources", zap.String("cluster_id", clusterID))
+
+	resources, err := a.sql.GetResourcesOnCluster(clusterID)
+	if err != nil {
+		a.logger.Error("Can't retrieve resources on cluster", zap.String("cluster_id", clusterID), zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	c.PureJSON(http.StatusOK, NewResourceListResponse(resources))
+}
+
 // HandlerGetClusterTags handles the request for obtain the list of tags of a Cluster
 //
 //	@Summary		Obtain Cluster Tags
@@ -658,6 +925,155 @@ func (a APIServer) HandlerGetClusterTags(c *gin.Context) {
 	c.PureJSON(http.StatusOK, NewTagListResponse(tags))
 }
 
+// HandlerGetClusterTimeline handles the request for obtain the lifecycle timeline of a Cluster
+//
+//	@Summary		Obtain the lifecycle timeline of a Cluster
+//	@Description	Returns the recorded state transitions of a Cluster (first seen, status changes, console link discovery, termination) merged with the actions and scheduled actions executed on it, ordered by time
+//	@Tags			Clusters
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_id	path		string	true	"Cluster ID"
+//	@Success		200			{object}	ClusterTimelineResponse
+//	@Failure		404			{object}	GenericErrorResponse
+//	@Failure		500			{object}	GenericErrorResponse
+//	@Router			/clusters/{cluster_id}/timeline [get]
+func (a APIServer) HandlerGetClusterTimeline(c *gin.Context) {
+	clusterID := c.Param("cluster_id")
+	a.logger.Debug("Retrieving Cluster timeline", zap.String("cluster_id", clusterID))
+
+	timeline, err := a.sql.GetClusterTimeline(clusterID)
+	if err != nil {
+		a.logger.Error("Can't retrieve Cluster timeline", zap.String("cluster_id", clusterID), zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	// The timeline of removed clusters is kept, so the cluster is only checked when there are no events
+	if len(timeline) == 0 {
+		if _, err := a.sql.GetClusterByID(clusterID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				c.PureJSON(http.StatusNotFound, NewGenericErrorResponse("Cluster not found"))
+				return
+			}
+			a.logger.Error("Can't retrieve cluster", zap.String("cluster_id", clusterID), zap.Error(err))
+			c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse("Can't retrieve cluster"))
+			return
+		}
+	}
+
+	c.PureJSON(http.StatusOK, NewClusterTimelineResponse(clusterID, timeline))
+}
+
+const (
+	// Value of the 'group_by' parameter of HandlerGetClusterExpenses for getting the cost of every service
+	clusterExpensesGroupByService = "service"
+)
+
+// HandlerGetClusterExpenses handles the request for obtain the expenses of a Cluster
+//
+//	@Summary		Obtain the expenses of a Cluster
+//	@Description	Returns the cluster level daily expenses of a Cluster by service (compute, storage, load balancers, data transfer...). With 'group_by=service', it returns the cost of every service instead, where the days without cluster level expenses are covered by the instances expenses
+//	@Tags			Clusters
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_id	path		string	true	"Cluster ID"
+//	@Param			group_by	query		string	false	"Grouping of the expenses. Only 'service' is supported"
+//	@Param			month		query		string	false	"Month of the expenses (YYYY-MM)"
+//	@Success		200			{object}	ClusterExpenseListResponse
+//	@Success		200			{object}	ClusterServiceCostsResponse
+//	@Failure		400			{object}	GenericErrorResponse
+//	@Failure		404			{object}	GenericErrorResponse
+//	@Failure		500			{object}	GenericErrorResponse
+//	@Router			/clusters/{cluster_id}/expenses [get]
+func (a APIServer) HandlerGetClusterExpenses(c *gin.Context) {
+	clusterID := c.Param("cluster_id")
+	groupBy := c.Query("group_by")
+	a.logger.Debug("Retrieving Cluster expenses", zap.String("cluster_id", clusterID), zap.String("group_by", groupBy))
+
+	if groupBy != "" && groupBy != clusterExpensesGroupByService {
+		c.PureJSON(http.StatusBadRequest, NewGenericErrorResponse(fmt.Sprintf("invalid 'group_by' value %q: only %q is supported", groupBy, clusterExpensesGroupByService)))
+		return
+	}
+
+	month, from, to, err := parseMonthQuery(c)
+	if err != nil {
+		c.PureJSON(http.StatusBadRequest, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	if _, err := a.sql.GetClusterByID(clusterID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.PureJSON(http.StatusNotFound, NewGenericErrorResponse("Cluster not found"))
+			return
+		}
+		a.logger.Error("Can't retrieve cluster", zap.String("cluster_id", clusterID), zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse("Can't retrieve cluster"))
+		return
+	}
+
+	if groupBy == clusterExpensesGroupByService {
+		costs, err := a.sql.GetClusterServiceCosts(clusterID, from, to)
+		if err != nil {
+			a.logger.Error("Can't retrieve Cluster service costs", zap.String("cluster_id", clusterID), zap.Error(err))
+			c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+			return
+		}
+		c.PureJSON(http.StatusOK, NewClusterServiceCostsResponse(clusterID, month, costs))
+		return
+	}
+
+	expenses, err := a.sql.GetClusterExpenses(clusterID, from, to)
+	if err != nil {
+		a.logger.Error("Can't retrieve Cluster expenses", zap.String("cluster_id", clusterID), zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+	c.PureJSON(http.StatusOK, NewClusterExpenseListResponse(clusterID, month, expenses))
+}
+
+// HandlerGetClusterForecast handles the request for obtain the cost forecast of a Cluster
+//
+//	@Summary		Obtain the cost forecast of a Cluster
+//	@Description	Returns the projected end of month and next month costs of a Cluster, based on its daily costs of the last weeks. The linear projection follows the trend of the daily costs, and the day of week projection the average cost of every day of the week. The Terminated clusters don't have projected costs
+//	@Tags			Clusters
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_id	path		string	true	"Cluster ID"
+//	@Success		200			{object}	ClusterForecastResponse
+//	@Failure		404			{object}	GenericErrorResponse
+//	@Failure		500			{object}	GenericErrorResponse
+//	@Router			/clusters/{cluster_id}/forecast [get]
+func (a APIServer) HandlerGetClusterForecast(c *gin.Context) {
+	clusterID := c.Param("cluster_id")
+	a.logger.Debug("Retrieving Cluster forecast", zap.String("cluster_id", clusterID))
+
+	clusters, err := a.sql.GetClusterByID(clusterID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.PureJSON(http.StatusNotFound, NewGenericErrorResponse("Cluster not found"))
+			return
+		}
+		a.logger.Error("Can't retrieve cluster", zap.String("cluster_id", clusterID), zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse("Can't retrieve cluster"))
+		return
+	}
+
+	clusterForecast, err := newForecast(time.Now(), func(from time.Time, to time.Time) ([]forecast.DailyCost, error) {
+		return a.sql.GetClusterDailyCosts(clusterID, from, to)
+	})
+	if err != nil {
+		a.logger.Error("Can't retrieve Cluster forecast", zap.String("cluster_id", clusterID), zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+	// The known costs of the current month are kept, but a Terminated cluster doesn't generate more costs
+	if clusters[0].Status == inventory.Terminated {
+		clusterForecast = clusterForecast.WithoutProjection()
+	}
+
+	c.PureJSON(http.StatusOK, NewClusterForecastResponse(clusterID, clusterForecast))
+}
+
 // HandlerPostCluster handles the request for writing a new Cluster in the inventory
 //
 //	@Summary		Creates a new Cluster in the inventory
@@ -1015,6 +1431,77 @@ func (a APIServer) HandlerGetClustersOnAccount(c *gin.Context) {
 	c.PureJSON(http.StatusOK, NewClusterListResponse(clusters))
 }
 
+// HandlerGetAccountScans handles the request for obtain the latest scans of an Account
+//
+//	@Summary		Obtain the latest scans of an Account
+//	@Description	Returns the result of the latest scans of an Account, from the newest: start and end timestamps, scanned and failed regions, errors and number of elements found. The accounts failing on their first scan are reported even if they're not on the inventory
+//	@Tags			Accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			account_name	path		string	true	"Account Name"
+//	@Param			limit			query		int		false	"Maximum number of scans (default 50, max 500)"
+//	@Success		200				{object}	AccountScanListResponse
+//	@Failure		400				{object}	GenericErrorResponse
+//	@Failure		500				{object}	GenericErrorResponse
+//	@Router			/accounts/{account_name}/scans [get]
+func (a APIServer) HandlerGetAccountScans(c *gin.Context) {
+	accountName := c.Param("account_name")
+	a.logger.Debug("Retrieving Account's scans", zap.String("account_name", accountName))
+
+	limit, err := parseLimitQuery(c, defaultScansLimit, maxScansLimit)
+	if err != nil {
+		c.PureJSON(http.StatusBadRequest, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	accountScans, err := a.sql.GetAccountScans(accountName, limit)
+	if err != nil {
+		a.logger.Error("Can't retrieve scans of account", zap.String("account_name", accountName), zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	c.PureJSON(http.StatusOK, NewAccountScanListResponse(accountScans))
+}
+
+// HandlerGetAccountForecast handles the request for obtain the cost forecast of an Account
+//
+//	@Summary		Obtain the cost forecast of an Account
+//	@Description	Returns the projected end of month and next month costs of an Account, based on the daily costs of its active clusters of the last weeks. The linear projection follows the trend of the daily costs, and the day of week projection the average cost of every day of the week. The Terminated and Missing clusters are not included
+//	@Tags			Accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			account_name	path		string	true	"Account Name"
+//	@Success		200				{object}	AccountForecastResponse
+//	@Failure		404				{object}	GenericErrorResponse
+//	@Failure		500				{object}	GenericErrorResponse
+//	@Router			/accounts/{account_name}/forecast [get]
+func (a APIServer) HandlerGetAccountForecast(c *gin.Context) {
+	accountName := c.Param("account_name")
+	a.logger.Debug("Retrieving Account forecast", zap.String("account_name", accountName))
+
+	if _, err := a.sql.GetAccountByName(accountName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.PureJSON(http.StatusNotFound, NewGenericErrorResponse("Account not found"))
+			return
+		}
+		a.logger.Error("Can't retrieve account", zap.String("account_name", accountName), zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse("Can't retrieve account"))
+		return
+	}
+
+	accountForecast, err := newForecast(time.Now(), func(from time.Time, to time.Time) ([]forecast.DailyCost, error) {
+		return a.sql.GetAccountDailyCosts(accountName, from, to)
+	})
+	if err != nil {
+		a.logger.Error("Can't retrieve Account forecast", zap.String("account_name", accountName), zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	c.PureJSON(http.StatusOK, NewAccountForecastResponse(accountName, accountForecast))
+}
+
 // HandlerPostAccount handles the request for writing a new Account in the inventory
 //
 //	@Summary		Creates a new Account in the inventory
@@ -1096,14 +1583,303 @@ func (a APIServer) HandlerPatchAccount(c *gin.Context) {
 	c.PureJSON(http.StatusNotImplemented, nil)
 }
 
+// ==================== Scans         Handlers ====================
+
+// scanRequestBody is the optional body of the on-demand scan requests
+type scanRequestBody struct {
+	TriggeredBy string `json:"triggered_by"`
+}
+
+// HandlerScanAccount handles the request for scanning a single Account on demand
+//
+//	@Summary		Scan an Account
+//	@Description	Requests the Scanner to scan only the specified Account. Returns a job whose status can be polled
+//	@Tags			Scans
+//	@Accept			json
+//	@Produce		json
+//	@Param			account_name	path		string			true	"Account Name"
+//	@Param			request			body		scanRequestBody	false	"Who requested the scan"
+//	@Success		202				{object}	scan.Job
+//	@Failure		400				{object}	GenericErrorResponse
+//	@Failure		409				{object}	GenericErrorResponse
+//	@Failure		503				{object}	GenericErrorResponse
+//	@Router			/accounts/{account_name}/scan [post]
+func (a APIServer) HandlerScanAccount(c *gin.Context) {
+	accountName := c.Param("account_name")
+
+	var body scanRequestBody
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.PureJSON(http.StatusBadRequest, NewGenericErrorResponse("Invalid request body"))
+			return
+		}
+	}
+
+	a.logger.Debug("Account scan request received", zap.String("account_name", accountName))
+	a.requestScan(c, scan.Request{
+		AccountName: accountName,
+		TriggeredBy: body.TriggeredBy,
+	})
+}
+
+// HandlerScanCluster handles the request for scanning a single Cluster on demand
+//
+//	@Summary		Scan a Cluster
+//	@Description	Requests the Scanner to scan only the region of the specified Cluster, and update only that Cluster. Returns a job whose status can be polled
+//	@Tags			Scans
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_id	path		string			true	"Cluster ID"
+//	@Param			request		body		scanRequestBody	false	"Who requested the scan"
+//	@Success		202			{object}	scan.Job
+//	@Failure		400			{object}	GenericErrorResponse
+//	@Failure		404			{object}	GenericErrorResponse
+//	@Failure		409			{object}	GenericErrorResponse
+//	@Failure		500			{object}	GenericErrorResponse
+//	@Failure		503			{object}	GenericErrorResponse
+//	@Router			/clusters/{cluster_id}/scan [post]
+func (a APIServer) HandlerScanCluster(c *gin.Context) {
+	clusterID := c.Param("cluster_id")
+
+	var body scanRequestBody
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.PureJSON(http.StatusBadRequest, NewGenericErrorResponse("Invalid request body"))
+			return
+		}
+	}
+
+	a.logger.Debug("Cluster scan request received", zap.String("cluster_id", clusterID))
+
+	// The scan is limited to the cluster account and region
+	clusters, err := a.sql.GetClusterByID(clusterID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.PureJSON(http.StatusNotFound, NewGenericErrorResponse("Cluster not found"))
+			return
+		}
+		a.logger.Error("Can't retrieve cluster", zap.String("cluster_id", clusterID), zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse("Can't retrieve cluster"))
+		return
+	}
+
+	a.requestScan(c, scan.Request{
+		AccountName: clusters[0].AccountName,
+		ClusterID:   clusterID,
+		Region:      clusters[0].Region,
+		TriggeredBy: body.TriggeredBy,
+	})
+}
+
+// requestScan sends the scan request to the Scanner and writes the new scan job as response
+func (a APIServer) requestScan(c *gin.Context, request scan.Request) {
+	job, err := a.scanner.TriggerScan(request)
+	if err != nil {
+		a.logger.Error("Can't request scan",
+			zap.String("account_name", request.AccountName),
+			zap.String("cluster_id", request.ClusterID),
+			zap.Error(err))
+		if errors.Is(err, ErrScanAlreadyRunning) {
+			c.PureJSON(http.StatusConflict, NewGenericErrorResponse(err.Error()))
+			return
+		}
+		c.PureJSON(http.StatusServiceUnavailable, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	c.PureJSON(http.StatusAccepted, job)
+}
+
+// HandlerGetScanJob handles the request for polling the status of an on-demand scan
+//
+//	@Summary		Get scan job status
+//	@Description	Returns the status of a scan job requested to the Scanner
+//	@Tags			Scans
+//	@Accept			json
+//	@Produce		json
+//	@Param			job_id	path		string	true	"Scan Job ID"
+//	@Success		200		{object}	scan.Job
+//	@Failure		404		{object}	GenericErrorResponse
+//	@Failure		503		{object}	GenericErrorResponse
+//	@Router			/scans/{job_id} [get]
+func (a APIServer) HandlerGetScanJob(c *gin.Context) {
+	jobID := c.Param("job_id")
+
+	job, err := a.scanner.GetScanJob(jobID)
+	if err != nil {
+		a.logger.Error("Can't get scan job", zap.String("job_id", jobID), zap.Error(err))
+		if errors.Is(err, ErrScanJobNotFound) {
+			c.PureJSON(http.StatusNotFound, NewGenericErrorResponse(err.Error()))
+			return
+		}
+		c.PureJSON(http.StatusServiceUnavailable, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	c.PureJSON(http.StatusOK, job)
+}
+
+// ==================== Scan Sessions Handlers ====================
+
+const (
+	// Default and maximum number of scan sessions or account scans returned
+	// by HandlerGetScanSessions and HandlerGetAccountScans
+	defaultScansLimit = 50
+	maxScansLimit     = 500
+)
+
+// HandlerPostScanSession handles the request for applying the results of a scan into the inventory
+//
+//	@Summary		Applies a scan into the inventory
+//	@Description	Receives the inventory scanned by the Scanner, compares it with the DB content in the scope of the scan, and writes only the added and updated elements in a single transaction. The clusters of the failed regions of the partially scanned accounts are kept as they are. The changes are recorded as a new scan session
+//	@Tags			Scan Sessions
+//	@Accept			json
+//	@Produce		json
+//	@Param			session	body		scan.SessionRequest	true	"Scan scope and scanned inventory"
+//	@Success		200		{object}	models.ScanSession
+//	@Failure		400		{object}	GenericErrorResponse
+//	@Failure		500		{object}	GenericErrorResponse
+//	@Router			/scan_sessions [post]
+func (a APIServer) HandlerPostScanSession(c *gin.Context) {
+	var request scan.SessionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		a.logger.Error("Can't obtain data from body request", zap.Error(err))
+		c.PureJSON(http.StatusBadRequest, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	if err := request.Scope.Validate(); err != nil {
+		c.PureJSON(http.StatusBadRequest, NewGenericErrorResponse(err.Error()))
+		return
+	}
+	if request.Inventory.Version != inventory.InventorySnapshotVersion {
+		c.PureJSON(http.StatusBadRequest, NewGenericErrorResponse(fmt.Sprintf("unsupported inventory snapshot version: %d", request.Inventory.Version)))
+		return
+	}
+
+	// Only the scanned accounts are compared; the rest of the inventory is not modified
+	accountNames := make([]string, 0, len(request.Inventory.Accounts))
+	for _, account := range request.Inventory.Accounts {
+		accountNames = append(accountNames, account.Name)
+	}
+
+	scanned := inventory.NewInventoryStateFromSnapshot(request.Inventory, request.Scope.ClusterID)
+	current, err := a.sql.GetInventoryState(accountNames, request.Scope.ClusterID, scanned.Expenses, scanned.ClusterExpenses)
+	if err != nil {
+		a.logger.Error("Can't retrieve current inventory state", zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	// The elements of the failed regions of the partially scanned accounts couldn't be checked
+	failedRegions := make(map[string][]string)
+	for _, accountScan := range request.AccountScans {
+		if accountScan.Status == models.AccountScanPartial {
+			failedRegions[accountScan.AccountName] = accountScan.FailedRegions
+		}
+	}
+	scanned.KeepFailedRegions(current, failedRegions)
+
+	diff := inventory.DiffInventory(current, scanned)
+	session := models.ScanSession{
+		SessionTimestamp: time.Now().UTC(),
+		TriggeredBy:      request.Scope.TriggeredBy,
+		AccountName:      request.Scope.AccountName,
+		ClusterID:        request.Scope.ClusterID,
+		ChangeSummary:    diff.Summary(),
+	}
+
+	session.ID, err = a.sql.WriteScanSession(session, diff, request.AccountScans)
+	if err != nil {
+		a.logger.Error("Can't write scan session into DB", zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	a.logger.Info("Scan session applied",
+		zap.Int64("session_id", session.ID),
+		zap.String("account_name", session.AccountName),
+		zap.String("cluster_id", session.ClusterID),
+		zap.Int("added", session.Added),
+		zap.Int("updated", session.Updated),
+		zap.Int("disappeared", session.Disappeared),
+		zap.Int("unchanged", session.Unchanged))
+	c.PureJSON(http.StatusOK, session)
+}
+
+// HandlerGetScanSessions handles the request for obtain the list of the latest scan sessions
+//
+//	@Summary		Obtain the latest scan sessions
+//	@Description	Returns the latest scan sessions with their change summary, from the newest
+//	@Tags			Scan Sessions
+//	@Accept			json
+//	@Produce		json
+//	@Param			limit	query		int	false	"Maximum number of sessions (default 50, max 500)"
+//	@Success		200		{object}	ScanSessionListResponse
+//	@Failure		400		{object}	GenericErrorResponse
+//	@Failure		500		{object}	GenericErrorResponse
+//	@Router			/scan_sessions [get]
+func (a APIServer) HandlerGetScanSessions(c *gin.Context) {
+	limit, err := parseLimitQuery(c, defaultScansLimit, maxScansLimit)
+	if err != nil {
+		c.PureJSON(http.StatusBadRequest, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	sessions, err := a.sql.GetScanSessions(limit)
+	if err != nil {
+		a.logger.Error("Can't retrieve scan sessions list", zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	c.PureJSON(http.StatusOK, NewScanSessionListResponse(sessions))
+}
+
+// HandlerGetScanSessionByID handles the request for obtain a scan session and its changes
+//
+//	@Summary		Obtain a scan session
+//	@Description	Returns a scan session with the list of the elements added, updated and disappeared on it
+//	@Tags			Scan Sessions
+//	@Accept			json
+//	@Produce		json
+//	@Param			session_id	path		int	true	"Scan Session ID"
+//	@Success		200			{object}	models.ScanSession
+//	@Failure		400			{object}	GenericErrorResponse
+//	@Failure		404			{object}	GenericErrorResponse
+//	@Failure		500			{object}	GenericErrorResponse
+//	@Router			/scan_sessions/{session_id} [get]
+func (a APIServer) HandlerGetScanSessionByID(c *gin.Context) {
+	sessionID, err := strconv.ParseInt(c.Param("session_id"), 10, 64)
+	if err != nil {
+		c.PureJSON(http.StatusBadRequest, NewGenericErrorResponse("Invalid scan session ID"))
+		return
+	}
+
+	sessions, err := a.sql.GetScanSessionByID(sessionID)
+	if err != nil {
+		a.logger.Error("Can't retrieve scan session", zap.Int64("session_id", sessionID), zap.Error(err))
+		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
+		return
+	}
+	if len(sessions) == 0 {
+		c.PureJSON(http.StatusNotFound, NewGenericErrorResponse("Scan session not found"))
+		return
+	}
+
+	c.PureJSON(http.StatusOK, sessions[0])
+}
+
 // ==================== Extra      Handlers ====================
 
 // HandlerRefreshInventory handles the request for refreshing the entire
 // inventory just after a full scan. This method is used for recalculating some
-// values and mark the missing clusters as "terminated"
+// values and mark the clusters not found by the last successful scan of their
+// accounts as "missing", and as "terminated" after the grace period. The costs
+// of the accounts without billing information are estimated as well
 //
 //	@Summary		Refresh data on inventory
-//	@Description	Recalculating some values and mark the missing clusters as "terminated"
+//	@Description	Recalculating some values, mark the missing clusters as "missing" or "terminated" and estimate the costs without billing information
 //	@Tags			Inventory
 //	@Accept			json
 //	@Produce		json
@@ -1111,11 +1887,15 @@ func (a APIServer) HandlerPatchAccount(c *gin.Context) {
 //	@Failure		500	{object}	nil
 //	@Router			/inventory/refresh [post]
 func (a APIServer) HandlerRefreshInventory(c *gin.Context) {
-	if err := a.sql.RefreshInventory(); err != nil {
+	if err := a.sql.RefreshInventory(a.cfg.TerminationGracePeriod); err != nil {
 		a.logger.Error("Can't refresh inventory data on DB", zap.Error(err))
 		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse(err.Error()))
 		return
 	}
+	// The inventory is already refreshed, so the estimation errors are only logged
+	if err := a.estimateCosts(); err != nil {
+		a.logger.Error("Can't estimate the costs without billing information", zap.Error(err))
+	}
 	// This function doesn't return any 200OK code for preventing duplicated responses
 }
 
@@ -1178,14 +1958,28 @@ func (a APIServer) HandlerGetClusterEvents(c *gin.Context) {
 //	@Tags			Overview
 //	@Accept			json
 //	@Produce		json
+//	@Param			at			query		string	false	"Point in time (RFC3339 timestamp or YYYY-MM-DD date) for obtaining the overview of the inventory at that moment"
 //	@Success		200			{object}	models.OverviewSummary
+//	@Failure		400			{object}	GenericErrorResponse
 //	@Failure		500			{object}	GenericErrorResponse
 //	@Router			/overview	[get]
 func (a APIServer) HandlerGetInventoryOverview(c *gin.Context) {
 	a.logger.Debug("Retrieving overview data")
 
-	overview, err := a.getInventoryOverview()
+	at, err := parseAtQuery(c)
 	if err != nil {
+		c.PureJSON(http.StatusBadRequest, NewGenericErrorResponse(err.Error()))
+		return
+	}
+
+	var overview models.OverviewSummary
+	if at != nil {
+		overview, err = a.getInventoryOverviewAt(*at)
+	} else {
+		overview, err = a.getInventoryOverview()
+	}
+	if err != nil {
+		a.logger.Error("Can't retrieve inventory overview", zap.Error(err))
 		c.PureJSON(http.StatusInternalServerError, NewGenericErrorResponse("failed to retrieve inventory overview"))
 		return
 	}
@@ -1222,7 +2016,143 @@ func (a APIServer) getInventoryOverview() (models.OverviewSummary, error) {
 	if err != nil {
 		return models.OverviewSummary{}, fmt.Errorf("failed to get scanner last scan timestamp: %w", err)
 	}
-	overview.Scanner = models.Scanner{LastScanTimestamp: scannerLastScan}
+
+	// Get the last scan of every account
+	accountScans, err := a.sql.GetLatestAccountScans(nil)
+	if err != nil {
+		return models.OverviewSummary{}, fmt.Errorf("failed to get latest account scans: %w", err)
+	}
+	overview.Scanner = newScannerSummary(scannerLastScan, accountScans)
+
+	// Get the cost forecast of the whole inventory
+	overview.Forecast, err = newForecast(time.Now(), a.sql.GetInventoryDailyCosts)
+	if err != nil {
+		return models.OverviewSummary{}, fmt.Errorf("failed to get inventory forecast: %w", err)
+	}
+
+	return overview, nil
+}
+
+// getInventoryOverviewAt retrieves the inventory overview at a point in time,
+// based on the inventory history.
+func (a APIServer) getInventoryOverviewAt(at time.Time) (models.OverviewSummary, error) {
+	var overview models.OverviewSummary
+
+	clusters, err := a.sql.GetClustersOverviewAt(at)
+	if err != nil {
+		return models.OverviewSummary{}, fmt.Errorf("failed to get clusters overview: %w", err)
+	}
+	overview.Clusters = clusters
+
+	instances, err := a.sql.GetInstancesOverviewAt(at)
+	if err != nil {
+		return models.OverviewSummary{}, fmt.Errorf("failed to get instances overview: %w", err)
+	}
+	overview.Instances = instances
+
+	providers, err := a.sql.GetProvidersOverviewAt(at)
+	if err != nil {
+		return models.OverviewSummary{}, fmt.Errorf("failed to get providers overview: %w", err)
+	}
+	overview.Providers = providers
+
+	scannerLastScan, err := a.sql.GetScannerLastScanTimestampAt(at)
+	if err != nil {
+		return models.OverviewSummary{}, fmt.Errorf("failed to get scanner last scan timestamp: %w", err)
+	}
+
+	accountScans, err := a.sql.GetLatestAccountScans(&at)
+	if err != nil {
+		return models.OverviewSummary{}, fmt.Errorf("failed to get latest account scans: %w", err)
+	}
+	overview.Scanner = newScannerSummary(scannerLastScan, accountScans)
+
+	overview.Forecast, err = newForecast(at, a.sql.GetInventoryDailyCosts)
+	if err != nil {
+		return models.OverviewSummary{}, fmt.Errorf("failed to get inventory forecast: %w", err)
+	}
 
 	return overview, nil
 }
+
+// newForecast projects the costs on the specified date from the daily costs
+// returned by getDailyCosts for the days needed by the forecast
+func newForecast(now time.Time, getDailyCosts func(from time.Time, to time.Time) ([]forecast.DailyCost, error)) (forecast.Forecast, error) {
+	costs, err := getDailyCosts(forecast.HistoryStart(now), now)
+	if err != nil {
+		return forecast.Forecast{}, err
+	}
+	return forecast.NewForecast(costs, now), nil
+}
+
+// newScannerSummary builds the scanner section of the inventory overview from
+// the last scan of every account
+func newScannerSummary(lastScanTimestamp *time.Time, accountScans []models.AccountScan) models.Scanner {
+	scanner := models.Scanner{
+		LastScanTimestamp: lastScanTimestamp,
+		Accounts:          accountScans,
+	}
+	if scanner.Accounts == nil {
+		scanner.Accounts = []models.AccountScan{}
+	}
+
+	for _, accountScan := range accountScans {
+		if accountScan.Status == models.AccountScanFailed {
+			scanner.FailedAccounts++
+		}
+	}
+	return scanner
+}
+
+// parseLimitQuery reads the optional 'limit' query parameter. Returns the
+// default value if the parameter is not set, and caps it to the maximum value
+func parseLimitQuery(c *gin.Context, defaultLimit int, maxLimit int) (int, error) {
+	value := c.Query("limit")
+	if value == "" {
+		return defaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("limit must be a positive integer")
+	}
+	return min(limit, maxLimit), nil
+}
+
+// parseMonthQuery reads the optional 'month' query parameter (YYYY-MM).
+// Returns the month and its first day and the first day of the next month
+// for filtering the dates, or nil dates if the parameter is not set
+func parseMonthQuery(c *gin.Context) (string, *time.Time, *time.Time, error) {
+	value := c.Query("month")
+	if value == "" {
+		return "", nil, nil, nil
+	}
+
+	from, err := time.Parse("2006-01", value)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("invalid 'month' value %q: expected a YYYY-MM month", value)
+	}
+	to := from.AddDate(0, 1, 0)
+	return value, &from, &to, nil
+}
+
+// parseAtQuery reads the optional 'at' query parameter of the point-in-time
+// queries. It accepts RFC3339 timestamps and dates (YYYY-MM-DD), which refer
+// to the end of that day (UTC). Returns nil if the parameter is not set
+func parseAtQuery(c *gin.Context) (*time.Time, error) {
+	value := c.Query("at")
+	if value == "" {
+		return nil, nil
+	}
+
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return &at, nil
+	}
+
+	day, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'at' value %q: expected an RFC3339 timestamp or a YYYY-MM-DD date", value)
+	}
+	at := day.Add(24*time.Hour - time.Nanosecond)
+	return &at, nil
+}
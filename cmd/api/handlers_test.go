@@ -0,0 +1,646 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/actions"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/config"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/events"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/forecast"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/models"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/scan"
+	sqlclient "github.com/RHEcosystemAppEng/cluster-iq/internal/sql_client"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// newTestAPIServer returns an APIServer with its routes configured, backed by
+// a mocked DB and by the specified Scanner daemon URL
+func newTestAPIServer(t *testing.T, scannerURL string) (*APIServer, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cannot create the DB mock: %v", err)
+	}
+	t.Cleanup(func() {
+		assert.Nil(t, mock.ExpectationsWereMet())
+		db.Close()
+	})
+
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+	api := &APIServer{
+		cfg:     &config.APIServerConfig{},
+		logger:  logger,
+		router:  gin.New(),
+		scanner: NewAPIScannerClient(scannerURL, logger),
+		sql:     sqlclient.NewSQLClientWithDB(sqlx.NewDb(db, "postgres"), logger),
+	}
+	NewRouter(api).SetupRoutes()
+
+	return api, mock
+}
+
+// serveRequest sends a request to the APIServer router and returns the recorded response
+func serveRequest(api *APIServer, method string, target string, body string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	request := httptest.NewRequest(method, target, reader)
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	api.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// expectQuery registers the expected execution of a query on the DB mock
+func expectQuery(mock sqlmock.Sqlmock, query string) *sqlmock.ExpectedQuery {
+	return mock.ExpectQuery(regexp.QuoteMeta(query))
+}
+
+// TestHandlerScanCluster tests the cluster scan is requested on the cluster account and region
+func TestHandlerScanCluster(t *testing.T) {
+	var request scan.Request
+	scanner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(scan.Job{ID: "job-1", Request: request, Status: scan.JobPending})
+	}))
+	defer scanner.Close()
+
+	api, mock := newTestAPIServer(t, scanner.URL)
+	expectQuery(mock, sqlclient.SelectClustersByIDuery).
+		WithArgs("ocp-a1b2c-account").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_name", "region"}).AddRow("ocp-a1b2c-account", "account", "eu-west-1"))
+
+	response := serveRequest(api, http.MethodPost, "/api/v1/clusters/ocp-a1b2c-account/scan", `{"triggered_by":"user"}`)
+
+	assert.Equal(t, http.StatusAccepted, response.Code)
+	assert.Equal(t, scan.Request{AccountName: "account", ClusterID: "ocp-a1b2c-account", Region: "eu-west-1", TriggeredBy: "user"}, request)
+}
+
+// TestHandlerScanClusterErrors tests unknown clusters return 404 and the DB errors return 500 without requesting any scan
+func TestHandlerScanClusterErrors(t *testing.T) {
+	scanner := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("unexpected scan request")
+	}))
+	defer scanner.Close()
+
+	api, mock := newTestAPIServer(t, scanner.URL)
+	expectQuery(mock, sqlclient.SelectClustersByIDuery).WithArgs("unknown").WillReturnError(sql.ErrNoRows)
+	expectQuery(mock, sqlclient.SelectClustersByIDuery).WithArgs("ocp-a1b2c-account").WillReturnError(errors.New("connection refused"))
+
+	response := serveRequest(api, http.MethodPost, "/api/v1/clusters/unknown/scan", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = serveRequest(api, http.MethodPost, "/api/v1/clusters/ocp-a1b2c-account/scan", "")
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+	assert.NotContains(t, response.Body.String(), "connection refused")
+}
+
+// expectRefreshInventory registers the queries of a successful inventory refresh on the DB mock
+func expectRefreshInventory(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	for _, query := range []string{
+		sqlclient.SetChangeSourceQuery,
+		sqlclient.UpdateMissingInstancesQuery,
+		sqlclient.UpdateMissingClustersQuery,
+		sqlclient.UpdateTerminatedInstancesQuery,
+		sqlclient.UpdateTerminatedClustersQuery,
+		sqlclient.DeleteOutdatedResourcesQuery,
+		sqlclient.InsertRefreshedInstancesHistoryQuery,
+		sqlclient.InsertRefreshedClustersHistoryQuery,
+	} {
+		mock.ExpectExec(regexp.QuoteMeta(query)).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	mock.ExpectCommit()
+}
+
+// TestHandlerRefreshInventoryEstimationError tests the costs estimation errors don't fail the refreshed inventory
+func TestHandlerRefreshInventoryEstimationError(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	expectRefreshInventory(mock)
+	expectQuery(mock, sqlclient.SelectUnbilledInstancesSamplesQuery).WillReturnError(errors.New("connection reset"))
+
+	response := serveRequest(api, http.MethodPost, "/api/v1/inventory/refresh", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+// TestHandlerRefreshInventoryError tests the refresh errors are returned
+func TestHandlerRefreshInventoryError(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(sqlclient.SetChangeSourceQuery)).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	response := serveRequest(api, http.MethodPost, "/api/v1/inventory/refresh", "")
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+// TestHandlerGetClusterExpenses tests the cluster level daily expenses are returned filtered by month
+func TestHandlerGetClusterExpenses(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	expectQuery(mock, sqlclient.SelectClustersByIDuery).
+		WithArgs("ocp-a1b2c-account").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("ocp-a1b2c-account"))
+	expectQuery(mock, sqlclient.SelectClusterExpensesQuery).
+		WithArgs("ocp-a1b2c-account", from, from.AddDate(0, 1, 0)).
+		WillReturnRows(sqlmock.NewRows([]string{"cluster_id", "service", "date", "amount"}).
+			AddRow("ocp-a1b2c-account", "Amazon Elastic Compute Cloud - Compute", from, 10.5).
+			AddRow("ocp-a1b2c-account", "Amazon Simple Storage Service", from, 0.25))
+
+	response := serveRequest(api, http.MethodGet, "/api/v1/clusters/ocp-a1b2c-account/expenses?month=2024-05", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var body ClusterExpenseListResponse
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Equal(t, "2024-05", body.Month)
+	assert.Equal(t, 10.75, body.TotalCost)
+	assert.Equal(t, 2, body.Count)
+	assert.Equal(t, "Amazon Simple Storage Service", body.Expenses[1].Service)
+}
+
+// TestHandlerGetClusterExpensesByService tests 'group_by=service' returns the cost of every service of the whole history
+func TestHandlerGetClusterExpensesByService(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	expectQuery(mock, sqlclient.SelectClustersByIDuery).
+		WithArgs("ocp-a1b2c-account").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("ocp-a1b2c-account"))
+	expectQuery(mock, sqlclient.SelectClusterServiceCostsQuery).
+		WithArgs("ocp-a1b2c-account", nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"service", "amount"}).
+			AddRow("Amazon Elastic Compute Cloud - Compute", 100.5).
+			AddRow("Instances", 20))
+
+	response := serveRequest(api, http.MethodGet, "/api/v1/clusters/ocp-a1b2c-account/expenses?group_by=service", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var body ClusterServiceCostsResponse
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Empty(t, body.Month)
+	assert.Equal(t, 120.5, body.TotalCost)
+	assert.Equal(t, []models.ServiceCost{
+		{Service: "Amazon Elastic Compute Cloud - Compute", Amount: 100.5},
+		{Service: "Instances", Amount: 20},
+	}, body.Services)
+}
+
+// TestHandlerGetClusterExpensesErrors tests the invalid parameters return 400 without querying the DB, the unknown clusters return 404 and the DB errors of the cluster return 500
+func TestHandlerGetClusterExpensesErrors(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	expectQuery(mock, sqlclient.SelectClustersByIDuery).WithArgs("unknown").WillReturnError(sql.ErrNoRows)
+	expectQuery(mock, sqlclient.SelectClustersByIDuery).WithArgs("ocp-a1b2c-account").WillReturnError(errors.New("connection refused"))
+
+	response := serveRequest(api, http.MethodGet, "/api/v1/clusters/ocp-a1b2c-account/expenses?group_by=account", "")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	response = serveRequest(api, http.MethodGet, "/api/v1/clusters/ocp-a1b2c-account/expenses?month=May", "")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	response = serveRequest(api, http.MethodGet, "/api/v1/clusters/unknown/expenses", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = serveRequest(api, http.MethodGet, "/api/v1/clusters/ocp-a1b2c-account/expenses", "")
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+// newDailyCostsRows returns the daily costs rows of the history days before today, all of them with the same amount
+func newDailyCostsRows(amount float64) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"date", "amount"})
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for date := today.AddDate(0, 0, -forecast.HistoryDays); date.Before(today); date = date.AddDate(0, 0, 1) {
+		rows.AddRow(date, amount)
+	}
+	return rows
+}
+
+// assertConstantForecast checks the projections of a history of constant daily costs
+func assertConstantForecast(t *testing.T, amount float64, result forecast.Forecast) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthDays := monthStart.AddDate(0, 1, -1).Day()
+	nextMonthDays := monthStart.AddDate(0, 2, -1).Day()
+
+	assert.Equal(t, forecast.HistoryDays, result.HistoryDays)
+	if assert.NotNil(t, result.LastCostDate) {
+		assert.Equal(t, today.AddDate(0, 0, -1), result.LastCostDate.UTC())
+	}
+	assert.InDelta(t, amount*float64(today.Day()-1), result.CurrentMonthSoFarCost, 0.001)
+	assert.InDelta(t, amount*float64(monthDays), result.Linear.EndOfMonthCost, 0.001)
+	assert.InDelta(t, amount*float64(nextMonthDays), result.Linear.NextMonthCost, 0.001)
+	assert.InDelta(t, amount*float64(monthDays), result.DayOfWeek.EndOfMonthCost, 0.001)
+	assert.InDelta(t, amount*float64(nextMonthDays), result.DayOfWeek.NextMonthCost, 0.001)
+}
+
+// TestHandlerGetClusterForecast tests the cluster forecast is projected from its daily costs of the history days
+func TestHandlerGetClusterForecast(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	expectQuery(mock, sqlclient.SelectClustersByIDuery).
+		WithArgs("ocp-a1b2c-account").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("ocp-a1b2c-account"))
+	expectQuery(mock, sqlclient.SelectClusterDailyCostsQuery).
+		WithArgs("ocp-a1b2c-account", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(newDailyCostsRows(10))
+
+	response := serveRequest(api, http.MethodGet, "/api/v1/clusters/ocp-a1b2c-account/forecast", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var body ClusterForecastResponse
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Equal(t, "ocp-a1b2c-account", body.ClusterID)
+	assertConstantForecast(t, 10, body.Forecast)
+}
+
+// TestHandlerGetClusterForecastTerminated tests the Terminated clusters keep their known costs of the current month without projected costs
+func TestHandlerGetClusterForecastTerminated(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	expectQuery(mock, sqlclient.SelectClustersByIDuery).
+		WithArgs("ocp-a1b2c-account").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow("ocp-a1b2c-account", inventory.Terminated))
+	expectQuery(mock, sqlclient.SelectClusterDailyCostsQuery).
+		WithArgs("ocp-a1b2c-account", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(newDailyCostsRows(10))
+
+	response := serveRequest(api, http.MethodGet, "/api/v1/clusters/ocp-a1b2c-account/forecast", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var body ClusterForecastResponse
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &body))
+	monthSoFarCost := body.Forecast.CurrentMonthSoFarCost
+	assert.Equal(t, forecast.Projection{EndOfMonthCost: monthSoFarCost}, body.Forecast.Linear)
+	assert.Equal(t, forecast.Projection{EndOfMonthCost: monthSoFarCost}, body.Forecast.DayOfWeek)
+}
+
+// TestHandlerGetAccountForecast tests the account forecast is projected from the daily costs of its clusters, and the unknown accounts return 404
+func TestHandlerGetAccountForecast(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	expectQuery(mock, sqlclient.SelectAccountsByNameQuery).
+		WithArgs("account").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("account"))
+	expectQuery(mock, sqlclient.SelectAccountDailyCostsQuery).
+		WithArgs("account", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(newDailyCostsRows(2.5))
+	expectQuery(mock, sqlclient.SelectAccountsByNameQuery).WithArgs("unknown").WillReturnError(sql.ErrNoRows)
+
+	response := serveRequest(api, http.MethodGet, "/api/v1/accounts/account/forecast", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var body AccountForecastResponse
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Equal(t, "account", body.AccountName)
+	assertConstantForecast(t, 2.5, body.Forecast)
+
+	response = serveRequest(api, http.MethodGet, "/api/v1/accounts/unknown/forecast", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+// TestHandlerGetClusterForecastError tests the unknown clusters return 404, and the DB errors of the cluster and the daily costs return 500
+func TestHandlerGetClusterForecastError(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	expectQuery(mock, sqlclient.SelectClustersByIDuery).WithArgs("unknown").WillReturnError(sql.ErrNoRows)
+	response := serveRequest(api, http.MethodGet, "/api/v1/clusters/unknown/forecast", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	expectQuery(mock, sqlclient.SelectClustersByIDuery).WithArgs("ocp-a1b2c-account").WillReturnError(errors.New("connection refused"))
+	response = serveRequest(api, http.MethodGet, "/api/v1/clusters/ocp-a1b2c-account/forecast", "")
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+
+	expectQuery(mock, sqlclient.SelectClustersByIDuery).
+		WithArgs("ocp-a1b2c-account").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("ocp-a1b2c-account"))
+	expectQuery(mock, sqlclient.SelectClusterDailyCostsQuery).WillReturnError(errors.New("connection refused"))
+	response = serveRequest(api, http.MethodGet, "/api/v1/clusters/ocp-a1b2c-account/forecast", "")
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+// TestHandlerGetAccountForecastError tests the DB errors of the account return 500
+func TestHandlerGetAccountForecastError(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	expectQuery(mock, sqlclient.SelectAccountsByNameQuery).WithArgs("account").WillReturnError(errors.New("connection refused"))
+
+	response := serveRequest(api, http.MethodGet, "/api/v1/accounts/account/forecast", "")
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+// TestNewForecastHistory tests the daily costs are requested for the history days before the forecast date
+func TestNewForecastHistory(t *testing.T) {
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	var from, to time.Time
+	_, err := newForecast(now, func(f time.Time, t time.Time) ([]forecast.DailyCost, error) {
+		from, to = f, t
+		return nil, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, forecast.HistoryStart(now), from)
+	assert.Equal(t, now, to)
+}
+
+// TestParseAtQuery tests the 'at' parameter accepts RFC3339 timestamps and dates, which refer to the end of the day
+func TestParseAtQuery(t *testing.T) {
+	parse := func(target string) (*time.Time, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		return parseAtQuery(c)
+	}
+
+	at, err := parse("/clusters")
+	assert.Nil(t, err)
+	assert.Nil(t, at)
+
+	at, err = parse("/clusters?at=2025-03-01T10:30:00Z")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC), at.UTC())
+
+	at, err = parse("/clusters?at=2025-03-01")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2025, 3, 1, 23, 59, 59, 999999999, time.UTC), *at)
+
+	_, err = parse("/clusters?at=yesterday")
+	assert.NotNil(t, err)
+}
+
+// TestHandlerGetClustersAt tests the clusters are obtained from the history at the end of the requested day, and the invalid dates return 400
+func TestHandlerGetClustersAt(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	expectQuery(mock, sqlclient.SelectClustersAtQuery).
+		WithArgs(time.Date(2025, 3, 1, 23, 59, 59, 999999999, time.UTC)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "status"}).AddRow("ocp-a1b2c-account", "ocp", "Missing"))
+
+	response := serveRequest(api, http.MethodGet, "/api/v1/clusters?at=2025-03-01", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"status":"Missing"`)
+
+	response = serveRequest(api, http.MethodGet, "/api/v1/clusters?at=March", "")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+// TestHandlerPostScanSessionBadRequest tests the invalid bodies, scopes and snapshot versions return 400 without reading the inventory
+func TestHandlerPostScanSessionBadRequest(t *testing.T) {
+	api, _ := newTestAPIServer(t, "")
+
+	for _, body := range []string{
+		`{"scope":`,
+		`{"scope": {"cluster_id": "ocp-a1b2c-account"}, "inventory": {"version": 1}}`,
+		`{"scope": {}, "inventory": {"version": 99}}`,
+	} {
+		response := serveRequest(api, http.MethodPost, "/api/v1/scan_sessions", body)
+		assert.Equal(t, http.StatusBadRequest, response.Code, body)
+	}
+}
+
+// TestHandlerGetScanSessions tests the latest scan sessions are requested with the default limit, and the invalid limits return 400
+func TestHandlerGetScanSessions(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	expectQuery(mock, sqlclient.SelectScanSessionsQuery).
+		WithArgs(defaultScansLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "triggered_by", "added"}).AddRow(7, "scanner", 3))
+
+	response := serveRequest(api, http.MethodGet, "/api/v1/scan_sessions", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"triggeredBy":"scanner"`)
+
+	response = serveRequest(api, http.MethodGet, "/api/v1/scan_sessions?limit=none", "")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+// TestHandlerGetScanSessionByID tests the scan sessions are returned with their changes, the unknown sessions return 404 and the invalid IDs return 400
+func TestHandlerGetScanSessionByID(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	expectQuery(mock, sqlclient.SelectScanSessionByIDQuery).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "updated"}).AddRow(7, 1))
+	expectQuery(mock, sqlclient.SelectScanSessionChangesQuery).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"session_id", "element_type", "element_id", "change"}).AddRow(7, "cluster", "ocp-a1b2c-account", "Updated"))
+	expectQuery(mock, sqlclient.SelectScanSessionByIDQuery).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	response := serveRequest(api, http.MethodGet, "/api/v1/scan_sessions/7", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"elementID":"ocp-a1b2c-account"`)
+
+	response = serveRequest(api, http.MethodGet, "/api/v1/scan_sessions/8", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = serveRequest(api, http.MethodGet, "/api/v1/scan_sessions/latest", "")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+// TestHandlerGetClusterTimeline tests the timeline events are returned, and the clusters without events return 404 when they don't exist and 500 when they can't be retrieved
+func TestHandlerGetClusterTimeline(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	expectQuery(mock, sqlclient.SelectClusterTimelineQuery).
+		WithArgs("ocp-a1b2c-account").
+		WillReturnRows(sqlmock.NewRows([]string{"event_timestamp", "category", "event_type", "source"}).
+			AddRow(time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC), "ScheduledAction", "PowerOffCluster", "agent"))
+	expectQuery(mock, sqlclient.SelectClusterTimelineQuery).
+		WithArgs("unknown").
+		WillReturnRows(sqlmock.NewRows([]string{"event_timestamp"}))
+	expectQuery(mock, sqlclient.SelectClustersByIDuery).WithArgs("unknown").WillReturnError(sql.ErrNoRows)
+	expectQuery(mock, sqlclient.SelectClusterTimelineQuery).
+		WithArgs("unreachable").
+		WillReturnRows(sqlmock.NewRows([]string{"event_timestamp"}))
+	expectQuery(mock, sqlclient.SelectClustersByIDuery).WithArgs("unreachable").WillReturnError(errors.New("connection refused"))
+
+	response := serveRequest(api, http.MethodGet, "/api/v1/clusters/ocp-a1b2c-account/timeline", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"category":"ScheduledAction"`)
+
+	response = serveRequest(api, http.MethodGet, "/api/v1/clusters/unknown/timeline", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = serveRequest(api, http.MethodGet, "/api/v1/clusters/unreachable/timeline", "")
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+// TestParseLimitQuery tests the 'limit' parameter defaults to the default limit, is capped to the maximum limit, and rejects the non positive and non numeric values
+func TestParseLimitQuery(t *testing.T) {
+	parse := func(target string) (int, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		return parseLimitQuery(c, defaultScansLimit, maxScansLimit)
+	}
+
+	limit, err := parse("/scans")
+	assert.Nil(t, err)
+	assert.Equal(t, defaultScansLimit, limit)
+
+	limit, err = parse("/scans?limit=10")
+	assert.Nil(t, err)
+	assert.Equal(t, 10, limit)
+
+	limit, err = parse("/scans?limit=5000")
+	assert.Nil(t, err)
+	assert.Equal(t, maxScansLimit, limit)
+
+	for _, value := range []string{"-1", "0", "ten"} {
+		_, err = parse("/scans?limit=" + value)
+		assert.NotNil(t, err, value)
+	}
+}
+
+// TestHandlerGetAccountScans tests the scans of an account are requested with the capped limit, and the invalid limits return 400 without querying the DB
+func TestHandlerGetAccountScans(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	expectQuery(mock, sqlclient.SelectAccountScansQuery).
+		WithArgs("account", maxScansLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_name", "status"}).AddRow(3, "account", "Partial"))
+
+	response := serveRequest(api, http.MethodGet, "/api/v1/accounts/account/scans?limit=1000", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"status":"Partial"`)
+
+	response = serveRequest(api, http.MethodGet, "/api/v1/accounts/account/scans?limit=-5", "")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+// TestNewScannerSummary tests only the accounts whose last scan failed are counted as failed, and the accounts list is never null
+func TestNewScannerSummary(t *testing.T) {
+	lastScan := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	accountScans := []models.AccountScan{
+		{AccountName: "a", Status: models.AccountScanSuccess},
+		{AccountName: "b", Status: models.AccountScanFailed},
+		{AccountName: "c", Status: models.AccountScanPartial},
+		{AccountName: "d", Status: models.AccountScanFailed},
+	}
+
+	scanner := newScannerSummary(&lastScan, accountScans)
+	assert.Equal(t, &lastScan, scanner.LastScanTimestamp)
+	assert.Equal(t, 2, scanner.FailedAccounts)
+	assert.Equal(t, accountScans, scanner.Accounts)
+
+	scanner = newScannerSummary(nil, nil)
+	assert.Equal(t, 0, scanner.FailedAccounts)
+	assert.NotNil(t, scanner.Accounts)
+	data, err := json.Marshal(scanner)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"accounts":[]`)
+}
+
+// fakeEventClient records the audit events logged by the APIServer
+type fakeEventClient struct {
+	events []models.AuditLog
+}
+
+func (f *fakeEventClient) AddEvent(event models.AuditLog) (int64, error) {
+	f.events = append(f.events, event)
+	return int64(len(f.events)), nil
+}
+
+func (f *fakeEventClient) UpdateEventStatus(_ int64, _ string) error {
+	return nil
+}
+
+// useFakeEventClient sets a fake event client on the APIServer. The routes
+// are configured again, as the handlers are bound to a copy of the APIServer
+func useFakeEventClient(api *APIServer) *fakeEventClient {
+	eventClient := &fakeEventClient{}
+	api.eventService = events.NewEventService(eventClient, api.logger)
+	api.router = gin.New()
+	NewRouter(api).SetupRoutes()
+	return eventClient
+}
+
+// expectOrphanResources registers the known orphaned resources on the DB mock
+func expectOrphanResources(mock sqlmock.Sqlmock, orphans ...inventory.Resource) {
+	rows := sqlmock.NewRows([]string{"id", "type", "cluster_id"})
+	for _, orphan := range orphans {
+		rows.AddRow(orphan.ID, orphan.Type, orphan.ClusterID)
+	}
+	expectQuery(mock, sqlclient.SelectOrphanResourcesQuery).WillReturnRows(rows)
+	if len(orphans) > 0 {
+		expectQuery(mock, sqlclient.SelectResourcesTagsQuery).WillReturnRows(sqlmock.NewRows([]string{"key", "value", "instance_id"}))
+	}
+}
+
+// TestHandlerPostOrphans tests the new orphans are written and notified, and the known orphans of the checked clusters that were not found anymore are removed and notified as resolved
+func TestHandlerPostOrphans(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	eventClient := useFakeEventClient(api)
+
+	expectOrphanResources(mock,
+		inventory.Resource{ID: "vol-1", Type: inventory.VolumeResourceType, ClusterID: "checked"},
+		inventory.Resource{ID: "sg-1", Type: inventory.SecurityGroupResourceType, ClusterID: "checked"},
+		inventory.Resource{ID: "snap-1", Type: inventory.SnapshotResourceType, ClusterID: "checked"},
+		inventory.Resource{ID: "vol-2", Type: inventory.VolumeResourceType, ClusterID: "unchecked"},
+	)
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO resources").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(sqlclient.DeleteResourcesTagsQuery)).WithArgs(pq.Array([]string{"vol-1", "lb-1"})).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(sqlclient.DeleteResourcesQuery)).WithArgs(pq.Array([]string{"sg-1"})).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	body := `{"cluster_ids":["checked"],"orphans":[{"id":"vol-1","type":"Volume","clusterID":"checked"},{"id":"lb-1","type":"LoadBalancer","clusterID":"checked"}]}`
+	response := serveRequest(api, http.MethodPost, "/api/v1/orphans", body)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	if assert.Len(t, eventClient.events, 2) {
+		assert.Equal(t, actions.ActionOperation(inventory.ClusterOrphanedResourcesAction), eventClient.events[0].ActionName)
+		assert.Equal(t, "checked", eventClient.events[0].ResourceID)
+		assert.Equal(t, "Orphaned resources detected: LoadBalancer(lb-1)", *eventClient.events[0].Description)
+		assert.Equal(t, events.SeverityWarning, eventClient.events[0].Severity)
+
+		assert.Equal(t, actions.ActionOperation(inventory.ClusterOrphanedResourcesResolvedAction), eventClient.events[1].ActionName)
+		assert.Equal(t, "checked", eventClient.events[1].ResourceID)
+		assert.Equal(t, "Orphaned resources removed: SecurityGroup(sg-1)", *eventClient.events[1].Description)
+		assert.Equal(t, events.SeverityInfo, eventClient.events[1].Severity)
+	}
+}
+
+// TestHandlerPostOrphansUnchanged tests no event is logged when the known orphans are found again
+func TestHandlerPostOrphansUnchanged(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+	eventClient := useFakeEventClient(api)
+
+	expectOrphanResources(mock, inventory.Resource{ID: "vol-1", Type: inventory.VolumeResourceType, ClusterID: "checked"})
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO resources").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(sqlclient.DeleteResourcesTagsQuery)).WithArgs(pq.Array([]string{"vol-1"})).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	body := `{"cluster_ids":["checked"],"orphans":[{"id":"vol-1","type":"Volume","clusterID":"checked"}]}`
+	response := serveRequest(api, http.MethodPost, "/api/v1/orphans", body)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Empty(t, eventClient.events)
+}
+
+// TestHandlerPostOrphansErrors tests the invalid bodies are rejected, the empty requests are ignored and the DB errors are returned
+func TestHandlerPostOrphansErrors(t *testing.T) {
+	api, mock := newTestAPIServer(t, "")
+
+	response := serveRequest(api, http.MethodPost, "/api/v1/orphans", `{"cluster_ids":`)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	response = serveRequest(api, http.MethodPost, "/api/v1/orphans", `{"cluster_ids":[],"orphans":[]}`)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	expectQuery(mock, sqlclient.SelectOrphanResourcesQuery).WillReturnError(errors.New("connection reset"))
+	response = serveRequest(api, http.MethodPost, "/api/v1/orphans", `{"cluster_ids":["checked"]}`)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+
+	expectOrphanResources(mock, inventory.Resource{ID: "vol-1", Type: inventory.VolumeResourceType, ClusterID: "checked"})
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(sqlclient.DeleteResourcesQuery)).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+	response = serveRequest(api, http.MethodPost, "/api/v1/orphans", `{"cluster_ids":["checked"]}`)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
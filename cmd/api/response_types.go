@@ -5,7 +5,9 @@ import (
 
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/actions"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/events"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/forecast"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/models"
 )
 
 type ScheduledActionListResponse struct {
@@ -180,6 +182,39 @@ func NewInstanceListResponse(instances []inventory.Instance) *InstanceListRespon
 	return &response
 }
 
+// ResourceListResponse represents the API response containing a list of non-compute resources.
+type ResourceListResponse struct {
+	Count     int                  `json:"count,omitempty"` // Number of resources, omitted if empty.
+	Resources []inventory.Resource `json:"resources"`       // List of resources.
+}
+
+// NewResourceListResponse creates a new ResourceListResponse instance.
+// It ensures that an empty array is returned if the input resource list is empty.
+//
+// Parameters:
+// - resources: A slice of inventory.Resource.
+//
+// Returns:
+// - A pointer to a ResourceListResponse.
+func NewResourceListResponse(resources []inventory.Resource) *ResourceListResponse {
+	numResources := len(resources)
+
+	// If there is no resources, an empty array is returned instead of null
+	if numResources == 0 {
+		resources = []inventory.Resource{}
+	}
+
+	response := ResourceListResponse{
+		Resources: resources,
+	}
+	// If there is more than one resource, the response contains a 'count' field
+	if numResources > 1 {
+		response.Count = numResources
+	}
+
+	return &response
+}
+
 // ClusterListResponse represents the API response containing a list of clusters
 type ClusterListResponse struct {
 	Count    int                 `json:"count,omitempty"` // Number of clusters, omitted if empty.
@@ -302,3 +337,216 @@ func NewEventsListResponse(auditEvents []events.AuditEvent) *EventsListResponse
 	}
 	return &response
 }
+
+// ScanSessionListResponse represents the API response containing a list of scan sessions.
+type ScanSessionListResponse struct {
+	Count    int                  `json:"count,omitempty"` // Number of scan sessions, omitted if empty.
+	Sessions []models.ScanSession `json:"sessions"`        // List of scan sessions.
+}
+
+// NewScanSessionListResponse creates a new ScanSessionListResponse instance.
+// It ensures that an empty array is returned if the input scan session list is empty.
+//
+// Parameters:
+// - sessions: A slice of models.ScanSession.
+//
+// Returns:
+// - A pointer to a ScanSessionListResponse.
+func NewScanSessionListResponse(sessions []models.ScanSession) *ScanSessionListResponse {
+	numSessions := len(sessions)
+
+	// If there is no sessions, an empty array is returned instead of null
+	if numSessions == 0 {
+		sessions = []models.ScanSession{}
+	}
+
+	response := ScanSessionListResponse{
+		Sessions: sessions,
+	}
+	// If there is more than one session, the response contains a 'count' field
+	if numSessions > 1 {
+		response.Count = numSessions
+	}
+
+	return &response
+}
+
+// AccountScanListResponse represents the API response containing a list of account scans.
+type AccountScanListResponse struct {
+	Count int                  `json:"count,omitempty"` // Number of account scans, omitted if empty.
+	Scans []models.AccountScan `json:"scans"`           // List of account scans.
+}
+
+// NewAccountScanListResponse creates a new AccountScanListResponse instance.
+// It ensures that an empty array is returned if the input account scan list is empty.
+//
+// Parameters:
+// - accountScans: A slice of models.AccountScan.
+//
+// Returns:
+// - A pointer to an AccountScanListResponse.
+func NewAccountScanListResponse(accountScans []models.AccountScan) *AccountScanListResponse {
+	numScans := len(accountScans)
+
+	// If there is no scans, an empty array is returned instead of null
+	if numScans == 0 {
+		accountScans = []models.AccountScan{}
+	}
+
+	response := AccountScanListResponse{
+		Scans: accountScans,
+	}
+	// If there is more than one scan, the response contains a 'count' field
+	if numScans > 1 {
+		response.Count = numScans
+	}
+
+	return &response
+}
+
+// ClusterTimelineResponse represents the API response containing the lifecycle timeline of a cluster.
+type ClusterTimelineResponse struct {
+	ClusterID string                        `json:"clusterID"`       // ID of the cluster.
+	Count     int                           `json:"count,omitempty"` // Number of timeline events, omitted if empty.
+	Events    []models.ClusterTimelineEvent `json:"events"`          // Timeline events, from the oldest.
+}
+
+// NewClusterTimelineResponse creates a new ClusterTimelineResponse instance.
+// It ensures that an empty array is returned if the input event list is empty.
+//
+// Parameters:
+// - clusterID: ID of the cluster.
+// - timelineEvents: A slice of models.ClusterTimelineEvent.
+//
+// Returns:
+// - A pointer to a ClusterTimelineResponse.
+func NewClusterTimelineResponse(clusterID string, timelineEvents []models.ClusterTimelineEvent) *ClusterTimelineResponse {
+	// If there is no events, an empty array is returned instead of null
+	if len(timelineEvents) == 0 {
+		timelineEvents = []models.ClusterTimelineEvent{}
+	}
+
+	return &ClusterTimelineResponse{
+		ClusterID: clusterID,
+		Count:     len(timelineEvents),
+		Events:    timelineEvents,
+	}
+}
+
+// ClusterExpenseListResponse represents the API response containing the cluster level expenses of a cluster.
+type ClusterExpenseListResponse struct {
+	ClusterID string                     `json:"clusterID"`       // ID of the cluster.
+	Month     string                     `json:"month,omitempty"` // Month of the expenses (YYYY-MM), omitted if not filtered.
+	TotalCost float64                    `json:"totalCost"`       // Sum of the expenses.
+	Count     int                        `json:"count,omitempty"` // Number of expenses, omitted if empty.
+	Expenses  []inventory.ClusterExpense `json:"expenses"`        // Daily expenses by service.
+}
+
+// NewClusterExpenseListResponse creates a new ClusterExpenseListResponse instance.
+// It ensures that an empty array is returned if the input expense list is empty.
+//
+// Parameters:
+// - clusterID: ID of the cluster.
+// - month: Month of the expenses (YYYY-MM), or empty if they're not filtered.
+// - expenses: A slice of inventory.ClusterExpense.
+//
+// Returns:
+// - A pointer to a ClusterExpenseListResponse.
+func NewClusterExpenseListResponse(clusterID string, month string, expenses []inventory.ClusterExpense) *ClusterExpenseListResponse {
+	// If there is no expenses, an empty array is returned instead of null
+	if len(expenses) == 0 {
+		expenses = []inventory.ClusterExpense{}
+	}
+
+	response := ClusterExpenseListResponse{
+		ClusterID: clusterID,
+		Month:     month,
+		Count:     len(expenses),
+		Expenses:  expenses,
+	}
+	for _, expense := range expenses {
+		response.TotalCost += expense.Amount
+	}
+
+	return &response
+}
+
+// ClusterServiceCostsResponse represents the API response containing the cost of every service of a cluster.
+type ClusterServiceCostsResponse struct {
+	ClusterID string               `json:"clusterID"`       // ID of the cluster.
+	Month     string               `json:"month,omitempty"` // Month of the costs (YYYY-MM), omitted if not filtered.
+	TotalCost float64              `json:"totalCost"`       // Sum of the services costs.
+	Count     int                  `json:"count,omitempty"` // Number of services, omitted if empty.
+	Services  []models.ServiceCost `json:"services"`        // Cost of every service, from the most expensive.
+}
+
+// NewClusterServiceCostsResponse creates a new ClusterServiceCostsResponse instance.
+// It ensures that an empty array is returned if the input costs list is empty.
+//
+// Parameters:
+// - clusterID: ID of the cluster.
+// - month: Month of the costs (YYYY-MM), or empty if they're not filtered.
+// - costs: A slice of models.ServiceCost.
+//
+// Returns:
+// - A pointer to a ClusterServiceCostsResponse.
+func NewClusterServiceCostsResponse(clusterID string, month string, costs []models.ServiceCost) *ClusterServiceCostsResponse {
+	// If there is no costs, an empty array is returned instead of null
+	if len(costs) == 0 {
+		costs = []models.ServiceCost{}
+	}
+
+	response := ClusterServiceCostsResponse{
+		ClusterID: clusterID,
+		Month:     month,
+		Count:     len(costs),
+		Services:  costs,
+	}
+	for _, cost := range costs {
+		response.TotalCost += cost.Amount
+	}
+
+	return &response
+}
+
+// ClusterForecastResponse represents the API response containing the cost forecast of a cluster.
+type ClusterForecastResponse struct {
+	ClusterID string `json:"clusterID"` // ID of the cluster.
+	forecast.Forecast
+}
+
+// NewClusterForecastResponse creates a new ClusterForecastResponse instance.
+//
+// Parameters:
+// - clusterID: ID of the cluster.
+// - clusterForecast: Cost forecast of the cluster.
+//
+// Returns:
+// - A pointer to a ClusterForecastResponse.
+func NewClusterForecastResponse(clusterID string, clusterForecast forecast.Forecast) *ClusterForecastResponse {
+	return &ClusterForecastResponse{
+		ClusterID: clusterID,
+		Forecast:  clusterForecast,
+	}
+}
+
+// AccountForecastResponse represents the API response containing the cost forecast of an account.
+type AccountForecastResponse struct {
+	AccountName string `json:"accountName"` // Name of the account.
+	forecast.Forecast
+}
+
+// NewAccountForecastResponse creates a new AccountForecastResponse instance.
+//
+// Parameters:
+// - accountName: Name of the account.
+// - accountForecast: Cost forecast of the account.
+//
+// Returns:
+// - A pointer to an AccountForecastResponse.
+func NewAccountForecastResponse(accountName string, accountForecast forecast.Forecast) *AccountForecastResponse {
+	return &AccountForecastResponse{
+		AccountName: accountName,
+		Forecast:    accountForecast,
+	}
+}
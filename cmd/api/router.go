@@ -23,11 +23,15 @@ func (r *Router) SetupRoutes() {
 	r.setupScheduledActionsRoutes(baseGroup)
 	r.setupExpensesRoutes(baseGroup)
 	r.setupInstancesRoutes(baseGroup)
+	r.setupResourcesRoutes(baseGroup)
+	r.setupOrphansRoutes(baseGroup)
 	r.setupClustersRoutes(baseGroup)
 	r.setupAccountsRoutes(baseGroup)
 	r.setupEventsRoutes(baseGroup)
 	r.setupOverviewRoutes(baseGroup)
 	r.setupInventoryRoutes(baseGroup)
+	r.setupScansRoutes(baseGroup)
+	r.setupScanSessionsRoutes(baseGroup)
 }
 
 func (r *Router) setupHealthcheckRoutes(baseGroup *gin.RouterGroup) {
@@ -64,16 +68,33 @@ func (r *Router) setupInstancesRoutes(baseGroup *gin.RouterGroup) {
 	instancesGroup.PATCH("/:instance_id", r.api.HandlerPatchInstance)
 }
 
+func (r *Router) setupResourcesRoutes(baseGroup *gin.RouterGroup) {
+	resourcesGroup := baseGroup.Group("/resources")
+	resourcesGroup.GET("", r.api.HandlerGetResources)
+	resourcesGroup.POST("", r.api.HandlerPostResource)
+}
+
+func (r *Router) setupOrphansRoutes(baseGroup *gin.RouterGroup) {
+	orphansGroup := baseGroup.Group("/orphans")
+	orphansGroup.GET("", r.api.HandlerGetOrphans)
+	orphansGroup.POST("", r.api.HandlerPostOrphans)
+}
+
 func (r *Router) setupClustersRoutes(baseGroup *gin.RouterGroup) {
 	clustersGroup := baseGroup.Group("/clusters")
 	clustersGroup.GET("", r.api.HandlerGetClusters)
 	clustersGroup.GET("/:cluster_id", r.api.HandlerGetClustersByID)
 	clustersGroup.GET("/:cluster_id/instances", r.api.HandlerGetInstancesOnCluster)
+	clustersGroup.GET("/:cluster_id/resources", r.api.HandlerGetResourcesOnCluster)
 	clustersGroup.GET("/:cluster_id/tags", r.api.HandlerGetClusterTags)
 	clustersGroup.GET("/:cluster_id/events", r.api.HandlerGetClusterEvents)
+	clustersGroup.GET("/:cluster_id/timeline", r.api.HandlerGetClusterTimeline)
+	clustersGroup.GET("/:cluster_id/expenses", r.api.HandlerGetClusterExpenses)
+	clustersGroup.GET("/:cluster_id/forecast", r.api.HandlerGetClusterForecast)
 	clustersGroup.POST("", r.api.HandlerPostCluster)
 	clustersGroup.POST("/:cluster_id/power_on", r.api.HandlerPowerOnCluster)
 	clustersGroup.POST("/:cluster_id/power_off", r.api.HandlerPowerOffCluster)
+	clustersGroup.POST("/:cluster_id/scan", r.api.HandlerScanCluster)
 	clustersGroup.DELETE("/:cluster_id", r.api.HandlerDeleteCluster)
 	clustersGroup.PATCH("/:cluster_id", r.api.HandlerPatchCluster)
 }
@@ -83,7 +104,10 @@ func (r *Router) setupAccountsRoutes(baseGroup *gin.RouterGroup) {
 	accountsGroup.GET("", r.api.HandlerGetAccounts)
 	accountsGroup.GET("/:account_name", r.api.HandlerGetAccountsByName)
 	accountsGroup.GET("/:account_name/clusters", r.api.HandlerGetClustersOnAccount)
+	accountsGroup.GET("/:account_name/scans", r.api.HandlerGetAccountScans)
+	accountsGroup.GET("/:account_name/forecast", r.api.HandlerGetAccountForecast)
 	accountsGroup.POST("", r.api.HandlerPostAccount)
+	accountsGroup.POST("/:account_name/scan", r.api.HandlerScanAccount)
 	accountsGroup.DELETE("/:account_name", r.api.HandlerDeleteAccount)
 	accountsGroup.PATCH("/:account_name", r.api.HandlerPatchAccount)
 }
@@ -101,3 +125,15 @@ func (r *Router) setupInventoryRoutes(baseGroup *gin.RouterGroup) {
 func (r *Router) setupEventsRoutes(baseGroup *gin.RouterGroup) {
 	baseGroup.GET("/events", r.api.HandlerGetSystemEvents)
 }
+
+func (r *Router) setupScansRoutes(baseGroup *gin.RouterGroup) {
+	scansGroup := baseGroup.Group("/scans")
+	scansGroup.GET("/:job_id", r.api.HandlerGetScanJob)
+}
+
+func (r *Router) setupScanSessionsRoutes(baseGroup *gin.RouterGroup) {
+	scanSessionsGroup := baseGroup.Group("/scan_sessions")
+	scanSessionsGroup.GET("", r.api.HandlerGetScanSessions)
+	scanSessionsGroup.GET("/:session_id", r.api.HandlerGetScanSessionByID)
+	scanSessionsGroup.POST("", r.api.HandlerPostScanSession)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/scan"
+	"go.uber.org/zap"
+)
+
+const (
+	// scannerTimeoutSeconds defines the timeout in seconds for the requests to the Scanner daemon
+	scannerTimeoutSeconds = 10
+	// Scanner daemon endpoints
+	scannerScanPath    = "/scan"
+	scannerScanJobPath = "/scan/jobs"
+)
+
+var (
+	// ErrScanAlreadyRunning is returned when the Scanner rejects a scan because there's another one running
+	ErrScanAlreadyRunning = errors.New("a scan is already running")
+	// ErrScanJobNotFound is returned when the Scanner doesn't know the requested scan job
+	ErrScanJobNotFound = errors.New("scan job not found")
+)
+
+// APIScannerClient manages the requests from the API to the Scanner daemon
+// for running on-demand scans and polling their status.
+type APIScannerClient struct {
+	// url is the base URL of the Scanner daemon
+	url string
+	// client is the HTTP client used for the Scanner requests
+	client http.Client
+	// logger is used for logging the Scanner requests and errors
+	logger *zap.Logger
+}
+
+// NewAPIScannerClient initializes and returns a new APIScannerClient.
+//
+// Parameters:
+// - scannerURL: The URL of the Scanner daemon.
+// - logger: Logger instance for logging.
+//
+// Returns:
+// - A pointer to the initialized APIScannerClient.
+func NewAPIScannerClient(scannerURL string, logger *zap.Logger) *APIScannerClient {
+	return &APIScannerClient{
+		url:    scannerURL,
+		client: http.Client{Timeout: scannerTimeoutSeconds * time.Second},
+		logger: logger,
+	}
+}
+
+// TriggerScan requests a new scan job to the Scanner daemon.
+//
+// Parameters:
+// - request: Scope of the scan.
+//
+// Returns:
+// - The scan job created by the Scanner.
+// - ErrScanAlreadyRunning if there's another scan running, or an error if the request fails.
+func (s *APIScannerClient) TriggerScan(request scan.Request) (*scan.Job, error) {
+	b, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	httpRequest, err := http.NewRequestWithContext(context.Background(), http.MethodPost, s.url+scannerScanPath, bytes.NewBuffer(b))
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+
+	s.logger.Debug("Requesting scan to the Scanner",
+		zap.String("account_name", request.AccountName),
+		zap.String("cluster_id", request.ClusterID))
+	return s.doJobRequest(httpRequest, http.StatusAccepted)
+}
+
+// GetScanJob retrieves the status of a scan job from the Scanner daemon.
+//
+// Parameters:
+// - jobID: The ID of the scan job.
+//
+// Returns:
+// - The scan job.
+// - ErrScanJobNotFound if the Scanner doesn't know the job, or an error if the request fails.
+func (s *APIScannerClient) GetScanJob(jobID string) (*scan.Job, error) {
+	httpRequest, err := http.NewRequestWithContext(context.Background(), http.MethodGet, fmt.Sprintf("%s%s/%s", s.url, scannerScanJobPath, url.PathEscape(jobID)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.doJobRequest(httpRequest, http.StatusOK)
+}
+
+// doJobRequest performs a request to the Scanner daemon and decodes the scan job on the response
+func (s *APIScannerClient) doJobRequest(httpRequest *http.Request, expectedStatus int) (*scan.Job, error) {
+	response, err := s.client.Do(httpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach the scanner: %w", err)
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case expectedStatus:
+	case http.StatusConflict:
+		return nil, ErrScanAlreadyRunning
+	case http.StatusNotFound:
+		return nil, ErrScanJobNotFound
+	default:
+		var errorResponse GenericErrorResponse
+		_ = json.NewDecoder(response.Body).Decode(&errorResponse)
+		return nil, fmt.Errorf("scanner returned HTTP error code (%d): %s", response.StatusCode, errorResponse.Message)
+	}
+
+	var job scan.Job
+	if err := json.NewDecoder(response.Body).Decode(&job); err != nil {
+		return nil, fmt.Errorf("cannot decode scan job: %w", err)
+	}
+
+	return &job, nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/events"
 	ciqLogger "github.com/RHEcosystemAppEng/cluster-iq/internal/logger"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/middleware"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/pricing"
 	sqlclient "github.com/RHEcosystemAppEng/cluster-iq/internal/sql_client"
 	ginzap "github.com/gin-contrib/zap"
 	"github.com/gin-gonic/gin"
@@ -46,8 +47,10 @@ type APIServer struct {
 	router       *gin.Engine             // Gin router for handling HTTP requests
 	server       *http.Server            // HTTP server instance
 	grpc         *APIGRPCClient          // gRPC client for communication with external services
+	scanner      *APIScannerClient       // HTTP client for requesting on-demand scans to the Scanner
 	sql          *sqlclient.SQLClient    // SQL client for database operations
 	eventService *events.EventService    // Service for handling audit logs
+	prices       *pricing.PriceTable     // On-demand prices for estimating the costs without billing information
 }
 
 // NewAPIServer initializes a new instance of the APIServer.
@@ -78,6 +81,12 @@ func NewAPIServer(cfg *config.APIServerConfig, logger *zap.Logger) (*APIServer,
 	// Creating Event Service
 	eventService := events.NewEventService(sqlCli, logger)
 
+	// Loading the price table for the costs estimation
+	prices, err := pricing.LoadPriceTable(cfg.PriceTableFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load price table: %w", err)
+	}
+
 	// Creating APIServer
 	apiServer := &APIServer{
 		cfg:    cfg,
@@ -88,8 +97,10 @@ func NewAPIServer(cfg *config.APIServerConfig, logger *zap.Logger) (*APIServer,
 			Handler: engine,
 		},
 		grpc:         gRPCClient,
+		scanner:      NewAPIScannerClient(cfg.ScannerURL, logger),
 		sql:          sqlCli,
 		eventService: eventService,
+		prices:       prices,
 	}
 
 	// Initialize routes
@@ -123,7 +134,10 @@ func (a *APIServer) Start() error {
 		zap.String("commit", commit),
 		zap.String("api_url", a.cfg.ListenURL),
 		zap.String("db_url", a.cfg.DBURL),
-		zap.String("agent_url", a.cfg.AgentURL))
+		zap.String("agent_url", a.cfg.AgentURL),
+		zap.Int("termination_grace_period_hours", a.cfg.TerminationGracePeriod),
+		zap.String("price_table_file", a.cfg.PriceTableFile),
+		zap.Int("prices", a.prices.Len()))
 
 	// Start API
 	go func() {
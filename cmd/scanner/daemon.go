@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/config"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/scan"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// Scanner daemon HTTP endpoints
+	DaemonHealthEndpoint    = "/healthz"
+	DaemonReadinessEndpoint = "/readyz"
+	DaemonScanEndpoint      = "/scan"
+	DaemonScanJobEndpoint   = "/scan/jobs/{job_id}"
+
+	// Scan triggers for the periodic scans
+	ScanTriggerStartup  = "ClusterIQ Scanner (startup)"
+	ScanTriggerInterval = "ClusterIQ Scanner (interval)"
+
+	// Time to wait for the HTTP server to close the open connections
+	daemonShutdownTimeout = 10 * time.Second
+	// Number of finished scan jobs kept for polling their status. The jobs are
+	// only kept in memory, so they're lost when the Scanner restarts
+	maxScanJobs = 100
+)
+
+// ScanStatus describes the scans executed by the ScannerDaemon
+type ScanStatus struct {
+	Running bool      `json:"running"`
+	Runs    int       `json:"runs"`
+	Skipped int       `json:"skipped"`
+	LastJob *scan.Job `json:"last_job,omitempty"`
+}
+
+// ScannerDaemon keeps the Scanner running as a long-lived process. It rescans
+// every account on a fixed interval, and exposes an HTTP server for
+// triggering scans on demand and for the health and readiness probes. Only
+// one scan runs at a time; the scans requested while another is running are
+// skipped
+type ScannerDaemon struct {
+	scanner  *Scanner
+	interval time.Duration
+	server   *http.Server
+	logger   *zap.Logger
+	running  atomic.Bool    // True while a scan is running
+	ready    atomic.Bool    // True when the first scan has finished
+	wg       sync.WaitGroup // Tracks the running scan for the graceful shutdown
+	mutex    sync.RWMutex   // Protects status and jobs
+	status   ScanStatus
+	jobs     map[string]*scan.Job
+	jobIDs   []string // Job IDs sorted by creation for discarding the oldest ones
+}
+
+// NewScannerDaemon creates a ScannerDaemon for running the specified Scanner
+func NewScannerDaemon(scanner *Scanner, cfg *config.ScannerConfig, logger *zap.Logger) *ScannerDaemon {
+	d := &ScannerDaemon{
+		scanner:  scanner,
+		interval: time.Duration(cfg.ScanInterval) * time.Second,
+		logger:   logger,
+		jobs:     make(map[string]*scan.Job),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+DaemonHealthEndpoint, d.handleHealth)
+	mux.HandleFunc("GET "+DaemonReadinessEndpoint, d.handleReadiness)
+	mux.HandleFunc("GET "+DaemonScanEndpoint, d.handleGetScan)
+	mux.HandleFunc("POST "+DaemonScanEndpoint, d.handlePostScan)
+	mux.HandleFunc("GET "+DaemonScanJobEndpoint, d.handleGetScanJob)
+
+	d.server = &http.Server{
+		Addr:              cfg.ListenURL,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return d
+}
+
+// Run starts the HTTP server and the scans loop, and blocks until the context
+// is cancelled. Before returning, it waits for the running scan to finish
+func (d *ScannerDaemon) Run(ctx context.Context) error {
+	if d.interval <= 0 {
+		return fmt.Errorf("invalid scan interval: %s", d.interval)
+	}
+
+	serverErrChan := make(chan error, 1)
+	go func() {
+		d.logger.Info("Scanner daemon listening", zap.String("listen_url", d.server.Addr))
+		if err := d.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErrChan <- err
+		}
+	}()
+
+	d.logger.Info("Scanner daemon started", zap.Duration("scan_interval", d.interval))
+	d.StartScan(scan.Request{TriggeredBy: ScanTriggerStartup})
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.logger.Info("Stopping scanner daemon")
+			d.shutdown()
+			return nil
+		case err := <-serverErrChan:
+			d.shutdown()
+			return fmt.Errorf("scanner daemon HTTP server failed: %w", err)
+		case <-ticker.C:
+			d.StartScan(scan.Request{TriggeredBy: ScanTriggerInterval})
+		}
+	}
+}
+
+// shutdown stops the HTTP server and waits for the running scan
+func (d *ScannerDaemon) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), daemonShutdownTimeout)
+	defer cancel()
+	if err := d.server.Shutdown(ctx); err != nil {
+		d.logger.Error("Failed to stop scanner daemon HTTP server", zap.Error(err))
+	}
+
+	if d.running.Load() {
+		d.logger.Info("Waiting for the running scan to finish")
+	}
+	d.wg.Wait()
+}
+
+// StartScan runs a new scan job in background, unless there's another scan
+// already running.
+//
+// Parameters:
+//   - request: Scope of the scan and who requested it.
+//
+// Returns:
+//   - scan.Job: A copy of the new job.
+//   - bool: True if the scan was started, false if it was skipped.
+func (d *ScannerDaemon) StartScan(request scan.Request) (scan.Job, bool) {
+	if !d.running.CompareAndSwap(false, true) {
+		d.logger.Warn("Previous scan still running; skipping new scan",
+			zap.String("triggered_by", request.TriggeredBy),
+			zap.String("account", request.AccountName),
+			zap.String("cluster_id", request.ClusterID))
+		d.mutex.Lock()
+		d.status.Skipped++
+		d.mutex.Unlock()
+		return scan.Job{}, false
+	}
+
+	job := d.newJob(request)
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer d.running.Store(false)
+		d.runScan(job)
+	}()
+
+	return d.copyJob(job), true
+}
+
+// newJob registers a new pending scan job. The oldest jobs are discarded
+// when there are more than maxScanJobs
+func (d *ScannerDaemon) newJob(request scan.Request) *scan.Job {
+	job := &scan.Job{
+		ID:        uuid.NewString(),
+		Request:   request,
+		Status:    scan.JobPending,
+		CreatedAt: time.Now(),
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.jobs[job.ID] = job
+	d.jobIDs = append(d.jobIDs, job.ID)
+	if len(d.jobIDs) > maxScanJobs {
+		delete(d.jobs, d.jobIDs[0])
+		d.jobIDs = d.jobIDs[1:]
+	}
+
+	return job
+}
+
+// runScan executes the scan job and records its result
+func (d *ScannerDaemon) runScan(job *scan.Job) {
+	start := time.Now()
+	d.mutex.Lock()
+	d.status.Running = true
+	d.status.LastJob = job
+	job.Status = scan.JobRunning
+	job.StartedAt = &start
+	d.mutex.Unlock()
+
+	d.logger.Info("Scan job started",
+		zap.String("job_id", job.ID),
+		zap.String("triggered_by", job.Request.TriggeredBy))
+	err := d.scanner.Scan(job.Request)
+
+	finish := time.Now()
+	d.mutex.Lock()
+	d.status.Running = false
+	d.status.Runs++
+	job.FinishedAt = &finish
+	if err != nil {
+		job.Status = scan.JobFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = scan.JobSuccess
+	}
+	d.mutex.Unlock()
+
+	d.logger.Info("Scan job finished", zap.String("job_id", job.ID), zap.String("status", string(job.Status)))
+	d.ready.Store(true)
+}
+
+// GetJob returns a copy of a scan job, and false if the job doesn't exist
+func (d *ScannerDaemon) GetJob(jobID string) (scan.Job, bool) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	job, ok := d.jobs[jobID]
+	if !ok {
+		return scan.Job{}, false
+	}
+	return *job, true
+}
+
+// copyJob returns a copy of a job, which can be read without holding the mutex
+func (d *ScannerDaemon) copyJob(job *scan.Job) scan.Job {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return *job
+}
+
+// Status returns a copy of the current daemon status
+func (d *ScannerDaemon) Status() ScanStatus {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	status := d.status
+	if status.LastJob != nil {
+		lastJob := *status.LastJob
+		status.LastJob = &lastJob
+	}
+	return status
+}
+
+// handleHealth reports the daemon is alive
+func (d *ScannerDaemon) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReadiness reports the daemon as ready once the first scan has finished
+func (d *ScannerDaemon) handleReadiness(w http.ResponseWriter, _ *http.Request) {
+	if !d.ready.Load() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "waiting for the first scan"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// handleGetScan returns the status of the scans
+func (d *ScannerDaemon) handleGetScan(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, d.Status())
+}
+
+// handlePostScan triggers a new scan job. The optional request body limits
+// the scan scope. It returns 409 if there's a scan running
+func (d *ScannerDaemon) handlePostScan(w http.ResponseWriter, r *http.Request) {
+	var request scan.Request
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": "Invalid request body"})
+			return
+		}
+	}
+
+	if err := request.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+
+	job, ok := d.StartScan(request)
+	if !ok {
+		writeJSON(w, http.StatusConflict, map[string]string{"message": "A scan is already running"})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// handleGetScanJob returns the status of a scan job
+func (d *ScannerDaemon) handleGetScanJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := d.GetJob(r.PathValue("job_id"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Scan job not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// writeJSON writes the response body as JSON
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Error("Failed to write scanner daemon response", zap.Error(err))
+	}
+}
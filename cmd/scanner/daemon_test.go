@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/config"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/scan"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// newTestScannerDaemon returns a ScannerDaemon with a running scan, so no scan is actually executed
+func newTestScannerDaemon(interval time.Duration) *ScannerDaemon {
+	d := NewScannerDaemon(nil, &config.ScannerConfig{ListenURL: "127.0.0.1:0"}, zap.NewNop())
+	d.interval = interval
+	d.running.Store(true)
+	return d
+}
+
+// TestScannerDaemonSkipsTicksWhileRunning verifies the startup and interval scans are skipped while another scan is running
+func TestScannerDaemonSkipsTicksWhileRunning(t *testing.T) {
+	d := newTestScannerDaemon(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.Nil(t, d.Run(ctx))
+
+	status := d.Status()
+	assert.GreaterOrEqual(t, status.Skipped, 3)
+	assert.Equal(t, 0, status.Runs)
+	assert.Nil(t, status.LastJob)
+}
+
+// TestScannerDaemonPostScanWhileRunning verifies the scans requested while another scan is running are rejected with 409 and counted as skipped
+func TestScannerDaemonPostScanWhileRunning(t *testing.T) {
+	d := newTestScannerDaemon(time.Hour)
+
+	job, ok := d.StartScan(scan.Request{TriggeredBy: ScanTriggerInterval})
+	assert.False(t, ok)
+	assert.Empty(t, job.ID)
+
+	recorder := httptest.NewRecorder()
+	d.server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, DaemonScanEndpoint, strings.NewReader(`{"triggered_by":"user"}`)))
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+
+	assert.Equal(t, 2, d.Status().Skipped)
+}
+
+// TestScannerDaemonRunInvalidInterval verifies the daemon rejects the non positive intervals
+func TestScannerDaemonRunInvalidInterval(t *testing.T) {
+	d := newTestScannerDaemon(0)
+	assert.NotNil(t, d.Run(context.Background()))
+}
+
+// TestScannerDaemonReadiness verifies the daemon is ready once its first scan finishes, even if it failed, and the next scans are accepted after it
+func TestScannerDaemonReadiness(t *testing.T) {
+	scanner := NewScanner(&config.ScannerConfig{CloudCredentialsConfig: config.CloudCredentialsConfig{CredentialsFile: filepath.Join(t.TempDir(), "credentials")}}, zap.NewNop())
+	d := NewScannerDaemon(scanner, &config.ScannerConfig{ListenURL: "127.0.0.1:0"}, zap.NewNop())
+	readiness := func() int {
+		recorder := httptest.NewRecorder()
+		d.server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, DaemonReadinessEndpoint, nil))
+		return recorder.Code
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, readiness())
+
+	job, ok := d.StartScan(scan.Request{TriggeredBy: ScanTriggerInterval})
+	assert.True(t, ok)
+	d.wg.Wait()
+
+	finished, ok := d.GetJob(job.ID)
+	assert.True(t, ok)
+	assert.Equal(t, scan.JobFailed, finished.Status)
+	assert.Equal(t, http.StatusOK, readiness())
+
+	_, ok = d.StartScan(scan.Request{TriggeredBy: ScanTriggerInterval})
+	assert.True(t, ok)
+	d.wg.Wait()
+
+	status := d.Status()
+	assert.Equal(t, 2, status.Runs)
+	assert.Equal(t, 0, status.Skipped)
+	assert.False(t, status.Running)
+}
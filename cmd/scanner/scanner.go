@@ -5,20 +5,29 @@ import (
 	"context"
 	"crypto/md5"
 	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
 	"sync"
 	"syscall"
 	"time"
 
+	cpaws "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/config"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/credentials"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
 	ciqLogger "github.com/RHEcosystemAppEng/cluster-iq/internal/logger"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/models"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/scan"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/stocker"
 	"go.uber.org/zap"
 )
@@ -27,15 +36,24 @@ const (
 	APIAccountEndpoint          = "/accounts"
 	APIClusterEndpoint          = "/clusters"
 	APIInstanceEndpoint         = "/instances"
-	APIExpenseEndpoint          = "/expenses"
+	APIOrphanEndpoint           = "/orphans"
 	APIRefreshInventoryEndpoint = "/inventory/refresh"
+	APIScanSessionEndpoint      = "/scan_sessions"
+
+	// Scan triggers recorded on the scan sessions of the one-shot scans and imports
+	ScanTriggerOneShot = "ClusterIQ Scanner"
+	ScanTriggerImport  = "ClusterIQ Scanner (import)"
 
 	ScannerExitOK                                = 0
 	ScannerExitErrorReadingCloudProviderAccounts = 101
 	ScannerExitErrorCreatingStockers             = 201
 	ScannerExitErrorStartingStockers             = 202
+	ScannerExitErrorIncompleteScan               = 203
 	ScannerExitErrorPrintingInventory            = 301
 	ScannerExitErrorRefreshingInventory          = 302
+	ScannerExitErrorWritingOutputFile            = 303
+	ScannerExitErrorImportingFile                = 304
+	ScannerExitErrorDaemon                       = 401
 )
 
 var (
@@ -50,34 +68,55 @@ var (
 
 // Scanner models the cloud agnostic Scanner for looking up OCP deployments
 type Scanner struct {
-	inventory     inventory.Inventory
-	stockers      []stocker.Stocker
-	cfg           *config.ScannerConfig
-	client        http.Client
-	APIURL        string
-	logger        *zap.Logger
-	credsFileHash []byte
+	inventory       inventory.Inventory
+	accountConfigs  map[string]credentials.AccountConfig
+	stockers        []stocker.Stocker
+	cfg             *config.ScannerConfig
+	client          http.Client
+	APIURL          string
+	logger          *zap.Logger
+	request         scan.Request                   // Scope of the current scan
+	credsAccounts   []credentials.AccountConfig    // Accounts read from the credentials file, kept between scans
+	credsFileHash   []byte                         // Checksum of the credentials file content used for reading credsAccounts
+	failedAccounts  map[string]error               // Accounts that couldn't be scanned on the current scan
+	partialAccounts map[string]error               // Accounts with failed regions on the current scan, whose scanned regions are posted
+	accountScans    map[string]*models.AccountScan // Result of every account on the current scan
+	clusterCosts    *stocker.ClusterCostsCache     // Costs obtained per cluster, kept between scans
+}
+
+// ScanError is returned when a stage of the scan fails. ExitCode is the
+// code used by the scanner when it runs in one-shot mode
+type ScanError struct {
+	ExitCode int
+	Err      error
+}
+
+func (e *ScanError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ScanError) Unwrap() error {
+	return e.Err
 }
 
 // NewScanner creates and returns a new Scanner instance
 func NewScanner(cfg *config.ScannerConfig, logger *zap.Logger) *Scanner {
-	var credsFileHash []byte
-	// Calculate Credentials file MD5 checksum for checking on runtime
-	hash := md5.Sum([]byte(cfg.CredentialsFile))
-	copy(hash[:], credsFileHash)
-
 	tr := &http.Transport{
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 	}
 
 	return &Scanner{
-		inventory:     *inventory.NewInventory(),
-		stockers:      make([]stocker.Stocker, 0),
-		cfg:           cfg,
-		client:        http.Client{Transport: tr},
-		APIURL:        cfg.APIURL,
-		logger:        logger,
-		credsFileHash: credsFileHash,
+		inventory:       *inventory.NewInventory(),
+		accountConfigs:  make(map[string]credentials.AccountConfig),
+		stockers:        make([]stocker.Stocker, 0),
+		failedAccounts:  make(map[string]error),
+		partialAccounts: make(map[string]error),
+		accountScans:    make(map[string]*models.AccountScan),
+		cfg:             cfg,
+		client:          http.Client{Transport: tr},
+		APIURL:          cfg.APIURL,
+		logger:          logger,
+		clusterCosts:    stocker.NewClusterCostsCache(time.Duration(cfg.ClusterCostsCacheHours)*time.Hour, cfg.ClusterCostQueries),
 	}
 }
 
@@ -86,16 +125,62 @@ func init() {
 	logger = ciqLogger.NewLogger()
 }
 
+// loadCredentialsFile reads the cloud accounts from the credentials file. The
+// accounts are kept between scans, and the file is only parsed again when its
+// content changes.
+func (s *Scanner) loadCredentialsFile() error {
+	content, err := os.ReadFile(s.cfg.CredentialsFile)
+	if err != nil {
+		return err
+	}
+
+	// Calculate Credentials file MD5 checksum for checking on runtime
+	hash := md5.Sum(content)
+	if s.credsAccounts != nil && bytes.Equal(hash[:], s.credsFileHash) {
+		s.logger.Debug("Credentials file unchanged since the last scan")
+		return nil
+	}
+
+	accounts, err := credentials.ReadCloudAccounts(s.cfg.CredentialsFile)
+	if err != nil {
+		return err
+	}
+
+	s.credsAccounts = accounts
+	s.credsFileHash = hash[:]
+	s.logger.Info("Credentials file loaded",
+		zap.String("credentials_file_path", s.cfg.CredentialsFile),
+		zap.String("credentials_file_hash", hex.EncodeToString(s.credsFileHash)),
+		zap.Int("accounts", len(accounts)),
+	)
+
+	return nil
+}
+
 // readCloudProviderAccounts reads and loads cloud provider accounts from a credentials file.
 func (s *Scanner) readCloudProviderAccounts() error {
 	// Load cloud accounts credentials file.
-	accounts, err := credentials.ReadCloudAccounts(s.cfg.CredentialsFile)
-	if err != nil {
+	if err := s.loadCredentialsFile(); err != nil {
 		return err
 	}
 
+	// Adding the member accounts of the AWS Organizations with discovery enabled
+	accounts, err := credentials.ExpandAWSOrganizations(s.credsAccounts)
+	if err != nil {
+		s.logger.Error("AWS Organizations discovery failed; continuing with the rest of accounts", zap.Error(err))
+	}
+
+	s.accountConfigs = make(map[string]credentials.AccountConfig, len(accounts))
+	found := false
+
 	// Read INI file content.
 	for _, account := range accounts {
+		// Skipping the accounts out of the scan scope
+		if !s.request.IsFullScan() && account.Name != s.request.AccountName {
+			continue
+		}
+		found = true
+
 		newAccount := inventory.NewAccount(
 			"",
 			account.Name,
@@ -103,6 +188,16 @@ func (s *Scanner) readCloudProviderAccounts() error {
 			account.User,
 			account.Key,
 		)
+		// Getting the regions to be scanned from config
+		newAccount.SetRegionFilter(account.Regions, account.ExcludeRegions)
+		// Cluster scans only look into the cluster region
+		if s.request.IsClusterScan() {
+			if !newAccount.IsRegionEnabled(s.request.Region) {
+				return fmt.Errorf("region %s is not enabled for account %s", s.request.Region, account.Name)
+			}
+			newAccount.SetRegionFilter([]string{s.request.Region}, nil)
+		}
+
 		// Getting billing enabled flag from config
 		if account.BillingEnabled {
 			newAccount.EnableBilling()
@@ -112,6 +207,13 @@ func (s *Scanner) readCloudProviderAccounts() error {
 		if err := s.inventory.AddAccount(newAccount); err != nil {
 			return err
 		}
+
+		// Keeping the account config for the provider specific settings
+		s.accountConfigs[account.Name] = account
+	}
+
+	if !s.request.IsFullScan() && !found {
+		return fmt.Errorf("account %s not found on the credentials file", s.request.AccountName)
 	}
 
 	return nil
@@ -122,51 +224,64 @@ func (s *Scanner) createStockers() error {
 	var skippedAccounts int
 	var validStockers []stocker.Stocker
 	for _, account := range s.inventory.Accounts {
+		start := time.Now()
 		switch account.Provider {
 		case inventory.AWSProvider:
 			s.logger.Info("Processing AWS account", zap.String("account", account.Name))
 
 			// AWS API Stoker
-			awsStocker, err := stocker.NewAWSStocker(account, s.cfg.SkipNoOpenShiftInstances, s.logger)
+			awsStocker, err := stocker.NewAWSStocker(account, s.accountConfigs[account.Name].AWSAssumeRoleConfig(), s.cfg.SkipNoOpenShiftInstances, s.cfg.RegionWorkers, s.logger)
 			if err != nil {
 				s.logger.Error("Failed to create AWS stocker; skipping this account",
 					zap.String("account", account.Name),
 					zap.Error(err))
+				s.recordFailedAccount(account, start, err)
 				skippedAccounts++
 				continue
 			}
 			validStockers = append(validStockers, awsStocker)
 
-			// AWS Billing API Stoker
-			if account.IsBillingEnabled() {
-				s.logger.Warn("Enabled AWS Billing Stocker", zap.String("account", account.Name))
-				instancesToScan, err := s.getInstancesForBillingUpdate()
-				if err != nil {
-					s.logger.Error("Failed to retrieve the list of instances required for billing information from AWS Cost Explorer.",
-						zap.String("account", account.Name))
-				} else {
-					validStockers = append(validStockers, stocker.NewAWSBillingStocker(account, s.logger, instancesToScan))
+			// AWS Billing Stoker. Cluster scans don't update the billing
+			// information, and dry-runs can't get the instances from the API
+			if account.IsBillingEnabled() && !s.request.IsClusterScan() && !s.isDryRun() {
+				if billingStocker := s.newAWSBillingStocker(account); billingStocker != nil {
+					validStockers = append(validStockers, billingStocker)
 				}
 			}
 		case inventory.GCPProvider:
-			s.logger.Warn("Failed to scan GCP account",
-				zap.String("account", account.Name),
-				zap.String("reason", "not implemented"),
-			)
-			// TODO: Uncomment line below when GCP Stocker is implemented
-			// gcpStocker = stocker.NewGCPStocker(account, s.cfg.SkipNoOpenShiftInstances, s.logger))
+			s.logger.Info("Processing GCP account", zap.String("account", account.Name))
+
+			// GCP API Stocker
+			gcpStocker, err := stocker.NewGCPStocker(account, s.cfg.SkipNoOpenShiftInstances, s.logger)
+			if err != nil {
+				s.logger.Error("Failed to create GCP stocker; skipping this account",
+					zap.String("account", account.Name),
+					zap.Error(err))
+				s.recordFailedAccount(account, start, err)
+				skippedAccounts++
+				continue
+			}
+			validStockers = append(validStockers, gcpStocker)
 		case inventory.AzureProvider:
-			s.logger.Warn("Failed to scan Azure account",
-				zap.String("account", account.Name),
-				zap.String("reason", "not implemented"),
-			)
-			// TODO: Uncomment line below when Azure Stocker is implemented
-			// azureStocker = stocker.NewAzureStocker(account, s.cfg.SkipNoOpenShiftInstances, s.logger))
+			s.logger.Info("Processing Azure account", zap.String("account", account.Name))
+
+			// Azure API Stocker
+			azureStocker, err := stocker.NewAzureStocker(account, s.accountConfigs[account.Name].TenantID, s.cfg.SkipNoOpenShiftInstances, s.logger)
+			if err != nil {
+				s.logger.Error("Failed to create Azure stocker; skipping this account",
+					zap.String("account", account.Name),
+					zap.Error(err))
+				s.recordFailedAccount(account, start, err)
+				skippedAccounts++
+				continue
+			}
+			validStockers = append(validStockers, azureStocker)
 
 		default:
 			s.logger.Warn("Unsupported cloud provider, skipping account",
 				zap.String("account", account.Name),
 				zap.String("provider", string(account.Provider)))
+			s.recordFailedAccount(account, start, fmt.Errorf("unsupported cloud provider: %s", account.Provider))
 			continue
 		}
 	}
@@ -193,174 +308,290 @@ func (s *Scanner) createStockers() error {
 	return nil
 }
 
-// startStockers runs every stocker instance
-func (s *Scanner) startStockers() error {
-	var wg sync.WaitGroup
-	errChan := make(chan error, len(s.stockers))
-
-	// Running Stockers.MakeStock procedure on parallel
-	for _, stockerInstance := range s.stockers {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if err := stockerInstance.MakeStock(); err != nil {
-				errChan <- err
-			}
-		}()
+// newAWSBillingStocker creates the billing stocker of the source configured
+// for the account. It returns nil if the stocker can't be created, as the
+// billing failures don't prevent the account from being scanned
+func (s *Scanner) newAWSBillingStocker(account *inventory.Account) stocker.Stocker {
+	accountConfig := s.accountConfigs[account.Name]
+	s.logger.Warn("Enabled AWS Billing Stocker", zap.String("account", account.Name), zap.String("billing_source", accountConfig.BillingSource))
+
+	if accountConfig.BillingSource == credentials.BillingSourceCUR {
+		curStocker, err := stocker.NewAWSCURBillingStocker(account, accountConfig.AWSAssumeRoleConfig(), accountConfig.CURPath, accountConfig.CURRegion, s.logger)
+		if err != nil {
+			s.logger.Error("Failed to create AWS CUR billing stocker", zap.String("account", account.Name), zap.Error(err))
+			return nil
+		}
+		return curStocker
 	}
 
-	// Waiting for every Stock
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
-
-	// Collecting stockers errors
-	var errorList []error
-	for err := range errChan {
-		errorList = append(errorList, err)
+	instancesToScan, err := s.getInstancesForBillingUpdate()
+	if err != nil {
+		s.logger.Error("Failed to retrieve the list of instances required for billing information from AWS Cost Explorer.",
+			zap.String("account", account.Name))
+		return nil
 	}
+	if billingStocker := stocker.NewAWSBillingStocker(account, accountConfig.AWSAssumeRoleConfig(), s.logger, instancesToScan, accountConfig.ClusterCostTag, s.clusterCosts); billingStocker != nil {
+		return billingStocker
+	}
+	return nil
+}
 
-	// Processing errors when every stocker has finished
-	if len(errorList) > 0 {
-		for _, err := range errorList {
-			s.logger.Error("Stocker Error", zap.Error(err))
-		}
-		return fmt.Errorf("error when running Scanner stockers. Failed Stockers: (%d)", len(errorList))
+// isBillingStocker returns true if the stocker only gets billing information
+func isBillingStocker(stockerInstance stocker.Stocker) bool {
+	switch stockerInstance.(type) {
+	case *stocker.AWSBillingStocker, *stocker.AWSCURBillingStocker:
+		return true
+	default:
+		return false
 	}
+}
 
-	s.logger.Info("Stockers executed correctly")
-	return nil
+// recordFailedAccount records an account that couldn't be scanned
+func (s *Scanner) recordFailedAccount(account *inventory.Account, start time.Time, err error) {
+	s.failedAccounts[account.Name] = err
+	s.accountScans[account.Name] = &models.AccountScan{
+		AccountName:    account.Name,
+		Provider:       account.Provider,
+		StartTimestamp: start,
+		EndTimestamp:   time.Now(),
+		Status:         models.AccountScanFailed,
+		Errors:         errorMessages(err),
+	}
 }
 
-// postNewAccount posts into the API an account, its clusters, instances and expenses
-func (s *Scanner) postNewAccount(account inventory.Account) error {
-	s.logger.Debug("Posting new Account", zap.String("account", account.Name))
+// startStockers runs every stocker instance, and records the result of every
+// account. The accounts whose inventory stocker fails are recorded as failed,
+// and the scan continues with the rest. When the stocker only failed on some
+// regions, the account is recorded as partially scanned instead, so the
+// scanned regions are posted. Billing stockers run once every
+// inventory stocker has finished, as they complete the expenses of the
+// scanned clusters, and they are skipped for the failed accounts. Their
+// failures are reported on the account errors, but they don't fail the
+// account, as they don't affect the inventory. The throttled requests of both
+// stockers are added up on the account result. It returns an error if every
+// account failed
+func (s *Scanner) startStockers() error {
+	var mutex sync.Mutex
+	billingErrors := make(map[string][]error)
+	throttledRequests := make(map[string]int64)
+
+	runStocker := func(stockerInstance stocker.Stocker) {
+		// Reading the account before running the stocker, as it modifies it
+		account := stockerInstance.GetResults()
+		start := time.Now()
+		err := stockerInstance.MakeStock()
+		end := time.Now()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if reporter, ok := stockerInstance.(stocker.ThrottlingReporter); ok {
+			if throttled := reporter.GetThrottledRequests(); throttled > 0 {
+				s.logger.Warn("Cloud provider API requests throttled", zap.String("account", account.Name), zap.Int64("throttled_requests", throttled))
+				throttledRequests[account.Name] += throttled
+			}
+		}
+
+		if isBillingStocker(stockerInstance) {
+			if err != nil {
+				s.logger.Error("Billing Stocker Error", zap.String("account", account.Name), zap.Error(err))
+				billingErrors[account.Name] = append(billingErrors[account.Name], err)
+			}
+			return
+		}
 
-	// Converting to Array because API handler assumes a list of accounts
-	var accounts []inventory.Account
-	accounts = append(accounts, account)
-	b, err := json.Marshal(accounts)
-	if err != nil {
-		s.logger.Error("Failed to marshal account", zap.String("account", account.Name), zap.Error(err))
-		return err
+		accountScan := &models.AccountScan{
+			AccountName:    account.Name,
+			Provider:       account.Provider,
+			StartTimestamp: start,
+			EndTimestamp:   end,
+			Status:         models.AccountScanSuccess,
+		}
+		var report stocker.RegionsReport
+		if reporter, ok := stockerInstance.(stocker.RegionsReporter); ok {
+			report = reporter.GetRegionsReport()
+			accountScan.ScannedRegions = report.Scanned
+			accountScan.FailedRegions = report.Failed
+		}
+		if err != nil {
+			s.logger.Error("Stocker Error", zap.String("account", account.Name), zap.Error(err))
+			accountScan.Errors = errorMessages(err)
+			if len(report.Scanned) > 0 && stocker.OnlyRegionErrors(err) {
+				accountScan.Status = models.AccountScanPartial
+				s.partialAccounts[account.Name] = err
+			} else {
+				accountScan.Status = models.AccountScanFailed
+				s.failedAccounts[account.Name] = err
+			}
+		}
+		s.accountScans[account.Name] = accountScan
 	}
 
-	// Posting Account data
-	if err := postData(s.client, fmt.Sprintf("%s%s", s.cfg.APIURL, APIAccountEndpoint), b); err != nil {
-		return err
+	var inventoryStockers, billingStockers []stocker.Stocker
+	for _, stockerInstance := range s.stockers {
+		if isBillingStocker(stockerInstance) {
+			billingStockers = append(billingStockers, stockerInstance)
+		} else {
+			inventoryStockers = append(inventoryStockers, stockerInstance)
+		}
 	}
 
-	// Flattering account for posting its elements
-	clusters, instances, expenses := flatternAccount(account)
+	// Running the inventory Stockers.MakeStock procedure on parallel, and waiting for every Stock
+	runStockersParallel(inventoryStockers, runStocker)
 
-	// Posting Clusters
-	if len(clusters) > 0 {
-		if err := s.postClusters(clusters); err != nil {
-			return err
+	// Running the billing stockers of the scanned accounts
+	var pendingBillingStockers []stocker.Stocker
+	for _, stockerInstance := range billingStockers {
+		accountName := stockerInstance.GetResults().Name
+		if _, failed := s.failedAccounts[accountName]; failed {
+			s.logger.Warn("Skipping billing stocker of a failed account", zap.String("account", accountName))
+			continue
 		}
+		pendingBillingStockers = append(pendingBillingStockers, stockerInstance)
 	}
+	runStockersParallel(pendingBillingStockers, runStocker)
 
-	// Posting Instances
-	if len(instances) > 0 {
-		if err := s.postInstances(instances); err != nil {
-			return err
+	// Completing the account scans with the billing errors, the throttled requests and the number of elements found
+	for accountName, accountScan := range s.accountScans {
+		accountScan.ThrottledRequests = throttledRequests[accountName]
+		for _, err := range billingErrors[accountName] {
+			accountScan.Errors = append(accountScan.Errors, errorMessages(err)...)
 		}
-	}
 
-	// Posting Expenses
-	if len(expenses) > 0 {
-		if err := s.postExpenses(expenses); err != nil {
-			return err
+		account, ok := s.inventory.Accounts[accountName]
+		if !ok {
+			continue
+		}
+		accountScan.ClusterCount = len(account.Clusters)
+		for _, cluster := range account.Clusters {
+			accountScan.InstanceCount += len(cluster.Instances)
+			accountScan.ResourceCount += len(cluster.Resources)
 		}
 	}
+
+	if len(s.failedAccounts) >= len(s.inventory.Accounts) {
+		return fmt.Errorf("error when running Scanner stockers. Failed Accounts: (%d)", len(s.failedAccounts))
+	}
+
+	s.logger.Info("Stockers executed", zap.Int("failed_accounts", len(s.failedAccounts)))
 	return nil
 }
 
-// flatternAccount extracts every Cluster, Instance and Expense from an Account for posting
-func flatternAccount(account inventory.Account) ([]inventory.Cluster, []inventory.Instance, []inventory.Expense) {
-	var clusters []inventory.Cluster
-	var instances []inventory.Instance
-	var expenses []inventory.Expense
-	for _, cluster := range account.Clusters {
-		for _, instance := range cluster.Instances {
-			expenses = append(expenses, instance.Expenses...)
-			instances = append(instances, instance)
+// runStockersParallel runs every stocker on its own goroutine, and waits for all of them
+func runStockersParallel(stockers []stocker.Stocker, run func(stocker.Stocker)) {
+	var wg sync.WaitGroup
+	for _, stockerInstance := range stockers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run(stockerInstance)
+		}()
+	}
+	wg.Wait()
+}
 
-		}
-		clusters = append(clusters, *cluster)
+// errorMessages returns the message of every error joined on err
+func errorMessages(err error) []string {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []string{err.Error()}
 	}
 
-	return clusters, instances, expenses
+	var messages []string
+	for _, e := range joined.Unwrap() {
+		messages = append(messages, errorMessages(e)...)
+	}
+	return messages
 }
 
-// postClusters posts into the API, the new instances obtained after scanning
-func (s *Scanner) postClusters(clusters []inventory.Cluster) error {
-	b, err := json.Marshal(clusters)
-	if err != nil {
-		return err
+// removeFailedAccounts discards the failed accounts from the inventory, so
+// they're not posted into the API. Their clusters are kept as they are on the
+// inventory, instead of being considered missing. The partially scanned
+// accounts are posted, and the API keeps the clusters of their failed regions
+// as they are
+func (s *Scanner) removeFailedAccounts() {
+	for accountName := range s.failedAccounts {
+		s.logger.Warn("Account not scanned successfully; discarding its results", zap.String("account", accountName))
+		delete(s.inventory.Accounts, accountName)
 	}
-
-	return postData(s.client, fmt.Sprintf("%s%s", s.cfg.APIURL, APIClusterEndpoint), b)
 }
 
-// postInstances posts into the API, the instances obtained after scanning
-func (s *Scanner) postInstances(instances []inventory.Instance) error {
-	b, err := json.Marshal(instances)
-	if err != nil {
-		return err
+// reportFailedScan posts the results of the accounts when the scan can't
+// continue, so the failed accounts are recorded on the API. The inventory is
+// not modified, as every account failed
+func (s *Scanner) reportFailedScan() {
+	if s.isDryRun() || len(s.accountScans) == 0 {
+		return
 	}
 
-	return postData(s.client, fmt.Sprintf("%s%s", s.cfg.APIURL, APIInstanceEndpoint), b)
+	s.removeFailedAccounts()
+	if err := s.postScannerInventory(); err != nil {
+		s.logger.Error("Can't post the failed accounts", zap.Error(err))
+	}
 }
 
-// postExpenses posts into the API, the expenses obtained after scanning
-func (s *Scanner) postExpenses(expenses []inventory.Expense) error {
-	b, err := json.Marshal(expenses)
-	if err != nil {
-		return err
+// failedAccountsError returns an error listing the failed and the partially
+// scanned accounts, or nil if every account was scanned successfully
+func (s *Scanner) failedAccountsError() error {
+	if len(s.failedAccounts) == 0 && len(s.partialAccounts) == 0 {
+		return nil
 	}
 
-	return postData(s.client, fmt.Sprintf("%s%s", s.cfg.APIURL, APIExpenseEndpoint), b)
+	var errs []error
+	for _, accountName := range slices.Sorted(maps.Keys(s.failedAccounts)) {
+		errs = append(errs, fmt.Errorf("account %s: %w", accountName, s.failedAccounts[accountName]))
+	}
+	for _, accountName := range slices.Sorted(maps.Keys(s.partialAccounts)) {
+		errs = append(errs, fmt.Errorf("account %s (partially scanned): %w", accountName, s.partialAccounts[accountName]))
+	}
+	return fmt.Errorf("scan incomplete, %d account(s) failed and %d partially scanned: %w", len(s.failedAccounts), len(s.partialAccounts), errors.Join(errs...))
 }
 
-// postScannerInventory posts to ClusterIQ API the information obtained of the scanning process
-// This function parallelizes the post operations creating a thread by account(or stocker)
+// postScannerInventory posts to ClusterIQ API the information obtained of the
+// scanning process as a single scan session. The API compares it with the
+// inventory in the scan scope and writes only the changes
 func (s *Scanner) postScannerInventory() error {
-	var wg sync.WaitGroup
-	errChan := make(chan error, len(s.inventory.Accounts))
+	accountScans := make([]models.AccountScan, 0, len(s.accountScans))
+	for _, accountName := range slices.Sorted(maps.Keys(s.accountScans)) {
+		accountScans = append(accountScans, *s.accountScans[accountName])
+	}
 
-	for _, account := range s.inventory.Accounts {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if err := s.postNewAccount(*account); err != nil {
-				errChan <- err
-			}
-		}()
+	b, err := json.Marshal(scan.SessionRequest{
+		Scope:        s.request,
+		Inventory:    *inventory.NewInventorySnapshot(s.inventory),
+		AccountScans: accountScans,
+	})
+	if err != nil {
+		return err
+	}
 
+	request, err := http.NewRequestWithContext(context.TODO(), http.MethodPost, fmt.Sprintf("%s%s", s.cfg.APIURL, APIScanSessionEndpoint), bytes.NewBuffer(b))
+	if err != nil {
+		return err
 	}
-	// Waiting for every Stock
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
 
-	// Collecting account posting errors
-	var errorList []error
-	for err := range errChan {
-		errorList = append(errorList, err)
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("received HTTP error code (%d) when posting the scan session", response.StatusCode)
 	}
 
-	// Processing errors when every post account operation has finished
-	if len(errorList) > 0 {
-		for _, err := range errorList {
-			s.logger.Error("Post Account Error", zap.Error(err))
-		}
-		return fmt.Errorf("error when posting Scanner inventory")
+	var session models.ScanSession
+	if err := json.NewDecoder(response.Body).Decode(&session); err != nil {
+		return fmt.Errorf("can't decode scan session response: %w", err)
 	}
 
-	s.logger.Info("Inventory posted correctly")
+	s.logger.Info("Inventory posted correctly",
+		zap.Int64("session_id", session.ID),
+		zap.Int("added", session.Added),
+		zap.Int("updated", session.Updated),
+		zap.Int("disappeared", session.Disappeared),
+		zap.Int("unchanged", session.Unchanged))
 	return nil
 }
 
@@ -393,6 +624,93 @@ func (s *Scanner) refreshInventory() error {
 	return postData(s.client, fmt.Sprintf("%s%s", s.cfg.APIURL, APIRefreshInventoryEndpoint), nil)
 }
 
+// detectOrphans looks for the resources that remain on every AWS account
+// after their clusters were terminated, and posts them into the API
+func (s *Scanner) detectOrphans() error {
+	var request scan.OrphansRequest
+	for _, account := range s.inventory.Accounts {
+		if account.Provider != inventory.AWSProvider {
+			continue
+		}
+
+		clusters, err := s.getTerminatedClusters(account.Name)
+		if err != nil {
+			s.logger.Error("Failed to retrieve terminated clusters; skipping orphan detection for this account",
+				zap.String("account", account.Name),
+				zap.Error(err))
+			continue
+		}
+
+		if len(clusters) == 0 {
+			continue
+		}
+
+		orphanStocker, err := stocker.NewAWSOrphanStocker(account, s.accountConfigs[account.Name].AWSAssumeRoleConfig(), s.logger, clusters)
+		if err != nil {
+			s.logger.Error("Failed to create AWS orphan stocker; skipping orphan detection for this account",
+				zap.String("account", account.Name),
+				zap.Error(err))
+			continue
+		}
+
+		if err := orphanStocker.MakeStock(); err != nil {
+			s.logger.Error("Failed to detect orphaned resources", zap.String("account", account.Name), zap.Error(err))
+			continue
+		}
+		request.Orphans = append(request.Orphans, orphanStocker.GetOrphans()...)
+		request.ClusterIDs = append(request.ClusterIDs, orphanStocker.GetCheckedClusters()...)
+	}
+
+	s.logger.Info("Orphan detection finished", zap.Int("clusters", len(request.ClusterIDs)), zap.Int("orphans", len(request.Orphans)))
+	// The checked clusters are posted even without orphans, for resolving the previous ones
+	if len(request.ClusterIDs) == 0 {
+		return nil
+	}
+
+	return s.postOrphans(request)
+}
+
+// postOrphans posts into the API, the orphaned resources of terminated clusters
+func (s *Scanner) postOrphans(request scan.OrphansRequest) error {
+	b, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	return postData(s.client, fmt.Sprintf("%s%s", s.cfg.APIURL, APIOrphanEndpoint), b)
+}
+
+// getTerminatedClusters fetches from the backend API the clusters of an account that are Terminated
+func (s *Scanner) getTerminatedClusters(accountName string) ([]inventory.Cluster, error) {
+	requestURL := fmt.Sprintf("%s%s/%s%s", s.cfg.APIURL, APIAccountEndpoint, url.PathEscape(accountName), APIClusterEndpoint)
+
+	resp, err := s.client.Get(requestURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get clusters, status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Clusters []inventory.Cluster `json:"clusters"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	var terminated []inventory.Cluster
+	for _, cluster := range result.Clusters {
+		if cluster.Status == inventory.Terminated {
+			terminated = append(terminated, cluster)
+		}
+	}
+
+	return terminated, nil
+}
+
 // getInstances fetches instances from the backend API
 func (s *Scanner) getInstancesForBillingUpdate() ([]inventory.Instance, error) {
 	s.logger.Debug("Fetching instances for update billing from backend")
@@ -457,6 +775,167 @@ func signalHandler(sig os.Signal) {
 	logger.Warn("Ignoring signal: ", zap.String("signal_id", sig.String()))
 }
 
+// Scan runs a scan of the accounts defined on the credentials file, and posts
+// the results into the API. The scan request limits the scan to a single
+// account or cluster; an empty request scans every account. The inventory of
+// the previous scan is discarded, so the same Scanner can be used for several
+// scans, but not concurrently
+func (s *Scanner) Scan(request scan.Request) error {
+	t0 := time.Now()
+	s.logger.Info("Starting scan",
+		zap.String("account", request.AccountName),
+		zap.String("cluster_id", request.ClusterID),
+		zap.String("region", request.Region))
+
+	if err := request.Validate(); err != nil {
+		return &ScanError{ExitCode: ScannerExitErrorReadingCloudProviderAccounts, Err: err}
+	}
+
+	// Discarding the previous scan results
+	s.inventory = *inventory.NewInventory()
+	s.stockers = nil
+	s.failedAccounts = make(map[string]error)
+	s.partialAccounts = make(map[string]error)
+	s.accountScans = make(map[string]*models.AccountScan)
+	s.request = request
+
+	// Get Cloud Accounts from credentials file
+	if err := s.readCloudProviderAccounts(); err != nil {
+		s.logger.Error("Failed to get cloud provider accounts", zap.Error(err))
+		return &ScanError{ExitCode: ScannerExitErrorReadingCloudProviderAccounts, Err: err}
+	}
+
+	// Creating Stockers
+	if err := s.createStockers(); err != nil {
+		s.logger.Error("Failed to create stockers", zap.Error(err))
+		s.reportFailedScan()
+		return &ScanError{ExitCode: ScannerExitErrorCreatingStockers, Err: err}
+	}
+
+	// Running Stockers
+	if err := s.startStockers(); err != nil {
+		s.logger.Error("Failed to start up stocker instances", zap.Error(err))
+		s.reportFailedScan()
+		return &ScanError{ExitCode: ScannerExitErrorStartingStockers, Err: err}
+	}
+	s.removeFailedAccounts()
+
+	s.inventory.PrintInventory()
+
+	// Dry-run scans write the inventory to the output file without contacting the API
+	if s.isDryRun() {
+		if err := s.writeOutputFile(); err != nil {
+			s.logger.Error("Can't write scanned results to the output file", zap.Error(err))
+			return &ScanError{ExitCode: ScannerExitErrorWritingOutputFile, Err: err}
+		}
+		if err := s.failedAccountsError(); err != nil {
+			s.logger.Warn("Dry-run scan finished with failed accounts", zap.Duration("scan_duration_seconds", time.Since(t0)), zap.Error(err))
+			return &ScanError{ExitCode: ScannerExitErrorIncompleteScan, Err: err}
+		}
+		s.logger.Info("Dry-run scan finished successfully", zap.Duration("scan_duration_seconds", time.Since(t0)))
+		return nil
+	}
+
+	// Writing into DB
+	if err := s.postScannerInventory(); err != nil {
+		s.logger.Error("Can't post scanned results", zap.Error(err))
+		return &ScanError{ExitCode: ScannerExitErrorPrintingInventory, Err: err}
+	}
+
+	// Refresh Inventory in DB
+	if err := s.refreshInventory(); err != nil {
+		s.logger.Error("Can't refresh inventory after scanning update", zap.Error(err))
+		return &ScanError{ExitCode: ScannerExitErrorRefreshingInventory, Err: err}
+	}
+
+	// Looking for resources of terminated clusters. It runs after the
+	// inventory refresh for including the clusters terminated on this scan
+	if s.cfg.OrphanDetection && !request.IsClusterScan() {
+		if err := s.detectOrphans(); err != nil {
+			s.logger.Error("Can't post orphaned resources", zap.Error(err))
+		}
+	}
+
+	// The results of the successful accounts were posted, but the scan is
+	// reported as failed for the rest
+	if err := s.failedAccountsError(); err != nil {
+		s.logger.Warn("Scan finished with failed accounts", zap.Duration("scan_duration_seconds", time.Since(t0)), zap.Error(err))
+		return &ScanError{ExitCode: ScannerExitErrorIncompleteScan, Err: err}
+	}
+
+	s.logger.Info("Scan finished successfully", zap.Duration("scan_duration_seconds", time.Since(t0)))
+	return nil
+}
+
+// isDryRun returns true if the scan results are written to a file instead of posting them into the API
+func (s *Scanner) isDryRun() bool {
+	return s.cfg.OutputFile != ""
+}
+
+// writeOutputFile writes the scanned inventory into the output file. The file
+// is written atomically, so it's never left half written
+func (s *Scanner) writeOutputFile() error {
+	format := inventory.SnapshotFormatFromPath(s.cfg.OutputFile)
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(s.cfg.OutputFile), ".cluster-iq-inventory-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if err := inventory.EncodeInventorySnapshot(tmpFile, inventory.NewInventorySnapshot(s.inventory), format); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpFile.Name(), s.cfg.OutputFile); err != nil {
+		return err
+	}
+
+	s.logger.Info("Inventory written to output file",
+		zap.String("output_file", s.cfg.OutputFile),
+		zap.String("format", string(format)))
+	return nil
+}
+
+// Import reads an inventory file generated by a dry-run scan, and posts its
+// content into the API
+func (s *Scanner) Import() error {
+	s.logger.Info("Importing inventory file", zap.String("import_file", s.cfg.ImportFile))
+
+	file, err := os.Open(s.cfg.ImportFile)
+	if err != nil {
+		return &ScanError{ExitCode: ScannerExitErrorImportingFile, Err: err}
+	}
+	defer file.Close()
+
+	snapshot, err := inventory.DecodeInventorySnapshot(file, inventory.SnapshotFormatFromPath(s.cfg.ImportFile))
+	if err != nil {
+		s.logger.Error("Can't decode inventory file", zap.Error(err))
+		return &ScanError{ExitCode: ScannerExitErrorImportingFile, Err: err}
+	}
+
+	inv, err := snapshot.ToInventory()
+	if err != nil {
+		s.logger.Error("Can't load inventory file", zap.Error(err))
+		return &ScanError{ExitCode: ScannerExitErrorImportingFile, Err: err}
+	}
+
+	s.inventory = *inv
+	s.request = scan.Request{TriggeredBy: ScanTriggerImport}
+	if err := s.postScannerInventory(); err != nil {
+		s.logger.Error("Can't post imported inventory", zap.Error(err))
+		return &ScanError{ExitCode: ScannerExitErrorPrintingInventory, Err: err}
+	}
+
+	s.logger.Info("Inventory imported successfully",
+		zap.Time("inventory_creation_timestamp", inv.CreationTimestamp),
+		zap.Int("accounts", len(inv.Accounts)))
+	return nil
+}
+
 // Main method
 func main() {
 	// Ignore Logger sync error
@@ -469,15 +948,52 @@ func main() {
 		logger.Fatal("Failed to load scanner configuration", zap.Error(err))
 	}
 
-	scan := NewScanner(cfg, logger)
+	// Retry policy shared by every AWS connection
+	if err := cpaws.SetAWSRetryPolicy(cpaws.AWSRetryPolicy{
+		MaxRetries: cfg.AWSMaxRetries,
+		MinDelay:   time.Duration(cfg.AWSRetryMinDelayMilis) * time.Millisecond,
+		MaxDelay:   time.Duration(cfg.AWSRetryMaxDelayMilis) * time.Millisecond,
+	}); err != nil {
+		logger.Fatal("Invalid AWS retry configuration", zap.Error(err))
+	}
+
+	scanner := NewScanner(cfg, logger)
 
-	scan.logger.Info("==================== Starting ClusterIQ Scanner ====================",
+	scanner.logger.Info("==================== Starting ClusterIQ Scanner ====================",
 		zap.String("version", version),
 		zap.String("commit", commit),
-		zap.String("credentials_file_path", scan.cfg.CredentialsFile),
-		zap.ByteString("credentials_file_hash", scan.credsFileHash),
+		zap.String("credentials_file_path", scanner.cfg.CredentialsFile),
+		zap.Bool("daemon_mode", scanner.cfg.DaemonMode),
+		zap.Int("aws_max_retries", scanner.cfg.AWSMaxRetries),
 	)
 
+	// Import mode posts a previously scanned inventory without scanning
+	if cfg.ImportFile != "" {
+		if err := scanner.Import(); err != nil {
+			var scanErr *ScanError
+			if errors.As(err, &scanErr) {
+				os.Exit(scanErr.ExitCode)
+			}
+			os.Exit(ScannerExitErrorImportingFile)
+		}
+		os.Exit(ScannerExitOK)
+	}
+
+	// Daemon mode keeps the scanner running, and rescans periodically or on demand
+	if cfg.DaemonMode {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+		defer stop()
+
+		daemon := NewScannerDaemon(scanner, cfg, logger)
+		if err := daemon.Run(ctx); err != nil {
+			logger.Error("Scanner daemon failed", zap.Error(err))
+			os.Exit(ScannerExitErrorDaemon)
+		}
+
+		scanner.logger.Info("==================== Stopped ClusterIQ Scanner ====================")
+		os.Exit(ScannerExitOK)
+	}
+
 	// Listen Signals block for receive OS signals. This is used by K8s/OCP for
 	// interacting with this software when it's deployed on a Pod
 	go func() {
@@ -488,40 +1004,17 @@ func main() {
 		logger.Info("Scanner stopped")
 	}()
 
-	// Get Cloud Accounts from credentials file
-	if err := scan.readCloudProviderAccounts(); err != nil {
-		logger.Error("Failed to get cloud provider accounts", zap.Error(err))
-		os.Exit(ScannerExitErrorReadingCloudProviderAccounts)
-	}
-
-	// Creating Stockers
-	if err := scan.createStockers(); err != nil {
-		logger.Error("Failed to create stockers", zap.Error(err))
-		os.Exit(ScannerExitErrorCreatingStockers)
-	}
-
 	t0 := time.Now()
-	// Running Stockers
-	if err := scan.startStockers(); err != nil {
-		logger.Error("Failed to start up stocker instances", zap.Error(err))
+	if err := scanner.Scan(scan.Request{TriggeredBy: ScanTriggerOneShot}); err != nil {
+		var scanErr *ScanError
+		if errors.As(err, &scanErr) {
+			os.Exit(scanErr.ExitCode)
+		}
 		os.Exit(ScannerExitErrorStartingStockers)
 	}
 
-	// Writing into DB
-	scan.inventory.PrintInventory()
-	if err := scan.postScannerInventory(); err != nil {
-		logger.Error("Can't post scanned results", zap.Error(err))
-		os.Exit(ScannerExitErrorPrintingInventory)
-	}
-
-	// Refresh Inventory in DB
-	if err := scan.refreshInventory(); err != nil {
-		logger.Error("Can't refresh inventory after scanning update", zap.Error(err))
-		os.Exit(ScannerExitErrorRefreshingInventory)
-	}
-
 	logger.Info("Scanner finished successfully")
-	scan.logger.Info("==================== Finished ClusterIQ Scanner ====================",
+	scanner.logger.Info("==================== Finished ClusterIQ Scanner ====================",
 		zap.Duration("scan_duration_seconds", time.Since(t0)),
 	)
 	os.Exit(ScannerExitOK)
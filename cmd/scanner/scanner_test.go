@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/config"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/scan"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeAWSTransport serves every AWS request with the handler, whatever the
+// endpoint of its region
+type fakeAWSTransport struct {
+	handler http.Handler
+}
+
+func (f fakeAWSTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	f.handler.ServeHTTP(recorder, r)
+	return recorder.Result(), nil
+}
+
+// useFakeAWSTransport sends the requests of the AWS connections to the
+// handler during the test. The API requests of the scanner use their own client
+func useFakeAWSTransport(t *testing.T, handler http.HandlerFunc) {
+	// A custom CA bundle can only be loaded into the default transport
+	t.Setenv("AWS_CA_BUNDLE", "")
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = fakeAWSTransport{handler: handler}
+	t.Cleanup(func() { http.DefaultClient.Transport = transport })
+}
+
+// fakeScannerAPI serves the clusters of every account and records the orphans posted by the scanner
+type fakeScannerAPI struct {
+	clusters map[string][]inventory.Cluster
+	orphans  []scan.OrphansRequest
+}
+
+func (f *fakeScannerAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, APIAccountEndpoint+"/") && strings.HasSuffix(r.URL.Path, APIClusterEndpoint):
+		clusters, ok := f.clusters[strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, APIAccountEndpoint+"/"), APIClusterEndpoint)]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"clusters": clusters})
+	case r.Method == http.MethodPost && r.URL.Path == APIOrphanEndpoint:
+		var request scan.OrphansRequest
+		if json.NewDecoder(r.Body).Decode(&request) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.orphans = append(f.orphans, request)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// newTestScanner returns a Scanner using the fake API, with an AWS account for every account name
+func newTestScanner(t *testing.T, api http.Handler, accountNames ...string) *Scanner {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	s := NewScanner(&config.ScannerConfig{APIURL: server.URL}, zap.NewNop())
+	for _, name := range accountNames {
+		assert.Nil(t, s.inventory.AddAccount(inventory.NewAccount(name, name, inventory.AWSProvider, "AKIAEXAMPLE", "secret")))
+	}
+	return s
+}
+
+// newTestCluster returns a cluster of the account with the specified status
+func newTestCluster(name, accountName string, status inventory.InstanceStatus) inventory.Cluster {
+	cluster := *inventory.NewCluster(name, name+"-infra", inventory.AWSProvider, "eu-west-1", accountName, "", "")
+	cluster.Status = status
+	return cluster
+}
+
+// TestGetTerminatedClusters verifies only the Terminated clusters of the account are returned
+func TestGetTerminatedClusters(t *testing.T) {
+	terminated := newTestCluster("alpha", "account", inventory.Terminated)
+	api := &fakeScannerAPI{clusters: map[string][]inventory.Cluster{"account": {
+		terminated,
+		newTestCluster("beta", "account", inventory.Running),
+		newTestCluster("gamma", "account", inventory.Stopped),
+	}}}
+	s := newTestScanner(t, api)
+
+	clusters, err := s.getTerminatedClusters("account")
+	assert.Nil(t, err)
+	if assert.Len(t, clusters, 1) {
+		assert.Equal(t, terminated.ID, clusters[0].ID)
+	}
+
+	_, err = s.getTerminatedClusters("unknown")
+	assert.NotNil(t, err)
+}
+
+// TestDetectOrphans verifies the orphaned resources are only looked for on the Terminated clusters, and the checked clusters are posted with their orphans
+func TestDetectOrphans(t *testing.T) {
+	terminated := newTestCluster("alpha", "with-terminated", inventory.Terminated)
+	api := &fakeScannerAPI{clusters: map[string][]inventory.Cluster{
+		"with-terminated": {terminated, newTestCluster("beta", "with-terminated", inventory.Running)},
+		"live":            {newTestCluster("gamma", "live", inventory.Running)},
+	}}
+	s := newTestScanner(t, api, "with-terminated", "live")
+
+	var tagKeys []string
+	useFakeAWSTransport(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ TagFilters []struct{ Key string } }
+		if json.NewDecoder(r.Body).Decode(&body) != nil || len(body.TagFilters) != 1 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		tagKeys = append(tagKeys, body.TagFilters[0].Key)
+
+		mappings := []any{}
+		if strings.HasPrefix(r.URL.Host, "tagging.eu-west-1.") {
+			mappings = append(mappings, map[string]any{"ResourceARN": "arn:aws:ec2:eu-west-1:123456789012:volume/vol-0001"})
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		_ = json.NewEncoder(w).Encode(map[string]any{"ResourceTagMappingList": mappings})
+	})
+
+	assert.Nil(t, s.detectOrphans())
+
+	tagKey := inventory.ClusterTagKey + "alpha-alpha-infra"
+	assert.Equal(t, []string{tagKey, tagKey}, tagKeys)
+	if assert.Len(t, api.orphans, 1) {
+		assert.Equal(t, []string{terminated.ID}, api.orphans[0].ClusterIDs)
+		if assert.Len(t, api.orphans[0].Orphans, 1) {
+			assert.Equal(t, "vol-0001", api.orphans[0].Orphans[0].ID)
+			assert.Equal(t, terminated.ID, api.orphans[0].Orphans[0].ClusterID)
+		}
+	}
+}
+
+// TestDetectOrphansWithoutTerminatedClusters verifies nothing is posted when there are no Terminated clusters to check
+func TestDetectOrphansWithoutTerminatedClusters(t *testing.T) {
+	api := &fakeScannerAPI{clusters: map[string][]inventory.Cluster{"live": {newTestCluster("gamma", "live", inventory.Running)}}}
+	s := newTestScanner(t, api, "live")
+
+	assert.Nil(t, s.detectOrphans())
+	assert.Empty(t, api.orphans)
+}
@@ -4,6 +4,8 @@ DROP TRIGGER update_instance_total_cost_after_delete ON expenses;
 DROP TRIGGER update_instance_daily_cost_after_insert ON expenses;
 DROP TRIGGER update_instance_daily_cost_after_delete ON expenses;
 DROP TRIGGER update_cluster_total_cost ON instances;
+DROP TRIGGER update_cluster_cost_info_after_expenses ON cluster_expenses;
+DROP TRIGGER record_cluster_transitions ON clusters;
 
 -- Drop Functins
 DROP FUNCTION update_instance_total_costs_after_insert;
@@ -11,9 +13,23 @@ DROP FUNCTION update_instance_total_costs_after_delete;
 DROP FUNCTION update_instance_daily_costs_after_insert;
 DROP FUNCTION update_instance_daily_costs_after_delete;
 DROP FUNCTION update_cluster_total_costs;
+DROP FUNCTION update_cluster_cost_info_after_expenses;
+DROP FUNCTION refresh_cluster_costs;
+DROP FUNCTION cluster_daily_costs;
+DROP FUNCTION record_cluster_transitions;
 
 -- Drop tables
+DROP TABLE cluster_transitions;
+DROP TABLE instances_history;
+DROP TABLE clusters_history;
+DROP TABLE account_scans;
+DROP TABLE scan_session_changes;
+DROP TABLE scan_sessions;
+DROP TABLE resource_tags;
+DROP TABLE resources;
+DROP TABLE resource_types;
 DROP TABLE tags;
+DROP TABLE cluster_expenses;
 DROP TABLE expenses;
 DROP TABLE instances;
 DROP TABLE clusters;
@@ -39,6 +39,7 @@ INSERT INTO
 VALUES
   ('Running'),
   ('Stopped'),
+  ('Missing'),
   ('Terminated')
 ;
 
@@ -53,7 +54,9 @@ CREATE TABLE IF NOT EXISTS accounts (
   total_cost NUMERIC(12,2) DEFAULT 0.0,
   last_15_days_cost NUMERIC(12,2) DEFAULT 0.0,
   last_month_cost NUMERIC(12,2) DEFAULT 0.0,
-  current_month_so_far_cost NUMERIC(12,2) DEFAULT 0.0
+  current_month_so_far_cost NUMERIC(12,2) DEFAULT 0.0,
+  -- True when the costs include estimated expenses
+  cost_estimated BOOLEAN DEFAULT false
 );
 
 
@@ -76,7 +79,9 @@ CREATE TABLE IF NOT EXISTS clusters (
   total_cost NUMERIC(12,2) DEFAULT 0.0,
   last_15_days_cost NUMERIC(12,2) DEFAULT 0.0,
   last_month_cost NUMERIC(12,2) DEFAULT 0.0,
-  current_month_so_far_cost NUMERIC(12,2) DEFAULT 0.0
+  current_month_so_far_cost NUMERIC(12,2) DEFAULT 0.0,
+  -- True when the costs include estimated expenses
+  cost_estimated BOOLEAN DEFAULT false
 );
 
 
@@ -93,7 +98,9 @@ CREATE TABLE IF NOT EXISTS instances (
   creation_timestamp TIMESTAMP WITH TIME ZONE,
   age INT,
   daily_cost NUMERIC(12,2) DEFAULT 0.0,
-  total_cost NUMERIC(12,2) DEFAULT 0.0
+  total_cost NUMERIC(12,2) DEFAULT 0.0,
+  -- True when the costs include estimated expenses
+  cost_estimated BOOLEAN DEFAULT false
 );
 
 
@@ -106,13 +113,70 @@ CREATE TABLE IF NOT EXISTS tags (
 );
 
 
--- Instances expenses
+-- Instances expenses. Estimated expenses are calculated from the instance
+-- price and running hours for the accounts without billing information
 CREATE TABLE IF NOT EXISTS expenses (
   instance_id TEXT REFERENCES instances(id) ON DELETE CASCADE,
   date DATE,
   amount NUMERIC(12,2) DEFAULT 0.0,
+  estimated BOOLEAN DEFAULT false,
   PRIMARY KEY (instance_id, date)
 );
+-- Index for the daily costs of a date range of the whole inventory
+CREATE INDEX IF NOT EXISTS expenses_date_idx ON expenses (date);
+
+-- Clusters expenses by service, obtained from the resources tagged as part of the cluster
+CREATE TABLE IF NOT EXISTS cluster_expenses (
+  cluster_id TEXT REFERENCES clusters(id) ON DELETE CASCADE,
+  service TEXT,
+  date DATE,
+  amount NUMERIC(12,2) DEFAULT 0.0,
+  PRIMARY KEY (cluster_id, service, date)
+);
+-- Index for the daily costs of a date range of the whole inventory
+CREATE INDEX IF NOT EXISTS cluster_expenses_date_idx ON cluster_expenses (date);
+
+-- Resource types (non-compute resources)
+CREATE TABLE IF NOT EXISTS resource_types (
+  name TEXT PRIMARY KEY
+);
+
+-- Default values for Resource types table
+INSERT INTO
+  resource_types(name)
+VALUES
+  ('Volume'),
+  ('Snapshot'),
+  ('ElasticIP'),
+  ('LoadBalancer'),
+  ('SecurityGroup'),
+  ('HostedZone')
+;
+
+
+-- Non-compute resources (volumes, snapshots, IPs...) associated to clusters
+CREATE TABLE IF NOT EXISTS resources (
+  id TEXT PRIMARY KEY,
+  name TEXT,
+  type TEXT REFERENCES resource_types(name),
+  provider TEXT REFERENCES providers(name),
+  region TEXT,
+  status TEXT,
+  class TEXT,
+  size BIGINT DEFAULT 0,
+  cluster_id TEXT REFERENCES clusters(id) ON DELETE CASCADE,
+  instance_id TEXT,
+  last_scan_timestamp TIMESTAMP WITH TIME ZONE,
+  creation_timestamp TIMESTAMP WITH TIME ZONE
+);
+
+-- Non-compute resources Tags
+CREATE TABLE IF NOT EXISTS resource_tags (
+  key TEXT,
+  value TEXT,
+  resource_id TEXT REFERENCES resources(id) ON DELETE CASCADE,
+  PRIMARY KEY (key, resource_id)
+);
 
 -- Action types table
 CREATE TABLE IF NOT EXISTS action_types (
@@ -167,8 +231,117 @@ CREATE TABLE IF NOT EXISTS audit_logs (
   description TEXT NULL,
   severity TEXT DEFAULT 'info'::TEXT NOT NULL,
   CONSTRAINT audit_logs_pkey PRIMARY KEY (id),
-  CONSTRAINT audit_logs_resource_type_check CHECK ((resource_type = ANY (ARRAY['cluster'::TEXT, 'instance'::TEXT])))
+  CONSTRAINT audit_logs_resource_type_check CHECK ((resource_type = ANY (ARRAY['cluster'::TEXT, 'instance'::TEXT, 'account'::TEXT])))
+);
+
+-- Scan sessions. Every scan posted to the API with its changes summary
+CREATE TABLE IF NOT EXISTS scan_sessions (
+  id BIGINT GENERATED ALWAYS AS IDENTITY NOT NULL,
+  session_timestamp TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  triggered_by TEXT,
+  account_name TEXT,
+  cluster_id TEXT,
+  added INTEGER DEFAULT 0,
+  updated INTEGER DEFAULT 0,
+  disappeared INTEGER DEFAULT 0,
+  unchanged INTEGER DEFAULT 0,
+  CONSTRAINT scan_sessions_pkey PRIMARY KEY (id)
+);
+
+-- Changes detected on every scan session
+CREATE TABLE IF NOT EXISTS scan_session_changes (
+  session_id BIGINT REFERENCES scan_sessions(id) ON DELETE CASCADE,
+  element_type TEXT NOT NULL,
+  element_id TEXT NOT NULL,
+  change TEXT NOT NULL,
+  fields TEXT[]
 );
+CREATE INDEX IF NOT EXISTS scan_session_changes_session_id_idx ON scan_session_changes (session_id);
+
+-- Result of scanning every account on every scan session. The accounts are not
+-- referenced, as an account failing on its first scan is not on the inventory
+CREATE TABLE IF NOT EXISTS account_scans (
+  id BIGINT GENERATED ALWAYS AS IDENTITY NOT NULL,
+  session_id BIGINT REFERENCES scan_sessions(id) ON DELETE CASCADE,
+  account_name TEXT NOT NULL,
+  provider TEXT,
+  start_timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+  end_timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+  status TEXT NOT NULL,
+  scanned_regions TEXT[],
+  failed_regions TEXT[],
+  errors TEXT[],
+  cluster_count INTEGER DEFAULT 0,
+  instance_count INTEGER DEFAULT 0,
+  resource_count INTEGER DEFAULT 0,
+  throttled_requests BIGINT DEFAULT 0,
+  CONSTRAINT account_scans_pkey PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS account_scans_account_name_idx ON account_scans (account_name, start_timestamp);
+
+-- Clusters history. State of the clusters on every scan session, and when the
+-- inventory refresh marks them as Terminated. Used for point-in-time queries,
+-- which use the time the state was recorded, as the refreshed states keep the
+-- last scan timestamp of the cluster
+CREATE TABLE IF NOT EXISTS clusters_history (
+  session_id BIGINT REFERENCES scan_sessions(id) ON DELETE CASCADE,
+  id TEXT NOT NULL,
+  name TEXT,
+  infra_id TEXT,
+  provider TEXT,
+  status TEXT,
+  region TEXT,
+  account_name TEXT,
+  console_link TEXT,
+  instance_count INTEGER,
+  last_scan_timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+  creation_timestamp TIMESTAMP WITH TIME ZONE,
+  age INT,
+  owner TEXT,
+  total_cost NUMERIC(12,2) DEFAULT 0.0,
+  last_15_days_cost NUMERIC(12,2) DEFAULT 0.0,
+  last_month_cost NUMERIC(12,2) DEFAULT 0.0,
+  current_month_so_far_cost NUMERIC(12,2) DEFAULT 0.0,
+  recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS clusters_history_id_recorded_at_idx ON clusters_history (id, recorded_at);
+CREATE INDEX IF NOT EXISTS clusters_history_recorded_at_idx ON clusters_history (recorded_at);
+
+-- Instances history. State of the instances on every scan session, and when
+-- the inventory refresh marks them as Terminated. Used for point-in-time
+-- queries, which use the time the state was recorded
+CREATE TABLE IF NOT EXISTS instances_history (
+  session_id BIGINT REFERENCES scan_sessions(id) ON DELETE CASCADE,
+  id TEXT NOT NULL,
+  name TEXT,
+  provider TEXT,
+  instance_type TEXT,
+  availability_zone TEXT,
+  status TEXT,
+  cluster_id TEXT,
+  last_scan_timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+  creation_timestamp TIMESTAMP WITH TIME ZONE,
+  age INT,
+  daily_cost NUMERIC(12,2) DEFAULT 0.0,
+  total_cost NUMERIC(12,2) DEFAULT 0.0,
+  recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS instances_history_id_recorded_at_idx ON instances_history (id, recorded_at);
+CREATE INDEX IF NOT EXISTS instances_history_recorded_at_idx ON instances_history (recorded_at);
+
+-- Cluster state transitions (first seen, status changes, console link
+-- discovery...), recorded by the record_cluster_transitions trigger
+CREATE TABLE IF NOT EXISTS cluster_transitions (
+  id BIGINT GENERATED ALWAYS AS IDENTITY NOT NULL,
+  cluster_id TEXT NOT NULL,
+  transition_timestamp TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  transition TEXT NOT NULL,
+  old_value TEXT,
+  new_value TEXT,
+  source TEXT NOT NULL,
+  CONSTRAINT cluster_transitions_pkey PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS cluster_transitions_cluster_id_idx ON cluster_transitions (cluster_id, transition_timestamp);
 
 -- ## Functions ##
 -- Updates the total cost of an instance after a new expense record is inserted
@@ -184,6 +357,11 @@ BEGIN
       SELECT SUM(amount)
       FROM expenses
       WHERE instance_id = NEW.instance_id
+    ),
+    cost_estimated = EXISTS (
+      SELECT 1
+      FROM expenses
+      WHERE instance_id = NEW.instance_id AND estimated
     )
     WHERE id = NEW.instance_id;
   RETURN NEW;
@@ -203,6 +381,11 @@ BEGIN
       SELECT SUM(amount)
       FROM expenses
       WHERE instance_id = OLD.instance_id
+    ),
+    cost_estimated = EXISTS (
+      SELECT 1
+      FROM expenses
+      WHERE instance_id = OLD.instance_id AND estimated
     )
     WHERE id = OLD.instance_id;
   RETURN OLD;
@@ -247,9 +430,35 @@ BEGIN
 END;
 $$;
 
--- Updates the total cost of a cluster based on its associated instances
-CREATE OR REPLACE FUNCTION update_cluster_cost_info()
-  RETURNS TRIGGER
+-- Returns the daily costs of a cluster. The days with cluster level expenses
+-- use them, as they include every service of the cluster resources, and the
+-- rest of days fall back to the expenses of the cluster instances
+CREATE OR REPLACE FUNCTION cluster_daily_costs(target_cluster_id TEXT)
+  RETURNS TABLE (cost_date DATE, cost_amount NUMERIC)
+  LANGUAGE SQL
+  STABLE
+  AS
+$$
+  SELECT cluster_expenses.date, SUM(cluster_expenses.amount)
+  FROM cluster_expenses
+  WHERE cluster_expenses.cluster_id = target_cluster_id
+  GROUP BY cluster_expenses.date
+  UNION ALL
+  SELECT expenses.date, SUM(expenses.amount)
+  FROM instances
+  JOIN expenses ON instances.id = expenses.instance_id
+  WHERE instances.cluster_id = target_cluster_id
+    AND NOT EXISTS (
+      SELECT 1 FROM cluster_expenses
+      WHERE cluster_expenses.cluster_id = target_cluster_id
+        AND cluster_expenses.date = expenses.date
+    )
+  GROUP BY expenses.date;
+$$;
+
+-- Updates the costs of a cluster based on its daily costs
+CREATE OR REPLACE FUNCTION refresh_cluster_costs(target_cluster_id TEXT)
+  RETURNS void
   LANGUAGE PLPGSQL
   AS
 $$
@@ -257,34 +466,58 @@ BEGIN
   UPDATE clusters
   SET
     total_cost = (
-      SELECT COALESCE(SUM(total_cost), 0) as sum
-      FROM instances
-      WHERE cluster_id = NEW.cluster_id
+      SELECT COALESCE(SUM(cost_amount), 0)
+      FROM cluster_daily_costs(target_cluster_id)
     ),
     last_15_days_cost = (
-      SELECT COALESCE(SUM(expenses.amount), 0)
-      FROM instances
-      JOIN expenses ON instances.id = expenses.instance_id
-      WHERE instances.cluster_id = NEW.cluster_id
-        AND expenses.date >= NOW()::date - interval '15 day'
+      SELECT COALESCE(SUM(cost_amount), 0)
+      FROM cluster_daily_costs(target_cluster_id)
+      WHERE cost_date >= NOW()::date - interval '15 day'
     ),
     last_month_cost = (
-      SELECT COALESCE(SUM(expenses.amount), 0)
-      FROM instances
-      JOIN expenses ON instances.id = expenses.instance_id
-      WHERE instances.cluster_id = NEW.cluster_id
-        AND EXTRACT(YEAR FROM NOW()::date - interval '1 month') = EXTRACT(YEAR FROM expenses.date)
-        AND EXTRACT(MONTH FROM NOW()::date - interval '1 month') = EXTRACT(MONTH FROM expenses.date)
+      SELECT COALESCE(SUM(cost_amount), 0)
+      FROM cluster_daily_costs(target_cluster_id)
+      WHERE EXTRACT(YEAR FROM NOW()::date - interval '1 month') = EXTRACT(YEAR FROM cost_date)
+        AND EXTRACT(MONTH FROM NOW()::date - interval '1 month') = EXTRACT(MONTH FROM cost_date)
     ),
     current_month_so_far_cost = (
-      SELECT COALESCE(SUM(expenses.amount), 0)
+      SELECT COALESCE(SUM(cost_amount), 0)
+      FROM cluster_daily_costs(target_cluster_id)
+      WHERE EXTRACT(MONTH FROM NOW()::date) = EXTRACT(MONTH FROM cost_date)
+    ),
+    cost_estimated = EXISTS (
+      SELECT 1
       FROM instances
-      JOIN expenses ON instances.id = expenses.instance_id
-      WHERE instances.cluster_id = NEW.cluster_id
-        AND (EXTRACT(MONTH FROM NOW()::date) = EXTRACT(MONTH FROM expenses.date)
-      )
+      WHERE cluster_id = target_cluster_id AND cost_estimated
     )
-    WHERE id = NEW.cluster_id;
+    WHERE id = target_cluster_id;
+END;
+$$;
+
+-- Updates the total cost of a cluster based on its associated instances
+CREATE OR REPLACE FUNCTION update_cluster_cost_info()
+  RETURNS TRIGGER
+  LANGUAGE PLPGSQL
+  AS
+$$
+BEGIN
+  PERFORM refresh_cluster_costs(NEW.cluster_id);
+  RETURN NEW;
+END;
+$$;
+
+-- Updates the total cost of a cluster after its cluster level expenses change
+CREATE OR REPLACE FUNCTION update_cluster_cost_info_after_expenses()
+  RETURNS TRIGGER
+  LANGUAGE PLPGSQL
+  AS
+$$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    PERFORM refresh_cluster_costs(OLD.cluster_id);
+    RETURN OLD;
+  END IF;
+  PERFORM refresh_cluster_costs(NEW.cluster_id);
   RETURN NEW;
 END;
 $$;
@@ -317,32 +550,133 @@ BEGIN
       SELECT COALESCE(SUM(clusters.current_month_so_far_cost), 0)
       FROM clusters
       WHERE account_name = NEW.account_name
+    ),
+    cost_estimated = EXISTS (
+      SELECT 1
+      FROM clusters
+      WHERE account_name = NEW.account_name AND cost_estimated
     )
     WHERE name = NEW.account_name;
   RETURN NEW;
 END;
 $$;
 
+-- Records the state transitions of a cluster. The source of the change
+-- (scan, refresh, action...) is read from the 'cluster_iq.change_source'
+-- setting of the transaction
+CREATE OR REPLACE FUNCTION record_cluster_transitions()
+  RETURNS TRIGGER
+  LANGUAGE PLPGSQL
+  AS
+$$
+DECLARE
+  change_source TEXT := COALESCE(NULLIF(current_setting('cluster_iq.change_source', true), ''), 'api');
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    INSERT INTO cluster_transitions (cluster_id, transition, old_value, new_value, source)
+    VALUES (NEW.id, 'FirstSeen', NULL, NEW.status, change_source);
+    IF COALESCE(NEW.console_link, '') != '' THEN
+      INSERT INTO cluster_transitions (cluster_id, transition, old_value, new_value, source)
+      VALUES (NEW.id, 'ConsoleLinkDiscovered', NULL, NEW.console_link, change_source);
+    END IF;
+    RETURN NEW;
+  END IF;
+
+  IF NEW.status IS DISTINCT FROM OLD.status THEN
+    INSERT INTO cluster_transitions (cluster_id, transition, old_value, new_value, source)
+    VALUES (
+      NEW.id,
+      CASE WHEN NEW.status = 'Terminated' THEN 'Terminated' ELSE 'StatusChanged' END,
+      OLD.status,
+      NEW.status,
+      change_source
+    );
+  END IF;
+
+  IF COALESCE(NEW.console_link, '') != '' AND COALESCE(NEW.console_link, '') != COALESCE(OLD.console_link, '') THEN
+    INSERT INTO cluster_transitions (cluster_id, transition, old_value, new_value, source)
+    VALUES (
+      NEW.id,
+      CASE WHEN COALESCE(OLD.console_link, '') = '' THEN 'ConsoleLinkDiscovered' ELSE 'ConsoleLinkChanged' END,
+      OLD.console_link,
+      NEW.console_link,
+      change_source
+    );
+  END IF;
+  RETURN NEW;
+END;
+$$;
 -- ## Maintenance Functions ##
--- Marks instances as 'Terminated' if they haven't been scanned in the last 24 hours
-CREATE OR REPLACE FUNCTION check_terminated_instances()
+-- The accounts last_scan_timestamp is only updated when the account is scanned
+-- successfully, and every element found by that scan shares the same timestamp.
+-- An element older than its account was not found by the last successful scan.
+
+-- Marks instances as 'Missing' if the last successful scan of their account didn't find them
+CREATE OR REPLACE FUNCTION check_missing_instances()
+RETURNS void AS $$
+BEGIN
+  UPDATE instances
+  SET status = 'Missing'
+  FROM clusters
+  JOIN accounts ON accounts.name = clusters.account_name
+  WHERE instances.cluster_id = clusters.id
+    AND instances.last_scan_timestamp < accounts.last_scan_timestamp
+    AND instances.status NOT IN ('Missing', 'Terminated');
+END;
+$$ LANGUAGE plpgsql;
+
+-- Marks clusters as 'Missing' if the last successful scan of their account didn't find them
+CREATE OR REPLACE FUNCTION check_missing_clusters()
+RETURNS void AS $$
+BEGIN
+  UPDATE clusters
+  SET status = 'Missing'
+  FROM accounts
+  WHERE accounts.name = clusters.account_name
+    AND clusters.last_scan_timestamp < accounts.last_scan_timestamp
+    AND clusters.status NOT IN ('Missing', 'Terminated');
+END;
+$$ LANGUAGE plpgsql;
+
+-- Marks 'Missing' instances as 'Terminated' when their account kept being scanned
+-- successfully without finding them during the grace period
+CREATE OR REPLACE FUNCTION check_terminated_instances(grace_period INTERVAL)
 RETURNS void AS $$
 BEGIN
   UPDATE instances
   SET status = 'Terminated'
-  WHERE last_scan_timestamp < NOW() - INTERVAL '1 day'
-    AND status IS DISTINCT FROM 'Terminated';
+  FROM clusters
+  JOIN accounts ON accounts.name = clusters.account_name
+  WHERE instances.cluster_id = clusters.id
+    AND instances.status = 'Missing'
+    AND accounts.last_scan_timestamp >= instances.last_scan_timestamp + grace_period;
 END;
 $$ LANGUAGE plpgsql;
 
--- Marks clusters as 'Terminated' if they haven't been scanned in the last 24 hours
-CREATE OR REPLACE FUNCTION check_terminated_clusters()
+-- Marks 'Missing' clusters as 'Terminated' when their account kept being scanned
+-- successfully without finding them during the grace period
+CREATE OR REPLACE FUNCTION check_terminated_clusters(grace_period INTERVAL)
 RETURNS void AS $$
 BEGIN
   UPDATE clusters
   SET status = 'Terminated'
-  WHERE last_scan_timestamp < NOW() - INTERVAL '1 day'
-    AND status IS DISTINCT FROM 'Terminated';
+  FROM accounts
+  WHERE accounts.name = clusters.account_name
+    AND clusters.status = 'Missing'
+    AND accounts.last_scan_timestamp >= clusters.last_scan_timestamp + grace_period;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Removes the resources not found by the successful scans of their account during
+-- the grace period, as they were deleted from the cloud provider
+CREATE OR REPLACE FUNCTION check_deleted_resources(grace_period INTERVAL)
+RETURNS void AS $$
+BEGIN
+  DELETE FROM resources
+  USING clusters
+  JOIN accounts ON accounts.name = clusters.account_name
+  WHERE resources.cluster_id = clusters.id
+    AND accounts.last_scan_timestamp >= resources.last_scan_timestamp + grace_period;
 END;
 $$ LANGUAGE plpgsql;
 
@@ -396,6 +730,20 @@ ON instances
 FOR EACH ROW
   EXECUTE PROCEDURE update_cluster_cost_info();
 
+-- Trigger to update cluster costs info after its cluster level expenses change
+CREATE TRIGGER update_cluster_cost_info_after_expenses
+AFTER INSERT OR UPDATE OR DELETE
+ON cluster_expenses
+FOR EACH ROW
+  EXECUTE PROCEDURE update_cluster_cost_info_after_expenses();
+
+-- Trigger to record the cluster state transitions
+CREATE TRIGGER record_cluster_transitions
+AFTER INSERT OR UPDATE OF status, console_link
+ON clusters
+FOR EACH ROW
+  EXECUTE PROCEDURE record_cluster_transitions();
+
 -- Trigger to update account total cost after a cluster is updated
 CREATE TRIGGER update_account_cost_info
 AFTER UPDATE
@@ -20,9 +20,9 @@ type AWSExecutor struct {
 // NewAWSExecutor creates a new AWSExecutor for a specific inventory Account,
 // configures the AWSConnection, and establishes the connection with AWS
 // to validate that the connection is correct.
-func NewAWSExecutor(account *inventory.Account, ch <-chan actions.Action, logger *zap.Logger) *AWSExecutor {
+func NewAWSExecutor(account *inventory.Account, role cpaws.AWSAssumeRoleConfig, ch <-chan actions.Action, logger *zap.Logger) *AWSExecutor {
 	// Generate AWSConnection
-	conn, err := cpaws.NewAWSConnection(account.GetUser(), account.GetPassword(), "", cpaws.WithEC2())
+	conn, err := cpaws.NewAWSConnection(account.GetUser(), account.GetPassword(), role, "", cpaws.WithEC2())
 	if err != nil {
 		logger.Error("Cannot create an AWS connection for the AWS Executor", zap.Error(err))
 		return nil
@@ -47,6 +47,9 @@ func NewAWSExecutor(account *inventory.Account, ch <-chan actions.Action, logger
 func (e *AWSExecutor) ProcessAction(action actions.Action) error {
 	e.logger.Debug("Processing incoming action")
 	target := action.GetTarget()
+	if !e.account.IsRegionEnabled(target.GetRegion()) {
+		return fmt.Errorf("region %s is not enabled for account %s", target.GetRegion(), e.account.Name)
+	}
 	if err := e.SetRegion(target.GetRegion()); err != nil {
 		return err
 	}
@@ -0,0 +1,116 @@
+package cloudagent
+
+import (
+	"fmt"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/actions"
+	cpazure "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/azure"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"go.uber.org/zap"
+)
+
+// AzureExecutor implements the CloudExecutor interface for Azure
+type AzureExecutor struct {
+	account          *inventory.Account
+	tenantID         string
+	conn             *cpazure.AzureConnection
+	logger           *zap.Logger
+	executionChannel <-chan actions.Action
+}
+
+// NewAzureExecutor creates a new AzureExecutor for a specific inventory
+// Account, and establishes the connection with Azure using the account
+// Service Principal credentials (user: ClientID, password: ClientSecret).
+func NewAzureExecutor(account *inventory.Account, tenantID string, ch <-chan actions.Action, logger *zap.Logger) *AzureExecutor {
+	exec := AzureExecutor{
+		account:          account,
+		tenantID:         tenantID,
+		logger:           logger,
+		executionChannel: ch,
+	}
+
+	if err := exec.Connect(); err != nil {
+		logger.Error("Cannot connect AzureExecutor to Azure API", zap.String("account_name", account.Name), zap.Error(err))
+		return nil
+	}
+	return &exec
+}
+
+// ProcessAction gets an action, and starts the procude for the defined ActionOperation
+func (e *AzureExecutor) ProcessAction(action actions.Action) error {
+	e.logger.Debug("Processing incoming action")
+	target := action.GetTarget()
+	if !e.account.IsRegionEnabled(target.GetRegion()) {
+		return fmt.Errorf("region %s is not enabled for account %s", target.GetRegion(), e.account.Name)
+	}
+	if err := e.SetRegion(target.GetRegion()); err != nil {
+		return err
+	}
+
+	switch a := action.GetActionOperation(); a {
+	case actions.PowerOnCluster:
+		return e.PowerOnCluster(target.GetInstances())
+
+	case actions.PowerOffCluster:
+		return e.PowerOffCluster(target.GetInstances())
+
+	default: // No registered ActionOperation
+		return fmt.Errorf("cannot identify ActionOperation while processing an Action")
+	}
+}
+
+// GetAccountName returns the account name
+func (e AzureExecutor) GetAccountName() string {
+	return e.account.Name
+}
+
+// SetRegion configures the Azure location where the cluster VMs are placed
+func (e *AzureExecutor) SetRegion(region string) error {
+	e.conn.SetRegion(region)
+	return nil
+}
+
+// PowerOnCluster attempts to start the VMs specified by instanceIDs.
+// It delegates the actual start operation, including state filtering,
+// to the underlying AzureConnection.
+func (e *AzureExecutor) PowerOnCluster(instanceIDs []string) error {
+	if len(instanceIDs) == 0 {
+		return fmt.Errorf("no instances to start")
+	}
+
+	e.logger.Info("Starting cluster instances", zap.Strings("instances", instanceIDs))
+	if err := e.conn.StartClusterInstances(instanceIDs); err != nil {
+		e.logger.Error("Failed to start cluster instances", zap.Strings("instances", instanceIDs), zap.Error(err))
+		return err
+	}
+	e.logger.Info("Successfully started cluster instances", zap.Strings("instances", instanceIDs))
+	return nil
+}
+
+// PowerOffCluster attempts to deallocate the VMs specified by instanceIDs.
+// Deallocating (instead of just stopping) the VMs stops the compute billing.
+// It delegates the actual operation, including state filtering, to the
+// underlying AzureConnection.
+func (e *AzureExecutor) PowerOffCluster(instanceIDs []string) error {
+	if len(instanceIDs) == 0 {
+		return fmt.Errorf("no instances to stop")
+	}
+
+	e.logger.Info("Stopping cluster instances", zap.Strings("instances", instanceIDs))
+	if err := e.conn.StopClusterInstances(instanceIDs); err != nil {
+		e.logger.Error("Failed to stop cluster instances", zap.Strings("instances", instanceIDs), zap.Error(err))
+		return err
+	}
+	e.logger.Info("Successfully stopped cluster instances", zap.Strings("instances", instanceIDs))
+	return nil
+}
+
+// Connect establishes the connection with Azure.
+func (e *AzureExecutor) Connect() error {
+	conn, err := cpazure.NewAzureConnection(e.tenantID, e.account.GetUser(), e.account.GetPassword())
+	if err != nil {
+		return err
+	}
+	e.conn = conn
+	return nil
+}
@@ -0,0 +1,126 @@
+package cloudagent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/actions"
+	cpazure "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/azure"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeAzureClient implements cpazure.AzureAPIClient recording the power operations by VM resource ID
+type fakeAzureClient struct {
+	vms         map[string][]*armcompute.VirtualMachine
+	started     []string
+	deallocated []string
+}
+
+func (f *fakeAzureClient) ListSubscriptions(_ context.Context) ([]string, error) {
+	var subscriptions []string
+	for subscription := range f.vms {
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, nil
+}
+
+func (f *fakeAzureClient) ListVirtualMachines(_ context.Context, subscriptionID string) ([]*armcompute.VirtualMachine, error) {
+	return f.vms[subscriptionID], nil
+}
+
+func (f *fakeAzureClient) StartVirtualMachine(_ context.Context, vmResourceID string) error {
+	f.started = append(f.started, vmResourceID)
+	return nil
+}
+
+func (f *fakeAzureClient) DeallocateVirtualMachine(_ context.Context, vmResourceID string) error {
+	f.deallocated = append(f.deallocated, vmResourceID)
+	return nil
+}
+
+// newFakeVM returns an Azure VM with the specified VMID, location and power state
+func newFakeVM(id string, location string, powerState string) *armcompute.VirtualMachine {
+	resourceID := "/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/" + id
+	code := "PowerState/" + powerState
+	return &armcompute.VirtualMachine{
+		ID:       &resourceID,
+		Name:     &id,
+		Location: &location,
+		Properties: &armcompute.VirtualMachineProperties{
+			VMID: &id,
+			InstanceView: &armcompute.VirtualMachineInstanceView{
+				Statuses: []*armcompute.InstanceViewStatus{{Code: &code}},
+			},
+		},
+	}
+}
+
+// newFakeAzureExecutor returns an AzureExecutor connected to the fake client
+func newFakeAzureExecutor(account *inventory.Account, client *fakeAzureClient) *AzureExecutor {
+	return &AzureExecutor{
+		account:  account,
+		tenantID: "tenant",
+		conn:     cpazure.NewAzureConnectionWithClient("tenant", client),
+		logger:   zap.NewNop(),
+	}
+}
+
+// newFakeAzureClient returns a fake client with the VMs of a cluster spread on two locations
+func newFakeAzureClient() *fakeAzureClient {
+	return &fakeAzureClient{
+		vms: map[string][]*armcompute.VirtualMachine{
+			"sub-1": {
+				newFakeVM("vm-1", "eastus", "deallocated"),
+				newFakeVM("vm-2", "eastus", "running"),
+				newFakeVM("vm-3", "westeurope", "deallocated"),
+				newFakeVM("vm-4", "eastus", "deallocated"),
+			},
+		},
+	}
+}
+
+// TestAzureExecutorPowerOn tests only the stopped VMs of the action location are started
+func TestAzureExecutorPowerOn(t *testing.T) {
+	client := newFakeAzureClient()
+	exec := newFakeAzureExecutor(inventory.NewAccount("", "azure-account", inventory.AzureProvider, "client", "secret"), client)
+
+	target := actions.NewActionTarget("azure-account", "eastus", "ocp-a1b2c", []string{"vm-1", "vm-2", "vm-3"})
+	assert.Nil(t, exec.ProcessAction(actions.NewInstantAction(actions.PowerOnCluster, *target, "Pending", true)))
+
+	assert.Len(t, client.started, 1)
+	assert.Contains(t, client.started[0], "/virtualMachines/vm-1")
+	assert.Empty(t, client.deallocated)
+}
+
+// TestAzureExecutorPowerOff tests only the running VMs of the action are deallocated
+func TestAzureExecutorPowerOff(t *testing.T) {
+	client := newFakeAzureClient()
+	exec := newFakeAzureExecutor(inventory.NewAccount("", "azure-account", inventory.AzureProvider, "client", "secret"), client)
+
+	target := actions.NewActionTarget("azure-account", "eastus", "ocp-a1b2c", []string{"vm-1", "vm-2", "vm-3"})
+	assert.Nil(t, exec.ProcessAction(actions.NewInstantAction(actions.PowerOffCluster, *target, "Pending", true)))
+
+	assert.Len(t, client.deallocated, 1)
+	assert.Contains(t, client.deallocated[0], "/virtualMachines/vm-2")
+	assert.Empty(t, client.started)
+}
+
+// TestAzureExecutorRejectedActions tests the actions on disabled locations or without instances are rejected
+func TestAzureExecutorRejectedActions(t *testing.T) {
+	client := newFakeAzureClient()
+	account := inventory.NewAccount("", "azure-account", inventory.AzureProvider, "client", "secret")
+	account.SetRegionFilter([]string{"eastus"}, nil)
+	exec := newFakeAzureExecutor(account, client)
+
+	target := actions.NewActionTarget("azure-account", "westeurope", "ocp-a1b2c", []string{"vm-3"})
+	assert.Error(t, exec.ProcessAction(actions.NewInstantAction(actions.PowerOnCluster, *target, "Pending", true)))
+
+	target = actions.NewActionTarget("azure-account", "eastus", "ocp-a1b2c", nil)
+	assert.Error(t, exec.ProcessAction(actions.NewInstantAction(actions.PowerOnCluster, *target, "Pending", true)))
+
+	assert.Empty(t, client.started)
+	assert.Empty(t, client.deallocated)
+}
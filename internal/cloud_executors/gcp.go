@@ -0,0 +1,113 @@
+package cloudagent
+
+import (
+	"fmt"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/actions"
+	cpgcp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/gcp"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"go.uber.org/zap"
+)
+
+// GCPExecutor implements the CloudExecutor interface for GCP
+type GCPExecutor struct {
+	account          *inventory.Account
+	conn             *cpgcp.GCPConnection
+	logger           *zap.Logger
+	executionChannel <-chan actions.Action
+}
+
+// NewGCPExecutor creates a new GCPExecutor for a specific inventory Account,
+// and establishes the connection with GCP using the account credentials
+// (user: ProjectID, password: Service Account key file).
+func NewGCPExecutor(account *inventory.Account, ch <-chan actions.Action, logger *zap.Logger) *GCPExecutor {
+	exec := GCPExecutor{
+		account:          account,
+		logger:           logger,
+		executionChannel: ch,
+	}
+
+	if err := exec.Connect(); err != nil {
+		logger.Error("Cannot connect GCPExecutor to GCP API", zap.String("account_name", account.Name), zap.Error(err))
+		return nil
+	}
+	return &exec
+}
+
+// ProcessAction gets an action, and starts the procude for the defined ActionOperation
+func (e *GCPExecutor) ProcessAction(action actions.Action) error {
+	e.logger.Debug("Processing incoming action")
+	target := action.GetTarget()
+	if !e.account.IsRegionEnabled(target.GetRegion()) {
+		return fmt.Errorf("region %s is not enabled for account %s", target.GetRegion(), e.account.Name)
+	}
+	if err := e.SetRegion(target.GetRegion()); err != nil {
+		return err
+	}
+
+	switch a := action.GetActionOperation(); a {
+	case actions.PowerOnCluster:
+		return e.PowerOnCluster(target.GetInstances())
+
+	case actions.PowerOffCluster:
+		return e.PowerOffCluster(target.GetInstances())
+
+	default: // No registered ActionOperation
+		return fmt.Errorf("cannot identify ActionOperation while processing an Action")
+	}
+}
+
+// GetAccountName returns the account name
+func (e GCPExecutor) GetAccountName() string {
+	return e.account.Name
+}
+
+// SetRegion configures the GCP region where the cluster instances are placed
+func (e *GCPExecutor) SetRegion(region string) error {
+	e.conn.SetRegion(region)
+	return nil
+}
+
+// PowerOnCluster attempts to start the Compute Engine instances specified by
+// instanceIDs. It delegates the actual start operation, including state
+// filtering, to the underlying GCPConnection.
+func (e *GCPExecutor) PowerOnCluster(instanceIDs []string) error {
+	if len(instanceIDs) == 0 {
+		return fmt.Errorf("no instances to start")
+	}
+
+	e.logger.Info("Starting cluster instances", zap.Strings("instances", instanceIDs))
+	if err := e.conn.StartClusterInstances(instanceIDs); err != nil {
+		e.logger.Error("Failed to start cluster instances", zap.Strings("instances", instanceIDs), zap.Error(err))
+		return err
+	}
+	e.logger.Info("Successfully started cluster instances", zap.Strings("instances", instanceIDs))
+	return nil
+}
+
+// PowerOffCluster attempts to stop the Compute Engine instances specified by
+// instanceIDs. It delegates the actual stop operation, including state
+// filtering, to the underlying GCPConnection.
+func (e *GCPExecutor) PowerOffCluster(instanceIDs []string) error {
+	if len(instanceIDs) == 0 {
+		return fmt.Errorf("no instances to stop")
+	}
+
+	e.logger.Info("Stopping cluster instances", zap.Strings("instances", instanceIDs))
+	if err := e.conn.StopClusterInstances(instanceIDs); err != nil {
+		e.logger.Error("Failed to stop cluster instances", zap.Strings("instances", instanceIDs), zap.Error(err))
+		return err
+	}
+	e.logger.Info("Successfully stopped cluster instances", zap.Strings("instances", instanceIDs))
+	return nil
+}
+
+// Connect establishes the connection with GCP.
+func (e *GCPExecutor) Connect() error {
+	conn, err := cpgcp.NewGCPConnection(e.account.GetUser(), e.account.GetPassword())
+	if err != nil {
+		return err
+	}
+	e.conn = conn
+	return nil
+}
@@ -0,0 +1,121 @@
+package cloudagent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/actions"
+	cpgcp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/gcp"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	compute "google.golang.org/api/compute/v1"
+	dns "google.golang.org/api/dns/v1"
+)
+
+// fakeGCPClient implements cpgcp.GCPAPIClient recording the power operations by instance name
+type fakeGCPClient struct {
+	instances map[string][]*compute.Instance
+	started   []string
+	stopped   []string
+	resumed   []string
+}
+
+func (f *fakeGCPClient) ListZones(_ context.Context, _ string) ([]string, error) {
+	var zones []string
+	for zone := range f.instances {
+		zones = append(zones, zone)
+	}
+	return zones, nil
+}
+
+func (f *fakeGCPClient) ListInstances(_ context.Context, _ string, zone string) ([]*compute.Instance, error) {
+	return f.instances[zone], nil
+}
+
+func (f *fakeGCPClient) ListManagedZones(_ context.Context, _ string) ([]*dns.ManagedZone, error) {
+	return nil, nil
+}
+
+func (f *fakeGCPClient) StartInstance(_ context.Context, _ string, _ string, instance string) error {
+	f.started = append(f.started, instance)
+	return nil
+}
+
+func (f *fakeGCPClient) StopInstance(_ context.Context, _ string, _ string, instance string) error {
+	f.stopped = append(f.stopped, instance)
+	return nil
+}
+
+func (f *fakeGCPClient) ResumeInstance(_ context.Context, _ string, _ string, instance string) error {
+	f.resumed = append(f.resumed, instance)
+	return nil
+}
+
+// newFakeGCPExecutor returns a GCPExecutor connected to the fake client
+func newFakeGCPExecutor(account *inventory.Account, client *fakeGCPClient) *GCPExecutor {
+	return &GCPExecutor{
+		account: account,
+		conn:    cpgcp.NewGCPConnectionWithClient("my-project", client),
+		logger:  zap.NewNop(),
+	}
+}
+
+// newFakeGCPInstances returns the instances of a cluster spread on two regions
+func newFakeGCPInstances() map[string][]*compute.Instance {
+	zoneURL := "https://www.googleapis.com/compute/v1/projects/my-project/zones/"
+	return map[string][]*compute.Instance{
+		"us-central1-a": {
+			{Id: 1, Name: "master-0", Zone: zoneURL + "us-central1-a", Status: "TERMINATED"},
+			{Id: 2, Name: "worker-0", Zone: zoneURL + "us-central1-a", Status: "SUSPENDED"},
+			{Id: 3, Name: "worker-1", Zone: zoneURL + "us-central1-a", Status: "RUNNING"},
+			{Id: 4, Name: "other", Zone: zoneURL + "us-central1-a", Status: "TERMINATED"},
+		},
+		"europe-west1-b": {
+			{Id: 5, Name: "worker-2", Zone: zoneURL + "europe-west1-b", Status: "TERMINATED"},
+		},
+	}
+}
+
+// TestGCPExecutorPowerOn tests the stopped instances of the action region are started and the suspended ones resumed
+func TestGCPExecutorPowerOn(t *testing.T) {
+	client := &fakeGCPClient{instances: newFakeGCPInstances()}
+	exec := newFakeGCPExecutor(inventory.NewAccount("", "gcp-account", inventory.GCPProvider, "my-project", ""), client)
+
+	target := actions.NewActionTarget("gcp-account", "us-central1", "ocp-a1b2c", []string{"1", "2", "3", "5"})
+	assert.Nil(t, exec.ProcessAction(actions.NewInstantAction(actions.PowerOnCluster, *target, "Pending", true)))
+
+	assert.Equal(t, []string{"master-0"}, client.started)
+	assert.Equal(t, []string{"worker-0"}, client.resumed)
+	assert.Empty(t, client.stopped)
+}
+
+// TestGCPExecutorPowerOff tests only the running instances of the action are stopped
+func TestGCPExecutorPowerOff(t *testing.T) {
+	client := &fakeGCPClient{instances: newFakeGCPInstances()}
+	exec := newFakeGCPExecutor(inventory.NewAccount("", "gcp-account", inventory.GCPProvider, "my-project", ""), client)
+
+	target := actions.NewActionTarget("gcp-account", "us-central1", "ocp-a1b2c", []string{"1", "2", "3"})
+	assert.Nil(t, exec.ProcessAction(actions.NewInstantAction(actions.PowerOffCluster, *target, "Pending", true)))
+
+	assert.Equal(t, []string{"worker-1"}, client.stopped)
+	assert.Empty(t, client.started)
+	assert.Empty(t, client.resumed)
+}
+
+// TestGCPExecutorRejectedActions tests the actions on disabled regions or without instances are rejected
+func TestGCPExecutorRejectedActions(t *testing.T) {
+	client := &fakeGCPClient{instances: newFakeGCPInstances()}
+	account := inventory.NewAccount("", "gcp-account", inventory.GCPProvider, "my-project", "")
+	account.SetRegionFilter(nil, []string{"europe-west1"})
+	exec := newFakeGCPExecutor(account, client)
+
+	target := actions.NewActionTarget("gcp-account", "europe-west1", "ocp-a1b2c", []string{"5"})
+	assert.Error(t, exec.ProcessAction(actions.NewInstantAction(actions.PowerOnCluster, *target, "Pending", true)))
+
+	target = actions.NewActionTarget("gcp-account", "us-central1", "ocp-a1b2c", nil)
+	assert.Error(t, exec.ProcessAction(actions.NewInstantAction(actions.PowerOffCluster, *target, "Pending", true)))
+
+	assert.Empty(t, client.started)
+	assert.Empty(t, client.stopped)
+}
@@ -0,0 +1,133 @@
+package cloudprovider
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	ini "gopkg.in/ini.v1"
+)
+
+const (
+	// Session name used on the AssumeRole requests. It identifies ClusterIQ on the target account CloudTrail logs
+	assumeRoleSessionName = "cluster-iq"
+	// Time before the temporary credentials expiration when they are refreshed
+	assumeRoleExpiryWindow = 5 * time.Minute
+)
+
+// AWSAssumeRoleConfig defines how to obtain temporary credentials for an AWS
+// account by assuming an IAM role. Its zero value means that the static
+// access keys are used directly
+type AWSAssumeRoleConfig struct {
+	// RoleARN of the IAM role to be assumed on the target account
+	RoleARN string
+	// ExternalID required by the role trust policy (optional)
+	ExternalID string
+	// SourceProfile is the shared config or credentials profile whose
+	// credentials are used for assuming the role when there are no static
+	// access keys (optional)
+	SourceProfile string
+	// WebIdentityTokenFile is the path to an OIDC token (e.g. IRSA projected
+	// service account token) exchanged for the role credentials (optional)
+	WebIdentityTokenFile string
+}
+
+// newAWSCredentials returns the credentials for an AWSConnection.
+//
+// The source identity comes from the static access keys if they are defined,
+// then from the SourceProfile, and otherwise from the SDK default credentials
+// chain (env vars, IRSA, instance profile...). If a RoleARN is configured, the
+// source identity assumes it (or the web identity token is exchanged for it).
+// The temporary credentials are refreshed automatically before they expire,
+// so long-running components can keep the same connection.
+func newAWSCredentials(user string, password string, role AWSAssumeRoleConfig, region string) (*credentials.Credentials, error) {
+	var sourceCreds *credentials.Credentials
+	switch {
+	case user != "":
+		// Third argument (token) it's not used. For more info check docs: https://pkg.go.dev/github.com/aws/aws-sdk-go/aws/credentials#NewStaticCredentials
+		sourceCreds = credentials.NewStaticCredentials(user, password, "")
+	case role.SourceProfile != "":
+		// The profile is resolved like the AWS CLI does, so it can be defined
+		// on the shared config file too (role chaining, credential_process, SSO...)
+		if !sharedProfileExists(role.SourceProfile) {
+			return nil, fmt.Errorf("source profile %s not found on the AWS shared config or credentials files", role.SourceProfile)
+		}
+		profileSession, err := session.NewSessionWithOptions(session.Options{
+			Config:            *withRetryPolicy(aws.NewConfig().WithRegion(region)),
+			Profile:           role.SourceProfile,
+			SharedConfigState: session.SharedConfigEnable,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot load source profile %s: %w", role.SourceProfile, err)
+		}
+		sourceCreds = profileSession.Config.Credentials
+	}
+
+	if role.RoleARN == "" {
+		if role.WebIdentityTokenFile != "" {
+			return nil, fmt.Errorf("web identity token file requires a role ARN")
+		}
+		// nil credentials make the AWS session to use the default credentials chain
+		return sourceCreds, nil
+	}
+
+	// Session used only for requesting the temporary credentials to STS
+	sourceSession, err := session.NewSession(withRetryPolicy(aws.NewConfig().WithCredentials(sourceCreds).WithRegion(region)))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create source session for assuming role %s: %w", role.RoleARN, err)
+	}
+
+	if role.WebIdentityTokenFile != "" {
+		provider := stscreds.NewWebIdentityRoleProviderWithOptions(
+			sts.New(sourceSession),
+			role.RoleARN,
+			assumeRoleSessionName,
+			stscreds.FetchTokenPath(role.WebIdentityTokenFile),
+			func(p *stscreds.WebIdentityRoleProvider) {
+				p.ExpiryWindow = assumeRoleExpiryWindow
+			},
+		)
+		return credentials.NewCredentials(provider), nil
+	}
+
+	return stscreds.NewCredentials(sourceSession, role.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+		p.RoleSessionName = assumeRoleSessionName
+		p.ExpiryWindow = assumeRoleExpiryWindow
+		if role.ExternalID != "" {
+			p.ExternalID = aws.String(role.ExternalID)
+		}
+	}), nil
+}
+
+// sharedProfileExists returns true if the profile is defined on the AWS shared
+// config or credentials files. It's checked explicitly because the SDK falls
+// back silently to the default credentials chain for the missing profiles
+func sharedProfileExists(profile string) bool {
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = defaults.SharedConfigFilename()
+	}
+	credentialsFile := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsFile == "" {
+		credentialsFile = defaults.SharedCredentialsFilename()
+	}
+
+	// The config file profiles are prefixed by "profile", except the default one
+	configSection := "profile " + profile
+	if profile == "default" {
+		configSection = profile
+	}
+	if cfg, err := ini.LooseLoad(configFile); err == nil && cfg.HasSection(configSection) {
+		return true
+	}
+	if cfg, err := ini.LooseLoad(credentialsFile); err == nil && cfg.HasSection(profile) {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package cloudprovider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewAWSCredentialsStatic verifies the static access keys are used when there's no role configured
+func TestNewAWSCredentialsStatic(t *testing.T) {
+	creds, err := newAWSCredentials("AKIAEXAMPLE", "secret", AWSAssumeRoleConfig{}, DefaultAWSRegion)
+	assert.Nil(t, err)
+	assert.NotNil(t, creds)
+
+	value, err := creds.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, "AKIAEXAMPLE", value.AccessKeyID)
+	assert.Equal(t, "secret", value.SecretAccessKey)
+}
+
+// TestNewAWSCredentialsDefaultChain verifies the default credentials chain is used when there are no keys nor role
+func TestNewAWSCredentialsDefaultChain(t *testing.T) {
+	creds, err := newAWSCredentials("", "", AWSAssumeRoleConfig{}, DefaultAWSRegion)
+	assert.Nil(t, err)
+	assert.Nil(t, creds)
+}
+
+// TestNewAWSCredentialsAssumeRole verifies temporary credentials are configured when a role is defined
+func TestNewAWSCredentialsAssumeRole(t *testing.T) {
+	role := AWSAssumeRoleConfig{
+		RoleARN:    "arn:aws:iam::123456789012:role/ClusterIQReadOnly",
+		ExternalID: "external",
+	}
+	creds, err := newAWSCredentials("AKIAEXAMPLE", "secret", role, DefaultAWSRegion)
+	assert.Nil(t, err)
+	assert.NotNil(t, creds)
+	// The role is not assumed until the credentials are requested for the first time
+	assert.True(t, creds.IsExpired())
+}
+
+// TestNewAWSCredentialsWebIdentityWithoutRole verifies the web identity token can't be used without a role
+func TestNewAWSCredentialsWebIdentityWithoutRole(t *testing.T) {
+	creds, err := newAWSCredentials("", "", AWSAssumeRoleConfig{WebIdentityTokenFile: "/var/run/secrets/token"}, DefaultAWSRegion)
+	assert.NotNil(t, err)
+	assert.Nil(t, creds)
+}
+
+// useSharedConfigFiles makes the AWS SDK read the specified shared config
+// file content, with an empty shared credentials file
+func useSharedConfigFiles(t *testing.T, config string) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	credentialsPath := filepath.Join(dir, "credentials")
+	assert.Nil(t, os.WriteFile(configPath, []byte(config), 0o600))
+	assert.Nil(t, os.WriteFile(credentialsPath, nil, 0o600))
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsPath)
+	t.Setenv("AWS_PROFILE", "")
+}
+
+// TestNewAWSCredentialsSourceProfileFromConfig verifies the source profiles defined only on the shared config file are resolved
+func TestNewAWSCredentialsSourceProfileFromConfig(t *testing.T) {
+	process := filepath.Join(t.TempDir(), "credential-process")
+	assert.Nil(t, os.WriteFile(process, []byte(`#!/bin/sh
+echo '{"Version": 1, "AccessKeyId": "AKIAPROCESS", "SecretAccessKey": "process-secret"}'
+`), 0o700))
+	useSharedConfigFiles(t, "[profile hub]\ncredential_process = "+process+"\n")
+	creds, err := newAWSCredentials("", "", AWSAssumeRoleConfig{SourceProfile: "hub"}, DefaultAWSRegion)
+	assert.Nil(t, err)
+	assert.NotNil(t, creds)
+
+	value, err := creds.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, "AKIAPROCESS", value.AccessKeyID)
+	assert.Equal(t, "process-secret", value.SecretAccessKey)
+}
+
+// TestNewAWSCredentialsMissingSourceProfile verifies the source profiles not defined on any shared file are rejected
+func TestNewAWSCredentialsMissingSourceProfile(t *testing.T) {
+	useSharedConfigFiles(t, "")
+	creds, err := newAWSCredentials("", "", AWSAssumeRoleConfig{SourceProfile: "hub"}, DefaultAWSRegion)
+	assert.NotNil(t, err)
+	assert.Nil(t, creds)
+}
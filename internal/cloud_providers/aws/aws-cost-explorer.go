@@ -1,15 +1,46 @@
 package cloudprovider
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/costexplorer"
 )
 
+const (
+	// Cost Explorer SERVICE dimension value for the EC2 instances costs
+	costExplorerEC2ComputeService = "Amazon Elastic Compute Cloud - Compute"
+	// Cost metric requested to Cost Explorer
+	costExplorerCostMetric = "UnblendedCost"
+	// Date format of the Cost Explorer time periods
+	costExplorerDateFormat = "2006-01-02"
+	// Separator between the key and the value of the Cost Explorer tag groups
+	costExplorerTagSeparator = "$"
+)
+
 // AWSCostExplorerConnection represents the client object for the CostExplorer service
 type AWSCostExplorerConnection struct {
 	client *costexplorer.CostExplorer
 }
 
+// DailyCost is the cost of a Cost Explorer group (a resource, a service...) during a day
+type DailyCost struct {
+	Group  string
+	Date   time.Time
+	Amount float64
+}
+
+// TaggedDailyCost is the daily cost of a Cost Explorer group of the resources
+// with the same tag value
+type TaggedDailyCost struct {
+	TagValue string
+	DailyCost
+}
+
 // NewAWSCostExplorerConnection creates a new AWSCostExplorerConnection object
 func NewAWSCostExplorerConnection(session *session.Session) *AWSCostExplorerConnection {
 	return &AWSCostExplorerConnection{
@@ -28,3 +59,218 @@ func WithCostExplorer() AWSConnectionOption {
 func (c *AWSCostExplorerConnection) GetCostAndUsageWithResources(input *costexplorer.GetCostAndUsageWithResourcesInput) (*costexplorer.GetCostAndUsageWithResourcesOutput, error) {
 	return c.client.GetCostAndUsageWithResources(input)
 }
+
+// GetEC2ResourcesDailyCosts returns the daily cost of every EC2 instance of
+// the account between start (included) and end (excluded). The costs of all
+// the instances are obtained on the same query grouped by RESOURCE_ID, so
+// only one request per results page is made. Cost Explorer only keeps the
+// resource level data of the last 14 days
+// Doc: (https://docs.aws.amazon.com/sdk-for-go/api/service/costexplorer/#CostExplorer.GetCostAndUsageWithResources)
+func (c *AWSCostExplorerConnection) GetEC2ResourcesDailyCosts(start time.Time, end time.Time) ([]DailyCost, error) {
+	input := &costexplorer.GetCostAndUsageWithResourcesInput{
+		TimePeriod: &costexplorer.DateInterval{
+			Start: aws.String(start.Format(costExplorerDateFormat)),
+			End:   aws.String(end.Format(costExplorerDateFormat)),
+		},
+		Granularity: aws.String(costexplorer.GranularityDaily),
+		Filter: &costexplorer.Expression{
+			Dimensions: &costexplorer.DimensionValues{
+				Key:    aws.String(costexplorer.DimensionService),
+				Values: []*string{aws.String(costExplorerEC2ComputeService)},
+			},
+		},
+		GroupBy: []*costexplorer.GroupDefinition{
+			{
+				Type: aws.String(costexplorer.GroupDefinitionTypeDimension),
+				Key:  aws.String(costexplorer.DimensionResourceId),
+			},
+		},
+		Metrics: []*string{aws.String(costExplorerCostMetric)},
+	}
+
+	var costs []DailyCost
+	for {
+		result, err := c.client.GetCostAndUsageWithResources(input)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting cost and usage with resources: %w", err)
+		}
+
+		pageCosts, err := groupsDailyCosts(result.ResultsByTime)
+		if err != nil {
+			return nil, err
+		}
+		costs = append(costs, pageCosts...)
+
+		// Continue if there are more results pages
+		if aws.StringValue(result.NextPageToken) == "" {
+			break
+		}
+		input.NextPageToken = result.NextPageToken
+	}
+
+	return costs, nil
+}
+
+// GetTaggedServicesDailyCosts returns the daily cost of every service used by
+// the resources tagged with tagKey=tagValue, between start (included) and end
+// (excluded). The tag must be activated as a cost allocation tag, and the
+// costs are only available since its activation
+// Doc: (https://docs.aws.amazon.com/sdk-for-go/api/service/costexplorer/#CostExplorer.GetCostAndUsage)
+func (c *AWSCostExplorerConnection) GetTaggedServicesDailyCosts(tagKey string, tagValue string, start time.Time, end time.Time) ([]DailyCost, error) {
+	input := &costexplorer.GetCostAndUsageInput{
+		TimePeriod: &costexplorer.DateInterval{
+			Start: aws.String(start.Format(costExplorerDateFormat)),
+			End:   aws.String(end.Format(costExplorerDateFormat)),
+		},
+		Granularity: aws.String(costexplorer.GranularityDaily),
+		Filter: &costexplorer.Expression{
+			Tags: &costexplorer.TagValues{
+				Key:    aws.String(tagKey),
+				Values: []*string{aws.String(tagValue)},
+			},
+		},
+		GroupBy: []*costexplorer.GroupDefinition{
+			{
+				Type: aws.String(costexplorer.GroupDefinitionTypeDimension),
+				Key:  aws.String(costexplorer.DimensionService),
+			},
+		},
+		Metrics: []*string{aws.String(costExplorerCostMetric)},
+	}
+
+	var costs []DailyCost
+	for {
+		result, err := c.client.GetCostAndUsage(input)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting cost and usage of tag %s: %w", tagKey, err)
+		}
+
+		pageCosts, err := groupsDailyCosts(result.ResultsByTime)
+		if err != nil {
+			return nil, err
+		}
+		costs = append(costs, pageCosts...)
+
+		// Continue if there are more results pages
+		if aws.StringValue(result.NextPageToken) == "" {
+			break
+		}
+		input.NextPageToken = result.NextPageToken
+	}
+
+	return costs, nil
+}
+
+// GetTagValuesServicesDailyCosts returns the daily cost of every service
+// used by the resources of every value of the tagKey tag, between start
+// (included) and end (excluded). The costs of all the tag values are obtained
+// on the same query grouped by the tag and SERVICE, so only one request per
+// results page is made. The costs of the resources without the tag are
+// discarded. The tag must be activated as a cost allocation tag, and the
+// costs are only available since its activation
+// Doc: (https://docs.aws.amazon.com/sdk-for-go/api/service/costexplorer/#CostExplorer.GetCostAndUsage)
+func (c *AWSCostExplorerConnection) GetTagValuesServicesDailyCosts(tagKey string, start time.Time, end time.Time) ([]TaggedDailyCost, error) {
+	input := &costexplorer.GetCostAndUsageInput{
+		TimePeriod: &costexplorer.DateInterval{
+			Start: aws.String(start.Format(costExplorerDateFormat)),
+			End:   aws.String(end.Format(costExplorerDateFormat)),
+		},
+		Granularity: aws.String(costexplorer.GranularityDaily),
+		GroupBy: []*costexplorer.GroupDefinition{
+			{
+				Type: aws.String(costexplorer.GroupDefinitionTypeTag),
+				Key:  aws.String(tagKey),
+			},
+			{
+				Type: aws.String(costexplorer.GroupDefinitionTypeDimension),
+				Key:  aws.String(costexplorer.DimensionService),
+			},
+		},
+		Metrics: []*string{aws.String(costExplorerCostMetric)},
+	}
+
+	var costs []TaggedDailyCost
+	for {
+		result, err := c.client.GetCostAndUsage(input)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting cost and usage by tag %s: %w", tagKey, err)
+		}
+
+		err = forEachGroupCost(result.ResultsByTime, func(keys []string, date time.Time, amount float64) {
+			// The tag groups are returned as "<key>$<value>", with an empty value for the untagged resources
+			_, value, _ := strings.Cut(keys[0], costExplorerTagSeparator)
+			if value == "" || len(keys) < 2 {
+				return
+			}
+			costs = append(costs, TaggedDailyCost{
+				TagValue:  value,
+				DailyCost: DailyCost{Group: keys[1], Date: date, Amount: amount},
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Continue if there are more results pages
+		if aws.StringValue(result.NextPageToken) == "" {
+			break
+		}
+		input.NextPageToken = result.NextPageToken
+	}
+
+	return costs, nil
+}
+
+// groupsDailyCosts returns the daily cost of every group of the Cost Explorer
+// results. The first key of each group identifies it
+func groupsDailyCosts(resultsByTime []*costexplorer.ResultByTime) ([]DailyCost, error) {
+	var costs []DailyCost
+	err := forEachGroupCost(resultsByTime, func(keys []string, date time.Time, amount float64) {
+		costs = append(costs, DailyCost{Group: keys[0], Date: date, Amount: amount})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return costs, nil
+}
+
+// forEachGroupCost calls fn with the keys, the day and the cost of every
+// group of the Cost Explorer results. The groups without keys or cost are
+// skipped
+func forEachGroupCost(resultsByTime []*costexplorer.ResultByTime, fn func(keys []string, date time.Time, amount float64)) error {
+	for _, resultByTime := range resultsByTime {
+		date, err := parseCostExplorerDate(aws.StringValue(resultByTime.TimePeriod.Start))
+		if err != nil {
+			return err
+		}
+
+		for _, group := range resultByTime.Groups {
+			metric, ok := group.Metrics[costExplorerCostMetric]
+			if !ok || len(group.Keys) == 0 {
+				continue
+			}
+			amount, err := strconv.ParseFloat(aws.StringValue(metric.Amount), 64)
+			if err != nil {
+				return fmt.Errorf("Error parsing cost amount %q: %w", aws.StringValue(metric.Amount), err)
+			}
+			fn(aws.StringValueSlice(group.Keys), date, amount)
+		}
+	}
+
+	return nil
+}
+
+// parseCostExplorerDate parses the start of a Cost Explorer time period. It's
+// a date for the daily granularity, and a timestamp for the hourly one
+func parseCostExplorerDate(value string) (time.Time, error) {
+	if date, err := time.Parse(costExplorerDateFormat, value); err == nil {
+		return date, nil
+	}
+
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Error parsing cost period start %q: %w", value, err)
+	}
+	return date, nil
+}
@@ -0,0 +1,148 @@
+package cloudprovider
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCostExplorerAPI serves a Cost Explorer operation returning a fixed list of pages
+type fakeCostExplorerAPI struct {
+	operation string
+	pages     []string
+	requests  []map[string]any
+}
+
+func (f *fakeCostExplorerAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Amz-Target") != "AWSInsightsIndexService."+f.operation {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f.requests = append(f.requests, body)
+
+	page := 0
+	if token, ok := body["NextPageToken"].(string); ok && token == "page-2" {
+		page = 1
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	_, _ = w.Write([]byte(f.pages[page]))
+}
+
+// TestGetEC2ResourcesDailyCosts verifies the costs of every instance are obtained grouped by resource on every results page
+func TestGetEC2ResourcesDailyCosts(t *testing.T) {
+	api := &fakeCostExplorerAPI{
+		operation: "GetCostAndUsageWithResources",
+		pages: []string{
+			`{"ResultsByTime": [{"TimePeriod": {"Start": "2024-05-01", "End": "2024-05-02"}, "Groups": [
+				{"Keys": ["i-0001"], "Metrics": {"UnblendedCost": {"Amount": "1.5", "Unit": "USD"}}},
+				{"Keys": ["i-0002"], "Metrics": {"UnblendedCost": {"Amount": "0.25", "Unit": "USD"}}}
+			]}], "NextPageToken": "page-2"}`,
+			`{"ResultsByTime": [{"TimePeriod": {"Start": "2024-05-02", "End": "2024-05-03"}, "Groups": [
+				{"Keys": ["i-0001"], "Metrics": {"UnblendedCost": {"Amount": "2", "Unit": "USD"}}}
+			]}]}`,
+		},
+	}
+	conn := NewAWSCostExplorerConnection(newFakeAWSSession(t, api))
+
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	costs, err := conn.GetEC2ResourcesDailyCosts(start, start.AddDate(0, 0, 2))
+	assert.Nil(t, err)
+	assert.Equal(t, []DailyCost{
+		{Group: "i-0001", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Amount: 1.5},
+		{Group: "i-0002", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Amount: 0.25},
+		{Group: "i-0001", Date: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), Amount: 2},
+	}, costs)
+
+	assert.Len(t, api.requests, 2)
+	assert.Equal(t, map[string]any{"Start": "2024-05-01", "End": "2024-05-03"}, api.requests[0]["TimePeriod"])
+	assert.Equal(t, []any{map[string]any{"Type": "DIMENSION", "Key": "RESOURCE_ID"}}, api.requests[0]["GroupBy"])
+}
+
+// TestGetTaggedServicesDailyCosts verifies the costs of the tagged resources are obtained grouped by service
+func TestGetTaggedServicesDailyCosts(t *testing.T) {
+	api := &fakeCostExplorerAPI{
+		operation: "GetCostAndUsage",
+		pages: []string{
+			`{"ResultsByTime": [{"TimePeriod": {"Start": "2024-05-01", "End": "2024-05-02"}, "Groups": [
+				{"Keys": ["Amazon Elastic Compute Cloud - Compute"], "Metrics": {"UnblendedCost": {"Amount": "10.5", "Unit": "USD"}}},
+				{"Keys": ["Amazon Simple Storage Service"], "Metrics": {"UnblendedCost": {"Amount": "0.75", "Unit": "USD"}}}
+			]}], "NextPageToken": "page-2"}`,
+			`{"ResultsByTime": [{"TimePeriod": {"Start": "2024-05-02", "End": "2024-05-03"}, "Groups": [
+				{"Keys": ["Amazon Elastic Load Balancing"], "Metrics": {"UnblendedCost": {"Amount": "3", "Unit": "USD"}}}
+			]}]}`,
+		},
+	}
+	conn := NewAWSCostExplorerConnection(newFakeAWSSession(t, api))
+
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	costs, err := conn.GetTaggedServicesDailyCosts("kubernetes.io/cluster/test-abcde", "owned", start, start.AddDate(0, 0, 2))
+	assert.Nil(t, err)
+	assert.Equal(t, []DailyCost{
+		{Group: "Amazon Elastic Compute Cloud - Compute", Date: start, Amount: 10.5},
+		{Group: "Amazon Simple Storage Service", Date: start, Amount: 0.75},
+		{Group: "Amazon Elastic Load Balancing", Date: start.AddDate(0, 0, 1), Amount: 3},
+	}, costs)
+
+	assert.Len(t, api.requests, 2)
+	assert.Equal(t, map[string]any{"Tags": map[string]any{"Key": "kubernetes.io/cluster/test-abcde", "Values": []any{"owned"}}}, api.requests[0]["Filter"])
+	assert.Equal(t, []any{map[string]any{"Type": "DIMENSION", "Key": "SERVICE"}}, api.requests[0]["GroupBy"])
+	assert.Equal(t, "page-2", api.requests[1]["NextPageToken"])
+}
+
+// TestGetTagValuesServicesDailyCosts verifies the costs of every tag value are obtained by service on a single query, skipping the untagged resources
+func TestGetTagValuesServicesDailyCosts(t *testing.T) {
+	api := &fakeCostExplorerAPI{
+		operation: "GetCostAndUsage",
+		pages: []string{
+			`{"ResultsByTime": [{"TimePeriod": {"Start": "2024-05-01", "End": "2024-05-02"}, "Groups": [
+				{"Keys": ["cluster-name$test-abcde", "Amazon Elastic Compute Cloud - Compute"], "Metrics": {"UnblendedCost": {"Amount": "10.5", "Unit": "USD"}}},
+				{"Keys": ["cluster-name$other-fghij", "Amazon Simple Storage Service"], "Metrics": {"UnblendedCost": {"Amount": "0.75", "Unit": "USD"}}},
+				{"Keys": ["cluster-name$", "Amazon Route 53"], "Metrics": {"UnblendedCost": {"Amount": "0.5", "Unit": "USD"}}}
+			]}], "NextPageToken": "page-2"}`,
+			`{"ResultsByTime": [{"TimePeriod": {"Start": "2024-05-02", "End": "2024-05-03"}, "Groups": [
+				{"Keys": ["cluster-name$test-abcde", "Amazon Elastic Load Balancing"], "Metrics": {"UnblendedCost": {"Amount": "3", "Unit": "USD"}}}
+			]}]}`,
+		},
+	}
+	conn := NewAWSCostExplorerConnection(newFakeAWSSession(t, api))
+
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	costs, err := conn.GetTagValuesServicesDailyCosts("cluster-name", start, start.AddDate(0, 0, 2))
+	assert.Nil(t, err)
+	assert.Equal(t, []TaggedDailyCost{
+		{TagValue: "test-abcde", DailyCost: DailyCost{Group: "Amazon Elastic Compute Cloud - Compute", Date: start, Amount: 10.5}},
+		{TagValue: "other-fghij", DailyCost: DailyCost{Group: "Amazon Simple Storage Service", Date: start, Amount: 0.75}},
+		{TagValue: "test-abcde", DailyCost: DailyCost{Group: "Amazon Elastic Load Balancing", Date: start.AddDate(0, 0, 1), Amount: 3}},
+	}, costs)
+
+	assert.Len(t, api.requests, 2)
+	assert.Nil(t, api.requests[0]["Filter"])
+	assert.Equal(t, []any{
+		map[string]any{"Type": "TAG", "Key": "cluster-name"},
+		map[string]any{"Type": "DIMENSION", "Key": "SERVICE"},
+	}, api.requests[0]["GroupBy"])
+	assert.Equal(t, "page-2", api.requests[1]["NextPageToken"])
+}
+
+// TestParseCostExplorerDate verifies the daily and hourly periods start are parsed
+func TestParseCostExplorerDate(t *testing.T) {
+	date, err := parseCostExplorerDate("2024-05-01")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), date)
+
+	date, err = parseCostExplorerDate("2024-05-01T13:00:00Z")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC), date)
+
+	_, err = parseCostExplorerDate("yesterday")
+	assert.NotNil(t, err)
+}
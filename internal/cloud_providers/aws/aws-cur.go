@@ -0,0 +1,317 @@
+package cloudprovider
+
+import (
+	"cmp"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+const (
+	// CUR columns used for the costs. The Parquet and CUR 2.0 files use these
+	// names, and the legacy CSV files their "lineItem/ResourceId" form
+	curResourceIDColumn     = "line_item_resource_id"
+	curUsageStartDateColumn = "line_item_usage_start_date"
+	curUnblendedCostColumn  = "line_item_unblended_cost"
+	// Number of Parquet rows read at once
+	curParquetBatchSize = 1024
+	// Suffix of the manifest files delivered with every CUR assembly
+	curManifestSuffix = "-Manifest.json"
+	// Layout of the billing period dates on the legacy CUR manifests
+	curManifestDateLayout = "20060102T150405.000Z"
+)
+
+// CURManifest describes an assembly of a CUR billing period. AWS delivers the
+// report several times a day, and every delivery (assembly) contains the
+// whole billing period, so only the files of the latest assembly of every
+// period must be read. The manifest on the billing period directory always
+// points to the latest assembly, and every assembly directory keeps a copy of
+// its own manifest
+type CURManifest struct {
+	AssemblyID    string `json:"assemblyId"`
+	BillingPeriod struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"billingPeriod"`
+	// S3 keys of the CUR files (legacy CUR)
+	ReportKeys []string `json:"reportKeys"`
+	// S3 URLs of the CUR files (CUR 2.0)
+	DataFiles []string `json:"dataFiles"`
+}
+
+// IsCURManifest returns true if the file name is the name of a CUR manifest
+func IsCURManifest(name string) bool {
+	return strings.HasSuffix(name, curManifestSuffix)
+}
+
+// ReadCURManifest decodes a CUR manifest
+func ReadCURManifest(reader io.Reader) (*CURManifest, error) {
+	var manifest CURManifest
+	if err := json.NewDecoder(reader).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("Error decoding CUR manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// IsAssemblyCopy returns true if the manifest located on manifestPath is the
+// copy kept on its assembly directory, which can be an outdated assembly
+func (m CURManifest) IsAssemblyCopy(manifestPath string) bool {
+	return m.AssemblyID != "" && path.Base(path.Dir(manifestPath)) == m.AssemblyID
+}
+
+// EndsAfter returns true if the billing period of the manifest ends after
+// the specified date. The manifests without a valid billing period end are
+// considered recent, as their line items are filtered by date anyway
+func (m CURManifest) EndsAfter(date time.Time) bool {
+	end, err := time.Parse(curManifestDateLayout, m.BillingPeriod.End)
+	if err != nil {
+		if end, err = time.Parse(time.RFC3339, m.BillingPeriod.End); err != nil {
+			return true
+		}
+	}
+	return end.After(date)
+}
+
+// Keys returns the S3 keys of the CUR files of the assembly
+func (m CURManifest) Keys() []string {
+	keys := slices.Clone(m.ReportKeys)
+	for _, dataFile := range m.DataFiles {
+		if _, key, ok := ParseS3URL(dataFile); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// curParquetLineItem defines the columns read from the CUR Parquet files.
+// The rest of the columns are ignored
+type curParquetLineItem struct {
+	ResourceID     string    `parquet:"line_item_resource_id,optional"`
+	UsageStartDate time.Time `parquet:"line_item_usage_start_date,timestamp(millisecond)"`
+	UnblendedCost  float64   `parquet:"line_item_unblended_cost"`
+}
+
+// curCostKey identifies the cost of a resource during a day
+type curCostKey struct {
+	resourceID string
+	date       time.Time
+}
+
+// CURReport adds up the daily costs of the resources found on the line items
+// of AWS Cost and Usage Report (CUR) files. It supports the legacy CUR and the
+// CUR 2.0 exports on CSV (plain or gzip compressed) and Parquet formats. Only
+// the line items of the resources accepted by the filter and started since
+// the specified date are added
+type CURReport struct {
+	since  time.Time
+	filter func(resourceID string) bool
+	costs  map[curCostKey]float64
+}
+
+// NewCURReport creates a new empty CURReport
+func NewCURReport(since time.Time, filter func(resourceID string) bool) *CURReport {
+	return &CURReport{
+		since:  since,
+		filter: filter,
+		costs:  make(map[curCostKey]float64),
+	}
+}
+
+// IsCURFile returns true if the file name has the extension of a CUR data file
+func IsCURFile(name string) bool {
+	return strings.HasSuffix(name, ".csv") || strings.HasSuffix(name, ".csv.gz") || strings.HasSuffix(name, ".parquet")
+}
+
+// ReadFile adds the line items of a CUR file. The format is chosen by the file extension
+func (r *CURReport) ReadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch {
+	case strings.HasSuffix(path, ".parquet"):
+		var info os.FileInfo
+		if info, err = file.Stat(); err == nil {
+			err = r.ReadParquet(file, info.Size())
+		}
+	case strings.HasSuffix(path, ".csv.gz"):
+		var gzipReader *gzip.Reader
+		if gzipReader, err = gzip.NewReader(file); err == nil {
+			defer gzipReader.Close()
+			err = r.ReadCSV(gzipReader)
+		}
+	case strings.HasSuffix(path, ".csv"):
+		err = r.ReadCSV(file)
+	default:
+		return fmt.Errorf("unsupported CUR file format: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("Error reading CUR file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// ReadCSV adds the line items of a CUR CSV file
+func (r *CURReport) ReadCSV(reader io.Reader) error {
+	csvReader := csv.NewReader(reader)
+	csvReader.ReuseRecord = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return fmt.Errorf("Error reading CUR header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[normalizeCURColumn(name)] = i
+	}
+	resourceIDIndex, ok1 := columns[curResourceIDColumn]
+	startDateIndex, ok2 := columns[curUsageStartDateColumn]
+	costIndex, ok3 := columns[curUnblendedCostColumn]
+	if !ok1 || !ok2 || !ok3 {
+		return fmt.Errorf("missing CUR columns: %s, %s and %s are required", curResourceIDColumn, curUsageStartDateColumn, curUnblendedCostColumn)
+	}
+
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		resourceID := record[resourceIDIndex]
+		if resourceID == "" || !r.filter(resourceID) {
+			continue
+		}
+
+		startDate, err := parseCURDate(record[startDateIndex])
+		if err != nil {
+			return err
+		}
+		cost, err := strconv.ParseFloat(record[costIndex], 64)
+		if err != nil {
+			return fmt.Errorf("Error parsing CUR cost %q: %w", record[costIndex], err)
+		}
+		r.addLineItem(resourceID, startDate, cost)
+	}
+
+	return nil
+}
+
+// ReadParquet adds the line items of a CUR Parquet file
+func (r *CURReport) ReadParquet(reader io.ReaderAt, size int64) error {
+	file, err := parquet.OpenFile(reader, size)
+	if err != nil {
+		return err
+	}
+
+	for _, column := range []string{curResourceIDColumn, curUsageStartDateColumn, curUnblendedCostColumn} {
+		if _, ok := file.Schema().Lookup(column); !ok {
+			return fmt.Errorf("missing CUR columns: %s, %s and %s are required", curResourceIDColumn, curUsageStartDateColumn, curUnblendedCostColumn)
+		}
+	}
+
+	parquetReader := parquet.NewGenericReader[curParquetLineItem](file)
+	defer parquetReader.Close()
+
+	lineItems := make([]curParquetLineItem, curParquetBatchSize)
+	for {
+		n, err := parquetReader.Read(lineItems)
+		for _, lineItem := range lineItems[:n] {
+			if lineItem.ResourceID == "" || !r.filter(lineItem.ResourceID) {
+				continue
+			}
+			r.addLineItem(lineItem.ResourceID, lineItem.UsageStartDate, lineItem.UnblendedCost)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addLineItem adds the cost of a line item to the cost of its resource on the usage day
+func (r *CURReport) addLineItem(resourceID string, startDate time.Time, cost float64) {
+	if startDate.Before(r.since) {
+		return
+	}
+	date := startDate.UTC().Truncate(24 * time.Hour)
+	r.costs[curCostKey{resourceID: resourceID, date: date}] += cost
+}
+
+// DailyCosts returns the daily cost of every resource, sorted by date and
+// resource. The costs are rounded to cents, discarding the floating point
+// residue of the line items netting to zero (e.g. usage and its credit)
+func (r *CURReport) DailyCosts() []DailyCost {
+	costs := make([]DailyCost, 0, len(r.costs))
+	for key, amount := range r.costs {
+		amount = math.Round(amount*100) / 100
+		costs = append(costs, DailyCost{Group: key.resourceID, Date: key.date, Amount: amount})
+	}
+
+	slices.SortFunc(costs, func(a DailyCost, b DailyCost) int {
+		if c := a.Date.Compare(b.Date); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Group, b.Group)
+	})
+
+	return costs
+}
+
+// normalizeCURColumn converts the legacy CSV column names
+// ("lineItem/ResourceId") into the Parquet and CUR 2.0 ones
+// ("line_item_resource_id")
+func normalizeCURColumn(name string) string {
+	var builder strings.Builder
+	previous := '_'
+	for _, char := range name {
+		switch {
+		case char == '/':
+			char = '_'
+		case unicode.IsUpper(char):
+			if previous != '_' {
+				builder.WriteRune('_')
+			}
+			char = unicode.ToLower(char)
+		}
+		builder.WriteRune(char)
+		previous = char
+	}
+	return builder.String()
+}
+
+// parseCURDate parses the line items dates. The legacy CUR files use RFC3339
+// timestamps and the CUR 2.0 CSV files use "YYYY-MM-DD hh:mm:ss" timestamps
+func parseCURDate(value string) (time.Time, error) {
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+	date, err := time.Parse(time.DateTime, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Error parsing CUR date %q: %w", value, err)
+	}
+	return date, nil
+}
@@ -0,0 +1,165 @@
+package cloudprovider
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCURParquetLineItem is a CUR Parquet line item including columns not read by the CURReport
+type testCURParquetLineItem struct {
+	PayerAccountID string    `parquet:"bill_payer_account_id"`
+	LineItemType   string    `parquet:"line_item_line_item_type"`
+	ResourceID     string    `parquet:"line_item_resource_id,optional"`
+	UsageStartDate time.Time `parquet:"line_item_usage_start_date,timestamp(millisecond)"`
+	UnblendedCost  float64   `parquet:"line_item_unblended_cost"`
+}
+
+// testCURCSV is a legacy CUR CSV file with line items of two days, a resource not accepted by the filter, a line item without resource and an old line item
+const testCURCSV = `identity/LineItemId,lineItem/LineItemType,lineItem/UsageStartDate,lineItem/ResourceId,lineItem/UnblendedCost
+a,Usage,2024-05-01T00:00:00Z,i-0001,1.5
+b,Usage,2024-05-01T01:00:00Z,i-0001,0.5
+c,Usage,2024-05-01T01:00:00Z,i-0002,0.25
+d,Usage,2024-05-02T00:00:00Z,i-0001,2
+e,Usage,2024-05-02T00:00:00Z,i-9999,100
+f,Tax,2024-05-02T00:00:00Z,,10
+g,Usage,2024-04-01T00:00:00Z,i-0001,7
+`
+
+// newTestCURReport returns a CURReport accepting the i-0001 and i-0002 resources since May 2024
+func newTestCURReport() *CURReport {
+	return NewCURReport(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), func(resourceID string) bool {
+		return resourceID == "i-0001" || resourceID == "i-0002"
+	})
+}
+
+// TestCURReportReadCSV verifies the daily costs of the accepted resources are added up from the CSV files
+func TestCURReportReadCSV(t *testing.T) {
+	report := newTestCURReport()
+	assert.Nil(t, report.ReadCSV(strings.NewReader(testCURCSV)))
+
+	assert.Equal(t, []DailyCost{
+		{Group: "i-0001", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Amount: 2},
+		{Group: "i-0002", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Amount: 0.25},
+		{Group: "i-0001", Date: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), Amount: 2},
+	}, report.DailyCosts())
+
+	assert.NotNil(t, report.ReadCSV(strings.NewReader("identity/LineItemId,lineItem/ResourceId\na,i-0001\n")))
+}
+
+// TestCURReportReadFile verifies the CSV, compressed CSV and Parquet files are read by their extension
+func TestCURReportReadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// Plain and compressed CSV files with the same line items
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "report-1.csv"), []byte(testCURCSV), 0o600))
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	_, err := gzipWriter.Write([]byte(testCURCSV))
+	assert.Nil(t, err)
+	assert.Nil(t, gzipWriter.Close())
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "report-2.csv.gz"), compressed.Bytes(), 0o600))
+
+	// Parquet file
+	var parquetFile bytes.Buffer
+	writer := parquet.NewGenericWriter[testCURParquetLineItem](&parquetFile)
+	_, err = writer.Write([]testCURParquetLineItem{
+		{PayerAccountID: "123456789012", LineItemType: "Usage", ResourceID: "i-0002", UsageStartDate: time.Date(2024, 5, 1, 5, 0, 0, 0, time.UTC), UnblendedCost: 0.75},
+		{PayerAccountID: "123456789012", LineItemType: "Usage", ResourceID: "i-9999", UsageStartDate: time.Date(2024, 5, 1, 5, 0, 0, 0, time.UTC), UnblendedCost: 100},
+		{PayerAccountID: "123456789012", LineItemType: "Tax", UsageStartDate: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), UnblendedCost: 10},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "report-3.snappy.parquet"), parquetFile.Bytes(), 0o600))
+
+	report := newTestCURReport()
+	for _, name := range []string{"report-1.csv", "report-2.csv.gz", "report-3.snappy.parquet"} {
+		assert.True(t, IsCURFile(name))
+		assert.Nil(t, report.ReadFile(filepath.Join(dir, name)))
+	}
+
+	assert.Equal(t, []DailyCost{
+		{Group: "i-0001", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Amount: 4},
+		{Group: "i-0002", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Amount: 1.25},
+		{Group: "i-0001", Date: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), Amount: 4},
+	}, report.DailyCosts())
+
+	assert.False(t, IsCURFile("report-Manifest.json"))
+	assert.NotNil(t, report.ReadFile(filepath.Join(dir, "report-Manifest.json")))
+}
+
+// TestNormalizeCURColumn verifies the legacy CSV column names are converted into the Parquet ones
+func TestNormalizeCURColumn(t *testing.T) {
+	assert.Equal(t, curResourceIDColumn, normalizeCURColumn("lineItem/ResourceId"))
+	assert.Equal(t, curUsageStartDateColumn, normalizeCURColumn("lineItem/UsageStartDate"))
+	assert.Equal(t, curUnblendedCostColumn, normalizeCURColumn("lineItem/UnblendedCost"))
+	assert.Equal(t, curUnblendedCostColumn, normalizeCURColumn(curUnblendedCostColumn))
+}
+
+// TestParseCURDate verifies the legacy and CUR 2.0 dates are parsed
+func TestParseCURDate(t *testing.T) {
+	date, err := parseCURDate("2024-05-01T13:00:00Z")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC), date)
+
+	date, err = parseCURDate("2024-05-01 13:00:00")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC), date)
+
+	_, err = parseCURDate("yesterday")
+	assert.NotNil(t, err)
+}
+
+// TestCURReportCredits verifies the daily costs are rounded to cents, so the line items netting to zero don't leave residues
+func TestCURReportCredits(t *testing.T) {
+	report := newTestCURReport()
+	assert.Nil(t, report.ReadCSV(strings.NewReader(`lineItem/UsageStartDate,lineItem/ResourceId,lineItem/UnblendedCost
+2024-05-01T00:00:00Z,i-0001,0.1
+2024-05-01T01:00:00Z,i-0001,0.2
+2024-05-01T02:00:00Z,i-0001,-0.3
+2024-05-01T00:00:00Z,i-0002,1.004
+2024-05-01T01:00:00Z,i-0002,-2
+`)))
+
+	assert.Equal(t, []DailyCost{
+		{Group: "i-0001", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Amount: 0},
+		{Group: "i-0002", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Amount: -1},
+	}, report.DailyCosts())
+}
+
+// TestCURManifest verifies the assembly copies and the billing period of the legacy and CUR 2.0 manifests are identified
+func TestCURManifest(t *testing.T) {
+	assert.True(t, IsCURManifest("cur/report/20240501-20240601/report-Manifest.json"))
+	assert.False(t, IsCURManifest("cur/report/20240501-20240601/report-1.csv.gz"))
+
+	manifest, err := ReadCURManifest(strings.NewReader(`{
+		"assemblyId": "7a9c2b1e",
+		"billingPeriod": {"start": "20240501T000000.000Z", "end": "20240601T000000.000Z"},
+		"reportKeys": ["cur/report/20240501-20240601/7a9c2b1e/report-1.csv.gz"]
+	}`))
+	assert.Nil(t, err)
+	assert.False(t, manifest.IsAssemblyCopy("cur/report/20240501-20240601/report-Manifest.json"))
+	assert.True(t, manifest.IsAssemblyCopy("cur/report/20240501-20240601/7a9c2b1e/report-Manifest.json"))
+	assert.True(t, manifest.EndsAfter(time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, manifest.EndsAfter(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, []string{"cur/report/20240501-20240601/7a9c2b1e/report-1.csv.gz"}, manifest.Keys())
+
+	manifest, err = ReadCURManifest(strings.NewReader(`{
+		"assemblyId": "4f1d",
+		"billingPeriod": {"start": "2024-05-01T00:00:00.000Z", "end": "2024-06-01T00:00:00.000Z"},
+		"dataFiles": ["s3://cur-bucket/export/data/BILLING_PERIOD=2024-05/export-00001.snappy.parquet"]
+	}`))
+	assert.Nil(t, err)
+	assert.False(t, manifest.EndsAfter(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, []string{"export/data/BILLING_PERIOD=2024-05/export-00001.snappy.parquet"}, manifest.Keys())
+
+	_, err = ReadCURManifest(strings.NewReader("not a manifest"))
+	assert.NotNil(t, err)
+}
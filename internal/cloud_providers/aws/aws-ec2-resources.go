@@ -0,0 +1,164 @@
+package cloudprovider
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	// Status of an Elastic IP associated to an instance or network interface
+	elasticIPAssociatedStatus = "associated"
+	// Status of an Elastic IP not associated to any resource
+	elasticIPUnassociatedStatus = "unassociated"
+)
+
+// GetVolumes gets the list of EBS volumes of the configured region and returns them as an Array of inventory.Resource
+// Doc: (https://docs.aws.amazon.com/sdk-for-go/api/service/ec2/#EC2.DescribeVolumesPages)
+func (c *AWSEC2Connection) GetVolumes() ([]inventory.Resource, error) {
+	var volumes []*ec2.Volume
+
+	err := c.client.DescribeVolumesPages(&ec2.DescribeVolumesInput{},
+		func(page *ec2.DescribeVolumesOutput, lastPage bool) bool {
+			volumes = append(volumes, page.Volumes...)
+			return !lastPage // Continue if there are more Volumes pages
+		})
+	if err != nil {
+		return nil, fmt.Errorf("Error getting EBS volumes: %w", err)
+	}
+
+	resources := make([]inventory.Resource, 0, len(volumes))
+	for _, volume := range volumes {
+		resources = append(resources, *EBSVolumeToInventoryResource(volume))
+	}
+
+	return resources, nil
+}
+
+// GetSnapshots gets the list of EBS snapshots owned by the account in the configured region and returns them as an Array of inventory.Resource
+// Doc: (https://docs.aws.amazon.com/sdk-for-go/api/service/ec2/#EC2.DescribeSnapshotsPages)
+func (c *AWSEC2Connection) GetSnapshots() ([]inventory.Resource, error) {
+	var snapshots []*ec2.Snapshot
+
+	// Public and shared snapshots are ignored, they are not billed to the account
+	input := &ec2.DescribeSnapshotsInput{
+		OwnerIds: aws.StringSlice([]string{"self"}),
+	}
+
+	err := c.client.DescribeSnapshotsPages(input,
+		func(page *ec2.DescribeSnapshotsOutput, lastPage bool) bool {
+			snapshots = append(snapshots, page.Snapshots...)
+			return !lastPage // Continue if there are more Snapshots pages
+		})
+	if err != nil {
+		return nil, fmt.Errorf("Error getting EBS snapshots: %w", err)
+	}
+
+	resources := make([]inventory.Resource, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		resources = append(resources, *EBSSnapshotToInventoryResource(snapshot, c.GetRegion()))
+	}
+
+	return resources, nil
+}
+
+// GetElasticIPs gets the list of Elastic IPs of the configured region and returns them as an Array of inventory.Resource.
+// The addresses without AllocationId (EC2-Classic) are skipped, as they can't be identified
+// Doc: (https://docs.aws.amazon.com/sdk-for-go/api/service/ec2/#EC2.DescribeAddresses)
+func (c *AWSEC2Connection) GetElasticIPs() ([]inventory.Resource, error) {
+	// DescribeAddresses doesn't support pagination
+	result, err := c.client.DescribeAddresses(&ec2.DescribeAddressesInput{})
+	if err != nil {
+		return nil, fmt.Errorf("Error getting Elastic IPs: %w", err)
+	}
+
+	resources := make([]inventory.Resource, 0, len(result.Addresses))
+	for _, address := range result.Addresses {
+		if aws.StringValue(address.AllocationId) == "" {
+			continue
+		}
+		resources = append(resources, *ElasticIPToInventoryResource(address, c.GetRegion()))
+	}
+
+	return resources, nil
+}
+
+// EBSVolumeToInventoryResource converts an EC2 Volume into an inventory.Resource
+func EBSVolumeToInventoryResource(volume *ec2.Volume) *inventory.Resource {
+	id := aws.StringValue(volume.VolumeId)
+	tags := ConvertEC2TagtoTag(volume.Tags, id)
+
+	// Only the first attachment is considered. Multi-Attach volumes are not used by OpenShift
+	var instanceID string
+	if len(volume.Attachments) > 0 {
+		instanceID = aws.StringValue(volume.Attachments[0].InstanceId)
+	}
+
+	return inventory.NewResource(
+		id,
+		inventory.GetInstanceNameFromTags(tags),
+		inventory.VolumeResourceType,
+		inventory.AWSProvider,
+		aws.StringValue(volume.AvailabilityZone),
+		aws.StringValue(volume.State),
+		aws.StringValue(volume.VolumeType),
+		aws.Int64Value(volume.Size),
+		instanceID,
+		tags,
+		aws.TimeValue(volume.CreateTime),
+	)
+}
+
+// EBSSnapshotToInventoryResource converts an EC2 Snapshot into an inventory.Resource
+func EBSSnapshotToInventoryResource(snapshot *ec2.Snapshot, region string) *inventory.Resource {
+	id := aws.StringValue(snapshot.SnapshotId)
+	tags := ConvertEC2TagtoTag(snapshot.Tags, id)
+
+	return inventory.NewResource(
+		id,
+		inventory.GetInstanceNameFromTags(tags),
+		inventory.SnapshotResourceType,
+		inventory.AWSProvider,
+		region,
+		aws.StringValue(snapshot.State),
+		aws.StringValue(snapshot.StorageTier),
+		aws.Int64Value(snapshot.VolumeSize),
+		"",
+		tags,
+		aws.TimeValue(snapshot.StartTime),
+	)
+}
+
+// ElasticIPToInventoryResource converts an EC2 Address into an inventory.Resource.
+// The Elastic IPs don't have a creation timestamp, so it remains empty
+func ElasticIPToInventoryResource(address *ec2.Address, region string) *inventory.Resource {
+	id := aws.StringValue(address.AllocationId)
+	tags := ConvertEC2TagtoTag(address.Tags, id)
+
+	name := inventory.GetInstanceNameFromTags(tags)
+	if name == "" {
+		name = aws.StringValue(address.PublicIp)
+	}
+
+	status := elasticIPUnassociatedStatus
+	if address.AssociationId != nil {
+		status = elasticIPAssociatedStatus
+	}
+
+	return inventory.NewResource(
+		id,
+		name,
+		inventory.ElasticIPResourceType,
+		inventory.AWSProvider,
+		region,
+		status,
+		aws.StringValue(address.Domain),
+		0,
+		aws.StringValue(address.InstanceId),
+		tags,
+		time.Time{},
+	)
+}
@@ -0,0 +1,60 @@
+package cloudprovider
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeEC2AddressesAPI serves the DescribeAddresses operation with a fixed set of addresses
+type fakeEC2AddressesAPI struct {
+	addresses string
+}
+
+func (f *fakeEC2AddressesAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.Form.Get("Action") != "DescribeAddresses" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	fmt.Fprintf(w, `<DescribeAddressesResponse><addressesSet>%s</addressesSet></DescribeAddressesResponse>`, f.addresses)
+}
+
+// TestGetElasticIPs verifies the Elastic IPs are converted into resources, skipping the addresses without AllocationId
+func TestGetElasticIPs(t *testing.T) {
+	api := &fakeEC2AddressesAPI{addresses: `
+		<item>
+			<allocationId>eipalloc-0001</allocationId>
+			<publicIp>203.0.113.10</publicIp>
+			<domain>vpc</domain>
+			<associationId>eipassoc-0001</associationId>
+			<instanceId>i-0001</instanceId>
+			<tagSet><item><key>Name</key><value>ocp-a1b2c-eip-0</value></item></tagSet>
+		</item>
+		<item>
+			<allocationId>eipalloc-0002</allocationId>
+			<publicIp>203.0.113.11</publicIp>
+			<domain>vpc</domain>
+		</item>
+		<item>
+			<publicIp>203.0.113.12</publicIp>
+			<domain>standard</domain>
+		</item>`}
+	conn := NewAWSEC2Connection(newFakeAWSSession(t, api))
+
+	resources, err := conn.GetElasticIPs()
+	assert.Nil(t, err)
+	assert.Len(t, resources, 2)
+
+	assert.Equal(t, "eipalloc-0001", resources[0].ID)
+	assert.Equal(t, "ocp-a1b2c-eip-0", resources[0].Name)
+	assert.Equal(t, inventory.ElasticIPResourceType, resources[0].Type)
+	assert.Equal(t, elasticIPAssociatedStatus, resources[0].Status)
+	assert.Equal(t, "i-0001", resources[0].InstanceID)
+
+	assert.Equal(t, "eipalloc-0002", resources[1].ID)
+	assert.Equal(t, "203.0.113.11", resources[1].Name)
+	assert.Equal(t, elasticIPUnassociatedStatus, resources[1].Status)
+}
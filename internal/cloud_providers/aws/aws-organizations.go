@@ -0,0 +1,76 @@
+package cloudprovider
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/organizations"
+)
+
+const (
+	// OrganizationsRegion is the region where the Organizations API endpoint is available
+	OrganizationsRegion = "us-east-1"
+)
+
+// OrganizationAccount defines a member account of an AWS Organization
+type OrganizationAccount struct {
+	ID   string
+	Name string
+}
+
+// AWSOrganizationsConnection defines the connection with the AWS Organizations API
+type AWSOrganizationsConnection struct {
+	client *organizations.Organizations
+}
+
+// NewAWSOrganizationsConnection returns a new AWSOrganizationsConnection based on the specified session
+func NewAWSOrganizationsConnection(session *session.Session) *AWSOrganizationsConnection {
+	return &AWSOrganizationsConnection{
+		client: organizations.New(session),
+	}
+}
+
+// WithOrganizations configures an AWSConnection instance for including the Organizations client
+func WithOrganizations() AWSConnectionOption {
+	return func(conn *AWSConnection) {
+		conn.Organizations = NewAWSOrganizationsConnection(conn.awsSession)
+	}
+}
+
+// GetManagementAccountID returns the ID of the management account of the organization
+// Doc: (https://docs.aws.amazon.com/sdk-for-go/api/service/organizations/#Organizations.DescribeOrganization)
+func (c *AWSOrganizationsConnection) GetManagementAccountID() (string, error) {
+	result, err := c.client.DescribeOrganization(&organizations.DescribeOrganizationInput{})
+	if err != nil {
+		return "", fmt.Errorf("Error describing organization: %w", err)
+	}
+
+	return aws.StringValue(result.Organization.MasterAccountId), nil
+}
+
+// GetActiveAccounts returns every ACTIVE account of the organization. Suspended
+// accounts or accounts pending closure are ignored
+// Doc: (https://docs.aws.amazon.com/sdk-for-go/api/service/organizations/#Organizations.ListAccountsPages)
+func (c *AWSOrganizationsConnection) GetActiveAccounts() ([]OrganizationAccount, error) {
+	var accounts []OrganizationAccount
+
+	err := c.client.ListAccountsPages(&organizations.ListAccountsInput{},
+		func(page *organizations.ListAccountsOutput, lastPage bool) bool {
+			for _, account := range page.Accounts {
+				if aws.StringValue(account.Status) != organizations.AccountStatusActive {
+					continue
+				}
+				accounts = append(accounts, OrganizationAccount{
+					ID:   aws.StringValue(account.Id),
+					Name: aws.StringValue(account.Name),
+				})
+			}
+			return !lastPage // Continue if there are more accounts pages
+		})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing organization accounts: %w", err)
+	}
+
+	return accounts, nil
+}
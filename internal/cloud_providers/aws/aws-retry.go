@@ -0,0 +1,106 @@
+package cloudprovider
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+const (
+	// Name of the session handler counting the throttled requests
+	throttlingCounterHandlerName = "cluster-iq.ThrottlingCounter"
+)
+
+// AWSRetryPolicy defines how the failed AWS API requests are retried. The
+// throttled (Throttling, RequestLimitExceeded, TooManyRequests...) and the
+// transient errors are retried with exponential backoff and jitter, and the
+// Retry-After header returned by the API is respected
+type AWSRetryPolicy struct {
+	// MaxRetries is the maximum number of retries for every request
+	MaxRetries int
+	// MinDelay is the minimum delay before retrying a request
+	MinDelay time.Duration
+	// MaxDelay is the maximum delay before retrying a request
+	MaxDelay time.Duration
+}
+
+// DefaultAWSRetryPolicy is the retry policy used when no other is configured
+var DefaultAWSRetryPolicy = AWSRetryPolicy{
+	MaxRetries: 8,
+	MinDelay:   500 * time.Millisecond,
+	MaxDelay:   30 * time.Second,
+}
+
+var (
+	// retryPolicy shared by every AWSConnection
+	retryPolicy      = DefaultAWSRetryPolicy
+	retryPolicyMutex sync.RWMutex
+)
+
+// SetAWSRetryPolicy configures the retry policy for the AWS API requests. It
+// only applies to the connections created after calling it, so it should be
+// configured on the startup
+func SetAWSRetryPolicy(policy AWSRetryPolicy) error {
+	if policy.MaxRetries < 0 {
+		return fmt.Errorf("invalid AWS max retries: %d", policy.MaxRetries)
+	}
+	if policy.MinDelay <= 0 || policy.MaxDelay < policy.MinDelay {
+		return fmt.Errorf("invalid AWS retry delays: min %s, max %s", policy.MinDelay, policy.MaxDelay)
+	}
+
+	retryPolicyMutex.Lock()
+	defer retryPolicyMutex.Unlock()
+	retryPolicy = policy
+
+	return nil
+}
+
+// GetAWSRetryPolicy returns the retry policy for the AWS API requests
+func GetAWSRetryPolicy() AWSRetryPolicy {
+	retryPolicyMutex.RLock()
+	defer retryPolicyMutex.RUnlock()
+	return retryPolicy
+}
+
+// newRetryer returns the SDK retryer implementing the policy. The same delays
+// are used for the throttled and the transient errors
+func (p AWSRetryPolicy) newRetryer() client.DefaultRetryer {
+	return client.DefaultRetryer{
+		NumMaxRetries:    p.MaxRetries,
+		MinRetryDelay:    p.MinDelay,
+		MinThrottleDelay: p.MinDelay,
+		MaxRetryDelay:    p.MaxDelay,
+		MaxThrottleDelay: p.MaxDelay,
+	}
+}
+
+// withRetryPolicy configures the current AWS retry policy on an AWS config
+func withRetryPolicy(cfg *aws.Config) *aws.Config {
+	return request.WithRetryer(cfg, GetAWSRetryPolicy().newRetryer())
+}
+
+// isThrottledRequest returns true if the request failed because the API rate limit was exceeded
+func isThrottledRequest(r *request.Request) bool {
+	if r.Error != nil && request.IsErrorThrottle(r.Error) {
+		return true
+	}
+	return r.HTTPResponse != nil && r.HTTPResponse.StatusCode == http.StatusTooManyRequests
+}
+
+// newThrottlingCounterHandler returns a session handler that counts the throttled requests
+func newThrottlingCounterHandler(counter *atomic.Int64) request.NamedHandler {
+	return request.NamedHandler{
+		Name: throttlingCounterHandlerName,
+		Fn: func(r *request.Request) {
+			if isThrottledRequest(r) {
+				counter.Add(1)
+			}
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package cloudprovider
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSetAWSRetryPolicy verifies the retry policy is validated and applied to the new connections config
+func TestSetAWSRetryPolicy(t *testing.T) {
+	t.Cleanup(func() { _ = SetAWSRetryPolicy(DefaultAWSRetryPolicy) })
+
+	assert.NotNil(t, SetAWSRetryPolicy(AWSRetryPolicy{MaxRetries: -1, MinDelay: time.Second, MaxDelay: time.Second}))
+	assert.NotNil(t, SetAWSRetryPolicy(AWSRetryPolicy{MaxRetries: 3, MinDelay: 0, MaxDelay: time.Second}))
+	assert.NotNil(t, SetAWSRetryPolicy(AWSRetryPolicy{MaxRetries: 3, MinDelay: time.Second, MaxDelay: time.Millisecond}))
+	assert.Equal(t, DefaultAWSRetryPolicy, GetAWSRetryPolicy())
+
+	policy := AWSRetryPolicy{MaxRetries: 3, MinDelay: 100 * time.Millisecond, MaxDelay: 5 * time.Second}
+	assert.Nil(t, SetAWSRetryPolicy(policy))
+	assert.Equal(t, policy, GetAWSRetryPolicy())
+
+	conn := &AWSConnection{region: DefaultAWSRegion}
+	assert.Nil(t, conn.newAWSConfig())
+	retryer, ok := conn.awsConfig.Retryer.(request.Retryer)
+	assert.True(t, ok)
+	assert.Equal(t, 3, retryer.MaxRetries())
+}
+
+// TestAWSRetryPolicyRetryer verifies the throttled requests are retried with a delay within the policy limits
+func TestAWSRetryPolicyRetryer(t *testing.T) {
+	policy := AWSRetryPolicy{MaxRetries: 5, MinDelay: 200 * time.Millisecond, MaxDelay: 2 * time.Second}
+	retryer := policy.newRetryer()
+
+	r := &request.Request{
+		Error:        awserr.New("Throttling", "Rate exceeded", nil),
+		RetryCount:   3,
+		HTTPRequest:  &http.Request{Header: http.Header{}},
+		HTTPResponse: &http.Response{StatusCode: http.StatusBadRequest, Header: http.Header{}},
+	}
+	assert.True(t, retryer.ShouldRetry(r))
+	for i := 0; i < 10; i++ {
+		delay := retryer.RetryRules(r)
+		assert.GreaterOrEqual(t, delay, policy.MinDelay)
+		assert.LessOrEqual(t, delay, policy.MaxDelay)
+	}
+}
+
+// TestThrottlingCounterHandler verifies only the throttled requests are counted
+func TestThrottlingCounterHandler(t *testing.T) {
+	var counter atomic.Int64
+	handler := newThrottlingCounterHandler(&counter)
+
+	handler.Fn(&request.Request{Error: awserr.New("RequestLimitExceeded", "Request limit exceeded", nil)})
+	handler.Fn(&request.Request{Error: awserr.New("Throttling", "Rate exceeded", nil)})
+	handler.Fn(&request.Request{HTTPResponse: &http.Response{StatusCode: http.StatusTooManyRequests}})
+	handler.Fn(&request.Request{Error: awserr.New("InternalError", "Internal error", nil), HTTPResponse: &http.Response{StatusCode: http.StatusInternalServerError}})
+	handler.Fn(&request.Request{})
+	assert.Equal(t, int64(3), counter.Load())
+}
+
+// TestNewRegionalConnection verifies the regional connections use their own region and the same services, sharing the throttled requests counter with their connection
+func TestNewRegionalConnection(t *testing.T) {
+	conn, err := NewAWSConnection("AKIAEXAMPLE", "secret", AWSAssumeRoleConfig{}, "", WithEC2(), WithTagging())
+	assert.Nil(t, err)
+
+	regionalConns := make([]*AWSConnection, 0, 2)
+	for _, region := range []string{"us-east-1", "ap-south-1"} {
+		regionalConn, err := conn.NewRegionalConnection(region)
+		assert.Nil(t, err)
+		assert.Equal(t, region, regionalConn.GetRegion())
+		assert.Equal(t, region, regionalConn.EC2.GetRegion())
+		assert.NotNil(t, regionalConn.Tagging)
+		assert.Nil(t, regionalConn.S3)
+		regionalConns = append(regionalConns, regionalConn)
+	}
+	assert.Equal(t, DefaultAWSRegion, conn.GetRegion())
+
+	regionalConns[0].throttledRequests.Add(2)
+	regionalConns[1].throttledRequests.Add(1)
+	assert.Equal(t, int64(3), conn.GetThrottledRequests())
+	assert.Equal(t, int64(3), regionalConns[0].GetThrottledRequests())
+}
@@ -2,6 +2,7 @@ package cloudprovider
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
@@ -10,6 +11,13 @@ import (
 	"github.com/aws/aws-sdk-go/service/route53"
 )
 
+const (
+	// Max number of resources per ListTagsForResources request
+	maxTagsBatchSize = 10
+	// Prefix of the hosted zone IDs returned by the Route53 API
+	hostedZoneIDPrefix = "/hostedzone/"
+)
+
 type AWSRoute53Connection struct {
 	client *route53.Route53
 }
@@ -32,34 +40,79 @@ type HostedZone struct {
 	Tags []*route53.Tag
 }
 
-// GetZonesWithTags retrieves all hosted zones and their associated tags from AWS Route53
+// GetZonesWithTags retrieves all hosted zones and their associated tags from AWS Route53.
+// Every page of hosted zones is listed, and the tags are requested in batches
+// of up to maxTagsBatchSize zones. Throttled requests are retried by the
+// connection retry policy
 func (c *AWSRoute53Connection) GetZonesWithTags() ([]HostedZone, error) {
-	// Route 53 returns up to 100 items in each response.
-	// If you have a lot of hosted zones, use the MaxItems parameter to list them in groups of up to 100.
-	// https://pkg.go.dev/github.com/aws/aws-sdk-go/service/route53#Route53.ListHostedZonesByName
-	input := route53.ListHostedZonesByNameInput{}
-	result, err := c.client.ListHostedZonesByName(&input)
+	zones, err := c.GetHostedZones()
 	if err != nil {
 		return nil, err
 	}
-	zonesWithTags := make([]HostedZone, 0, len(result.HostedZones))
 
-	for _, zone := range result.HostedZones {
-		hztype := route53.TagResourceTypeHostedzone
-		tags, err := c.client.ListTagsForResource(&route53.ListTagsForResourceInput{
-			ResourceType: &hztype,
-			ResourceId:   aws.String(*zone.Id),
-		})
+	zonesWithTags := make([]HostedZone, 0, len(zones))
+	for batch := range slices.Chunk(zones, maxTagsBatchSize) {
+		tags, err := c.getHostedZonesTags(batch)
 		if err != nil {
-			continue
+			// Skipping the zones would lose the console links of their clusters, so the error is returned once the retries are exhausted
+			return nil, err
 		}
-		zonesWithTags = append(zonesWithTags, HostedZone{
-			Zone: zone,
-			Tags: tags.ResourceTagSet.Tags,
+		for _, zone := range batch {
+			zonesWithTags = append(zonesWithTags, HostedZone{
+				Zone: zone,
+				Tags: tags[hostedZoneShortID(aws.StringValue(zone.Id))],
+			})
+		}
+	}
+
+	return zonesWithTags, nil
+}
+
+// GetHostedZones returns every hosted zone of the account
+// Doc: (https://docs.aws.amazon.com/sdk-for-go/api/service/route53/#Route53.ListHostedZonesPages)
+func (c *AWSRoute53Connection) GetHostedZones() ([]*route53.HostedZone, error) {
+	var zones []*route53.HostedZone
+
+	err := c.client.ListHostedZonesPages(&route53.ListHostedZonesInput{},
+		func(page *route53.ListHostedZonesOutput, lastPage bool) bool {
+			zones = append(zones, page.HostedZones...)
+			return !lastPage // Continue if there are more hosted zones pages
 		})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing the hosted zones: %w", err)
+	}
 
+	return zones, nil
+}
+
+// getHostedZonesTags returns the tags of a batch of hosted zones indexed by
+// the zone short ID. The batch can't exceed maxTagsBatchSize zones
+// Doc: (https://docs.aws.amazon.com/sdk-for-go/api/service/route53/#Route53.ListTagsForResources)
+func (c *AWSRoute53Connection) getHostedZonesTags(zones []*route53.HostedZone) (map[string][]*route53.Tag, error) {
+	ids := make([]*string, 0, len(zones))
+	for _, zone := range zones {
+		ids = append(ids, aws.String(hostedZoneShortID(aws.StringValue(zone.Id))))
 	}
-	return zonesWithTags, nil
+
+	result, err := c.client.ListTagsForResources(&route53.ListTagsForResourcesInput{
+		ResourceType: aws.String(route53.TagResourceTypeHostedzone),
+		ResourceIds:  ids,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error getting the tags of the hosted zones %s: %w", aws.StringValueSlice(ids), err)
+	}
+
+	tags := make(map[string][]*route53.Tag, len(result.ResourceTagSets))
+	for _, tagSet := range result.ResourceTagSets {
+		tags[hostedZoneShortID(aws.StringValue(tagSet.ResourceId))] = tagSet.Tags
+	}
+
+	return tags, nil
+}
+
+// hostedZoneShortID removes the "/hostedzone/" prefix of the IDs returned by the Route53 API
+func hostedZoneShortID(id string) string {
+	return strings.TrimPrefix(id, hostedZoneIDPrefix)
 }
 
 // ZoneBelongsToCluster returns true or false if the hosted zone is associated to a cluster Ingress (routers)
@@ -0,0 +1,112 @@
+package cloudprovider
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRoute53API serves the ListHostedZones and ListTagsForResources
+// operations for a fixed number of hosted zones, returning them in pages
+type fakeRoute53API struct {
+	zones      int
+	pageSize   int
+	tagBatches [][]string
+}
+
+func (f *fakeRoute53API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/hostedzone"):
+		start := 0
+		if marker := r.URL.Query().Get("marker"); marker != "" {
+			_, _ = fmt.Sscanf(marker, "Z%d", &start)
+		}
+		end := min(start+f.pageSize, f.zones)
+
+		fmt.Fprint(w, `<ListHostedZonesResponse><HostedZones>`)
+		for i := start; i < end; i++ {
+			fmt.Fprintf(w, `<HostedZone><Id>/hostedzone/Z%d</Id><Name>zone-%d.example.com.</Name><CallerReference>ref</CallerReference></HostedZone>`, i, i)
+		}
+		fmt.Fprint(w, `</HostedZones>`)
+		if end < f.zones {
+			fmt.Fprintf(w, `<IsTruncated>true</IsTruncated><NextMarker>Z%d</NextMarker>`, end)
+		} else {
+			fmt.Fprint(w, `<IsTruncated>false</IsTruncated>`)
+		}
+		fmt.Fprintf(w, `<MaxItems>%d</MaxItems></ListHostedZonesResponse>`, f.pageSize)
+
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/tags/hostedzone"):
+		var body struct {
+			ResourceIds []string `xml:"ResourceIds>ResourceId"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.tagBatches = append(f.tagBatches, body.ResourceIds)
+
+		fmt.Fprint(w, `<ListTagsForResourcesResponse><ResourceTagSets>`)
+		for _, id := range body.ResourceIds {
+			fmt.Fprintf(w, `<ResourceTagSet><ResourceType>hostedzone</ResourceType><ResourceId>%s</ResourceId><Tags><Tag><Key>cluster-%s</Key><Value>owned</Value></Tag></Tags></ResourceTagSet>`, id, id)
+		}
+		fmt.Fprint(w, `</ResourceTagSets></ListTagsForResourcesResponse>`)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// newFakeAWSSession returns an AWS session sending the requests to a fake API
+func newFakeAWSSession(t *testing.T, api http.Handler) *session.Session {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	sess, err := session.NewSession(aws.NewConfig().
+		WithCredentials(credentials.NewStaticCredentials("AKIAEXAMPLE", "secret", "")).
+		WithRegion(DefaultAWSRegion).
+		WithEndpoint(server.URL).
+		WithMaxRetries(0))
+	assert.Nil(t, err)
+
+	return sess
+}
+
+// TestGetZonesWithTags verifies every hosted zones page is listed and their tags are requested in batches
+func TestGetZonesWithTags(t *testing.T) {
+	api := &fakeRoute53API{zones: 23, pageSize: 10}
+	conn := NewAWSRoute53Connection(newFakeAWSSession(t, api))
+
+	zones, err := conn.GetZonesWithTags()
+	assert.Nil(t, err)
+	assert.Len(t, zones, 23)
+	for i, zone := range zones {
+		assert.Equal(t, fmt.Sprintf("/hostedzone/Z%d", i), aws.StringValue(zone.Zone.Id))
+		assert.Len(t, zone.Tags, 1)
+		assert.Equal(t, fmt.Sprintf("cluster-Z%d", i), aws.StringValue(zone.Tags[0].Key))
+	}
+
+	assert.Len(t, api.tagBatches, 3)
+	for _, batch := range api.tagBatches {
+		assert.LessOrEqual(t, len(batch), maxTagsBatchSize)
+	}
+	assert.Equal(t, "Z0", api.tagBatches[0][0])
+}
+
+// TestGetZonesWithTagsNoZones verifies no tags are requested when there are no hosted zones
+func TestGetZonesWithTagsNoZones(t *testing.T) {
+	api := &fakeRoute53API{zones: 0, pageSize: 10}
+	conn := NewAWSRoute53Connection(newFakeAWSSession(t, api))
+
+	zones, err := conn.GetZonesWithTags()
+	assert.Nil(t, err)
+	assert.Empty(t, zones)
+	assert.Empty(t, api.tagBatches)
+}
@@ -0,0 +1,91 @@
+package cloudprovider
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	// Scheme of the S3 URLs (s3://<bucket>/<prefix>)
+	s3URLScheme = "s3://"
+)
+
+// ParseS3URL splits an S3 URL (s3://<bucket>/<prefix>) into its bucket and
+// prefix. It returns false if the URL is not an S3 URL
+func ParseS3URL(url string) (string, string, bool) {
+	path, ok := strings.CutPrefix(url, s3URLScheme)
+	if !ok {
+		return "", "", false
+	}
+
+	bucket, prefix, _ := strings.Cut(path, "/")
+	if bucket == "" {
+		return "", "", false
+	}
+	return bucket, prefix, true
+}
+
+// AWSS3Connection defines the connection with the AWS S3 API
+type AWSS3Connection struct {
+	client *s3.S3
+}
+
+// NewAWSS3Connection returns a new AWSS3Connection based on the specified session
+func NewAWSS3Connection(session *session.Session) *AWSS3Connection {
+	return &AWSS3Connection{
+		client: s3.New(session),
+	}
+}
+
+// WithS3 configures an AWSConnection instance for including the S3 client
+func WithS3() AWSConnectionOption {
+	return func(conn *AWSConnection) {
+		conn.S3 = NewAWSS3Connection(conn.awsSession)
+	}
+}
+
+// ListObjectKeys returns the keys of every object of the bucket starting by the prefix
+// Doc: (https://docs.aws.amazon.com/sdk-for-go/api/service/s3/#S3.ListObjectsV2Pages)
+func (c *AWSS3Connection) ListObjectKeys(bucket string, prefix string) ([]string, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+
+	var keys []string
+	err := c.client.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			keys = append(keys, aws.StringValue(object.Key))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing objects of bucket %s: %w", bucket, err)
+	}
+
+	return keys, nil
+}
+
+// DownloadObject writes the content of an object into w
+// Doc: (https://docs.aws.amazon.com/sdk-for-go/api/service/s3/#S3.GetObject)
+func (c *AWSS3Connection) DownloadObject(bucket string, key string, w io.Writer) error {
+	output, err := c.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("Error getting object %s of bucket %s: %w", key, bucket, err)
+	}
+	defer output.Body.Close()
+
+	if _, err := io.Copy(w, output.Body); err != nil {
+		return fmt.Errorf("Error downloading object %s of bucket %s: %w", key, bucket, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package cloudprovider
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeS3API serves the ListObjectsV2 and GetObject operations of a single
+// bucket using path-style requests, returning one object per page
+type fakeS3API struct {
+	bucket  string
+	objects map[string]string
+}
+
+func (f *fakeS3API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if r.Method != http.MethodGet || bucket != f.bucket {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if key != "" {
+		content, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, content)
+		return
+	}
+
+	var keys []string
+	for k := range f.objects {
+		if strings.HasPrefix(k, r.URL.Query().Get("prefix")) && k > r.URL.Query().Get("continuation-token") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	fmt.Fprint(w, `<ListBucketResult>`)
+	if len(keys) > 0 {
+		fmt.Fprintf(w, `<Contents><Key>%s</Key></Contents>`, keys[0])
+	}
+	if len(keys) > 1 {
+		fmt.Fprintf(w, `<IsTruncated>true</IsTruncated><NextContinuationToken>%s</NextContinuationToken>`, keys[0])
+	} else {
+		fmt.Fprint(w, `<IsTruncated>false</IsTruncated>`)
+	}
+	fmt.Fprint(w, `</ListBucketResult>`)
+}
+
+// TestAWSS3Connection verifies every objects page of the prefix is listed and the objects are downloaded
+func TestAWSS3Connection(t *testing.T) {
+	api := &fakeS3API{
+		bucket: "cur-bucket",
+		objects: map[string]string{
+			"cur/report/20240501-20240601/report-1.csv.gz": "first",
+			"cur/report/20240501-20240601/report-2.csv.gz": "second",
+			"cur/report/report-Manifest.json":              "{}",
+			"other/file.csv":                               "other",
+		},
+	}
+	sess := newFakeAWSSession(t, api).Copy(aws.NewConfig().WithS3ForcePathStyle(true))
+	conn := NewAWSS3Connection(sess)
+
+	keys, err := conn.ListObjectKeys("cur-bucket", "cur/report/")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"cur/report/20240501-20240601/report-1.csv.gz",
+		"cur/report/20240501-20240601/report-2.csv.gz",
+		"cur/report/report-Manifest.json",
+	}, keys)
+
+	var content bytes.Buffer
+	assert.Nil(t, conn.DownloadObject("cur-bucket", "cur/report/20240501-20240601/report-2.csv.gz", &content))
+	assert.Equal(t, "second", content.String())
+
+	assert.NotNil(t, conn.DownloadObject("cur-bucket", "missing.csv", &content))
+	_, err = conn.ListObjectKeys("missing-bucket", "")
+	assert.NotNil(t, err)
+}
+
+// TestParseS3URL verifies the bucket and prefix are obtained only from the S3 URLs
+func TestParseS3URL(t *testing.T) {
+	bucket, prefix, ok := ParseS3URL("s3://cur-bucket/cur/report/")
+	assert.True(t, ok)
+	assert.Equal(t, "cur-bucket", bucket)
+	assert.Equal(t, "cur/report/", prefix)
+
+	bucket, prefix, ok = ParseS3URL("s3://cur-bucket")
+	assert.True(t, ok)
+	assert.Equal(t, "cur-bucket", bucket)
+	assert.Equal(t, "", prefix)
+
+	_, _, ok = ParseS3URL("/var/lib/cur")
+	assert.False(t, ok)
+	_, _, ok = ParseS3URL("s3:///cur")
+	assert.False(t, ok)
+}
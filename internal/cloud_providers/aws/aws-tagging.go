@@ -0,0 +1,144 @@
+package cloudprovider
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+)
+
+const (
+	// Resource Groups Tagging API filters for the resources that can remain
+	// on the account after a cluster deletion
+	TaggingLoadBalancerType  = "elasticloadbalancing:loadbalancer"
+	TaggingVolumeType        = "ec2:volume"
+	TaggingSecurityGroupType = "ec2:security-group"
+	TaggingHostedZoneType    = "route53:hostedzone"
+
+	// TaggingGlobalRegion is the region where the global services (Route53)
+	// resources must be queried from
+	TaggingGlobalRegion = "us-east-1"
+
+	// Region assigned to the resources of global services
+	globalRegionCode = "global"
+)
+
+// AWSTaggingConnection defines the connection with the AWS Resource Groups Tagging API
+type AWSTaggingConnection struct {
+	client *resourcegroupstaggingapi.ResourceGroupsTaggingAPI
+}
+
+// NewAWSTaggingConnection returns a new AWSTaggingConnection based on the specified session
+func NewAWSTaggingConnection(session *session.Session) *AWSTaggingConnection {
+	return &AWSTaggingConnection{
+		client: resourcegroupstaggingapi.New(session),
+	}
+}
+
+// WithTagging configures an AWSConnection instance for including the Resource Groups Tagging API client
+func WithTagging() AWSConnectionOption {
+	return func(conn *AWSConnection) {
+		conn.Tagging = NewAWSTaggingConnection(conn.awsSession)
+	}
+}
+
+// GetResourcesByTagKey returns every resource of the configured region tagged
+// with the specified key, filtered by the resourceTypes list (e.g.
+// TaggingVolumeType). Resources of unsupported types are ignored
+// Doc: (https://docs.aws.amazon.com/sdk-for-go/api/service/resourcegroupstaggingapi/#ResourceGroupsTaggingAPI.GetResourcesPages)
+func (c *AWSTaggingConnection) GetResourcesByTagKey(tagKey string, resourceTypes []string) ([]inventory.Resource, error) {
+	var mappings []*resourcegroupstaggingapi.ResourceTagMapping
+
+	input := &resourcegroupstaggingapi.GetResourcesInput{
+		TagFilters: []*resourcegroupstaggingapi.TagFilter{
+			{Key: aws.String(tagKey)},
+		},
+		ResourceTypeFilters: aws.StringSlice(resourceTypes),
+	}
+
+	err := c.client.GetResourcesPages(input,
+		func(page *resourcegroupstaggingapi.GetResourcesOutput, lastPage bool) bool {
+			mappings = append(mappings, page.ResourceTagMappingList...)
+			return !lastPage // Continue if there are more resources pages
+		})
+	if err != nil {
+		return nil, fmt.Errorf("Error getting tagged resources: %w", err)
+	}
+
+	resources := make([]inventory.Resource, 0, len(mappings))
+	for _, mapping := range mappings {
+		resource := TaggedResourceToInventoryResource(mapping)
+		if resource == nil {
+			continue
+		}
+		resources = append(resources, *resource)
+	}
+
+	return resources, nil
+}
+
+// TaggedResourceToInventoryResource converts a Resource Groups Tagging API
+// mapping into an inventory.Resource based on its ARN. It returns nil if the
+// ARN can't be parsed or the resource type is not supported.
+// The Tagging API doesn't report the resource status nor its creation timestamp
+func TaggedResourceToInventoryResource(mapping *resourcegroupstaggingapi.ResourceTagMapping) *inventory.Resource {
+	resourceARN, err := arn.Parse(aws.StringValue(mapping.ResourceARN))
+	if err != nil {
+		return nil
+	}
+
+	// ARN resource sections look like "volume/vol-0123" or "hostedzone/Z0123"
+	kind, id, _ := strings.Cut(resourceARN.Resource, "/")
+	region := resourceARN.Region
+
+	var resourceType inventory.ResourceType
+	switch {
+	case resourceARN.Service == "ec2" && kind == "volume":
+		resourceType = inventory.VolumeResourceType
+	case resourceARN.Service == "ec2" && kind == "security-group":
+		resourceType = inventory.SecurityGroupResourceType
+	case resourceARN.Service == "elasticloadbalancing" && kind == "loadbalancer":
+		// Load Balancers are identified by their full ARN
+		resourceType = inventory.LoadBalancerResourceType
+		id = resourceARN.String()
+	case resourceARN.Service == "route53" && kind == "hostedzone":
+		resourceType = inventory.HostedZoneResourceType
+		region = globalRegionCode
+	default:
+		return nil
+	}
+
+	tags := ConvertTaggingTagToTag(mapping.Tags, id)
+	name := inventory.GetInstanceNameFromTags(tags)
+	if name == "" {
+		name = id
+	}
+
+	return inventory.NewResource(
+		id,
+		name,
+		resourceType,
+		inventory.AWSProvider,
+		region,
+		"",
+		"",
+		0,
+		"",
+		tags,
+		time.Time{},
+	)
+}
+
+// ConvertTaggingTagToTag transforms the Resource Groups Tagging API tags into inventory Tag
+func ConvertTaggingTagToTag(taggingTags []*resourcegroupstaggingapi.Tag, resourceID string) []inventory.Tag {
+	var tags []inventory.Tag
+	for _, tag := range taggingTags {
+		tags = append(tags, *inventory.NewTag(aws.StringValue(tag.Key), aws.StringValue(tag.Value), resourceID))
+	}
+	return tags
+}
@@ -0,0 +1,121 @@
+package cloudprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTaggingAPI serves the GetResources operation, returning every ARN on
+// its own page
+type fakeTaggingAPI struct {
+	arns     []string
+	requests []map[string]any
+}
+
+func (f *fakeTaggingAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]any
+	if r.Header.Get("X-Amz-Target") != "ResourceGroupsTaggingAPI_20170126.GetResources" || json.NewDecoder(r.Body).Decode(&body) != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	f.requests = append(f.requests, body)
+
+	page := 0
+	if token, ok := body["PaginationToken"].(string); ok {
+		_, _ = fmt.Sscanf(token, "page-%d", &page)
+	}
+
+	output := map[string]any{"ResourceTagMappingList": []any{}}
+	if page < len(f.arns) {
+		output["ResourceTagMappingList"] = []any{map[string]any{
+			"ResourceARN": f.arns[page],
+			"Tags":        []any{map[string]any{"Key": "kubernetes.io/cluster/ocp-a1b2c", "Value": "owned"}},
+		}}
+	}
+	if page+1 < len(f.arns) {
+		output["PaginationToken"] = fmt.Sprintf("page-%d", page+1)
+	}
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	_ = json.NewEncoder(w).Encode(output)
+}
+
+// newTagMapping returns a Resource Groups Tagging API mapping of the ARN with the specified tags
+func newTagMapping(resourceARN string, tags map[string]string) *resourcegroupstaggingapi.ResourceTagMapping {
+	mapping := &resourcegroupstaggingapi.ResourceTagMapping{ResourceARN: aws.String(resourceARN)}
+	for key, value := range tags {
+		mapping.Tags = append(mapping.Tags, &resourcegroupstaggingapi.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	return mapping
+}
+
+// TestTaggedResourceToInventoryResource verifies every supported ARN is converted into its resource type, region and ID, and the unsupported ones are ignored
+func TestTaggedResourceToInventoryResource(t *testing.T) {
+	lbARN := "arn:aws:elasticloadbalancing:eu-west-1:123456789012:loadbalancer/net/ocp-a1b2c-int/0123456789abcdef"
+	tests := []struct {
+		arn          string
+		resourceType inventory.ResourceType
+		id           string
+		region       string
+	}{
+		{"arn:aws:ec2:eu-west-1:123456789012:volume/vol-0001", inventory.VolumeResourceType, "vol-0001", "eu-west-1"},
+		{"arn:aws:ec2:eu-west-1:123456789012:security-group/sg-0001", inventory.SecurityGroupResourceType, "sg-0001", "eu-west-1"},
+		{lbARN, inventory.LoadBalancerResourceType, lbARN, "eu-west-1"},
+		{"arn:aws:route53:::hostedzone/Z0001", inventory.HostedZoneResourceType, "Z0001", globalRegionCode},
+	}
+	for _, test := range tests {
+		resource := TaggedResourceToInventoryResource(newTagMapping(test.arn, nil))
+		if assert.NotNil(t, resource, test.arn) {
+			assert.Equal(t, test.resourceType, resource.Type)
+			assert.Equal(t, test.id, resource.ID)
+			assert.Equal(t, test.id, resource.Name)
+			assert.Equal(t, test.region, resource.Region)
+			assert.Equal(t, inventory.AWSProvider, resource.Provider)
+		}
+	}
+
+	resource := TaggedResourceToInventoryResource(newTagMapping("arn:aws:ec2:eu-west-1:123456789012:volume/vol-0002", map[string]string{"Name": "ocp-a1b2c-master-0"}))
+	if assert.NotNil(t, resource) {
+		assert.Equal(t, "ocp-a1b2c-master-0", resource.Name)
+		assert.Equal(t, []inventory.Tag{*inventory.NewTag("Name", "ocp-a1b2c-master-0", "vol-0002")}, resource.Tags)
+	}
+
+	assert.Nil(t, TaggedResourceToInventoryResource(newTagMapping("arn:aws:ec2:eu-west-1:123456789012:instance/i-0001", nil)))
+	assert.Nil(t, TaggedResourceToInventoryResource(newTagMapping("not-an-arn", nil)))
+}
+
+// TestGetResourcesByTagKey verifies every page of tagged resources is requested filtered by the tag key and the resource types, skipping the unsupported resources
+func TestGetResourcesByTagKey(t *testing.T) {
+	api := &fakeTaggingAPI{arns: []string{
+		"arn:aws:ec2:eu-west-1:123456789012:volume/vol-0001",
+		"arn:aws:ec2:eu-west-1:123456789012:snapshot/snap-0001",
+		"arn:aws:ec2:eu-west-1:123456789012:security-group/sg-0001",
+	}}
+	conn := NewAWSTaggingConnection(newFakeAWSSession(t, api))
+
+	resources, err := conn.GetResourcesByTagKey("kubernetes.io/cluster/ocp-a1b2c", []string{TaggingVolumeType, TaggingSecurityGroupType})
+	assert.Nil(t, err)
+	if assert.Len(t, resources, 2) {
+		assert.Equal(t, "vol-0001", resources[0].ID)
+		assert.Equal(t, "sg-0001", resources[1].ID)
+	}
+
+	assert.Len(t, api.requests, 3)
+	assert.Equal(t, []any{map[string]any{"Key": "kubernetes.io/cluster/ocp-a1b2c"}}, api.requests[0]["TagFilters"])
+	assert.Equal(t, []any{TaggingVolumeType, TaggingSecurityGroupType}, api.requests[0]["ResourceTypeFilters"])
+}
+
+// TestGetResourcesByTagKeyError verifies the API errors are returned
+func TestGetResourcesByTagKeyError(t *testing.T) {
+	conn := NewAWSTaggingConnection(newFakeAWSSession(t, http.NotFoundHandler()))
+
+	_, err := conn.GetResourcesByTagKey("kubernetes.io/cluster/ocp-a1b2c", []string{TaggingVolumeType})
+	assert.NotNil(t, err)
+}
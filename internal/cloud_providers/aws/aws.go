@@ -2,6 +2,7 @@ package cloudprovider
 
 import (
 	"fmt"
+	"sync/atomic"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -24,41 +25,51 @@ const (
 // * Route53 (DNS)
 // * STS (SecurityTokenService)
 // * CostExplorer (billing data)
+// * Tagging (Resource Groups Tagging API)
+// * Organizations (member accounts discovery)
+// * S3 (Cost and Usage Report files)
 type AWSConnection struct {
-	credentials  *credentials.Credentials
-	awsConfig    *aws.Config
-	awsSession   *session.Session
-	EC2          *AWSEC2Connection
-	Route53      *AWSRoute53Connection
-	STS          *AWSSTSConnection
-	CostExplorer *AWSCostExplorerConnection
-	accountID    string
-	user         string
-	password     string
-	region       string
+	credentials   *credentials.Credentials
+	awsConfig     *aws.Config
+	awsSession    *session.Session
+	EC2           *AWSEC2Connection
+	Route53       *AWSRoute53Connection
+	STS           *AWSSTSConnection
+	CostExplorer  *AWSCostExplorerConnection
+	Tagging       *AWSTaggingConnection
+	Organizations *AWSOrganizationsConnection
+	S3            *AWSS3Connection
+	accountID     string
+	user          string
+	password      string
+	region        string
+	// Number of throttled requests. Shared with the regional connections
+	throttledRequests *atomic.Int64
 }
 
 // AWSConnectionOption defines the options for creating different sets of AWS services connections
 type AWSConnectionOption func(*AWSConnection)
 
-// NewAWSConnection creates a connection with AWS APIs. Based on the AWSConnectionOptions, it will create different clients for every available service
-func NewAWSConnection(user string, password string, region string, opts ...AWSConnectionOption) (*AWSConnection, error) {
-	var token string = ""
-
+// NewAWSConnection creates a connection with AWS APIs. Based on the AWSConnectionOptions, it will create different clients for every available service.
+// The credentials are obtained from the static access keys (user and password) or by assuming the role defined on the AWSAssumeRoleConfig
+func NewAWSConnection(user string, password string, role AWSAssumeRoleConfig, region string, opts ...AWSConnectionOption) (*AWSConnection, error) {
 	// If there's no region specified, it will take the default one.
 	if region == "" {
 		region = DefaultAWSRegion
 	}
 
-	// Third argument (token) it's not used. For more info check docs: https://pkg.go.dev/github.com/aws/aws-sdk-go/aws/credentials#NewStaticCredentials
-	creds := credentials.NewStaticCredentials(user, password, token)
+	creds, err := newAWSCredentials(user, password, role, region)
+	if err != nil {
+		return nil, err
+	}
 
 	// AccountID is empty by default. It will be configured automatically if the developer includes the STS service option
 	conn := &AWSConnection{
-		credentials: creds,
-		user:        user,
-		password:    password,
-		region:      region,
+		credentials:       creds,
+		user:              user,
+		password:          password,
+		region:            region,
+		throttledRequests: new(atomic.Int64),
 	}
 
 	// creating AWSConfig object
@@ -90,7 +101,7 @@ func NewAWSConnection(user string, password string, region string, opts ...AWSCo
 // newAWSConfig creates a new AWSConfig object instance to define the AWSSession config
 func (conn *AWSConnection) newAWSConfig() error {
 	// Preparing AWSConfig for new AWS API Session
-	conn.awsConfig = aws.NewConfig().WithCredentials(conn.credentials).WithRegion(conn.region)
+	conn.awsConfig = withRetryPolicy(aws.NewConfig().WithCredentials(conn.credentials).WithRegion(conn.region))
 	if conn.awsConfig == nil {
 		return fmt.Errorf("Cannot obtain AWS config for Account: %s\n", conn.accountID)
 	}
@@ -98,7 +109,8 @@ func (conn *AWSConnection) newAWSConfig() error {
 	return nil
 }
 
-// newAWSession creates a new AWSSession
+// newAWSession creates a new AWSSession. The throttled requests of every
+// client created from the session are counted on the connection
 func (conn *AWSConnection) newAWSession() error {
 	var err error
 
@@ -108,6 +120,11 @@ func (conn *AWSConnection) newAWSession() error {
 		return err
 	}
 
+	if conn.throttledRequests == nil {
+		conn.throttledRequests = new(atomic.Int64)
+	}
+	conn.awsSession.Handlers.Retry.PushBackNamed(newThrottlingCounterHandler(conn.throttledRequests))
+
 	return nil
 }
 
@@ -122,11 +139,74 @@ func (conn *AWSConnection) SetRegion(region string) error {
 	return conn.Connect()
 }
 
+// NewRegionalConnection returns a new AWSConnection for the specified region
+// with the same credentials and services than the current one. Unlike
+// SetRegion, the current connection is not modified, so several regional
+// connections can be used concurrently
+func (conn *AWSConnection) NewRegionalConnection(region string) (*AWSConnection, error) {
+	regionalConn := &AWSConnection{
+		credentials:       conn.credentials,
+		accountID:         conn.accountID,
+		user:              conn.user,
+		password:          conn.password,
+		region:            region,
+		throttledRequests: conn.throttledRequests,
+	}
+
+	// Enabling the same services than the current connection
+	var opts []AWSConnectionOption
+	if conn.EC2 != nil {
+		opts = append(opts, WithEC2())
+	}
+	if conn.Route53 != nil {
+		opts = append(opts, WithRoute53())
+	}
+	if conn.STS != nil {
+		opts = append(opts, WithSTS())
+	}
+	if conn.CostExplorer != nil {
+		opts = append(opts, WithCostExplorer())
+	}
+	if conn.Tagging != nil {
+		opts = append(opts, WithTagging())
+	}
+	if conn.Organizations != nil {
+		opts = append(opts, WithOrganizations())
+	}
+	if conn.S3 != nil {
+		opts = append(opts, WithS3())
+	}
+
+	if err := regionalConn.newAWSConfig(); err != nil {
+		return nil, err
+	}
+
+	if err := regionalConn.newAWSession(); err != nil {
+		return nil, err
+	}
+
+	for _, opt := range opts {
+		opt(regionalConn)
+	}
+
+	return regionalConn, nil
+}
+
 // GetAccountID returns the accountID obtained from AWS for the account on the current AWSConnection
 func (conn *AWSConnection) GetAccountID() string {
 	return conn.accountID
 }
 
+// GetThrottledRequests returns the number of requests throttled by the AWS
+// APIs on this connection and its regional connections. Every throttled
+// attempt is counted, even if the request succeeded after retrying it
+func (conn *AWSConnection) GetThrottledRequests() int64 {
+	if conn.throttledRequests == nil {
+		return 0
+	}
+	return conn.throttledRequests.Load()
+}
+
 // Connect establish or refresh the AWS service clients for the AWSConnection
 // object. This is needed because some clients needs to be re-created when
 // switching to a different region
@@ -162,5 +242,17 @@ func (conn *AWSConnection) Connect() error {
 		WithCostExplorer()(conn)
 	}
 
+	if conn.Tagging != nil {
+		WithTagging()(conn)
+	}
+
+	if conn.Organizations != nil {
+		WithOrganizations()(conn)
+	}
+
+	if conn.S3 != nil {
+		WithS3()(conn)
+	}
+
 	return nil
 }
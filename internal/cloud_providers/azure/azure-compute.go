@@ -0,0 +1,216 @@
+package cloudprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+)
+
+const (
+	// Prefix of the InstanceView status codes containing the VM power state
+	powerStatePrefix = "PowerState/"
+	// Prefix of the InstanceView status codes containing the VM provisioning state
+	provisioningStatePrefix = "ProvisioningState/"
+)
+
+// GetInstances returns the list of VMs of a subscription as an array of inventory.Instance
+func (conn *AzureConnection) GetInstances(subscriptionID string) ([]inventory.Instance, error) {
+	vms, err := conn.client.ListVirtualMachines(context.Background(), subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]inventory.Instance, 0, len(vms))
+	for _, vm := range vms {
+		instance := VirtualMachineToInventoryInstance(vm)
+		if instance == nil {
+			continue
+		}
+		instances = append(instances, *instance)
+	}
+
+	return instances, nil
+}
+
+// StartClusterInstances starts the VMs from the provided instances (VMIDs)
+// list. It looks for the VMs on every subscription, filtering by the
+// configured location, and only starts the stopped ones
+func (conn *AzureConnection) StartClusterInstances(instanceIDs []string) error {
+	vms, err := conn.findVirtualMachines(instanceIDs, inventory.Stopped)
+	if err != nil {
+		return err
+	}
+
+	for _, vm := range vms {
+		if err := conn.client.StartVirtualMachine(context.Background(), *vm.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StopClusterInstances deallocates the VMs from the provided instances
+// (VMIDs) list. It looks for the VMs on every subscription, filtering by the
+// configured location, and only deallocates the running ones
+func (conn *AzureConnection) StopClusterInstances(instanceIDs []string) error {
+	vms, err := conn.findVirtualMachines(instanceIDs, inventory.Running)
+	if err != nil {
+		return err
+	}
+
+	for _, vm := range vms {
+		if err := conn.client.DeallocateVirtualMachine(context.Background(), *vm.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findVirtualMachines returns the VMs whose VMID is on the instanceIDs list,
+// are placed on the configured location and are on the specified status
+func (conn *AzureConnection) findVirtualMachines(instanceIDs []string, status inventory.InstanceStatus) ([]*armcompute.VirtualMachine, error) {
+	if len(instanceIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := make(map[string]bool, len(instanceIDs))
+	for _, id := range instanceIDs {
+		ids[id] = true
+	}
+
+	subscriptions, err := conn.GetSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*armcompute.VirtualMachine
+	for _, subscription := range subscriptions {
+		vms, err := conn.client.ListVirtualMachines(context.Background(), subscription)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, vm := range vms {
+			if vm == nil || vm.ID == nil || vm.Properties == nil || vm.Properties.VMID == nil {
+				continue
+			}
+			if !ids[*vm.Properties.VMID] {
+				continue
+			}
+			if conn.location != "" && !strings.EqualFold(stringValue(vm.Location), conn.location) {
+				continue
+			}
+			if getVirtualMachineStatus(vm) != status {
+				continue
+			}
+			result = append(result, vm)
+		}
+	}
+
+	return result, nil
+}
+
+// VirtualMachineToInventoryInstance converts an Azure VirtualMachine into an
+// inventory.Instance. It returns nil if the VM doesn't have the minimum
+// properties to be identified
+func VirtualMachineToInventoryInstance(vm *armcompute.VirtualMachine) *inventory.Instance {
+	if vm == nil || vm.Properties == nil || vm.Properties.VMID == nil {
+		return nil
+	}
+
+	id := *vm.Properties.VMID
+	tags := ConvertAzureTagsToTags(vm.Tags, id)
+	name := stringValue(vm.Name)
+	instanceType := ""
+	if vm.Properties.HardwareProfile != nil && vm.Properties.HardwareProfile.VMSize != nil {
+		instanceType = string(*vm.Properties.HardwareProfile.VMSize)
+	}
+	availabilityZone := getVirtualMachineAvailabilityZone(vm)
+	status := getVirtualMachineStatus(vm)
+	clusterID := inventory.GetClusterIDFromTags(tags)
+	creationTimestamp := time.Time{}
+	if vm.Properties.TimeCreated != nil {
+		creationTimestamp = *vm.Properties.TimeCreated
+	}
+
+	return inventory.NewInstance(
+		id,
+		name,
+		inventory.AzureProvider,
+		instanceType,
+		availabilityZone,
+		status,
+		clusterID,
+		tags,
+		creationTimestamp,
+	)
+}
+
+// getVirtualMachineAvailabilityZone returns the location of the VM including
+// the zone if the VM is zonal. For example: "eastus-1" or "eastus"
+func getVirtualMachineAvailabilityZone(vm *armcompute.VirtualMachine) string {
+	location := stringValue(vm.Location)
+	if len(vm.Zones) > 0 && vm.Zones[0] != nil {
+		return fmt.Sprintf("%s-%s", location, *vm.Zones[0])
+	}
+	return location
+}
+
+// GetLocationFromAvailabilityZone returns the Azure location (region) from an
+// availability zone generated by getVirtualMachineAvailabilityZone
+func GetLocationFromAvailabilityZone(availabilityZone string) string {
+	location, _, _ := strings.Cut(availabilityZone, "-")
+	return location
+}
+
+// getVirtualMachineStatus translates the InstanceView power and provisioning
+// states of a VM into an inventory.InstanceStatus
+func getVirtualMachineStatus(vm *armcompute.VirtualMachine) inventory.InstanceStatus {
+	// Following the same criteria as inventory.AsInstanceStatus, VMs without
+	// a known power state are considered as Running
+	status := inventory.Running
+	if vm.Properties.InstanceView == nil {
+		return status
+	}
+
+	for _, s := range vm.Properties.InstanceView.Statuses {
+		if s == nil || s.Code == nil {
+			continue
+		}
+
+		code := *s.Code
+		switch {
+		case code == provisioningStatePrefix+"deleting":
+			return inventory.Terminated
+		case strings.HasPrefix(code, powerStatePrefix):
+			status = powerStateToInstanceStatus(strings.TrimPrefix(code, powerStatePrefix))
+		}
+	}
+
+	return status
+}
+
+// powerStateToInstanceStatus translates an Azure VM PowerState into an inventory.InstanceStatus
+// Doc: https://learn.microsoft.com/en-us/azure/virtual-machines/states-billing
+func powerStateToInstanceStatus(powerState string) inventory.InstanceStatus {
+	switch powerState {
+	case "stopped", "stopping", "deallocated", "deallocating":
+		return inventory.Stopped
+	default:
+		return inventory.Running
+	}
+}
+
+// stringValue returns the value of a string pointer or an empty string if it's nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
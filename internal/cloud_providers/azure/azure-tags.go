@@ -0,0 +1,38 @@
+package cloudprovider
+
+import (
+	"strings"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+)
+
+// Azure doesn't allow the '/' character on tag names, so `openshift-installer`
+// tags the cluster resources using one of these prefixes instead of
+// inventory.ClusterTagKey
+var azureClusterTagPrefixes = []string{
+	"kubernetes.io-cluster-",
+	"kubernetes.io_cluster.",
+}
+
+// ConvertAzureTagsToTags transforms the Azure resource tags into inventory
+// Tags. The cluster tags are normalized to inventory.ClusterTagKey, so the
+// cluster info can be parsed in the same way as for any other provider
+func ConvertAzureTagsToTags(azureTags map[string]*string, instanceID string) []inventory.Tag {
+	var tags []inventory.Tag
+	for key, value := range azureTags {
+		tags = append(tags, *inventory.NewTag(NormalizeClusterTagKey(key), stringValue(value), instanceID))
+	}
+	return tags
+}
+
+// NormalizeClusterTagKey translates an Azure cluster tag key
+// (`kubernetes.io-cluster-<infraID>`) into the inventory.ClusterTagKey format
+// (`kubernetes.io/cluster/<infraID>`). Any other key is returned unmodified
+func NormalizeClusterTagKey(key string) string {
+	for _, prefix := range azureClusterTagPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return inventory.ClusterTagKey + strings.TrimPrefix(key, prefix)
+		}
+	}
+	return key
+}
@@ -0,0 +1,185 @@
+package cloudprovider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+)
+
+// AzureAPIClient defines the subset of the Azure Resource Manager API used by
+// ClusterIQ. It allows to replace the real SDK based client by a local fake
+// for testing the stockers and executors without reaching Azure.
+type AzureAPIClient interface {
+	// ListSubscriptions returns the IDs of every enabled subscription visible for the configured credentials
+	ListSubscriptions(ctx context.Context) ([]string, error)
+	// ListVirtualMachines returns every VM of a subscription including its runtime status
+	ListVirtualMachines(ctx context.Context, subscriptionID string) ([]*armcompute.VirtualMachine, error)
+	// StartVirtualMachine starts the VM identified by its ARM resource ID
+	StartVirtualMachine(ctx context.Context, vmResourceID string) error
+	// DeallocateVirtualMachine stops and deallocates the VM identified by its ARM resource ID
+	DeallocateVirtualMachine(ctx context.Context, vmResourceID string) error
+}
+
+// AzureConnection defines the connection with Azure Resource Manager APIs.
+// The Azure account is identified by the Service Principal credentials
+// (TenantID, ClientID and ClientSecret), and it can access to one or more
+// subscriptions depending on the Service Principal role assignments.
+type AzureConnection struct {
+	client   AzureAPIClient
+	tenantID string
+	// location limits the power management operations to the VMs of a single Azure location (region)
+	location string
+}
+
+// NewAzureConnection creates a connection with Azure APIs using a Service
+// Principal (client secret) credentials
+func NewAzureConnection(tenantID string, clientID string, clientSecret string) (*AzureConnection, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("missing tenant ID for the Azure connection")
+	}
+
+	cred, err := azidentity.NewClientSecretCredential(tenantID, clientID, clientSecret, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure credentials: %w", err)
+	}
+
+	return NewAzureConnectionWithClient(tenantID, newAzureSDKClient(cred)), nil
+}
+
+// NewAzureConnectionWithClient creates an AzureConnection based on an already
+// configured AzureAPIClient
+func NewAzureConnectionWithClient(tenantID string, client AzureAPIClient) *AzureConnection {
+	return &AzureConnection{
+		client:   client,
+		tenantID: tenantID,
+	}
+}
+
+// GetTenantID returns the Azure TenantID of the AzureConnection
+func (conn AzureConnection) GetTenantID() string {
+	return conn.tenantID
+}
+
+// SetRegion configures the Azure location used for filtering the VMs on the power management operations
+func (conn *AzureConnection) SetRegion(location string) {
+	conn.location = location
+}
+
+// GetRegion returns the configured Azure location for the AzureConnection
+func (conn AzureConnection) GetRegion() string {
+	return conn.location
+}
+
+// GetSubscriptions returns the list of subscriptions available for the AzureConnection
+func (conn *AzureConnection) GetSubscriptions() ([]string, error) {
+	return conn.client.ListSubscriptions(context.Background())
+}
+
+// azureSDKClient implements AzureAPIClient using the official Azure SDK for Go
+type azureSDKClient struct {
+	credential azcore.TokenCredential
+}
+
+// newAzureSDKClient creates a new azureSDKClient based on a TokenCredential
+func newAzureSDKClient(credential azcore.TokenCredential) *azureSDKClient {
+	return &azureSDKClient{credential: credential}
+}
+
+// ListSubscriptions returns the IDs of every enabled subscription visible for the configured credentials
+func (c *azureSDKClient) ListSubscriptions(ctx context.Context) ([]string, error) {
+	client, err := armsubscriptions.NewClient(c.credential, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var subscriptions []string
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing Azure subscriptions: %w", err)
+		}
+
+		for _, sub := range page.Value {
+			// Disabled or deleted subscriptions can't be queried
+			if sub.SubscriptionID == nil || sub.State == nil || *sub.State != armsubscriptions.SubscriptionStateEnabled {
+				continue
+			}
+			subscriptions = append(subscriptions, *sub.SubscriptionID)
+		}
+	}
+
+	return subscriptions, nil
+}
+
+// ListVirtualMachines returns every VM of a subscription including its runtime status
+func (c *azureSDKClient) ListVirtualMachines(ctx context.Context, subscriptionID string) ([]*armcompute.VirtualMachine, error) {
+	client, err := armcompute.NewVirtualMachinesClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// statusOnly=true includes the InstanceView (PowerState) of every VM on the response
+	statusOnly := "true"
+	pager := client.NewListAllPager(&armcompute.VirtualMachinesClientListAllOptions{StatusOnly: &statusOnly})
+
+	var vms []*armcompute.VirtualMachine
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing virtual machines on subscription %s: %w", subscriptionID, err)
+		}
+		vms = append(vms, page.Value...)
+	}
+
+	return vms, nil
+}
+
+// StartVirtualMachine starts the VM identified by its ARM resource ID. It
+// doesn't wait until the VM is running
+func (c *azureSDKClient) StartVirtualMachine(ctx context.Context, vmResourceID string) error {
+	id, client, err := c.newVirtualMachinesClient(vmResourceID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.BeginStart(ctx, id.ResourceGroupName, id.Name, nil); err != nil {
+		return fmt.Errorf("error starting virtual machine %s: %w", id.Name, err)
+	}
+	return nil
+}
+
+// DeallocateVirtualMachine stops and deallocates the VM identified by its ARM
+// resource ID. Deallocated VMs don't generate compute costs. It doesn't wait
+// until the VM is deallocated
+func (c *azureSDKClient) DeallocateVirtualMachine(ctx context.Context, vmResourceID string) error {
+	id, client, err := c.newVirtualMachinesClient(vmResourceID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.BeginDeallocate(ctx, id.ResourceGroupName, id.Name, nil); err != nil {
+		return fmt.Errorf("error deallocating virtual machine %s: %w", id.Name, err)
+	}
+	return nil
+}
+
+// newVirtualMachinesClient parses a VM resource ID and creates a VirtualMachinesClient for its subscription
+func (c *azureSDKClient) newVirtualMachinesClient(vmResourceID string) (*arm.ResourceID, *armcompute.VirtualMachinesClient, error) {
+	id, err := arm.ParseResourceID(vmResourceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid virtual machine resource ID %s: %w", vmResourceID, err)
+	}
+
+	client, err := armcompute.NewVirtualMachinesClient(id.SubscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return id, client, nil
+}
@@ -0,0 +1,177 @@
+package cloudprovider
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	compute "google.golang.org/api/compute/v1"
+)
+
+// GetInstances returns the list of Compute Engine instances of a zone as an array of inventory.Instance
+func (conn *GCPConnection) GetInstances(zone string) ([]inventory.Instance, error) {
+	gceInstances, err := conn.client.ListInstances(context.Background(), conn.projectID, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]inventory.Instance, 0, len(gceInstances))
+	for _, gceInstance := range gceInstances {
+		if gceInstance == nil {
+			continue
+		}
+		instance, err := ComputeInstanceToInventoryInstance(gceInstance)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, *instance)
+	}
+
+	return instances, nil
+}
+
+// StartClusterInstances starts the Compute Engine instances from the provided
+// instances (IDs) list. It looks for the instances on every zone of the
+// configured region, and only starts the stopped ones. Suspended instances
+// can't be started, so they're resumed instead
+func (conn *GCPConnection) StartClusterInstances(instanceIDs []string) error {
+	// On GCP, stopped instances are reported as TERMINATED
+	instances, err := conn.findInstances(instanceIDs, "TERMINATED", "SUSPENDED")
+	if err != nil {
+		return err
+	}
+
+	for _, instance := range instances {
+		zone := path.Base(instance.Zone)
+		if instance.Status == "SUSPENDED" {
+			err = conn.client.ResumeInstance(context.Background(), conn.projectID, zone, instance.Name)
+		} else {
+			err = conn.client.StartInstance(context.Background(), conn.projectID, zone, instance.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StopClusterInstances stops the Compute Engine instances from the provided
+// instances (IDs) list. It looks for the instances on every zone of the
+// configured region, and only stops the running ones
+func (conn *GCPConnection) StopClusterInstances(instanceIDs []string) error {
+	instances, err := conn.findInstances(instanceIDs, "RUNNING")
+	if err != nil {
+		return err
+	}
+
+	for _, instance := range instances {
+		if err := conn.client.StopInstance(context.Background(), conn.projectID, path.Base(instance.Zone), instance.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findInstances returns the Compute Engine instances whose ID is on the
+// instanceIDs list, are placed on the configured region and are on any of the
+// specified Compute Engine statuses
+func (conn *GCPConnection) findInstances(instanceIDs []string, statuses ...string) ([]*compute.Instance, error) {
+	if len(instanceIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := make(map[string]bool, len(instanceIDs))
+	for _, id := range instanceIDs {
+		ids[id] = true
+	}
+
+	zones, err := conn.GetZones()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*compute.Instance
+	for _, zone := range zones {
+		if conn.region != "" && GetRegionFromZone(zone) != conn.region {
+			continue
+		}
+
+		instances, err := conn.client.ListInstances(context.Background(), conn.projectID, zone)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, instance := range instances {
+			if instance == nil || !ids[strconv.FormatUint(instance.Id, 10)] {
+				continue
+			}
+			if !slices.Contains(statuses, instance.Status) {
+				continue
+			}
+			result = append(result, instance)
+		}
+	}
+
+	return result, nil
+}
+
+// ComputeInstanceToInventoryInstance converts a Compute Engine instance into
+// an inventory.Instance. It returns an error if the creation timestamp is not
+// a valid RFC3339 timestamp
+func ComputeInstanceToInventoryInstance(instance *compute.Instance) (*inventory.Instance, error) {
+	id := strconv.FormatUint(instance.Id, 10)
+	tags := ConvertGCPLabelsToTags(instance.Labels, id)
+	// Zone and MachineType are returned as full resource URLs
+	availabilityZone := path.Base(instance.Zone)
+	instanceType := path.Base(instance.MachineType)
+	status := computeStatusToInstanceStatus(instance.Status)
+	clusterID := inventory.GetClusterIDFromTags(tags)
+	var creationTimestamp time.Time
+	if instance.CreationTimestamp != "" {
+		var err error
+		creationTimestamp, err = time.Parse(time.RFC3339, instance.CreationTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid creation timestamp of instance %s: %w", instance.Name, err)
+		}
+	}
+
+	return inventory.NewInstance(
+		id,
+		instance.Name,
+		inventory.GCPProvider,
+		instanceType,
+		availabilityZone,
+		status,
+		clusterID,
+		tags,
+		creationTimestamp,
+	), nil
+}
+
+// computeStatusToInstanceStatus translates a Compute Engine instance status into an inventory.InstanceStatus
+// Doc: https://cloud.google.com/compute/docs/instances/instance-life-cycle
+func computeStatusToInstanceStatus(status string) inventory.InstanceStatus {
+	switch status {
+	// On GCP, TERMINATED means the instance is stopped and it can be started again
+	case "STOPPING", "STOPPED", "SUSPENDING", "SUSPENDED", "TERMINATED":
+		return inventory.Stopped
+	default:
+		return inventory.Running
+	}
+}
+
+// GetRegionFromZone returns the GCP region of a zone. For example: "us-central1-a" -> "us-central1"
+func GetRegionFromZone(zone string) string {
+	i := strings.LastIndex(zone, "-")
+	if i < 0 {
+		return zone
+	}
+	return zone[:i]
+}
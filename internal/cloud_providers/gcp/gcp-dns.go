@@ -0,0 +1,24 @@
+package cloudprovider
+
+import (
+	"context"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	dns "google.golang.org/api/dns/v1"
+)
+
+// GetManagedZones returns every Cloud DNS managed zone of the project
+func (conn *GCPConnection) GetManagedZones() ([]*dns.ManagedZone, error) {
+	return conn.client.ListManagedZones(context.Background(), conn.projectID)
+}
+
+// ManagedZoneBelongsToCluster returns true if the managed zone is labeled as owned by the cluster
+func ManagedZoneBelongsToCluster(cluster *inventory.Cluster, zone *dns.ManagedZone) bool {
+	clusterTagKey := inventory.ClusterTagKey + cluster.Name + "-" + cluster.InfraID
+	for key := range zone.Labels {
+		if NormalizeClusterLabelKey(key) == clusterTagKey {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package cloudprovider
+
+import (
+	"strings"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+)
+
+// GCP labels don't allow the '/' and '.' characters, so `openshift-installer`
+// labels the cluster resources using this prefix instead of inventory.ClusterTagKey
+const gcpClusterLabelPrefix = "kubernetes-io-cluster-"
+
+// ConvertGCPLabelsToTags transforms the GCP resource labels into inventory
+// Tags. The cluster labels are normalized to inventory.ClusterTagKey, so the
+// cluster info can be parsed in the same way as for any other provider
+func ConvertGCPLabelsToTags(labels map[string]string, instanceID string) []inventory.Tag {
+	var tags []inventory.Tag
+	for key, value := range labels {
+		tags = append(tags, *inventory.NewTag(NormalizeClusterLabelKey(key), value, instanceID))
+	}
+	return tags
+}
+
+// NormalizeClusterLabelKey translates a GCP cluster label key
+// (`kubernetes-io-cluster-<infraID>`) into the inventory.ClusterTagKey format
+// (`kubernetes.io/cluster/<infraID>`). Any other key is returned unmodified
+func NormalizeClusterLabelKey(key string) string {
+	if strings.HasPrefix(key, gcpClusterLabelPrefix) {
+		return inventory.ClusterTagKey + strings.TrimPrefix(key, gcpClusterLabelPrefix)
+	}
+	return key
+}
@@ -0,0 +1,179 @@
+package cloudprovider
+
+import (
+	"context"
+	"fmt"
+
+	compute "google.golang.org/api/compute/v1"
+	dns "google.golang.org/api/dns/v1"
+	"google.golang.org/api/option"
+)
+
+// GCPAPIClient defines the subset of the Google Cloud APIs used by ClusterIQ.
+// It allows to replace the real SDK based client by a local fake for testing
+// the stockers and executors without reaching GCP.
+type GCPAPIClient interface {
+	// ListZones returns the names of every Compute Engine zone available for the project
+	ListZones(ctx context.Context, projectID string) ([]string, error)
+	// ListInstances returns every Compute Engine instance of a zone
+	ListInstances(ctx context.Context, projectID string, zone string) ([]*compute.Instance, error)
+	// ListManagedZones returns every Cloud DNS managed zone of the project
+	ListManagedZones(ctx context.Context, projectID string) ([]*dns.ManagedZone, error)
+	// StartInstance starts a Compute Engine instance
+	StartInstance(ctx context.Context, projectID string, zone string, instance string) error
+	// StopInstance stops a Compute Engine instance
+	StopInstance(ctx context.Context, projectID string, zone string, instance string) error
+	// ResumeInstance resumes a suspended Compute Engine instance
+	ResumeInstance(ctx context.Context, projectID string, zone string, instance string) error
+}
+
+// GCPConnection defines the connection with Google Cloud APIs. Every
+// GCPConnection is bound to a single GCP project.
+type GCPConnection struct {
+	client    GCPAPIClient
+	projectID string
+	// region limits the power management operations to the zones of a single GCP region
+	region string
+}
+
+// NewGCPConnection creates a connection with Google Cloud APIs for the given
+// project. If credentialsFile is empty, the Application Default Credentials
+// are used
+func NewGCPConnection(projectID string, credentialsFile string) (*GCPConnection, error) {
+	if projectID == "" {
+		return nil, fmt.Errorf("missing project ID for the GCP connection")
+	}
+
+	var opts []option.ClientOption
+	if credentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(credentialsFile))
+	}
+
+	client, err := newGCPSDKClient(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewGCPConnectionWithClient(projectID, client), nil
+}
+
+// NewGCPConnectionWithClient creates a GCPConnection based on an already
+// configured GCPAPIClient
+func NewGCPConnectionWithClient(projectID string, client GCPAPIClient) *GCPConnection {
+	return &GCPConnection{
+		client:    client,
+		projectID: projectID,
+	}
+}
+
+// GetProjectID returns the GCP ProjectID of the GCPConnection
+func (conn GCPConnection) GetProjectID() string {
+	return conn.projectID
+}
+
+// SetRegion configures the GCP region used for filtering the zones on the power management operations
+func (conn *GCPConnection) SetRegion(region string) {
+	conn.region = region
+}
+
+// GetRegion returns the configured region for the GCPConnection
+func (conn GCPConnection) GetRegion() string {
+	return conn.region
+}
+
+// GetZones returns the list of Compute Engine zones available for the project
+func (conn *GCPConnection) GetZones() ([]string, error) {
+	return conn.client.ListZones(context.Background(), conn.projectID)
+}
+
+// gcpSDKClient implements GCPAPIClient using the official Google APIs client library
+type gcpSDKClient struct {
+	compute *compute.Service
+	dns     *dns.Service
+}
+
+// newGCPSDKClient creates the Compute Engine and Cloud DNS services
+func newGCPSDKClient(ctx context.Context, opts ...option.ClientOption) (*gcpSDKClient, error) {
+	computeService, err := compute.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Compute Engine client: %w", err)
+	}
+
+	dnsService, err := dns.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud DNS client: %w", err)
+	}
+
+	return &gcpSDKClient{
+		compute: computeService,
+		dns:     dnsService,
+	}, nil
+}
+
+// ListZones returns the names of every Compute Engine zone available for the project
+func (c *gcpSDKClient) ListZones(ctx context.Context, projectID string) ([]string, error) {
+	var zones []string
+	err := c.compute.Zones.List(projectID).Pages(ctx, func(page *compute.ZoneList) error {
+		for _, zone := range page.Items {
+			zones = append(zones, zone.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing zones on project %s: %w", projectID, err)
+	}
+
+	return zones, nil
+}
+
+// ListInstances returns every Compute Engine instance of a zone
+func (c *gcpSDKClient) ListInstances(ctx context.Context, projectID string, zone string) ([]*compute.Instance, error) {
+	var instances []*compute.Instance
+	err := c.compute.Instances.List(projectID, zone).Pages(ctx, func(page *compute.InstanceList) error {
+		instances = append(instances, page.Items...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing instances on zone %s: %w", zone, err)
+	}
+
+	return instances, nil
+}
+
+// ListManagedZones returns every Cloud DNS managed zone of the project
+func (c *gcpSDKClient) ListManagedZones(ctx context.Context, projectID string) ([]*dns.ManagedZone, error) {
+	var zones []*dns.ManagedZone
+	err := c.dns.ManagedZones.List(projectID).Pages(ctx, func(page *dns.ManagedZonesListResponse) error {
+		zones = append(zones, page.ManagedZones...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing DNS managed zones on project %s: %w", projectID, err)
+	}
+
+	return zones, nil
+}
+
+// StartInstance starts a Compute Engine instance. It doesn't wait until the instance is running
+func (c *gcpSDKClient) StartInstance(ctx context.Context, projectID string, zone string, instance string) error {
+	if _, err := c.compute.Instances.Start(projectID, zone, instance).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("error starting instance %s on zone %s: %w", instance, zone, err)
+	}
+	return nil
+}
+
+// StopInstance stops a Compute Engine instance. It doesn't wait until the instance is stopped
+func (c *gcpSDKClient) StopInstance(ctx context.Context, projectID string, zone string, instance string) error {
+	if _, err := c.compute.Instances.Stop(projectID, zone, instance).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("error stopping instance %s on zone %s: %w", instance, zone, err)
+	}
+	return nil
+}
+
+// ResumeInstance resumes a suspended Compute Engine instance. It doesn't wait until the instance is running
+func (c *gcpSDKClient) ResumeInstance(ctx context.Context, projectID string, zone string, instance string) error {
+	if _, err := c.compute.Instances.Resume(projectID, zone, instance).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("error resuming instance %s on zone %s: %w", instance, zone, err)
+	}
+	return nil
+}
@@ -10,6 +10,12 @@ type ExecutorAgentServiceConfig struct {
 	DBURL  string `env:"CIQ_DB_URL,required"`
 	// Credentials for accessing the cloud providers accounts
 	Credentials CloudCredentialsConfig
+	// AWSRetry defines the retry policy for the AWS API requests
+	AWSRetry AWSRetryConfig
+	// CredsReloadInterval defines the amount of time between credentials file checks for hot reloading the executors. Disabled if <= 0
+	CredsReloadInterval int `env:"CIQ_AGENT_CREDS_RELOAD_SECONDS_INTERVAL" envDefault:"30"`
+	// OrgDiscoveryInterval defines the amount of time between AWS Organizations discoveries for adding and removing the member accounts executors. Disabled if <= 0
+	OrgDiscoveryInterval int `env:"CIQ_AGENT_ORG_DISCOVERY_SECONDS_INTERVAL" envDefault:"3600"`
 }
 
 // InstantAgentServiceConfig contains the config parameters for the InstantAgentService (gRPC)
@@ -6,8 +6,14 @@ import env "github.com/caarlos0/env/v11"
 type APIServerConfig struct {
 	ListenURL string `env:"CIQ_API_LISTEN_URL,required"`
 	AgentURL  string `env:"CIQ_AGENT_URL,required"`
-	DBURL     string `env:"CIQ_DB_URL,required"`
-	LogLevel  string `env:"CIQ_LOG_LEVEL,required"`
+	// ScannerURL refers to the ClusterIQ Scanner daemon endpoint, used for the on-demand scans
+	ScannerURL string `env:"CIQ_SCANNER_URL" envDefault:"http://scanner:8081"`
+	DBURL      string `env:"CIQ_DB_URL,required"`
+	LogLevel   string `env:"CIQ_LOG_LEVEL,required"`
+	// TerminationGracePeriod is the time (hours) the clusters and instances remain Missing before they're marked as Terminated
+	TerminationGracePeriod int `env:"CIQ_TERMINATION_GRACE_PERIOD_HOURS" envDefault:"24"`
+	// PriceTableFile is the CSV file with the on-demand prices for estimating the costs of the accounts without billing information. The bundled table is used if it's empty
+	PriceTableFile string `env:"CIQ_PRICE_TABLE_FILE" envDefault:""`
 }
 
 // LoadAPIServerConfig evaluates and return the APIServerConfig Object
@@ -4,3 +4,11 @@ package config
 type CloudCredentialsConfig struct {
 	CredentialsFile string `env:"CIQ_CREDS_FILE,required"`
 }
+
+// AWSRetryConfig represents the config parameters for retrying the failed or
+// throttled AWS API requests with exponential backoff
+type AWSRetryConfig struct {
+	AWSMaxRetries         int `env:"CIQ_AWS_MAX_RETRIES" envDefault:"8"`
+	AWSRetryMinDelayMilis int `env:"CIQ_AWS_RETRY_MIN_DELAY_MS" envDefault:"500"`
+	AWSRetryMaxDelayMilis int `env:"CIQ_AWS_RETRY_MAX_DELAY_MS" envDefault:"30000"`
+}
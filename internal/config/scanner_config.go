@@ -1,12 +1,32 @@
 package config
 
-import env "github.com/caarlos0/env/v11"
+import (
+	"fmt"
+
+	env "github.com/caarlos0/env/v11"
+)
 
 // ScannerConfig defines the config parameters for the ClusterIQ Scanner
 type ScannerConfig struct {
 	CloudCredentialsConfig
+	AWSRetryConfig
 	APIURL                   string `env:"CIQ_API_URL,required"`
 	SkipNoOpenShiftInstances bool   `env:"CIQ_SKIP_NO_OPENSHIFT_INSTANCES" envDefault:"true"`
+	OrphanDetection          bool   `env:"CIQ_ORPHAN_DETECTION" envDefault:"true"`
+	// RegionWorkers is the number of AWS regions scanned concurrently on every account
+	RegionWorkers int `env:"CIQ_SCANNER_REGION_WORKERS" envDefault:"4"`
+	// ClusterCostQueries is the maximum number of Cost Explorer queries per cluster made on every account scan, for the accounts without cluster cost tag. Zero disables the limit
+	ClusterCostQueries int `env:"CIQ_SCANNER_CLUSTER_COST_QUERIES" envDefault:"20"`
+	// ClusterCostsCacheHours is the time the costs obtained per cluster are reused before querying them again
+	ClusterCostsCacheHours int `env:"CIQ_SCANNER_CLUSTER_COSTS_CACHE_HOURS" envDefault:"24"`
+	// DaemonMode keeps the scanner running and rescanning every ScanInterval seconds
+	DaemonMode   bool   `env:"CIQ_SCANNER_DAEMON" envDefault:"false"`
+	ScanInterval int    `env:"CIQ_SCANNER_SECONDS_INTERVAL" envDefault:"3600"`
+	ListenURL    string `env:"CIQ_SCANNER_LISTEN_URL" envDefault:"0.0.0.0:8081"`
+	// OutputFile enables the dry-run mode: the inventory is written to this file (JSON or YAML by extension) instead of posting it into the API
+	OutputFile string `env:"CIQ_SCANNER_OUTPUT_FILE"`
+	// ImportFile enables the import mode: the inventory stored on this file is posted into the API without scanning
+	ImportFile string `env:"CIQ_SCANNER_IMPORT_FILE"`
 }
 
 // LoadScannerConfig evaluates and return the ScannerConfig object
@@ -16,5 +36,14 @@ func LoadScannerConfig() (*ScannerConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	if cfg.RegionWorkers < 1 {
+		return nil, fmt.Errorf("CIQ_SCANNER_REGION_WORKERS must be at least 1, got %d", cfg.RegionWorkers)
+	}
+	if cfg.ClusterCostQueries < 0 {
+		return nil, fmt.Errorf("CIQ_SCANNER_CLUSTER_COST_QUERIES can't be negative, got %d", cfg.ClusterCostQueries)
+	}
+	if cfg.ClusterCostsCacheHours < 0 {
+		return nil, fmt.Errorf("CIQ_SCANNER_CLUSTER_COSTS_CACHE_HOURS can't be negative, got %d", cfg.ClusterCostsCacheHours)
+	}
 	return cfg, nil
 }
@@ -0,0 +1,178 @@
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	cpaws "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+)
+
+const (
+	// Placeholder replaced by the member AccountID on the MemberRoleARN template
+	memberAccountIDPlaceholder = "{account_id}"
+)
+
+// discoverMembers lists the member accounts of an organization. It's a
+// variable so the tests can replace the AWS Organizations API calls
+var discoverMembers = discoverOrganizationMembers
+
+// OrganizationDiscoveryError is returned when the member accounts of an
+// organization can't be discovered
+type OrganizationDiscoveryError struct {
+	ManagementAccount string
+	Err               error
+}
+
+func (e *OrganizationDiscoveryError) Error() string {
+	return fmt.Sprintf("organization discovery failed for account %s: %v", e.ManagementAccount, e.Err)
+}
+
+func (e *OrganizationDiscoveryError) Unwrap() error {
+	return e.Err
+}
+
+// ExpandAWSOrganizations returns the accounts list including the member
+// accounts discovered on every AWS Organization management account with
+// OrganizationDiscovery enabled. Accounts explicitly defined on the
+// credentials file take precedence over the discovered ones with the same
+// name. If the discovery fails for an organization, its members are skipped
+// and an OrganizationDiscoveryError is returned together with the rest of
+// the accounts. The input slice is never modified
+func ExpandAWSOrganizations(accounts []AccountConfig) ([]AccountConfig, error) {
+	knownNames := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		knownNames[account.Name] = true
+	}
+
+	result := slices.Clone(accounts)
+	var errs []error
+	for _, account := range accounts {
+		if account.Provider != inventory.AWSProvider || !account.OrganizationDiscovery {
+			continue
+		}
+
+		members, err := discoverMembers(account)
+		if err != nil {
+			errs = append(errs, &OrganizationDiscoveryError{ManagementAccount: account.Name, Err: err})
+			continue
+		}
+
+		for _, member := range members {
+			if knownNames[member.Name] {
+				continue
+			}
+			knownNames[member.Name] = true
+			result = append(result, member)
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// KeepFailedOrganizations adds to the accounts the previous members of the
+// organizations whose discovery failed on err, so a transient discovery error
+// (e.g. throttling or expired credentials) doesn't drop them. The accounts
+// already on the list take precedence. The input slices are never modified
+func KeepFailedOrganizations(accounts []AccountConfig, previous []AccountConfig, err error) []AccountConfig {
+	failed := make(map[string]bool)
+	for _, e := range unwrapErrors(err) {
+		var discoveryErr *OrganizationDiscoveryError
+		if errors.As(e, &discoveryErr) {
+			failed[discoveryErr.ManagementAccount] = true
+		}
+	}
+
+	knownNames := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		knownNames[account.Name] = true
+	}
+
+	result := slices.Clone(accounts)
+	for _, account := range previous {
+		if !failed[account.ManagementAccount] || knownNames[account.Name] {
+			continue
+		}
+		knownNames[account.Name] = true
+		result = append(result, account)
+	}
+
+	return result
+}
+
+// unwrapErrors returns the errors joined on err, or err itself if it's not a join
+func unwrapErrors(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	if err == nil {
+		return nil
+	}
+	return []error{err}
+}
+
+// discoverOrganizationMembers lists the active member accounts of the
+// organization managed by the specified account, and returns an
+// AccountConfig for each of them. The management account is not included
+func discoverOrganizationMembers(management AccountConfig) ([]AccountConfig, error) {
+	if !strings.Contains(management.MemberRoleARN, memberAccountIDPlaceholder) {
+		return nil, fmt.Errorf("member_role_arn must include the %s placeholder", memberAccountIDPlaceholder)
+	}
+
+	conn, err := cpaws.NewAWSConnection(management.User, management.Key, management.AWSAssumeRoleConfig(), cpaws.OrganizationsRegion, cpaws.WithOrganizations())
+	if err != nil {
+		return nil, err
+	}
+
+	managementID, err := conn.Organizations.GetManagementAccountID()
+	if err != nil {
+		return nil, err
+	}
+
+	orgAccounts, err := conn.Organizations.GetActiveAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]AccountConfig, 0, len(orgAccounts))
+	for _, orgAccount := range orgAccounts {
+		if orgAccount.ID == managementID {
+			continue
+		}
+		members = append(members, NewMemberAccountConfig(management, orgAccount))
+	}
+
+	return members, nil
+}
+
+// NewMemberAccountConfig generates the AccountConfig of an organization
+// member account. The member inherits the source credentials and settings of
+// the management account, and assumes the role defined by the MemberRoleARN
+// template. The account name is the member name on the organization, or its
+// AccountID if it's empty
+func NewMemberAccountConfig(management AccountConfig, member cpaws.OrganizationAccount) AccountConfig {
+	name := member.Name
+	if name == "" {
+		name = member.ID
+	}
+
+	return AccountConfig{
+		Name:                 name,
+		Provider:             inventory.AWSProvider,
+		User:                 management.User,
+		Key:                  management.Key,
+		BillingEnabled:       management.BillingEnabled,
+		BillingSource:        management.BillingSource,
+		CURPath:              management.CURPath,
+		CURRegion:            management.CURRegion,
+		Regions:              management.Regions,
+		ExcludeRegions:       management.ExcludeRegions,
+		RoleARN:              strings.ReplaceAll(management.MemberRoleARN, memberAccountIDPlaceholder, member.ID),
+		ExternalID:           management.ExternalID,
+		SourceProfile:        management.SourceProfile,
+		WebIdentityTokenFile: management.WebIdentityTokenFile,
+		ManagementAccount:    management.Name,
+	}
+}
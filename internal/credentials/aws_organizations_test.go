@@ -0,0 +1,123 @@
+package credentials
+
+import (
+	"errors"
+	"testing"
+
+	cpaws "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewMemberAccountConfig verifies the member accounts inherit the management settings and assume the templated role
+func TestNewMemberAccountConfig(t *testing.T) {
+	management := AccountConfig{
+		Name:                  "management",
+		Provider:              inventory.AWSProvider,
+		User:                  "AKIAEXAMPLE",
+		Key:                   "secret",
+		BillingEnabled:        true,
+		ExcludeRegions:        []string{"ap-south-1"},
+		ExternalID:            "external",
+		OrganizationDiscovery: true,
+		MemberRoleARN:         "arn:aws:iam::{account_id}:role/ClusterIQReadOnly",
+	}
+
+	member := NewMemberAccountConfig(management, cpaws.OrganizationAccount{ID: "123456789012", Name: "sandbox-01"})
+	assert.Equal(t, "sandbox-01", member.Name)
+	assert.Equal(t, inventory.AWSProvider, member.Provider)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/ClusterIQReadOnly", member.RoleARN)
+	assert.Equal(t, "external", member.ExternalID)
+	assert.Equal(t, "AKIAEXAMPLE", member.User)
+	assert.True(t, member.BillingEnabled)
+	assert.Equal(t, []string{"ap-south-1"}, member.ExcludeRegions)
+	assert.False(t, member.OrganizationDiscovery)
+	assert.Equal(t, "management", member.ManagementAccount)
+
+	unnamed := NewMemberAccountConfig(management, cpaws.OrganizationAccount{ID: "210987654321"})
+	assert.Equal(t, "210987654321", unnamed.Name)
+}
+
+// TestExpandAWSOrganizationsWithoutDiscovery verifies the accounts list is not modified when the discovery is disabled
+func TestExpandAWSOrganizationsWithoutDiscovery(t *testing.T) {
+	accounts := []AccountConfig{
+		{Name: "aws-account", Provider: inventory.AWSProvider},
+		{Name: "gcp-account", Provider: inventory.GCPProvider, OrganizationDiscovery: true},
+	}
+
+	result, err := ExpandAWSOrganizations(accounts)
+	assert.Nil(t, err)
+	assert.Equal(t, accounts, result)
+}
+
+// TestExpandAWSOrganizationsInvalidTemplate verifies the discovery fails when the member role template has no AccountID placeholder
+func TestExpandAWSOrganizationsInvalidTemplate(t *testing.T) {
+	accounts := []AccountConfig{
+		{Name: "management", Provider: inventory.AWSProvider, OrganizationDiscovery: true, MemberRoleARN: "arn:aws:iam::123456789012:role/ClusterIQReadOnly"},
+	}
+
+	result, err := ExpandAWSOrganizations(accounts)
+	var discoveryErr *OrganizationDiscoveryError
+	if assert.ErrorAs(t, err, &discoveryErr) {
+		assert.Equal(t, "management", discoveryErr.ManagementAccount)
+	}
+	assert.Equal(t, accounts, result)
+}
+
+// TestExpandAWSOrganizationsDoesNotModifyInput verifies the discovered members are not written on the caller's slice, even when it has spare capacity
+func TestExpandAWSOrganizationsDoesNotModifyInput(t *testing.T) {
+	defer func(original func(AccountConfig) ([]AccountConfig, error)) { discoverMembers = original }(discoverMembers)
+	discoverMembers = func(management AccountConfig) ([]AccountConfig, error) {
+		return []AccountConfig{
+			{Name: "management", Provider: inventory.AWSProvider},
+			{Name: "sandbox-01", Provider: inventory.AWSProvider},
+		}, nil
+	}
+
+	accounts := make([]AccountConfig, 1, 4)
+	accounts[0] = AccountConfig{Name: "management", Provider: inventory.AWSProvider, OrganizationDiscovery: true}
+
+	result, err := ExpandAWSOrganizations(accounts)
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, "sandbox-01", result[1].Name)
+
+	assert.Len(t, accounts, 1)
+	assert.Equal(t, AccountConfig{}, accounts[:2][1])
+}
+
+// TestKeepFailedOrganizations verifies only the previous members of the organizations whose discovery failed are kept, without replacing the accounts already listed
+func TestKeepFailedOrganizations(t *testing.T) {
+	defer func(original func(AccountConfig) ([]AccountConfig, error)) { discoverMembers = original }(discoverMembers)
+	discoverMembers = func(management AccountConfig) ([]AccountConfig, error) {
+		if management.Name == "failed-org" {
+			return nil, errors.New("ThrottlingException")
+		}
+		return []AccountConfig{{Name: "current-member", Provider: inventory.AWSProvider, ManagementAccount: management.Name}}, nil
+	}
+
+	accounts := []AccountConfig{
+		{Name: "failed-org", Provider: inventory.AWSProvider, OrganizationDiscovery: true},
+		{Name: "discovered-org", Provider: inventory.AWSProvider, OrganizationDiscovery: true},
+		{Name: "explicit-member", Provider: inventory.AWSProvider, User: "AKIAEXPLICIT"},
+	}
+	previous := []AccountConfig{
+		{Name: "failed-org", Provider: inventory.AWSProvider, OrganizationDiscovery: true},
+		{Name: "kept-member", Provider: inventory.AWSProvider, ManagementAccount: "failed-org"},
+		{Name: "explicit-member", Provider: inventory.AWSProvider, ManagementAccount: "failed-org"},
+		{Name: "left-member", Provider: inventory.AWSProvider, ManagementAccount: "discovered-org"},
+	}
+
+	expanded, err := ExpandAWSOrganizations(accounts)
+	assert.NotNil(t, err)
+	result := KeepFailedOrganizations(expanded, previous, err)
+
+	var names []string
+	for _, account := range result {
+		names = append(names, account.Name)
+	}
+	assert.Equal(t, []string{"failed-org", "discovered-org", "explicit-member", "current-member", "kept-member"}, names)
+	assert.Equal(t, "AKIAEXPLICIT", result[2].User)
+
+	assert.Equal(t, expanded, KeepFailedOrganizations(expanded, previous, nil))
+}
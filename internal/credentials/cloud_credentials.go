@@ -1,19 +1,68 @@
 package credentials
 
 import (
+	"fmt"
+
+	cpaws "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
 	ini "gopkg.in/ini.v1"
 )
 
+const (
+	// BillingSourceCostExplorer gets the instances expenses from the AWS Cost Explorer API
+	BillingSourceCostExplorer = "cost_explorer"
+	// BillingSourceCUR gets the instances expenses from the AWS Cost and Usage Report files
+	BillingSourceCUR = "cur"
+)
+
 type AccountConfig struct {
 	Name           string
 	Provider       inventory.CloudProvider
 	User           string
 	Key            string
 	BillingEnabled bool
+	// BillingSource defines where the billing information is obtained from. Empty when the billing is disabled
+	BillingSource string
+	// CURPath is the local path or S3 URL (s3://<bucket>/<prefix>) of the CUR files. Only used by the CUR billing source
+	CURPath string
+	// CURRegion is the region of the CUR S3 bucket. Only used by the CUR billing source
+	CURRegion string
+	// ClusterCostTag is the cost allocation tag whose value is the InfraID or the name of the cluster owning each resource. Only used by the Cost Explorer billing source
+	ClusterCostTag string
+	// TenantID is the Azure Active Directory tenant of the Service Principal. Only used by Azure accounts
+	TenantID string
+	// Regions limits the scanned and managed regions of the account. Empty means every region
+	Regions []string
+	// ExcludeRegions lists the regions to be ignored on the account
+	ExcludeRegions []string
+	// RoleARN is the IAM role assumed for accessing the account. Only used by AWS accounts
+	RoleARN string
+	// ExternalID required by the trust policy of RoleARN. Only used by AWS accounts
+	ExternalID string
+	// SourceProfile is the shared config profile used for assuming RoleARN when user and key are empty. Only used by AWS accounts
+	SourceProfile string
+	// WebIdentityTokenFile is the OIDC token exchanged for the RoleARN credentials (IRSA). Only used by AWS accounts
+	WebIdentityTokenFile string
+	// OrganizationDiscovery enables the discovery of the member accounts when the account is an AWS Organization management account
+	OrganizationDiscovery bool
+	// MemberRoleARN is the template of the role assumed on every discovered member account. Only used with OrganizationDiscovery
+	MemberRoleARN string
+	// ManagementAccount is the name of the management account that discovered the account. Empty for the accounts of the credentials file
+	ManagementAccount string
 }
 
-// ReadCloudAccounts reads all account configs
+// AWSAssumeRoleConfig returns the role settings of the account for creating AWS connections
+func (a AccountConfig) AWSAssumeRoleConfig() cpaws.AWSAssumeRoleConfig {
+	return cpaws.AWSAssumeRoleConfig{
+		RoleARN:              a.RoleARN,
+		ExternalID:           a.ExternalID,
+		SourceProfile:        a.SourceProfile,
+		WebIdentityTokenFile: a.WebIdentityTokenFile,
+	}
+}
+
+// ReadCloudAccounts reads all account configs. The billing source of the
+// accounts with billing_enabled and without billing_source is Cost Explorer
 func ReadCloudAccounts(credsFile string) ([]AccountConfig, error) {
 	cfg, err := ini.Load(credsFile)
 	if err != nil {
@@ -27,14 +76,51 @@ func ReadCloudAccounts(credsFile string) ([]AccountConfig, error) {
 	var accounts []AccountConfig
 	for _, section := range cfg.Sections() {
 		account := AccountConfig{
-			Name:           section.Name(),
-			Provider:       inventory.GetCloudProvider(section.Key("provider").String()),
-			User:           section.Key("user").String(),
-			Key:            section.Key("key").String(),
-			BillingEnabled: section.Key("billing_enabled").MustBool(),
+			Name:                  section.Name(),
+			Provider:              inventory.GetCloudProvider(section.Key("provider").String()),
+			User:                  section.Key("user").String(),
+			Key:                   section.Key("key").String(),
+			BillingEnabled:        section.Key("billing_enabled").MustBool(),
+			TenantID:              section.Key("tenant_id").String(),
+			Regions:               section.Key("regions").Strings(","),
+			ExcludeRegions:        section.Key("exclude_regions").Strings(","),
+			RoleARN:               section.Key("role_arn").String(),
+			ExternalID:            section.Key("external_id").String(),
+			SourceProfile:         section.Key("source_profile").String(),
+			WebIdentityTokenFile:  section.Key("web_identity_token_file").String(),
+			OrganizationDiscovery: section.Key("organization_discovery").MustBool(),
+			MemberRoleARN:         section.Key("member_role_arn").String(),
+			BillingSource:         section.Key("billing_source").String(),
+			CURPath:               section.Key("cur_path").String(),
+			CURRegion:             section.Key("cur_region").String(),
+			ClusterCostTag:        section.Key("cluster_cost_tag").String(),
+		}
+
+		if account.BillingSource == "" && account.BillingEnabled {
+			account.BillingSource = BillingSourceCostExplorer
+		}
+		if err := account.validateBillingSource(); err != nil {
+			return nil, fmt.Errorf("invalid billing configuration for account %s: %w", account.Name, err)
 		}
+		account.BillingEnabled = account.BillingSource != ""
+
 		accounts = append(accounts, account)
 	}
 
 	return accounts, nil
 }
+
+// validateBillingSource checks the billing source is known and it has its required settings
+func (a AccountConfig) validateBillingSource() error {
+	switch a.BillingSource {
+	case "", BillingSourceCostExplorer:
+		return nil
+	case BillingSourceCUR:
+		if a.CURPath == "" {
+			return fmt.Errorf("cur_path is required by the %s billing source", BillingSourceCUR)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown billing source: %s", a.BillingSource)
+	}
+}
@@ -0,0 +1,69 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeCredentialsFile writes a credentials file on a temporary directory and returns its path
+func writeCredentialsFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "credentials")
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// TestReadCloudAccountsBillingSource verifies the billing source is read, and billing_enabled falls back to Cost Explorer
+func TestReadCloudAccountsBillingSource(t *testing.T) {
+	path := writeCredentialsFile(t, `
+[cost-explorer]
+provider = aws
+billing_enabled = true
+cluster_cost_tag = cluster-name
+
+[cur-s3]
+provider = aws
+billing_source = cur
+cur_path = s3://cur-bucket/cur/report/
+cur_region = eu-west-1
+
+[cur-local]
+provider = aws
+billing_enabled = false
+billing_source = cur
+cur_path = /var/lib/cur
+
+[no-billing]
+provider = aws
+`)
+
+	accounts, err := ReadCloudAccounts(path)
+	assert.Nil(t, err)
+	assert.Len(t, accounts, 4)
+
+	assert.Equal(t, BillingSourceCostExplorer, accounts[0].BillingSource)
+	assert.True(t, accounts[0].BillingEnabled)
+	assert.Equal(t, "cluster-name", accounts[0].ClusterCostTag)
+
+	assert.Equal(t, BillingSourceCUR, accounts[1].BillingSource)
+	assert.Equal(t, "s3://cur-bucket/cur/report/", accounts[1].CURPath)
+	assert.Equal(t, "eu-west-1", accounts[1].CURRegion)
+	assert.True(t, accounts[1].BillingEnabled)
+
+	assert.Equal(t, BillingSourceCUR, accounts[2].BillingSource)
+	assert.True(t, accounts[2].BillingEnabled)
+
+	assert.Equal(t, "", accounts[3].BillingSource)
+	assert.False(t, accounts[3].BillingEnabled)
+}
+
+// TestReadCloudAccountsInvalidBillingSource verifies the unknown billing sources and the CUR source without path are rejected
+func TestReadCloudAccountsInvalidBillingSource(t *testing.T) {
+	_, err := ReadCloudAccounts(writeCredentialsFile(t, "[account]\nprovider = aws\nbilling_source = invoices\n"))
+	assert.NotNil(t, err)
+
+	_, err = ReadCloudAccounts(writeCredentialsFile(t, "[account]\nprovider = aws\nbilling_source = cur\n"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,207 @@
+// Package forecast projects the end of month and next month costs of the
+// clusters and accounts from the history of their daily costs
+package forecast
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// HistoryDays is the number of days of history used for the projections.
+	// Four full weeks, so every day of the week has the same weight
+	HistoryDays = 28
+
+	// maxBillingDelay is the number of days the billing information can be
+	// delayed. When the last daily cost is older, the days after it are
+	// considered without costs instead of pending
+	maxBillingDelay = 3
+
+	day = 24 * time.Hour
+)
+
+// DailyCost is the cost of a cluster, account or the whole inventory during a day
+type DailyCost struct {
+	// Date (Year, month, day)
+	Date time.Time `db:"date" json:"date"`
+
+	// Cost in US Dollars
+	Amount float64 `db:"amount" json:"amount"`
+}
+
+// Projection contains the projected costs of a forecasting method
+type Projection struct {
+	// Cost at the end of the current month: the current month so far cost plus the projected costs of the rest of days
+	EndOfMonthCost float64 `json:"endOfMonthCost"`
+
+	// Projected cost of the next month
+	NextMonthCost float64 `json:"nextMonthCost"`
+}
+
+// Forecast contains the projected costs of a cluster, account or the whole inventory
+type Forecast struct {
+	// Last day with known costs. The projections start on the next day
+	LastCostDate *time.Time `json:"lastCostDate"`
+
+	// Number of days of history used for the projections
+	HistoryDays int `json:"historyDays"`
+
+	// Known costs of the current month
+	CurrentMonthSoFarCost float64 `json:"currentMonthSoFarCost"`
+
+	// Projection following the linear trend of the daily costs
+	Linear Projection `json:"linear"`
+
+	// Projection based on the average cost of every day of the week, for the
+	// costs following weekly patterns (e.g. clusters stopped on weekends)
+	DayOfWeek Projection `json:"dayOfWeek"`
+}
+
+// HistoryStart returns the first day of the daily costs needed for a forecast
+// on the specified date: the history days before the maximum billing delay,
+// which always include the beginning of the current month
+func HistoryStart(now time.Time) time.Time {
+	return now.UTC().Truncate(day).AddDate(0, 0, -(HistoryDays + maxBillingDelay))
+}
+
+// NewForecast projects the costs of the current and the next month from the
+// daily costs before the specified date. The days without costs in the
+// history count as zero cost days. If the last daily cost is within the
+// maximum billing delay, the projections start after it, as the billing
+// information of the following days may not be available yet
+func NewForecast(costs []DailyCost, now time.Time) Forecast {
+	today := now.UTC().Truncate(day)
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	nextMonthStart := monthStart.AddDate(0, 1, 0)
+	nextMonthEnd := nextMonthStart.AddDate(0, 1, 0)
+
+	// Adding up the costs by day, ignoring the incomplete current day and the future ones
+	amounts := make(map[time.Time]float64)
+	var first, last time.Time
+	for _, cost := range costs {
+		date := cost.Date.UTC().Truncate(day)
+		if !date.Before(today) {
+			continue
+		}
+		amounts[date] += cost.Amount
+		if first.IsZero() || date.Before(first) {
+			first = date
+		}
+		if last.IsZero() || date.After(last) {
+			last = date
+		}
+	}
+
+	var forecast Forecast
+	if len(amounts) == 0 {
+		return forecast
+	}
+
+	// The history ends on the last daily cost, unless it's older than the billing delay
+	historyEnd := last
+	if historyEnd.Before(today.AddDate(0, 0, -maxBillingDelay)) {
+		historyEnd = today.AddDate(0, 0, -1)
+	}
+	historyStart := historyEnd.AddDate(0, 0, 1-HistoryDays)
+	if first.After(historyStart) {
+		historyStart = first
+	}
+
+	var history []float64
+	for date := historyStart; !date.After(historyEnd); date = date.Add(day) {
+		history = append(history, amounts[date])
+	}
+	for date, amount := range amounts {
+		if !date.Before(monthStart) && !date.After(historyEnd) {
+			forecast.CurrentMonthSoFarCost += amount
+		}
+	}
+
+	forecast.LastCostDate = &historyEnd
+	forecast.HistoryDays = len(history)
+
+	// Linear trend of the history (least squares), where x is the days since the history start
+	intercept, slope := linearRegression(history)
+	linear := func(date time.Time) float64 {
+		x := float64(date.Sub(historyStart) / day)
+		return math.Max(0, intercept+slope*x)
+	}
+
+	// Average cost of every day of the week. The days of the week not included
+	// on the history use the average of the whole history
+	var weekdayAmounts, weekdayDays [7]float64
+	var total float64
+	for i, amount := range history {
+		weekday := historyStart.AddDate(0, 0, i).Weekday()
+		weekdayAmounts[weekday] += amount
+		weekdayDays[weekday]++
+		total += amount
+	}
+	dayOfWeek := func(date time.Time) float64 {
+		if weekdayDays[date.Weekday()] == 0 {
+			return total / float64(len(history))
+		}
+		return weekdayAmounts[date.Weekday()] / weekdayDays[date.Weekday()]
+	}
+
+	forecast.Linear = project(forecast.CurrentMonthSoFarCost, historyEnd, monthStart, nextMonthStart, nextMonthEnd, linear)
+	forecast.DayOfWeek = project(forecast.CurrentMonthSoFarCost, historyEnd, monthStart, nextMonthStart, nextMonthEnd, dayOfWeek)
+	forecast.CurrentMonthSoFarCost = roundCost(forecast.CurrentMonthSoFarCost)
+
+	return forecast
+}
+
+// WithoutProjection returns the forecast without projected costs, for the
+// clusters that don't generate costs anymore. The end of month costs are the
+// known costs of the current month, and the next month costs are zero
+func (f Forecast) WithoutProjection() Forecast {
+	f.Linear = Projection{EndOfMonthCost: f.CurrentMonthSoFarCost}
+	f.DayOfWeek = f.Linear
+	return f
+}
+
+// project adds up the daily costs projected by a method for the days after
+// the history end of the current and the next month
+func project(monthSoFarCost float64, historyEnd time.Time, monthStart time.Time, nextMonthStart time.Time, nextMonthEnd time.Time, dailyCost func(time.Time) float64) Projection {
+	projection := Projection{EndOfMonthCost: monthSoFarCost}
+	for date := historyEnd.Add(day); date.Before(nextMonthEnd); date = date.Add(day) {
+		switch {
+		case date.Before(monthStart):
+			// Days of the previous month pending of billing
+		case date.Before(nextMonthStart):
+			projection.EndOfMonthCost += dailyCost(date)
+		default:
+			projection.NextMonthCost += dailyCost(date)
+		}
+	}
+
+	projection.EndOfMonthCost = roundCost(projection.EndOfMonthCost)
+	projection.NextMonthCost = roundCost(projection.NextMonthCost)
+	return projection
+}
+
+// linearRegression returns the intercept and slope of the least squares line
+// of the values, where x is their index
+func linearRegression(values []float64) (float64, float64) {
+	n := float64(len(values))
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range values {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return sumY / n, 0
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	return (sumY - slope*sumX) / n, slope
+}
+
+// roundCost rounds a cost to cents, like the costs stored on the DB
+func roundCost(cost float64) float64 {
+	return math.Round(cost*100) / 100
+}
@@ -0,0 +1,107 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// dailyCosts returns the daily costs between two dates (both included), using the amount function for every day
+func dailyCosts(from time.Time, to time.Time, amount func(time.Time) float64) []DailyCost {
+	var costs []DailyCost
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		costs = append(costs, DailyCost{Date: date, Amount: amount(date)})
+	}
+	return costs
+}
+
+// date returns the specified day of 2024 in UTC
+func date(month time.Month, day int) time.Time {
+	return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// TestNewForecastConstantCosts verifies both methods project the same costs for constant daily costs, ignoring the current day
+func TestNewForecastConstantCosts(t *testing.T) {
+	costs := dailyCosts(date(time.April, 1), date(time.May, 20), func(time.Time) float64 { return 10 })
+
+	forecast := NewForecast(costs, date(time.May, 20).Add(12*time.Hour))
+	lastCostDate := date(time.May, 19)
+	assert.Equal(t, Forecast{
+		LastCostDate:          &lastCostDate,
+		HistoryDays:           HistoryDays,
+		CurrentMonthSoFarCost: 190,
+		Linear:                Projection{EndOfMonthCost: 310, NextMonthCost: 300},
+		DayOfWeek:             Projection{EndOfMonthCost: 310, NextMonthCost: 300},
+	}, forecast)
+}
+
+// TestNewForecastWeeklyPattern verifies the day of week projection follows the costs of every day of the week
+func TestNewForecastWeeklyPattern(t *testing.T) {
+	// Clusters stopped on weekends
+	costs := dailyCosts(date(time.April, 1), date(time.May, 19), func(d time.Time) float64 {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			return 0
+		}
+		return 10
+	})
+
+	forecast := NewForecast(costs, date(time.May, 20))
+	assert.Equal(t, 130.0, forecast.CurrentMonthSoFarCost)
+	// 10 working days left on May 2024, and 20 on June 2024
+	assert.Equal(t, Projection{EndOfMonthCost: 230, NextMonthCost: 200}, forecast.DayOfWeek)
+	assert.NotEqual(t, forecast.DayOfWeek, forecast.Linear)
+}
+
+// TestNewForecastBillingDelay verifies the projections start after the last daily cost when it's within the billing delay
+func TestNewForecastBillingDelay(t *testing.T) {
+	costs := dailyCosts(date(time.April, 1), date(time.May, 18), func(time.Time) float64 { return 10 })
+
+	forecast := NewForecast(costs, date(time.May, 20))
+	assert.Equal(t, date(time.May, 18), *forecast.LastCostDate)
+	assert.Equal(t, 180.0, forecast.CurrentMonthSoFarCost)
+	assert.Equal(t, Projection{EndOfMonthCost: 310, NextMonthCost: 300}, forecast.Linear)
+}
+
+// TestNewForecastStoppedCosts verifies the days after an old last daily cost are considered without costs
+func TestNewForecastStoppedCosts(t *testing.T) {
+	costs := dailyCosts(date(time.April, 1), date(time.May, 10), func(time.Time) float64 { return 10 })
+
+	forecast := NewForecast(costs, date(time.May, 20))
+	assert.Equal(t, date(time.May, 19), *forecast.LastCostDate)
+	assert.Equal(t, 100.0, forecast.CurrentMonthSoFarCost)
+	// Decreasing trend
+	assert.Equal(t, Projection{EndOfMonthCost: 100, NextMonthCost: 0}, forecast.Linear)
+	assert.Less(t, forecast.DayOfWeek.NextMonthCost, 300.0)
+}
+
+// TestNewForecastWithoutCosts verifies the forecast is empty without daily costs
+func TestNewForecastWithoutCosts(t *testing.T) {
+	assert.Equal(t, Forecast{}, NewForecast(nil, date(time.May, 20)))
+	assert.Equal(t, Forecast{}, NewForecast([]DailyCost{{Date: date(time.May, 20), Amount: 10}}, date(time.May, 20)))
+}
+
+// TestNewForecastShortHistory verifies only the days since the first daily cost are used
+func TestNewForecastShortHistory(t *testing.T) {
+	costs := dailyCosts(date(time.May, 15), date(time.May, 19), func(time.Time) float64 { return 10 })
+
+	forecast := NewForecast(costs, date(time.May, 20))
+	assert.Equal(t, 5, forecast.HistoryDays)
+	assert.Equal(t, Projection{EndOfMonthCost: 170, NextMonthCost: 300}, forecast.DayOfWeek)
+}
+
+// TestForecastWithoutProjection verifies the end of month costs are the known ones and the next month costs are zero
+func TestForecastWithoutProjection(t *testing.T) {
+	costs := dailyCosts(date(time.April, 1), date(time.May, 19), func(time.Time) float64 { return 10 })
+
+	forecast := NewForecast(costs, date(time.May, 20)).WithoutProjection()
+	assert.Equal(t, 190.0, forecast.CurrentMonthSoFarCost)
+	assert.Equal(t, Projection{EndOfMonthCost: 190, NextMonthCost: 0}, forecast.Linear)
+	assert.Equal(t, forecast.Linear, forecast.DayOfWeek)
+}
+
+// TestHistoryStart verifies the history covers the current month and the history days before the billing delay
+func TestHistoryStart(t *testing.T) {
+	assert.Equal(t, date(time.April, 19), HistoryStart(date(time.May, 20).Add(12*time.Hour)))
+	assert.Equal(t, date(time.April, 30), HistoryStart(date(time.May, 31)))
+}
@@ -2,6 +2,8 @@ package inventory
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -47,8 +49,17 @@ type Account struct {
 	// Current month so far cost
 	CurrentMonthSoFarCost float64 `db:"current_month_so_far_cost" json:"currentMonthSoFarCost"`
 
+	// True when the costs include expenses estimated from the instances price
+	CostEstimated bool `db:"cost_estimated" json:"costEstimated"`
+
 	// Billing information flag
 	billingEnabled bool
+
+	// Regions allowed to be scanned or managed. Empty means every region
+	regions []string
+
+	// Regions excluded from scanning and managing. It takes precedence over regions
+	excludeRegions []string
 }
 
 // NewAccount create a new Could Provider account to store its instances
@@ -112,6 +123,25 @@ func (a Account) IsBillingEnabled() bool {
 	return a.billingEnabled
 }
 
+// SetRegionFilter configures the regions allowed and excluded for this account
+func (a *Account) SetRegionFilter(regions []string, excludeRegions []string) {
+	a.regions = regions
+	a.excludeRegions = excludeRegions
+}
+
+// IsRegionEnabled checks if a region can be scanned or managed on this
+// account. A region is enabled when it's not excluded, and it's on the allowed
+// regions list or that list is empty. Region names are case insensitive
+func (a Account) IsRegionEnabled(region string) bool {
+	matchRegion := func(r string) bool { return strings.EqualFold(r, region) }
+
+	if slices.ContainsFunc(a.excludeRegions, matchRegion) {
+		return false
+	}
+
+	return len(a.regions) == 0 || slices.ContainsFunc(a.regions, matchRegion)
+}
+
 // PrintAccount prints account info and every cluster on it by stdout
 func (a Account) PrintAccount() {
 	fmt.Printf("\tAccount: %s[%s] #Clusters: %d\n", a.Name, a.ID, len(a.Clusters))
@@ -93,6 +93,33 @@ func TestIsBillingEnabled(t *testing.T) {
 	}
 }
 
+// TestIsRegionEnabled verifies the allowed and excluded regions of an Account
+func TestIsRegionEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		regions  []string
+		exclude  []string
+		region   string
+		expected bool
+	}{
+		{"No filter", nil, nil, "eu-west-1", true},
+		{"Allowed region", []string{"eu-west-1", "us-east-1"}, nil, "us-east-1", true},
+		{"Not allowed region", []string{"eu-west-1"}, nil, "us-east-1", false},
+		{"Excluded region", nil, []string{"ap-south-1"}, "ap-south-1", false},
+		{"Exclusion takes precedence", []string{"eu-west-1"}, []string{"eu-west-1"}, "eu-west-1", false},
+		{"Case insensitive", []string{"EastUS"}, nil, "eastus", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := NewAccount("0000-11A", "testAccount", UnknownProvider, "user01", "password")
+			account.SetRegionFilter(tt.regions, tt.exclude)
+
+			assert.Equal(t, tt.expected, account.IsRegionEnabled(tt.region))
+		})
+	}
+}
+
 // TestAddCluster for inventory.Account.AddCluster
 func TestAddCluster(t *testing.T) {
 	acc := NewAccount("0000-11A", "testAccount", AWSProvider, "user", "password")
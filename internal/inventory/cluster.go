@@ -60,8 +60,17 @@ type Cluster struct {
 	// Current month so far cost
 	CurrentMonthSoFarCost float64 `db:"current_month_so_far_cost" json:"currentMonthSoFarCost"`
 
+	// True when the costs include expenses estimated from the instances price
+	CostEstimated bool `db:"cost_estimated" json:"costEstimated"`
+
 	// Cluster's instance (nodes) lists
 	Instances []Instance
+
+	// Cluster's non-compute resources (volumes, snapshots, IPs...) list
+	Resources []Resource
+
+	// Cluster level expenses by service
+	Expenses []ClusterExpense
 }
 
 // NewCluster creates a new cluster instance
@@ -205,6 +214,16 @@ func (c *Cluster) AddInstance(instance Instance) error {
 	return c.Update()
 }
 
+// AddResource add a new non-compute resource to a cluster
+func (c *Cluster) AddResource(resource Resource) {
+	c.Resources = append(c.Resources, resource)
+}
+
+// AddExpense add a new cluster level expense to a cluster
+func (c *Cluster) AddExpense(expense ClusterExpense) {
+	c.Expenses = append(c.Expenses, expense)
+}
+
 // Obtain the required parameters for generate a ClusterID. If any key parameter is missing, it will return a non-nil error
 func GenerateClusterID(name string, infraID string, accountName string) (string, error) {
 	if name == "" || accountName == "" {
@@ -0,0 +1,384 @@
+package inventory
+
+import (
+	"math"
+	"slices"
+	"sort"
+)
+
+// ChangeType defines how an inventory element changed between two scans
+type ChangeType string
+
+const (
+	// AddedChange is used for elements that were not on the inventory
+	AddedChange ChangeType = "Added"
+	// UpdatedChange is used for elements whose scanned fields changed
+	UpdatedChange ChangeType = "Updated"
+	// DisappearedChange is used for elements on the inventory that were not found by the scan
+	DisappearedChange ChangeType = "Disappeared"
+)
+
+// InventoryChange describes the change of an inventory element detected by a scan
+type InventoryChange struct {
+	// ElementType is the kind of the changed element (account, cluster, instance, resource, expense)
+	ElementType string `json:"elementType"`
+	// ElementID is the ID of the changed element
+	ElementID string `json:"elementID"`
+	// Change is the type of change
+	Change ChangeType `json:"change"`
+	// Fields are the names of the modified fields on updates
+	Fields []string `json:"fields,omitempty"`
+}
+
+// InventoryState is a flat representation of the inventory elements, used for
+// comparing the inventory stored on the DB with the scan results
+type InventoryState struct {
+	Accounts  []Account
+	Clusters  []Cluster
+	Instances []Instance
+	Resources []Resource
+	Expenses  []Expense
+	// Cluster level expenses
+	ClusterExpenses []ClusterExpense
+}
+
+// NewInventoryStateFromSnapshot flattens the elements of an InventorySnapshot.
+// If clusterID is not empty, only that cluster and its elements are included,
+// and the accounts are excluded as the rest of their clusters were not scanned
+func NewInventoryStateFromSnapshot(snapshot InventorySnapshot, clusterID string) InventoryState {
+	var state InventoryState
+	for _, accountSnapshot := range snapshot.Accounts {
+		if clusterID == "" {
+			state.Accounts = append(state.Accounts, accountSnapshot.Account)
+		}
+
+		for _, cluster := range accountSnapshot.Clusters {
+			if clusterID != "" && cluster.ID != clusterID {
+				continue
+			}
+			state.Clusters = append(state.Clusters, *cluster)
+			state.Resources = append(state.Resources, cluster.Resources...)
+			state.ClusterExpenses = append(state.ClusterExpenses, cluster.Expenses...)
+			for _, instance := range cluster.Instances {
+				state.Instances = append(state.Instances, instance)
+				state.Expenses = append(state.Expenses, instance.Expenses...)
+			}
+		}
+	}
+
+	return state
+}
+
+// KeepFailedRegions adds to the scanned state the current clusters placed on
+// the failed regions of their account, with their instances and resources,
+// when the scan didn't find them. As the scan couldn't check those regions,
+// their elements are kept as they are instead of disappearing. failedRegions
+// contains the failed regions of every account by name
+func (s *InventoryState) KeepFailedRegions(current InventoryState, failedRegions map[string][]string) {
+	scannedIDs := make(map[string]bool, len(s.Clusters)+len(s.Instances)+len(s.Resources))
+	for _, cluster := range s.Clusters {
+		scannedIDs[cluster.ID] = true
+	}
+	for _, instance := range s.Instances {
+		scannedIDs[instance.ID] = true
+	}
+	for _, resource := range s.Resources {
+		scannedIDs[resource.ID] = true
+	}
+
+	keptClusters := make(map[string]bool)
+	for _, cluster := range current.Clusters {
+		if !slices.Contains(failedRegions[cluster.AccountName], cluster.Region) {
+			continue
+		}
+		keptClusters[cluster.ID] = true
+		if !scannedIDs[cluster.ID] {
+			s.Clusters = append(s.Clusters, cluster)
+		}
+	}
+
+	for _, instance := range current.Instances {
+		if keptClusters[instance.ClusterID] && !scannedIDs[instance.ID] {
+			s.Instances = append(s.Instances, instance)
+		}
+	}
+	for _, resource := range current.Resources {
+		if keptClusters[resource.ClusterID] && !scannedIDs[resource.ID] {
+			s.Resources = append(s.Resources, resource)
+		}
+	}
+}
+
+// InventoryDiff contains the elements that must be written to update an
+// inventory state with the scan results, and the list of changes
+type InventoryDiff struct {
+	// Added or updated elements
+	Accounts  []Account
+	Clusters  []Cluster
+	Instances []Instance
+	Resources []Resource
+	Expenses  []Expense
+	// Added or updated cluster level expenses
+	ClusterExpenses []ClusterExpense
+
+	// IDs of the scanned elements without changes. Only their last scan timestamp must be updated
+	UnchangedAccounts  []string
+	UnchangedClusters  []string
+	UnchangedInstances []string
+	UnchangedResources []string
+
+	// Changes detected by the scan
+	Changes []InventoryChange
+}
+
+// DiffInventory compares the current inventory state with the scanned one.
+// Disappeared elements are the current ones not found by the scan; clusters
+// and instances already Missing or Terminated are not reported again.
+// Instance and cluster expenses are never reported as disappeared, as the
+// billing information is not scanned on every run
+func DiffInventory(current InventoryState, scanned InventoryState) InventoryDiff {
+	var diff InventoryDiff
+
+	diff.Accounts, diff.UnchangedAccounts, diff.Changes = diffElements(
+		AccountResourceType, current.Accounts, scanned.Accounts,
+		func(a Account) string { return a.Name },
+		accountChangedFields,
+		nil,
+		false,
+		diff.Changes,
+	)
+
+	diff.Clusters, diff.UnchangedClusters, diff.Changes = diffElements(
+		ClusterResourceType, current.Clusters, scanned.Clusters,
+		func(c Cluster) string { return c.ID },
+		clusterChangedFields,
+		func(c Cluster) bool { return c.Status == Terminated || c.Status == Missing },
+		true,
+		diff.Changes,
+	)
+
+	diff.Instances, diff.UnchangedInstances, diff.Changes = diffElements(
+		InstanceResourceType, current.Instances, scanned.Instances,
+		func(i Instance) string { return i.ID },
+		instanceChangedFields,
+		func(i Instance) bool { return i.Status == Terminated || i.Status == Missing },
+		true,
+		diff.Changes,
+	)
+
+	diff.Resources, diff.UnchangedResources, diff.Changes = diffElements(
+		NonComputeResourceType, current.Resources, scanned.Resources,
+		func(r Resource) string { return r.ID },
+		resourceChangedFields,
+		nil,
+		true,
+		diff.Changes,
+	)
+
+	diff.Expenses, _, diff.Changes = diffElements(
+		ExpenseResourceType, current.Expenses, scanned.Expenses,
+		expenseKey,
+		expenseChangedFields,
+		nil,
+		false,
+		diff.Changes,
+	)
+
+	diff.ClusterExpenses, _, diff.Changes = diffElements(
+		ClusterExpenseResourceType, current.ClusterExpenses, scanned.ClusterExpenses,
+		clusterExpenseKey,
+		clusterExpenseChangedFields,
+		nil,
+		false,
+		diff.Changes,
+	)
+
+	return diff
+}
+
+// diffElements compares two lists of the same kind of elements by their key.
+// It returns the added and updated elements, the keys of the unchanged ones,
+// and the changes list with the new changes appended
+func diffElements[T any](
+	elementType string,
+	current []T,
+	scanned []T,
+	key func(T) string,
+	changedFields func(old T, new T) []string,
+	isGone func(T) bool,
+	reportDisappeared bool,
+	changes []InventoryChange,
+) ([]T, []string, []InventoryChange) {
+	currentByKey := make(map[string]T, len(current))
+	for _, element := range current {
+		currentByKey[key(element)] = element
+	}
+
+	var modified []T
+	var unchanged []string
+	scannedKeys := make(map[string]bool, len(scanned))
+	for _, element := range scanned {
+		k := key(element)
+		scannedKeys[k] = true
+
+		old, exists := currentByKey[k]
+		if !exists {
+			modified = append(modified, element)
+			changes = append(changes, InventoryChange{ElementType: elementType, ElementID: k, Change: AddedChange})
+			continue
+		}
+
+		if fields := changedFields(old, element); len(fields) > 0 {
+			modified = append(modified, element)
+			changes = append(changes, InventoryChange{ElementType: elementType, ElementID: k, Change: UpdatedChange, Fields: fields})
+			continue
+		}
+
+		unchanged = append(unchanged, k)
+	}
+
+	if reportDisappeared {
+		var disappeared []string
+		for k, element := range currentByKey {
+			if scannedKeys[k] || (isGone != nil && isGone(element)) {
+				continue
+			}
+			disappeared = append(disappeared, k)
+		}
+		// Sorting for reporting the changes in a stable order
+		sort.Strings(disappeared)
+		for _, k := range disappeared {
+			changes = append(changes, InventoryChange{ElementType: elementType, ElementID: k, Change: DisappearedChange})
+		}
+	}
+
+	return modified, unchanged, changes
+}
+
+// fieldsCollector keeps the names of the modified fields of an element
+type fieldsCollector []string
+
+func (f *fieldsCollector) check(name string, changed bool) {
+	if changed {
+		*f = append(*f, name)
+	}
+}
+
+func accountChangedFields(old Account, new Account) []string {
+	var fields fieldsCollector
+	fields.check("id", old.ID != new.ID)
+	fields.check("provider", old.Provider != new.Provider)
+	fields.check("clusterCount", old.ClusterCount != new.ClusterCount)
+	return fields
+}
+
+func clusterChangedFields(old Cluster, new Cluster) []string {
+	var fields fieldsCollector
+	fields.check("name", old.Name != new.Name)
+	fields.check("infra_id", old.InfraID != new.InfraID)
+	fields.check("provider", old.Provider != new.Provider)
+	fields.check("status", old.Status != new.Status)
+	fields.check("region", old.Region != new.Region)
+	fields.check("accountName", old.AccountName != new.AccountName)
+	fields.check("consoleLink", old.ConsoleLink != new.ConsoleLink)
+	fields.check("instanceCount", old.InstanceCount != new.InstanceCount)
+	fields.check("owner", old.Owner != new.Owner)
+	return fields
+}
+
+func instanceChangedFields(old Instance, new Instance) []string {
+	var fields fieldsCollector
+	fields.check("name", old.Name != new.Name)
+	fields.check("provider", old.Provider != new.Provider)
+	fields.check("instanceType", old.InstanceType != new.InstanceType)
+	fields.check("availabilityZone", old.AvailabilityZone != new.AvailabilityZone)
+	fields.check("status", old.Status != new.Status)
+	fields.check("clusterID", old.ClusterID != new.ClusterID)
+	fields.check("tags", !equalTags(old.Tags, new.Tags))
+	return fields
+}
+
+func resourceChangedFields(old Resource, new Resource) []string {
+	var fields fieldsCollector
+	fields.check("name", old.Name != new.Name)
+	fields.check("type", old.Type != new.Type)
+	fields.check("provider", old.Provider != new.Provider)
+	fields.check("region", old.Region != new.Region)
+	fields.check("status", old.Status != new.Status)
+	fields.check("class", old.Class != new.Class)
+	fields.check("size", old.Size != new.Size)
+	fields.check("clusterID", old.ClusterID != new.ClusterID)
+	fields.check("instanceID", old.InstanceID != new.InstanceID)
+	fields.check("tags", !equalTags(old.Tags, new.Tags))
+	return fields
+}
+
+func expenseChangedFields(old Expense, new Expense) []string {
+	var fields fieldsCollector
+	// Amounts are stored with 2 decimals on the DB
+	fields.check("amount", math.Round(old.Amount*100) != math.Round(new.Amount*100))
+	fields.check("estimated", old.Estimated != new.Estimated)
+	return fields
+}
+
+func clusterExpenseChangedFields(old ClusterExpense, new ClusterExpense) []string {
+	var fields fieldsCollector
+	// Amounts are stored with 2 decimals on the DB
+	fields.check("amount", math.Round(old.Amount*100) != math.Round(new.Amount*100))
+	return fields
+}
+
+// expenseKey identifies an expense by its instance and day
+func expenseKey(e Expense) string {
+	return e.InstanceID + "/" + e.Date.UTC().Format("2006-01-02")
+}
+
+// clusterExpenseKey identifies a cluster expense by its cluster, service and day
+func clusterExpenseKey(e ClusterExpense) string {
+	return e.ClusterID + "/" + e.Service + "/" + e.Date.UTC().Format("2006-01-02")
+}
+
+// equalTags compares two lists of tags ignoring their order
+func equalTags(a []Tag, b []Tag) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	tagKeys := func(tags []Tag) []string {
+		keys := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			keys = append(keys, tag.Key+"="+tag.Value)
+		}
+		slices.Sort(keys)
+		return keys
+	}
+
+	return slices.Equal(tagKeys(a), tagKeys(b))
+}
+
+// ChangeSummary counts the changes detected by a scan
+type ChangeSummary struct {
+	Added       int `db:"added" json:"added"`
+	Updated     int `db:"updated" json:"updated"`
+	Disappeared int `db:"disappeared" json:"disappeared"`
+	Unchanged   int `db:"unchanged" json:"unchanged"`
+}
+
+// Summary returns the number of changes of each type
+func (d InventoryDiff) Summary() ChangeSummary {
+	summary := ChangeSummary{
+		Unchanged: len(d.UnchangedAccounts) + len(d.UnchangedClusters) + len(d.UnchangedInstances) + len(d.UnchangedResources),
+	}
+	for _, change := range d.Changes {
+		switch change.Change {
+		case AddedChange:
+			summary.Added++
+		case UpdatedChange:
+			summary.Updated++
+		case DisappearedChange:
+			summary.Disappeared++
+		}
+	}
+
+	return summary
+}
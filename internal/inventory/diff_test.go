@@ -0,0 +1,184 @@
+package inventory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDiffInventory verifies the added, updated, unchanged and disappeared elements are detected
+func TestDiffInventory(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	current := InventoryState{
+		Accounts: []Account{{ID: "1", Name: accountName, Provider: AWSProvider, ClusterCount: 2}},
+		Clusters: []Cluster{
+			{ID: "cluster-a", Name: "a", Status: Running, AccountName: accountName},
+			{ID: "cluster-b", Name: "b", Status: Running, AccountName: accountName},
+			{ID: "cluster-old", Name: "old", Status: Terminated, AccountName: accountName},
+			{ID: "cluster-missing", Name: "missing", Status: Missing, AccountName: accountName},
+		},
+		Instances: []Instance{
+			{ID: "i-a", ClusterID: "cluster-a", Status: Running, Tags: []Tag{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}}},
+			{ID: "i-b", ClusterID: "cluster-b", Status: Running},
+		},
+		Resources: []Resource{{ID: "vol-a", Type: VolumeResourceType, ClusterID: "cluster-a", Size: 100}},
+		Expenses:  []Expense{{InstanceID: "i-a", Date: day, Amount: 1.001}},
+	}
+	scanned := InventoryState{
+		Accounts: []Account{{ID: "1", Name: accountName, Provider: AWSProvider, ClusterCount: 2}},
+		Clusters: []Cluster{
+			{ID: "cluster-a", Name: "a", Status: Stopped, AccountName: accountName},
+			{ID: "cluster-c", Name: "c", Status: Running, AccountName: accountName},
+		},
+		Instances: []Instance{
+			// Same tags on a different order
+			{ID: "i-a", ClusterID: "cluster-a", Status: Running, Tags: []Tag{{Key: "k2", Value: "v2"}, {Key: "k1", Value: "v1"}}},
+			{ID: "i-c", ClusterID: "cluster-c", Status: Running},
+		},
+		Resources: []Resource{{ID: "vol-a", Type: VolumeResourceType, ClusterID: "cluster-a", Size: 200}},
+		Expenses: []Expense{
+			{InstanceID: "i-a", Date: day.Add(10 * time.Hour), Amount: 1.0},
+			{InstanceID: "i-a", Date: day.Add(24 * time.Hour), Amount: 2.0},
+		},
+	}
+
+	diff := DiffInventory(current, scanned)
+
+	assert.Empty(t, diff.Accounts)
+	assert.Equal(t, []string{accountName}, diff.UnchangedAccounts)
+	assert.Len(t, diff.Clusters, 2)
+	assert.Empty(t, diff.UnchangedClusters)
+	assert.Equal(t, []Instance{scanned.Instances[1]}, diff.Instances)
+	assert.Equal(t, []string{"i-a"}, diff.UnchangedInstances)
+	assert.Equal(t, scanned.Resources, diff.Resources)
+	assert.Equal(t, []Expense{scanned.Expenses[1]}, diff.Expenses)
+
+	assert.Contains(t, diff.Changes, InventoryChange{ElementType: ClusterResourceType, ElementID: "cluster-a", Change: UpdatedChange, Fields: []string{"status"}})
+	assert.Contains(t, diff.Changes, InventoryChange{ElementType: ClusterResourceType, ElementID: "cluster-c", Change: AddedChange})
+	assert.Contains(t, diff.Changes, InventoryChange{ElementType: ClusterResourceType, ElementID: "cluster-b", Change: DisappearedChange})
+	assert.Contains(t, diff.Changes, InventoryChange{ElementType: InstanceResourceType, ElementID: "i-b", Change: DisappearedChange})
+	assert.Contains(t, diff.Changes, InventoryChange{ElementType: NonComputeResourceType, ElementID: "vol-a", Change: UpdatedChange, Fields: []string{"size"}})
+	// Missing and Terminated clusters are not reported as disappeared again
+	assert.NotContains(t, diff.Changes, InventoryChange{ElementType: ClusterResourceType, ElementID: "cluster-old", Change: DisappearedChange})
+	assert.NotContains(t, diff.Changes, InventoryChange{ElementType: ClusterResourceType, ElementID: "cluster-missing", Change: DisappearedChange})
+
+	assert.Equal(t, ChangeSummary{Added: 3, Updated: 2, Disappeared: 2, Unchanged: 2}, diff.Summary())
+}
+
+// TestDiffInventoryClusterExpenses verifies the cluster expenses are compared by cluster, service and day
+func TestDiffInventoryClusterExpenses(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	current := InventoryState{
+		ClusterExpenses: []ClusterExpense{
+			{ClusterID: "cluster-a", Service: "EC2", Date: day, Amount: 10.0},
+			{ClusterID: "cluster-a", Service: "ELB", Date: day, Amount: 1.0},
+			{ClusterID: "cluster-a", Service: "S3", Date: day, Amount: 0.5},
+		},
+	}
+	scanned := InventoryState{
+		ClusterExpenses: []ClusterExpense{
+			{ClusterID: "cluster-a", Service: "EC2", Date: day, Amount: 10.001},
+			{ClusterID: "cluster-a", Service: "ELB", Date: day, Amount: 1.5},
+			{ClusterID: "cluster-a", Service: "EC2", Date: day.AddDate(0, 0, 1), Amount: 9.0},
+		},
+	}
+
+	diff := DiffInventory(current, scanned)
+
+	assert.Equal(t, scanned.ClusterExpenses[1:], diff.ClusterExpenses)
+	assert.Contains(t, diff.Changes, InventoryChange{ElementType: ClusterExpenseResourceType, ElementID: "cluster-a/ELB/2024-05-01", Change: UpdatedChange, Fields: []string{"amount"}})
+	assert.Contains(t, diff.Changes, InventoryChange{ElementType: ClusterExpenseResourceType, ElementID: "cluster-a/EC2/2024-05-02", Change: AddedChange})
+	// Expenses not found by the scan are kept
+	assert.Len(t, diff.Changes, 2)
+}
+
+// TestNewInventoryStateFromSnapshotClusterScope verifies only the requested cluster is included on cluster scans
+func TestNewInventoryStateFromSnapshotClusterScope(t *testing.T) {
+	snapshot := NewInventorySnapshot(*newSnapshotTestInventory())
+
+	full := NewInventoryStateFromSnapshot(*snapshot, "")
+	assert.Len(t, full.Accounts, 1)
+	assert.Len(t, full.Clusters, 1)
+	assert.Len(t, full.Instances, 1)
+	assert.Len(t, full.Resources, 1)
+	assert.Len(t, full.Expenses, 1)
+
+	scoped := NewInventoryStateFromSnapshot(*snapshot, "cluster-a-infra-account")
+	assert.Empty(t, scoped.Accounts)
+	assert.Len(t, scoped.Clusters, 1)
+
+	other := NewInventoryStateFromSnapshot(*snapshot, "another-cluster")
+	assert.Empty(t, other.Clusters)
+	assert.Empty(t, other.Instances)
+}
+
+// TestKeepFailedRegions verifies the elements of the failed regions not found by the scan are kept unchanged, and the rest still disappear
+func TestKeepFailedRegions(t *testing.T) {
+	current := InventoryState{
+		Clusters: []Cluster{
+			{ID: "cluster-a", Name: "a", Status: Running, Region: "us-east-1", AccountName: accountName},
+			{ID: "cluster-b", Name: "b", Status: Running, Region: "eu-west-1", AccountName: accountName},
+			{ID: "cluster-c", Name: "c", Status: Running, Region: "eu-west-1", AccountName: "other-account"},
+		},
+		Instances: []Instance{
+			{ID: "i-a", ClusterID: "cluster-a", Status: Running},
+			{ID: "i-b", ClusterID: "cluster-b", Status: Running},
+			{ID: "i-c", ClusterID: "cluster-c", Status: Running},
+		},
+		Resources: []Resource{
+			{ID: "vol-a", Type: VolumeResourceType, ClusterID: "cluster-a"},
+			{ID: "vol-b", Type: VolumeResourceType, ClusterID: "cluster-b"},
+		},
+	}
+	scanned := InventoryState{}
+
+	scanned.KeepFailedRegions(current, map[string][]string{accountName: {"eu-west-1"}})
+	assert.Equal(t, []Cluster{current.Clusters[1]}, scanned.Clusters)
+	assert.Equal(t, []Instance{current.Instances[1]}, scanned.Instances)
+	assert.Equal(t, []Resource{current.Resources[1]}, scanned.Resources)
+
+	diff := DiffInventory(current, scanned)
+	assert.Equal(t, []string{"cluster-b"}, diff.UnchangedClusters)
+	assert.Equal(t, []string{"i-b"}, diff.UnchangedInstances)
+	assert.Equal(t, []string{"vol-b"}, diff.UnchangedResources)
+	assert.Contains(t, diff.Changes, InventoryChange{ElementType: ClusterResourceType, ElementID: "cluster-a", Change: DisappearedChange})
+	assert.Contains(t, diff.Changes, InventoryChange{ElementType: ClusterResourceType, ElementID: "cluster-c", Change: DisappearedChange})
+	assert.NotContains(t, diff.Changes, InventoryChange{ElementType: ClusterResourceType, ElementID: "cluster-b", Change: DisappearedChange})
+}
+
+// TestKeepFailedRegionsScannedElements verifies the elements found by the scan on a failed region are not duplicated
+func TestKeepFailedRegionsScannedElements(t *testing.T) {
+	cluster := Cluster{ID: "cluster-a", Name: "a", Status: Running, Region: "us-east-1", AccountName: accountName}
+	current := InventoryState{
+		Clusters:  []Cluster{cluster},
+		Instances: []Instance{{ID: "i-1", ClusterID: "cluster-a", Status: Running}, {ID: "i-2", ClusterID: "cluster-a", Status: Running}},
+	}
+	scanned := InventoryState{
+		Clusters:  []Cluster{{ID: "cluster-a", Name: "a", Status: Stopped, Region: "us-east-1", AccountName: accountName}},
+		Instances: []Instance{{ID: "i-1", ClusterID: "cluster-a", Status: Stopped}},
+	}
+
+	scanned.KeepFailedRegions(current, map[string][]string{accountName: {"us-east-1"}})
+	assert.Len(t, scanned.Clusters, 1)
+	assert.Equal(t, Stopped, scanned.Clusters[0].Status)
+	assert.Equal(t, []Instance{{ID: "i-1", ClusterID: "cluster-a", Status: Stopped}, current.Instances[1]}, scanned.Instances)
+}
+
+// TestDiffInventoryResourceTags verifies the resources are updated when their tags change, whatever their order
+func TestDiffInventoryResourceTags(t *testing.T) {
+	current := InventoryState{Resources: []Resource{
+		{ID: "vol-a", Type: VolumeResourceType, Tags: []Tag{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}}},
+		{ID: "vol-b", Type: VolumeResourceType, Tags: []Tag{{Key: "k1", Value: "v1"}}},
+	}}
+	scanned := InventoryState{Resources: []Resource{
+		{ID: "vol-a", Type: VolumeResourceType, Tags: []Tag{{Key: "k2", Value: "v2"}, {Key: "k1", Value: "v1"}}},
+		{ID: "vol-b", Type: VolumeResourceType, Tags: []Tag{{Key: "k1", Value: "changed"}}},
+	}}
+
+	diff := DiffInventory(current, scanned)
+
+	assert.Equal(t, []Resource{scanned.Resources[1]}, diff.Resources)
+	assert.Equal(t, []string{"vol-a"}, diff.UnchangedResources)
+	assert.Contains(t, diff.Changes, InventoryChange{ElementType: NonComputeResourceType, ElementID: "vol-b", Change: UpdatedChange, Fields: []string{"tags"}})
+}
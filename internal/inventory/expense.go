@@ -12,6 +12,10 @@ type Expense struct {
 
 	// Date (Year, month, day)
 	Date time.Time `db:"date" json:"date"`
+
+	// Estimated is true when the amount is estimated from the instance price
+	// and running hours instead of being obtained from the billing information
+	Estimated bool `db:"estimated" json:"estimated"`
 }
 
 // NewExpense create a expense for an instance
@@ -27,3 +31,45 @@ func NewExpense(instanceID string, amount float64, date time.Time) *Expense {
 		Date:       date,
 	}
 }
+
+// NewEstimatedExpense create an estimated expense for an instance
+func NewEstimatedExpense(instanceID string, amount float64, date time.Time) *Expense {
+	expense := NewExpense(instanceID, amount, date)
+	if expense != nil {
+		expense.Estimated = true
+	}
+	return expense
+}
+
+// ClusterExpense defines the cluster level expenses of a service (compute,
+// storage, load balancers, data transfer...) during a day. They're obtained
+// from the resources tagged as part of the cluster, so they include the costs
+// that can't be attributed to any instance
+type ClusterExpense struct {
+	// ClusterID references the cluster of the expense
+	ClusterID string `db:"cluster_id" json:"clusterID"`
+
+	// Service is the cloud provider service that generated the expense
+	Service string `db:"service" json:"service"`
+
+	// Ammount represents the cost in USDollars
+	Amount float64 `db:"amount" json:"amount"`
+
+	// Date (Year, month, day)
+	Date time.Time `db:"date" json:"date"`
+}
+
+// NewClusterExpense create a expense of a service for a cluster
+func NewClusterExpense(clusterID string, service string, amount float64, date time.Time) *ClusterExpense {
+	// Checking if cost is below zero, which is not possible
+	if amount < 0.0 {
+		return nil
+	}
+
+	return &ClusterExpense{
+		ClusterID: clusterID,
+		Service:   service,
+		Amount:    amount,
+		Date:      date,
+	}
+}
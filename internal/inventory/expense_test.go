@@ -26,3 +26,23 @@ func TestNewExpense(t *testing.T) {
 	wrongExpense := NewExpense(instanceID, -6.0, date)
 	assert.Nil(t, wrongExpense)
 }
+
+// TestNewEstimatedExpense verifies the estimated expenses are flagged, and negative amounts are rejected
+func TestNewEstimatedExpense(t *testing.T) {
+	date := time.Now()
+
+	expense := NewEstimatedExpense("testInstance", 1.5, date)
+	assert.Equal(t, &Expense{InstanceID: "testInstance", Amount: 1.5, Date: date, Estimated: true}, expense)
+
+	assert.Nil(t, NewEstimatedExpense("testInstance", -1.5, date))
+}
+
+// TestNewClusterExpense verifies the cluster expenses are created with their service, and negative amounts are rejected
+func TestNewClusterExpense(t *testing.T) {
+	date := time.Now()
+
+	expense := NewClusterExpense("cluster-a", "Amazon Elastic Load Balancing", 3.5, date)
+	assert.Equal(t, &ClusterExpense{ClusterID: "cluster-a", Service: "Amazon Elastic Load Balancing", Amount: 3.5, Date: date}, expense)
+
+	assert.Nil(t, NewClusterExpense("cluster-a", "Amazon Elastic Load Balancing", -1.0, date))
+}
@@ -51,6 +51,9 @@ type Instance struct {
 	// Total cost (US Dollars) accumulated since ClusterIQ is scanning
 	TotalCost float64 `db:"total_cost" json:"totalCost"`
 
+	// True when the costs include expenses estimated from the instance price
+	CostEstimated bool `db:"cost_estimated" json:"costEstimated"`
+
 	// Instance Tags as key-value array
 	Tags []Tag `json:"tags"`
 
@@ -10,6 +10,9 @@ const (
 	Running InstanceStatus = "Running"
 	// Stopped Instance status
 	Stopped InstanceStatus = "Stopped"
+	// Missing Instance status. Used for the elements not found by the last
+	// successful scan of their account, until they're considered Terminated
+	Missing InstanceStatus = "Missing"
 	// Terminated Instance status
 	Terminated InstanceStatus = "Terminated"
 )
@@ -23,6 +26,8 @@ func AsInstanceStatus(status string) InstanceStatus {
 		return Stopped
 	case "stopped":
 		return Stopped
+	case "missing":
+		return Missing
 	case "terminated":
 		return Terminated
 	default:
@@ -23,6 +23,10 @@ func TestAsInstanceStatus(t *testing.T) {
 			input:  "stopped",
 			result: Stopped,
 		},
+		{
+			input:  "missing",
+			result: Missing,
+		},
 		{
 			input:  "terminated",
 			result: Terminated,
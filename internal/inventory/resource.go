@@ -0,0 +1,92 @@
+package inventory
+
+import "time"
+
+// ResourceType defines the type of a non-compute cloud resource (storage, networking...)
+type ResourceType string
+
+const (
+	// VolumeResourceType block storage volume (e.g. AWS EBS volume)
+	VolumeResourceType ResourceType = "Volume"
+	// SnapshotResourceType block storage snapshot (e.g. AWS EBS snapshot)
+	SnapshotResourceType ResourceType = "Snapshot"
+	// ElasticIPResourceType static public IP address (e.g. AWS Elastic IP)
+	ElasticIPResourceType ResourceType = "ElasticIP"
+	// LoadBalancerResourceType network load balancer (e.g. AWS ELB/ALB/NLB)
+	LoadBalancerResourceType ResourceType = "LoadBalancer"
+	// SecurityGroupResourceType network firewall rule set (e.g. AWS Security Group)
+	SecurityGroupResourceType ResourceType = "SecurityGroup"
+	// HostedZoneResourceType DNS zone (e.g. AWS Route53 Hosted Zone)
+	HostedZoneResourceType ResourceType = "HostedZone"
+)
+
+// OrphanResourceTypes are the resource types looked for on the Terminated
+// clusters, as they can remain on the account after a cluster was removed
+var OrphanResourceTypes = []ResourceType{
+	LoadBalancerResourceType,
+	VolumeResourceType,
+	SecurityGroupResourceType,
+	HostedZoneResourceType,
+}
+
+// Resource models a non-compute cloud resource associated to a cluster. These
+// resources keep generating costs even when the cluster instances are stopped
+type Resource struct {
+	// Uniq Identifier of the resource
+	ID string `db:"id" json:"id"`
+
+	// Resource Name. In some Cloud Providers, the name is managed as a Tag
+	Name string `db:"name" json:"name"`
+
+	// Resource type
+	Type ResourceType `db:"type" json:"type"`
+
+	// Resource provider (public/private cloud provider)
+	Provider CloudProvider `db:"provider" json:"provider"`
+
+	// Region or Availability Zone in which the resource is placed
+	Region string `db:"region" json:"region"`
+
+	// Resource status as reported by the cloud provider (e.g. "in-use", "available")
+	Status string `db:"status" json:"status"`
+
+	// Resource class or tier (e.g. "gp3" for volumes)
+	Class string `db:"class" json:"class"`
+
+	// Size in GiB for storage resources. Zero for the rest
+	Size int64 `db:"size" json:"size"`
+
+	// ClusterID
+	ClusterID string `db:"cluster_id" json:"clusterID"`
+
+	// InstanceID of the instance the resource is attached to (if any)
+	InstanceID string `db:"instance_id" json:"instanceID"`
+
+	// Last scan timestamp of the resource
+	LastScanTimestamp time.Time `db:"last_scan_timestamp" json:"lastScanTimestamp"`
+
+	// Timestamp when the resource was created
+	CreationTimestamp time.Time `db:"creation_timestamp" json:"creationTimestamp"`
+
+	// Resource Tags as key-value array
+	Tags []Tag `db:"-" json:"tags"`
+}
+
+// NewResource returns a new Resource object
+func NewResource(id string, name string, resourceType ResourceType, provider CloudProvider, region string, status string, class string, size int64, instanceID string, tags []Tag, creationTimestamp time.Time) *Resource {
+	return &Resource{
+		ID:                id,
+		Name:              name,
+		Type:              resourceType,
+		Provider:          provider,
+		Region:            region,
+		Status:            status,
+		Class:             class,
+		Size:              size,
+		ClusterID:         GetClusterIDFromTags(tags),
+		InstanceID:        instanceID,
+		LastScanTimestamp: time.Now(),
+		CreationTimestamp: creationTimestamp,
+		Tags:              tags,
+	}
+}
@@ -0,0 +1,43 @@
+package inventory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewResource tests the creation of a Resource and the ClusterID parsing from its tags
+func TestNewResource(t *testing.T) {
+	creation := time.Now().Add(-24 * time.Hour)
+	tags := []Tag{
+		*NewTag(ClusterTagKey+"testCluster-abcde", "owned", "vol-123"),
+		*NewTag("Name", "testCluster-abcde-master-0", "vol-123"),
+	}
+
+	resource := NewResource("vol-123", "testCluster-abcde-master-0", VolumeResourceType, AWSProvider, "us-east-1a", "in-use", "gp3", 120, "i-123", tags, creation)
+
+	assert.NotNil(t, resource)
+	assert.Equal(t, "vol-123", resource.ID)
+	assert.Equal(t, VolumeResourceType, resource.Type)
+	assert.Equal(t, "testCluster-abcde", resource.ClusterID)
+	assert.Equal(t, int64(120), resource.Size)
+	assert.Equal(t, "i-123", resource.InstanceID)
+	assert.Equal(t, creation, resource.CreationTimestamp)
+	assert.False(t, resource.LastScanTimestamp.IsZero())
+
+	untagged := NewResource("eipalloc-123", "1.2.3.4", ElasticIPResourceType, AWSProvider, "us-east-1", "unassociated", "vpc", 0, "", nil, time.Time{})
+	assert.Equal(t, UnknownClusterIDCode, untagged.ClusterID)
+}
+
+// TestClusterAddResource tests adding resources to a cluster
+func TestClusterAddResource(t *testing.T) {
+	cluster := NewCluster("name", "infra", AWSProvider, "region", "acc", "", "")
+	resource := NewResource("snap-123", "", SnapshotResourceType, AWSProvider, "region", "completed", "standard", 8, "", nil, time.Now())
+
+	cluster.AddResource(*resource)
+	assert.Len(t, cluster.Resources, 1)
+	assert.Equal(t, "snap-123", cluster.Resources[0].ID)
+	// Resources are not considered as instances
+	assert.Equal(t, 0, cluster.InstanceCount)
+}
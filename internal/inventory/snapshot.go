@@ -0,0 +1,164 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InventorySnapshotVersion is the version of the InventorySnapshot format.
+// It must be increased on every incompatible change of the format
+const InventorySnapshotVersion = 1
+
+// SnapshotFormat defines the encoding of the InventorySnapshot files
+type SnapshotFormat string
+
+const (
+	// JSONSnapshotFormat encodes the snapshot as JSON
+	JSONSnapshotFormat SnapshotFormat = "json"
+	// YAMLSnapshotFormat encodes the snapshot as YAML
+	YAMLSnapshotFormat SnapshotFormat = "yaml"
+)
+
+// InventorySnapshot is the versioned and serializable representation of an
+// Inventory, including every account with its clusters, instances, resources
+// and expenses. It's used for exporting the scan results to a file and
+// importing them later
+type InventorySnapshot struct {
+	// Version of the snapshot format
+	Version int `json:"version"`
+
+	// Date of Inventory creation/update
+	CreationTimestamp time.Time `json:"creationTimestamp"`
+
+	// Accounts sorted by name
+	Accounts []AccountSnapshot `json:"accounts"`
+}
+
+// AccountSnapshot is the serializable representation of an Account. The
+// Account clusters are not serialized with the Account itself, so they're
+// included as a list
+type AccountSnapshot struct {
+	Account
+
+	// Clusters sorted by ID
+	Clusters []*Cluster `json:"clusters"`
+}
+
+// NewInventorySnapshot creates a snapshot of the Inventory. Accounts and
+// clusters are sorted for generating reproducible files
+func NewInventorySnapshot(inv Inventory) *InventorySnapshot {
+	snapshot := InventorySnapshot{
+		Version:           InventorySnapshotVersion,
+		CreationTimestamp: inv.CreationTimestamp,
+		Accounts:          make([]AccountSnapshot, 0, len(inv.Accounts)),
+	}
+
+	for _, account := range inv.Accounts {
+		accountSnapshot := AccountSnapshot{
+			Account:  *account,
+			Clusters: make([]*Cluster, 0, len(account.Clusters)),
+		}
+		for _, cluster := range account.Clusters {
+			accountSnapshot.Clusters = append(accountSnapshot.Clusters, cluster)
+		}
+		sort.Slice(accountSnapshot.Clusters, func(i, j int) bool {
+			return accountSnapshot.Clusters[i].ID < accountSnapshot.Clusters[j].ID
+		})
+		snapshot.Accounts = append(snapshot.Accounts, accountSnapshot)
+	}
+	sort.Slice(snapshot.Accounts, func(i, j int) bool {
+		return snapshot.Accounts[i].Name < snapshot.Accounts[j].Name
+	})
+
+	return &snapshot
+}
+
+// ToInventory rebuilds the Inventory stored on the snapshot
+func (s InventorySnapshot) ToInventory() (*Inventory, error) {
+	if s.Version != InventorySnapshotVersion {
+		return nil, fmt.Errorf("unsupported inventory snapshot version %d (supported: %d)", s.Version, InventorySnapshotVersion)
+	}
+
+	inv := NewInventory()
+	inv.CreationTimestamp = s.CreationTimestamp
+	for _, accountSnapshot := range s.Accounts {
+		account := accountSnapshot.Account
+		account.Clusters = make(map[string]*Cluster, len(accountSnapshot.Clusters))
+		for _, cluster := range accountSnapshot.Clusters {
+			account.Clusters[cluster.ID] = cluster
+		}
+		if err := inv.AddAccount(&account); err != nil {
+			return nil, err
+		}
+	}
+
+	return inv, nil
+}
+
+// SnapshotFormatFromPath returns the snapshot format based on the file
+// extension. YAML is used for '.yaml' and '.yml' files, and JSON otherwise
+func SnapshotFormatFromPath(path string) SnapshotFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return YAMLSnapshotFormat
+	default:
+		return JSONSnapshotFormat
+	}
+}
+
+// EncodeInventorySnapshot writes the snapshot on the specified format. YAML
+// snapshots use the same field names as the JSON ones
+func EncodeInventorySnapshot(w io.Writer, snapshot *InventorySnapshot, format SnapshotFormat) error {
+	b, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if format == YAMLSnapshotFormat {
+		var content any
+		if err := json.Unmarshal(b, &content); err != nil {
+			return err
+		}
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(content); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// DecodeInventorySnapshot reads a snapshot on the specified format
+func DecodeInventorySnapshot(r io.Reader, format SnapshotFormat) (*InventorySnapshot, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == YAMLSnapshotFormat {
+		var content any
+		if err := yaml.Unmarshal(b, &content); err != nil {
+			return nil, err
+		}
+		if b, err = json.Marshal(content); err != nil {
+			return nil, err
+		}
+	}
+
+	var snapshot InventorySnapshot
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
@@ -0,0 +1,100 @@
+package inventory
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSnapshotTestInventory returns an Inventory with fixed timestamps for comparing the decoded snapshots
+func newSnapshotTestInventory() *Inventory {
+	ts := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	inv := NewInventory()
+	inv.CreationTimestamp = ts
+
+	cluster := &Cluster{
+		ID:                "cluster-a-infra-account",
+		Name:              "cluster-a",
+		InfraID:           "infra",
+		Provider:          AWSProvider,
+		Status:            Running,
+		Region:            "eu-west-1",
+		AccountName:       accountName,
+		LastScanTimestamp: ts,
+		CreationTimestamp: ts,
+		Instances: []Instance{
+			{
+				ID:                "i-0123456789",
+				Name:              "cluster-a-master-0",
+				Provider:          AWSProvider,
+				Status:            Running,
+				ClusterID:         "cluster-a-infra-account",
+				LastScanTimestamp: ts,
+				CreationTimestamp: ts,
+				Tags:              []Tag{{Key: "owner", Value: "team", InstanceID: "i-0123456789"}},
+				Expenses:          []Expense{{InstanceID: "i-0123456789", Amount: 1.5, Date: ts}},
+			},
+		},
+		Resources: []Resource{
+			{
+				ID:                "vol-0123456789",
+				Type:              VolumeResourceType,
+				Provider:          AWSProvider,
+				ClusterID:         "cluster-a-infra-account",
+				LastScanTimestamp: ts,
+				CreationTimestamp: ts,
+				Tags:              []Tag{},
+			},
+		},
+	}
+
+	account := &Account{
+		ID:                "123456789012",
+		Name:              accountName,
+		Provider:          AWSProvider,
+		ClusterCount:      1,
+		Clusters:          map[string]*Cluster{cluster.ID: cluster},
+		LastScanTimestamp: ts,
+	}
+	inv.AddAccount(account)
+
+	return inv
+}
+
+// TestInventorySnapshotRoundTrip verifies an Inventory is rebuilt equally after encoding and decoding its snapshot on every format
+func TestInventorySnapshotRoundTrip(t *testing.T) {
+	for _, format := range []SnapshotFormat{JSONSnapshotFormat, YAMLSnapshotFormat} {
+		inv := newSnapshotTestInventory()
+
+		var buf bytes.Buffer
+		assert.Nil(t, EncodeInventorySnapshot(&buf, NewInventorySnapshot(*inv), format))
+
+		snapshot, err := DecodeInventorySnapshot(&buf, format)
+		assert.Nil(t, err)
+		assert.Equal(t, InventorySnapshotVersion, snapshot.Version)
+
+		decoded, err := snapshot.ToInventory()
+		assert.Nil(t, err)
+		assert.Equal(t, inv, decoded, "format: %s", format)
+	}
+}
+
+// TestInventorySnapshotUnsupportedVersion verifies snapshots with a different version are rejected
+func TestInventorySnapshotUnsupportedVersion(t *testing.T) {
+	snapshot := NewInventorySnapshot(*newSnapshotTestInventory())
+	snapshot.Version = InventorySnapshotVersion + 1
+
+	inv, err := snapshot.ToInventory()
+	assert.NotNil(t, err)
+	assert.Nil(t, inv)
+}
+
+// TestSnapshotFormatFromPath verifies the snapshot format is selected by the file extension
+func TestSnapshotFormatFromPath(t *testing.T) {
+	assert.Equal(t, YAMLSnapshotFormat, SnapshotFormatFromPath("/tmp/inventory.yaml"))
+	assert.Equal(t, YAMLSnapshotFormat, SnapshotFormatFromPath("inventory.YML"))
+	assert.Equal(t, JSONSnapshotFormat, SnapshotFormatFromPath("inventory.json"))
+	assert.Equal(t, JSONSnapshotFormat, SnapshotFormatFromPath("inventory"))
+}
@@ -9,8 +9,22 @@ const (
 	// Cluster actions
 	ClusterPowerOnAction  = "PowerOn"
 	ClusterPowerOffAction = "PowerOff"
+	// Cluster events
+	ClusterOrphanedResourcesAction         = "OrphanedResourcesDetected"
+	ClusterOrphanedResourcesResolvedAction = "OrphanedResourcesResolved"
+	// Account events
+	AccountAddedAction   = "AccountAdded"
+	AccountRemovedAction = "AccountRemoved"
+	AccountRotatedAction = "AccountRotated"
 
 	// Resource types
 	ClusterResourceType  = "cluster"
 	InstanceResourceType = "instance"
+	AccountResourceType  = "account"
+
+	// Inventory element types used on the scan changes
+	NonComputeResourceType = "resource"
+	ExpenseResourceType    = "expense"
+	// Cluster level expenses by service
+	ClusterExpenseResourceType = "cluster_expense"
 )
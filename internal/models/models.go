@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/actions"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/forecast"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
 	"github.com/lib/pq"
 )
@@ -58,6 +59,9 @@ type InstanceDB struct {
 
 	// TotalCost represents the total cost of the instance in US dollars since its creation.
 	TotalCost float64 `db:"total_cost"`
+
+	// CostEstimated indicates whether the costs include estimated expenses.
+	CostEstimated bool `db:"cost_estimated"`
 }
 
 // AuditLog represents an immutable record of an action taken within the system.
@@ -99,15 +103,22 @@ type OverviewSummary struct {
 	Instances InstancesSummary `json:"instances"`
 	Providers ProvidersSummary `json:"providers"`
 	Scanner   Scanner          `json:"scanner"`
+	// Cost forecast of the whole inventory
+	Forecast forecast.Forecast `json:"forecast"`
 }
 
 type Scanner struct {
 	LastScanTimestamp *time.Time `json:"last_scan_timestamp"`
+	// Number of accounts whose last scan failed
+	FailedAccounts int `json:"failed_accounts"`
+	// Last scan of every account
+	Accounts []AccountScan `json:"accounts"`
 }
 
 type ClustersSummary struct {
 	Running  int `json:"running"`
 	Stopped  int `json:"stopped"`
+	Missing  int `json:"missing"`
 	Archived int `json:"archived"`
 }
 
@@ -218,3 +229,120 @@ func FromDBScheduledActionToCronAction(action DBScheduledAction) *actions.CronAc
 	cronAction.ID = action.ID
 	return cronAction
 }
+
+// ScanSession represents an inventory scan posted into the API, with the
+// summary of the changes it applied
+type ScanSession struct {
+	// Unique identifier of the scan session.
+	ID int64 `db:"id" json:"id"`
+	// UTC timestamp of when the scan session was applied.
+	SessionTimestamp time.Time `db:"session_timestamp" json:"sessionTimestamp"`
+	// Scanner process or user that requested the scan.
+	TriggeredBy string `db:"triggered_by" json:"triggeredBy"`
+	// Account scanned; empty for full scans.
+	AccountName string `db:"account_name" json:"accountName"`
+	// Cluster scanned; empty for full and account scans.
+	ClusterID string `db:"cluster_id" json:"clusterID"`
+	// Number of changes of each type.
+	inventory.ChangeSummary
+	// Changes applied by the scan session. Only populated when requesting a single session.
+	Changes []inventory.InventoryChange `db:"-" json:"changes,omitempty"`
+}
+
+// ScanSessionChangeDB is an intermediate struct used to map the changes of a
+// scan session into inventory.InventoryChange objects
+type ScanSessionChangeDB struct {
+	// ID of the scan session that applied the change.
+	SessionID int64 `db:"session_id"`
+	// Kind of the changed element (account, cluster, instance, resource, expense).
+	ElementType string `db:"element_type"`
+	// ID of the changed element.
+	ElementID string `db:"element_id"`
+	// Type of change (Added, Updated, Disappeared).
+	Change inventory.ChangeType `db:"change"`
+	// Names of the modified fields on updates.
+	Fields pq.StringArray `db:"fields"`
+}
+
+// ToInventoryChange translates a ScanSessionChangeDB object into inventory.InventoryChange
+func (c ScanSessionChangeDB) ToInventoryChange() inventory.InventoryChange {
+	return inventory.InventoryChange{
+		ElementType: c.ElementType,
+		ElementID:   c.ElementID,
+		Change:      c.Change,
+		Fields:      c.Fields,
+	}
+}
+
+// AccountScanStatus defines the result of scanning an account
+type AccountScanStatus string
+
+const (
+	// AccountScanSuccess is used when every region of the account was scanned
+	AccountScanSuccess AccountScanStatus = "Success"
+	// AccountScanFailed is used when the account couldn't be scanned, so its inventory is not posted
+	AccountScanFailed AccountScanStatus = "Failed"
+	// AccountScanPartial is used when some regions of the account couldn't be
+	// scanned. The inventory of the scanned regions is posted, and the
+	// elements of the failed regions are kept as they are
+	AccountScanPartial AccountScanStatus = "Partial"
+)
+
+// AccountScan represents the result of scanning an account on a scan session.
+// The Scanner posts them on the scan sessions, including the failed accounts,
+// whose inventory is not posted, and the partially scanned ones
+type AccountScan struct {
+	// Unique identifier of the account scan.
+	ID int64 `db:"id" json:"id"`
+	// ID of the scan session that posted the account scan.
+	SessionID int64 `db:"session_id" json:"sessionID"`
+	// Name of the scanned account.
+	AccountName string `db:"account_name" json:"accountName"`
+	// Cloud provider of the account.
+	Provider inventory.CloudProvider `db:"provider" json:"provider"`
+	// Timestamps of the start and the end of the account scan, from the Scanner.
+	StartTimestamp time.Time `db:"start_timestamp" json:"startTimestamp"`
+	EndTimestamp   time.Time `db:"end_timestamp" json:"endTimestamp"`
+	// Result of the account scan.
+	Status AccountScanStatus `db:"status" json:"status"`
+	// Regions scanned correctly and failed regions. Zones on GCP and subscriptions on Azure.
+	ScannedRegions pq.StringArray `db:"scanned_regions" json:"scannedRegions,omitempty"`
+	FailedRegions  pq.StringArray `db:"failed_regions" json:"failedRegions,omitempty"`
+	// Error messages of the failed account or regions, and of the billing information retrieval.
+	Errors pq.StringArray `db:"errors" json:"errors,omitempty"`
+	// Number of elements found by the scan.
+	ClusterCount  int `db:"cluster_count" json:"clusterCount"`
+	InstanceCount int `db:"instance_count" json:"instanceCount"`
+	ResourceCount int `db:"resource_count" json:"resourceCount"`
+	// Number of API requests throttled by the cloud provider during the scan
+	ThrottledRequests int64 `db:"throttled_requests" json:"throttledRequests"`
+}
+
+// ClusterTimelineEvent is an entry of the lifecycle timeline of a cluster. It
+// can be a recorded state transition of the cluster or an action executed on it
+type ClusterTimelineEvent struct {
+	// UTC timestamp of the event.
+	Timestamp time.Time `db:"event_timestamp" json:"timestamp"`
+	// Kind of event: Transition, Action or ScheduledAction.
+	Category string `db:"category" json:"category"`
+	// Transition (FirstSeen, StatusChanged, ConsoleLinkDiscovered, ConsoleLinkChanged, Terminated) or action name.
+	Type string `db:"event_type" json:"type"`
+	// What caused the event: scan, refresh, action or api for transitions; who triggered the actions.
+	Source string `db:"source" json:"source"`
+	// Previous value on transitions (status or console link).
+	OldValue *string `db:"old_value" json:"oldValue,omitempty"`
+	// New value on transitions (status or console link).
+	NewValue *string `db:"new_value" json:"newValue,omitempty"`
+	// Outcome of the actions.
+	Result *string `db:"result" json:"result,omitempty"`
+	// Description of the actions.
+	Description *string `db:"description" json:"description,omitempty"`
+}
+
+// ServiceCost is the cost of a cloud provider service on a cluster
+type ServiceCost struct {
+	// Service that generated the cost.
+	Service string `db:"service" json:"service"`
+	// Cost in US Dollars.
+	Amount float64 `db:"amount" json:"amount"`
+}
@@ -0,0 +1,122 @@
+package pricing
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+)
+
+const (
+	// Maximum time an instance is considered running after a scan found it
+	// Running, for not charging the periods without scans
+	maxRunningInterval = 24 * time.Hour
+)
+
+// InstanceSample is the state of an instance recorded by a scan
+type InstanceSample struct {
+	InstanceID       string                   `db:"id"`
+	Provider         inventory.CloudProvider  `db:"provider"`
+	InstanceType     string                   `db:"instance_type"`
+	AvailabilityZone string                   `db:"availability_zone"`
+	Status           inventory.InstanceStatus `db:"status"`
+	Timestamp        time.Time                `db:"last_scan_timestamp"`
+}
+
+// Estimation contains the estimated expenses of a set of instances
+type Estimation struct {
+	// Expenses are the estimated daily expenses, sorted by instance and date
+	Expenses []inventory.Expense
+	// UnknownInstanceTypes lists the instance types without price (provider/region/type)
+	UnknownInstanceTypes []string
+}
+
+// expenseKey identifies an estimated expense
+type expenseKey struct {
+	instanceID string
+	date       time.Time
+}
+
+// EstimateExpenses estimates the daily expenses of the instances since the
+// specified date, based on the scan samples of their state. An instance is
+// considered running from every sample with Running status until its next
+// sample (or now for its last one), up to maxRunningInterval. The running
+// hours are charged at the price of the instance type and region of the
+// sample, split by day (UTC). The instances whose type has no price are
+// skipped and reported
+func (t *PriceTable) EstimateExpenses(samples []InstanceSample, since time.Time, now time.Time) Estimation {
+	sorted := slices.Clone(samples)
+	slices.SortStableFunc(sorted, func(a InstanceSample, b InstanceSample) int {
+		if c := cmp.Compare(a.InstanceID, b.InstanceID); c != 0 {
+			return c
+		}
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	costs := make(map[expenseKey]float64)
+	unknownTypes := make(map[string]bool)
+	for i, sample := range sorted {
+		if sample.Status != inventory.Running {
+			continue
+		}
+
+		end := now
+		if i+1 < len(sorted) && sorted[i+1].InstanceID == sample.InstanceID {
+			end = sorted[i+1].Timestamp
+		}
+		end = minTime(end, sample.Timestamp.Add(maxRunningInterval))
+		start := maxTime(sample.Timestamp, since)
+		if !end.After(start) {
+			continue
+		}
+
+		region := RegionFromAvailabilityZone(sample.Provider, sample.AvailabilityZone)
+		price, ok := t.HourlyPrice(sample.Provider, region, sample.InstanceType)
+		if !ok {
+			unknownTypes[fmt.Sprintf("%s/%s/%s", sample.Provider, region, sample.InstanceType)] = true
+			continue
+		}
+
+		// Splitting the running period by day
+		for start.Before(end) {
+			day := start.UTC().Truncate(24 * time.Hour)
+			periodEnd := minTime(end, day.Add(24*time.Hour))
+			costs[expenseKey{instanceID: sample.InstanceID, date: day}] += periodEnd.Sub(start).Hours() * price
+			start = periodEnd
+		}
+	}
+
+	var estimation Estimation
+	for key, amount := range costs {
+		estimation.Expenses = append(estimation.Expenses, *inventory.NewEstimatedExpense(key.instanceID, amount, key.date))
+	}
+	slices.SortFunc(estimation.Expenses, func(a inventory.Expense, b inventory.Expense) int {
+		if c := cmp.Compare(a.InstanceID, b.InstanceID); c != 0 {
+			return c
+		}
+		return a.Date.Compare(b.Date)
+	})
+
+	for instanceType := range unknownTypes {
+		estimation.UnknownInstanceTypes = append(estimation.UnknownInstanceTypes, instanceType)
+	}
+	slices.Sort(estimation.UnknownInstanceTypes)
+
+	return estimation
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
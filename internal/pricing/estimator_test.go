@@ -0,0 +1,70 @@
+package pricing
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestPriceTable returns a price table with a single AWS instance type
+func newTestPriceTable(t *testing.T) *PriceTable {
+	table, err := NewPriceTable(strings.NewReader("provider,region,instance_type,hourly_price\nAWS,*,m5.xlarge,0.5\nAWS,eu-west-1,m5.xlarge,1\n"))
+	assert.Nil(t, err)
+	return table
+}
+
+// TestEstimateExpenses verifies the running hours between samples are charged by day at the price of their region
+func TestEstimateExpenses(t *testing.T) {
+	day := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	samples := []InstanceSample{
+		// Running from 22:00 to 02:00 of the next day, stopped afterwards
+		{InstanceID: "i-0001", Provider: inventory.AWSProvider, InstanceType: "m5.xlarge", AvailabilityZone: "us-east-1a", Status: inventory.Running, Timestamp: day.Add(22 * time.Hour)},
+		{InstanceID: "i-0001", Provider: inventory.AWSProvider, InstanceType: "m5.xlarge", AvailabilityZone: "us-east-1a", Status: inventory.Stopped, Timestamp: day.Add(26 * time.Hour)},
+		{InstanceID: "i-0001", Provider: inventory.AWSProvider, InstanceType: "m5.xlarge", AvailabilityZone: "us-east-1a", Status: inventory.Stopped, Timestamp: day.Add(30 * time.Hour)},
+		// Regional price, running until now
+		{InstanceID: "i-0002", Provider: inventory.AWSProvider, InstanceType: "m5.xlarge", AvailabilityZone: "eu-west-1b", Status: inventory.Running, Timestamp: day.Add(44 * time.Hour)},
+	}
+
+	estimation := newTestPriceTable(t).EstimateExpenses(samples, day, day.Add(46*time.Hour))
+	assert.Equal(t, []inventory.Expense{
+		*inventory.NewEstimatedExpense("i-0001", 1, day),
+		*inventory.NewEstimatedExpense("i-0001", 1, day.Add(24*time.Hour)),
+		*inventory.NewEstimatedExpense("i-0002", 2, day.Add(24*time.Hour)),
+	}, estimation.Expenses)
+	assert.Empty(t, estimation.UnknownInstanceTypes)
+}
+
+// TestEstimateExpensesLimits verifies the periods before the start date and beyond the maximum running interval aren't charged
+func TestEstimateExpensesLimits(t *testing.T) {
+	day := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	samples := []InstanceSample{
+		// Last sample before the start date, running for 2 hours since it
+		{InstanceID: "i-0001", Provider: inventory.AWSProvider, InstanceType: "m5.xlarge", AvailabilityZone: "us-east-1a", Status: inventory.Running, Timestamp: day.Add(-2 * time.Hour)},
+		{InstanceID: "i-0001", Provider: inventory.AWSProvider, InstanceType: "m5.xlarge", AvailabilityZone: "us-east-1a", Status: inventory.Running, Timestamp: day.Add(2 * time.Hour)},
+		// No scans after it for 3 days
+		{InstanceID: "i-0001", Provider: inventory.AWSProvider, InstanceType: "m5.xlarge", AvailabilityZone: "us-east-1a", Status: inventory.Stopped, Timestamp: day.Add(74 * time.Hour)},
+	}
+
+	estimation := newTestPriceTable(t).EstimateExpenses(samples, day, day.Add(96*time.Hour))
+	assert.Equal(t, []inventory.Expense{
+		*inventory.NewEstimatedExpense("i-0001", 12, day),
+		*inventory.NewEstimatedExpense("i-0001", 1, day.Add(24*time.Hour)),
+	}, estimation.Expenses)
+}
+
+// TestEstimateExpensesUnknownInstanceTypes verifies the instance types without price are skipped and reported once
+func TestEstimateExpensesUnknownInstanceTypes(t *testing.T) {
+	day := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	samples := []InstanceSample{
+		{InstanceID: "i-0001", Provider: inventory.AWSProvider, InstanceType: "x2.huge", AvailabilityZone: "us-east-1a", Status: inventory.Running, Timestamp: day},
+		{InstanceID: "i-0002", Provider: inventory.AWSProvider, InstanceType: "x2.huge", AvailabilityZone: "us-east-1b", Status: inventory.Running, Timestamp: day},
+		{InstanceID: "vm-1", Provider: inventory.AzureProvider, InstanceType: "Standard_X", AvailabilityZone: "eastus-1", Status: inventory.Running, Timestamp: day},
+	}
+
+	estimation := newTestPriceTable(t).EstimateExpenses(samples, day, day.Add(time.Hour))
+	assert.Empty(t, estimation.Expenses)
+	assert.Equal(t, []string{"AWS/us-east-1/x2.huge", "Azure/eastus/Standard_X"}, estimation.UnknownInstanceTypes)
+}
@@ -0,0 +1,137 @@
+// Package pricing estimates the costs of the instances whose accounts don't
+// provide billing information, based on a table of on-demand prices and the
+// running hours recorded by the scans
+package pricing
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+)
+
+const (
+	// AnyRegion is the region of the prices used for the regions without a specific price
+	AnyRegion = "*"
+)
+
+// bundledPrices is the default price table. Its columns are provider,
+// region, instance_type and hourly_price (on-demand Linux price in USD)
+//
+//go:embed prices.csv
+var bundledPrices []byte
+
+// awsRegionRegexp matches the region on the AWS availability zones, including
+// the Local Zones. For example: "us-east-1a" and "us-east-1-bos-1a" -> "us-east-1"
+var awsRegionRegexp = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-\d+`)
+
+// priceKey identifies the price of an instance type on a region
+type priceKey struct {
+	provider     inventory.CloudProvider
+	region       string
+	instanceType string
+}
+
+// PriceTable contains the hourly on-demand prices of the instance types by
+// provider and region
+type PriceTable struct {
+	prices map[priceKey]float64
+}
+
+// NewPriceTable reads a price table in CSV format. The first line is the
+// header (provider,region,instance_type,hourly_price), and "*" can be used as
+// region for the prices of any region without a specific price
+func NewPriceTable(r io.Reader) (*PriceTable, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = 4
+	csvReader.Comment = '#'
+
+	if _, err := csvReader.Read(); err != nil {
+		return nil, fmt.Errorf("Error reading price table header: %w", err)
+	}
+
+	table := &PriceTable{prices: make(map[priceKey]float64)}
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Error reading price table: %w", err)
+		}
+
+		provider := inventory.GetCloudProvider(record[0])
+		if provider == inventory.UnknownProvider {
+			return nil, fmt.Errorf("unknown provider on price table: %s", record[0])
+		}
+		price, err := strconv.ParseFloat(record[3], 64)
+		if err != nil || price < 0 {
+			return nil, fmt.Errorf("invalid price on price table for %s %s: %s", record[1], record[2], record[3])
+		}
+		table.prices[priceKey{provider: provider, region: record[1], instanceType: record[2]}] = price
+	}
+
+	return table, nil
+}
+
+// LoadPriceTable returns the price table of the specified file, or the
+// bundled one if the path is empty
+func LoadPriceTable(path string) (*PriceTable, error) {
+	if path == "" {
+		return NewPriceTable(bytes.NewReader(bundledPrices))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return NewPriceTable(file)
+}
+
+// HourlyPrice returns the hourly price of an instance type on a region. The
+// price for any region is used if there's no specific price for the region.
+// It returns false if the instance type is not on the table
+func (t *PriceTable) HourlyPrice(provider inventory.CloudProvider, region string, instanceType string) (float64, bool) {
+	if price, ok := t.prices[priceKey{provider: provider, region: region, instanceType: instanceType}]; ok {
+		return price, true
+	}
+	price, ok := t.prices[priceKey{provider: provider, region: AnyRegion, instanceType: instanceType}]
+	return price, ok
+}
+
+// Len returns the number of prices on the table
+func (t *PriceTable) Len() int {
+	return len(t.prices)
+}
+
+// RegionFromAvailabilityZone returns the region of an instance availability
+// zone, following the zones format of every provider:
+//   - AWS: "us-east-1a" -> "us-east-1"
+//   - GCP: "us-central1-a" -> "us-central1"
+//   - Azure: "eastus-1" -> "eastus"
+func RegionFromAvailabilityZone(provider inventory.CloudProvider, availabilityZone string) string {
+	switch provider {
+	case inventory.AWSProvider:
+		if region := awsRegionRegexp.FindString(availabilityZone); region != "" {
+			return region
+		}
+	case inventory.GCPProvider:
+		if i := strings.LastIndex(availabilityZone, "-"); i >= 0 {
+			return availabilityZone[:i]
+		}
+	case inventory.AzureProvider:
+		location, _, _ := strings.Cut(availabilityZone, "-")
+		return location
+	}
+	return availabilityZone
+}
@@ -0,0 +1,80 @@
+package pricing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewPriceTable verifies the regional prices are preferred over the prices for any region
+func TestNewPriceTable(t *testing.T) {
+	table, err := NewPriceTable(strings.NewReader(`# Comment
+provider,region,instance_type,hourly_price
+AWS,*,m5.xlarge,0.192
+AWS,eu-west-1,m5.xlarge,0.214
+GCP,us-central1,e2-standard-4,0.134
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, table.Len())
+
+	price, ok := table.HourlyPrice(inventory.AWSProvider, "eu-west-1", "m5.xlarge")
+	assert.True(t, ok)
+	assert.Equal(t, 0.214, price)
+
+	price, ok = table.HourlyPrice(inventory.AWSProvider, "us-east-2", "m5.xlarge")
+	assert.True(t, ok)
+	assert.Equal(t, 0.192, price)
+
+	_, ok = table.HourlyPrice(inventory.GCPProvider, "europe-west1", "e2-standard-4")
+	assert.False(t, ok)
+	_, ok = table.HourlyPrice(inventory.AzureProvider, "eastus", "m5.xlarge")
+	assert.False(t, ok)
+}
+
+// TestNewPriceTableInvalid verifies the tables with unknown providers, invalid prices or missing columns are rejected
+func TestNewPriceTableInvalid(t *testing.T) {
+	header := "provider,region,instance_type,hourly_price\n"
+	for _, content := range []string{
+		"",
+		header + "OpenStack,*,m1.large,0.1\n",
+		header + "AWS,*,m5.xlarge,cheap\n",
+		header + "AWS,*,m5.xlarge,-0.1\n",
+		header + "AWS,*,m5.xlarge\n",
+	} {
+		_, err := NewPriceTable(strings.NewReader(content))
+		assert.NotNil(t, err, content)
+	}
+}
+
+// TestLoadPriceTable verifies the bundled table is used without file, and the file table replaces it
+func TestLoadPriceTable(t *testing.T) {
+	bundled, err := LoadPriceTable("")
+	assert.Nil(t, err)
+	assert.Greater(t, bundled.Len(), 0)
+	_, ok := bundled.HourlyPrice(inventory.AWSProvider, "us-east-1", "m5.xlarge")
+	assert.True(t, ok)
+
+	path := filepath.Join(t.TempDir(), "prices.csv")
+	assert.Nil(t, os.WriteFile(path, []byte("provider,region,instance_type,hourly_price\nAzure,*,Standard_D4s_v3,0.192\n"), 0o600))
+	table, err := LoadPriceTable(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, table.Len())
+
+	_, err = LoadPriceTable(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.NotNil(t, err)
+}
+
+// TestRegionFromAvailabilityZone verifies the region is obtained from the zones format of every provider
+func TestRegionFromAvailabilityZone(t *testing.T) {
+	assert.Equal(t, "us-east-1", RegionFromAvailabilityZone(inventory.AWSProvider, "us-east-1a"))
+	assert.Equal(t, "us-east-1", RegionFromAvailabilityZone(inventory.AWSProvider, "us-east-1-bos-1a"))
+	assert.Equal(t, "us-gov-west-1", RegionFromAvailabilityZone(inventory.AWSProvider, "us-gov-west-1b"))
+	assert.Equal(t, "us-central1", RegionFromAvailabilityZone(inventory.GCPProvider, "us-central1-a"))
+	assert.Equal(t, "eastus", RegionFromAvailabilityZone(inventory.AzureProvider, "eastus-1"))
+	assert.Equal(t, "eastus", RegionFromAvailabilityZone(inventory.AzureProvider, "eastus"))
+	assert.Equal(t, "unknown", RegionFromAvailabilityZone(inventory.AWSProvider, "unknown"))
+}
@@ -0,0 +1,89 @@
+// Package scan defines the on-demand scan requests and jobs shared by the
+// ClusterIQ Scanner daemon and the API, and the scan sessions posted by the
+// Scanner for applying its results
+package scan
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/models"
+)
+
+// JobStatus defines the status of a scan job
+type JobStatus string
+
+const (
+	// JobPending means the scan job was accepted but it didn't start yet
+	JobPending JobStatus = "Pending"
+	// JobRunning means the scan job is running
+	JobRunning JobStatus = "Running"
+	// JobSuccess means the scan job finished correctly
+	JobSuccess JobStatus = "Success"
+	// JobFailed means the scan job finished with errors
+	JobFailed JobStatus = "Failed"
+)
+
+// Request defines the scope of a scan. An empty Request scans every account.
+// If AccountName is defined, only that account is scanned. If ClusterID is
+// also defined, only the cluster Region is scanned, and only that cluster is
+// posted into the API
+type Request struct {
+	AccountName string `json:"account_name,omitempty"`
+	ClusterID   string `json:"cluster_id,omitempty"`
+	Region      string `json:"region,omitempty"`
+	TriggeredBy string `json:"triggered_by,omitempty"`
+}
+
+// Validate checks the Request scope is consistent
+func (r Request) Validate() error {
+	if r.ClusterID != "" && (r.AccountName == "" || r.Region == "") {
+		return fmt.Errorf("cluster scans require the account name and the cluster region")
+	}
+	return nil
+}
+
+// IsFullScan returns true if the Request is not limited to an account
+func (r Request) IsFullScan() bool {
+	return r.AccountName == ""
+}
+
+// IsClusterScan returns true if the Request is limited to a single cluster
+func (r Request) IsClusterScan() bool {
+	return r.ClusterID != ""
+}
+
+// Job represents a scan requested to the Scanner daemon
+type Job struct {
+	ID         string     `json:"id"`
+	Request    Request    `json:"request"`
+	Status     JobStatus  `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// IsFinished returns true if the Job is not pending nor running
+func (j Job) IsFinished() bool {
+	return j.Status == JobSuccess || j.Status == JobFailed
+}
+
+// SessionRequest is posted by the Scanner into the API for applying the
+// results of a scan. Only the elements in the Scope are compared with the
+// inventory. AccountScans describes the result of every account on the scope,
+// including the failed accounts, which are not on the Inventory
+type SessionRequest struct {
+	Scope        Request                     `json:"scope"`
+	Inventory    inventory.InventorySnapshot `json:"inventory"`
+	AccountScans []models.AccountScan        `json:"account_scans"`
+}
+
+// OrphansRequest is posted by the Scanner into the API with the orphaned
+// resources found on the Terminated clusters. The known orphans of the
+// checked clusters that were not found anymore are considered as resolved
+type OrphansRequest struct {
+	ClusterIDs []string             `json:"cluster_ids"`
+	Orphans    []inventory.Resource `json:"orphans"`
+}
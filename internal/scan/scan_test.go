@@ -0,0 +1,24 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRequestValidate verifies the cluster scans require the account and the region
+func TestRequestValidate(t *testing.T) {
+	assert.Nil(t, Request{}.Validate())
+	assert.Nil(t, Request{AccountName: "account"}.Validate())
+	assert.Nil(t, Request{AccountName: "account", ClusterID: "cluster-id", Region: "eu-west-1"}.Validate())
+	assert.NotNil(t, Request{AccountName: "account", ClusterID: "cluster-id"}.Validate())
+	assert.NotNil(t, Request{ClusterID: "cluster-id", Region: "eu-west-1"}.Validate())
+}
+
+// TestRequestScope verifies the scope of the scan requests
+func TestRequestScope(t *testing.T) {
+	assert.True(t, Request{}.IsFullScan())
+	assert.False(t, Request{AccountName: "account"}.IsFullScan())
+	assert.False(t, Request{AccountName: "account"}.IsClusterScan())
+	assert.True(t, Request{AccountName: "account", ClusterID: "cluster-id", Region: "eu-west-1"}.IsClusterScan())
+}
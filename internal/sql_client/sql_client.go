@@ -7,17 +7,31 @@ package sqlclient
 import (
 	"database/sql"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/actions"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/events"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/forecast"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/models"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/pricing"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
+const (
+	// Maximum number of elements written on a single batch insert
+	namedExecBatchSize = 1000
+
+	// Sources of the cluster state transitions, recorded by the DB on the cluster_transitions table
+	ChangeSourceScan    = "scan"
+	ChangeSourceRefresh = "refresh"
+	ChangeSourceAction  = "action"
+)
+
 // Ensure SQLClient implements SQLEventClient
 var _ events.SQLEventClient = (*SQLClient)(nil)
 
@@ -113,10 +127,16 @@ func NewSQLClient(dbURL string, logger *zap.Logger) (*SQLClient, error) {
 		return nil, err
 	}
 
+	return NewSQLClientWithDB(db, logger), nil
+}
+
+// NewSQLClientWithDB creates a SQLClient based on an already opened database
+// connection
+func NewSQLClientWithDB(db *sqlx.DB, logger *zap.Logger) *SQLClient {
 	return &SQLClient{
 		db:     db,
 		logger: logger,
-	}, nil
+	}
 }
 
 // Ping performs a ping operation to check if the DB is alive
@@ -501,6 +521,56 @@ func (a SQLClient) WriteExpenses(expenses []inventory.Expense) error {
 	return nil
 }
 
+// GetUnbilledInstancesSamples retrieves the states recorded by the scans of
+// the instances whose accounts don't have billing information.
+//
+// Parameters:
+// - since: Timestamp of the first state. The last state before it is included as well.
+//
+// Returns:
+// - A slice of pricing.InstanceSample objects.
+// - An error if the query fails.
+func (a SQLClient) GetUnbilledInstancesSamples(since time.Time) ([]pricing.InstanceSample, error) {
+	var samples []pricing.InstanceSample
+	if err := a.db.Select(&samples, SelectUnbilledInstancesSamplesQuery, since); err != nil {
+		return nil, err
+	}
+
+	return samples, nil
+}
+
+// WriteEstimatedExpenses writes a batch of estimated expenses to the database
+// in a transaction. The expenses obtained from the billing information are
+// kept, and the estimated expenses of the accounts that already have billing
+// information are removed.
+//
+// Parameters:
+// - expenses: A slice of estimated inventory.Expense objects to insert.
+//
+// Returns:
+// - An error if the transaction fails.
+func (a SQLClient) WriteEstimatedExpenses(expenses []inventory.Expense) error {
+	tx, err := a.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			a.logger.Error("Error during transaction rollback", zap.Error(rbErr))
+		}
+	}()
+
+	if _, err := tx.Exec(DeleteBilledEstimatedExpensesQuery); err != nil {
+		return fmt.Errorf("failed to delete billed estimated expenses: %w", err)
+	}
+
+	if err := namedExecInBatches(tx, InsertEstimatedExpensesQuery, expenses); err != nil {
+		return fmt.Errorf("failed to write estimated expenses: %w", err)
+	}
+
+	return tx.Commit()
+}
+
 // GetInstances retrieves all instances from the database and maps them to inventory.Instance objects.
 //
 // Returns:
@@ -690,6 +760,117 @@ func (a SQLClient) GetClusterByID(clusterID string) ([]inventory.Cluster, error)
 	return []inventory.Cluster{cluster}, nil
 }
 
+// GetClusterTimeline retrieves the lifecycle timeline of a cluster: its
+// recorded state transitions (first seen, status changes, console link
+// discovery, termination) merged with the actions executed on it, ordered by
+// time.
+//
+// Parameters:
+// - clusterID: The unique identifier of the cluster.
+//
+// Returns:
+// - A slice of models.ClusterTimelineEvent objects, from the oldest.
+// - An error if the query fails.
+func (a SQLClient) GetClusterTimeline(clusterID string) ([]models.ClusterTimelineEvent, error) {
+	var timeline []models.ClusterTimelineEvent
+	if err := a.db.Select(&timeline, SelectClusterTimelineQuery, clusterID); err != nil {
+		return nil, err
+	}
+	return timeline, nil
+}
+
+// GetClusterExpenses retrieves the cluster level expenses of a cluster.
+//
+// Parameters:
+// - clusterID: The unique identifier of the cluster.
+// - from: Optional first day of the expenses (included).
+// - to: Optional last day of the expenses (excluded).
+//
+// Returns:
+// - A slice of inventory.ClusterExpense objects ordered by date and service.
+// - An error if the query fails.
+func (a SQLClient) GetClusterExpenses(clusterID string, from *time.Time, to *time.Time) ([]inventory.ClusterExpense, error) {
+	var expenses []inventory.ClusterExpense
+	if err := a.db.Select(&expenses, SelectClusterExpensesQuery, clusterID, from, to); err != nil {
+		return nil, err
+	}
+	return expenses, nil
+}
+
+// GetClusterServiceCosts retrieves the cost of every service of a cluster.
+// The days without cluster level expenses are covered by the expenses of the
+// cluster instances, so the services costs add up to the cluster cost.
+//
+// Parameters:
+// - clusterID: The unique identifier of the cluster.
+// - from: Optional first day of the expenses (included).
+// - to: Optional last day of the expenses (excluded).
+//
+// Returns:
+// - A slice of models.ServiceCost objects, from the most expensive.
+// - An error if the query fails.
+func (a SQLClient) GetClusterServiceCosts(clusterID string, from *time.Time, to *time.Time) ([]models.ServiceCost, error) {
+	var costs []models.ServiceCost
+	if err := a.db.Select(&costs, SelectClusterServiceCostsQuery, clusterID, from, to); err != nil {
+		return nil, err
+	}
+	return costs, nil
+}
+
+// GetClusterDailyCosts retrieves the daily costs of a cluster, including the
+// cluster level expenses and the expenses of its instances.
+//
+// Parameters:
+// - clusterID: The unique identifier of the cluster.
+// - from: First day of the costs (included).
+// - to: Last day of the costs (excluded).
+//
+// Returns:
+// - A slice of forecast.DailyCost objects, sorted by date.
+// - An error if the query fails.
+func (a SQLClient) GetClusterDailyCosts(clusterID string, from time.Time, to time.Time) ([]forecast.DailyCost, error) {
+	var costs []forecast.DailyCost
+	if err := a.db.Select(&costs, SelectClusterDailyCostsQuery, clusterID, from, to); err != nil {
+		return nil, err
+	}
+	return costs, nil
+}
+
+// GetAccountDailyCosts retrieves the daily costs of the clusters of an account.
+//
+// Parameters:
+// - accountName: The name of the account.
+// - from: First day of the costs (included).
+// - to: Last day of the costs (excluded).
+//
+// Returns:
+// - A slice of forecast.DailyCost objects, sorted by date.
+// - An error if the query fails.
+func (a SQLClient) GetAccountDailyCosts(accountName string, from time.Time, to time.Time) ([]forecast.DailyCost, error) {
+	var costs []forecast.DailyCost
+	if err := a.db.Select(&costs, SelectAccountDailyCostsQuery, accountName, from, to); err != nil {
+		return nil, err
+	}
+	return costs, nil
+}
+
+// GetInventoryDailyCosts retrieves the daily costs of every cluster in the inventory.
+//
+// Parameters:
+// - from: First day of the costs (included).
+// - to: Last day of the costs (excluded).
+//
+// Returns:
+// - A slice of forecast.DailyCost objects, sorted by date.
+// - An error if the query fails.
+func (a SQLClient) GetInventoryDailyCosts(from time.Time, to time.Time) ([]forecast.DailyCost, error) {
+	var costs []forecast.DailyCost
+	if err := a.db.Select(&costs, SelectInventoryDailyCostsQuery, from, to); err != nil {
+		return nil, err
+	}
+	return costs, nil
+}
+
 // GetClusterTags retrieves the tags associated with a specific cluster.
 //
 // Parameters:
@@ -722,6 +903,130 @@ func (a SQLClient) GetInstancesOnCluster(clusterID string) ([]inventory.Instance
 	return instances, nil
 }
 
+// GetResources retrieves all the non-compute resources from the database.
+//
+// Returns:
+// - A slice of inventory.Resource objects.
+// - An error if the query fails.
+func (a SQLClient) GetResources() ([]inventory.Resource, error) {
+	var resources []inventory.Resource
+	if err := a.db.Select(&resources, SelectResourcesQuery); err != nil {
+		return nil, err
+	}
+	if err := a.joinResourcesTags(resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// GetResourcesOnCluster retrieves all the non-compute resources belonging to a specific cluster.
+//
+// Parameters:
+// - clusterID: The unique identifier of the cluster.
+//
+// Returns:
+// - A slice of inventory.Resource objects representing the volumes, snapshots and IPs of the cluster.
+// - An error if the query fails.
+func (a SQLClient) GetResourcesOnCluster(clusterID string) ([]inventory.Resource, error) {
+	var resources []inventory.Resource
+	if err := a.db.Select(&resources, SelectResourcesOnClusterQuery, clusterID); err != nil {
+		return nil, err
+	}
+	if err := a.joinResourcesTags(resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// GetOrphanResources retrieves the non-compute resources belonging to terminated clusters.
+//
+// Returns:
+// - A slice of inventory.Resource objects that remain on the cloud accounts after their clusters were removed.
+// - An error if the query fails.
+func (a SQLClient) GetOrphanResources() ([]inventory.Resource, error) {
+	var resources []inventory.Resource
+	if err := a.db.Select(&resources, SelectOrphanResourcesQuery); err != nil {
+		return nil, err
+	}
+	if err := a.joinResourcesTags(resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// WriteOrphanResources writes the orphaned resources found by the scanner
+// and removes the resolved ones in a transaction.
+//
+// Parameters:
+// - orphans: A slice of inventory.Resource objects found on the Terminated clusters.
+// - resolvedIDs: IDs of the known orphaned resources that were not found anymore.
+//
+// Returns:
+// - An error if the transaction fails.
+func (a SQLClient) WriteOrphanResources(orphans []inventory.Resource, resolvedIDs []string) error {
+	tx, err := a.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			a.logger.Error("Failed to rollback WriteOrphanResources transaction", zap.Error(rbErr))
+		}
+	}()
+
+	if err := namedExecInBatches(tx, InsertResourcesQuery, orphans); err != nil {
+		return fmt.Errorf("failed to write orphaned resources: %w", err)
+	}
+	if err := writeResourcesTags(tx, orphans); err != nil {
+		return err
+	}
+	if len(resolvedIDs) > 0 {
+		if _, err := tx.Exec(DeleteResourcesQuery, pq.Array(resolvedIDs)); err != nil {
+			return fmt.Errorf("failed to remove resolved orphaned resources: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// WriteResources writes a batch of non-compute resources to the database in a transaction.
+//
+// Parameters:
+// - resources: A slice of inventory.Resource objects to insert.
+//
+// Returns:
+// - An error if the transaction fails.
+func (a SQLClient) WriteResources(resources []inventory.Resource) error {
+	tx, err := a.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				a.logger.Error("Failed to rollback WriteResources transaction", zap.Error(rbErr))
+			}
+		}
+	}()
+
+	if _, err = tx.NamedExec(InsertResourcesQuery, resources); err != nil {
+		a.logger.Error("Failed to prepare InsertResourcesQuery query", zap.Error(err))
+		return err
+	}
+	if err = writeResourcesTags(tx, resources); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	return nil
+}
+
 // WriteClusters inserts a list of clusters into the database in a transaction.
 //
 // Parameters:
@@ -803,16 +1108,23 @@ func (a SQLClient) GetAccounts() ([]inventory.Account, error) {
 // GetProvidersOverview returns a summary of cloud providers (AWS, GCP, Azure) with
 // their respective account and cluster counts.
 func (a SQLClient) GetProvidersOverview() (models.ProvidersSummary, error) {
-	var providerRows []struct {
-		Provider     string `db:"provider"`
-		AccountCount int    `db:"account_count"`
-		ClusterCount int    `db:"cluster_count"`
-	}
-
+	var providerRows []providerOverviewRow
 	if err := a.db.Select(&providerRows, SelectProvidersOverviewQuery); err != nil {
 		return models.ProvidersSummary{}, err
 	}
 
+	return newProvidersSummary(providerRows), nil
+}
+
+// providerOverviewRow is the result of the providers overview queries
+type providerOverviewRow struct {
+	Provider     string `db:"provider"`
+	AccountCount int    `db:"account_count"`
+	ClusterCount int    `db:"cluster_count"`
+}
+
+// newProvidersSummary maps the providers overview rows into a models.ProvidersSummary
+func newProvidersSummary(providerRows []providerOverviewRow) models.ProvidersSummary {
 	// Initialize the summary
 	summary := models.ProvidersSummary{}
 
@@ -833,7 +1145,7 @@ func (a SQLClient) GetProvidersOverview() (models.ProvidersSummary, error) {
 		}
 	}
 
-	return summary, nil
+	return summary
 }
 
 // GetAccountByName retrieves an account by its name from the database.
@@ -925,11 +1237,18 @@ func (a SQLClient) DeleteAccount(accountName string) error {
 	return nil
 }
 
-// RefreshInventory refreshes the database by updating the status of terminated instances and clusters.
+// RefreshInventory refreshes the database by updating the status of missing and terminated instances and clusters.
+// Only the elements of the accounts scanned successfully are evaluated: the elements not found by the last
+// successful scan of their account are marked as Missing, and they're marked as Terminated (or removed, for the
+// non-compute resources) when the account kept being scanned without finding them during the grace period.
+// The status changes are recorded on the inventory history.
+//
+// Parameters:
+// - gracePeriodHours: Hours an element remains Missing before it's marked as Terminated.
 //
 // Returns:
 // - An error if any update query fails.
-func (a SQLClient) RefreshInventory() error {
+func (a SQLClient) RefreshInventory(gracePeriodHours int) error {
 	tx, err := a.db.Beginx()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -942,16 +1261,47 @@ func (a SQLClient) RefreshInventory() error {
 		}
 	}()
 
-	_, err = tx.Exec(UpdateTerminatedInstancesQuery)
+	_, err = tx.Exec(SetChangeSourceQuery, ChangeSourceRefresh)
+	if err != nil {
+		return fmt.Errorf("failed to set the change source: %w", err)
+	}
+
+	_, err = tx.Exec(UpdateMissingInstancesQuery)
+	if err != nil {
+		return fmt.Errorf("failed to refresh missing instances: %w", err)
+	}
+
+	_, err = tx.Exec(UpdateMissingClustersQuery)
+	if err != nil {
+		return fmt.Errorf("failed to refresh missing clusters: %w", err)
+	}
+
+	_, err = tx.Exec(UpdateTerminatedInstancesQuery, gracePeriodHours)
 	if err != nil {
 		return fmt.Errorf("failed to refresh terminated instances: %w", err)
 	}
 
-	_, err = tx.Exec(UpdateTerminatedClustersQuery)
+	_, err = tx.Exec(UpdateTerminatedClustersQuery, gracePeriodHours)
 	if err != nil {
 		return fmt.Errorf("failed to refresh terminated clusters: %w", err)
 	}
 
+	_, err = tx.Exec(DeleteOutdatedResourcesQuery, gracePeriodHours)
+	if err != nil {
+		return fmt.Errorf("failed to remove deleted resources: %w", err)
+	}
+
+	// Recording the status changes on the inventory history
+	_, err = tx.Exec(InsertRefreshedInstancesHistoryQuery)
+	if err != nil {
+		return fmt.Errorf("failed to record refreshed instances history: %w", err)
+	}
+
+	_, err = tx.Exec(InsertRefreshedClustersHistoryQuery)
+	if err != nil {
+		return fmt.Errorf("failed to record refreshed clusters history: %w", err)
+	}
+
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -964,6 +1314,7 @@ func (a SQLClient) RefreshInventory() error {
 // This function first verifies if the requested status exists in the database. If the status is valid, it updates:
 // 1. The status of the cluster identified by the given `clusterID`.
 // 2. The status of all instances associated with the cluster.
+// Both updates run in a single transaction, and the cluster status transition is recorded as caused by an action.
 //
 // Parameters:
 // - status: The new status to be applied to the cluster and its instances.
@@ -979,12 +1330,27 @@ func (a SQLClient) UpdateClusterStatusByClusterID(status string, clusterID strin
 		return fmt.Errorf("the requested status (%s) doesn't exist on the DB", status)
 	}
 
+	tx, err := a.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			a.logger.Error("Failed to rollback UpdateClusterStatusByClusterID transaction", zap.Error(rbErr))
+		}
+	}()
+
+	// The status transition is recorded as caused by an action
+	if _, err := tx.Exec(SetChangeSourceQuery, ChangeSourceAction); err != nil {
+		return err
+	}
+
 	// Updating cluster status
 	{
 		var result sql.Result
 		var err error
 		var rows int64
-		if result, err = a.db.Exec(UpdateStatusClusterByClusterIDQuery, status, clusterID); err != nil {
+		if result, err = tx.Exec(UpdateStatusClusterByClusterIDQuery, status, clusterID); err != nil {
 			return err
 		}
 		if rows, err = result.RowsAffected(); err != nil {
@@ -1001,7 +1367,7 @@ func (a SQLClient) UpdateClusterStatusByClusterID(status string, clusterID strin
 		var result sql.Result
 		var err error
 		var rows int64
-		if result, err = a.db.Exec(UpdateStatusInstancesByClusterIDQuery, status, clusterID); err != nil {
+		if result, err = tx.Exec(UpdateStatusInstancesByClusterIDQuery, status, clusterID); err != nil {
 			return err
 		}
 		if rows, err = result.RowsAffected(); err != nil {
@@ -1013,6 +1379,9 @@ func (a SQLClient) UpdateClusterStatusByClusterID(status string, clusterID strin
 		a.logger.Debug("Instances status updated successfully", zap.String("cluster_id", clusterID))
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
@@ -1044,6 +1413,479 @@ func (a SQLClient) GetScannerLastScanTimestamp() (*time.Time, error) {
 	return nil, nil
 }
 
+// GetAccountScans retrieves the latest scans of an account.
+//
+// Parameters:
+// - accountName: The name of the account.
+// - limit: Maximum number of account scans to retrieve.
+//
+// Returns:
+// - A slice of models.AccountScan objects, sorted from the newest.
+// - An error if the query fails.
+func (a SQLClient) GetAccountScans(accountName string, limit int) ([]models.AccountScan, error) {
+	var accountScans []models.AccountScan
+	if err := a.db.Select(&accountScans, SelectAccountScansQuery, accountName, limit); err != nil {
+		return nil, err
+	}
+	return accountScans, nil
+}
+
+// GetLatestAccountScans retrieves the last scan of every account.
+//
+// Parameters:
+// - at: Optional point in time. If it's not nil, only the scans started before it are considered.
+//
+// Returns:
+// - A slice of models.AccountScan objects, sorted by account name.
+// - An error if the query fails.
+func (a SQLClient) GetLatestAccountScans(at *time.Time) ([]models.AccountScan, error) {
+	var accountScans []models.AccountScan
+	if err := a.db.Select(&accountScans, SelectLatestAccountScansQuery, at); err != nil {
+		return nil, err
+	}
+	return accountScans, nil
+}
+
+// GetInventoryState retrieves the inventory elements in the scope of a scan
+// session, for comparing them with the scan results.
+//
+// Parameters:
+// - accountNames: Names of the scanned accounts.
+// - clusterID: ID of the scanned cluster. If empty, every cluster of the accounts is included.
+// - expenses: Scanned expenses. Only the stored expenses of the same instances and dates are retrieved.
+// - clusterExpenses: Scanned cluster level expenses. Only the stored expenses of the same clusters and dates are retrieved.
+//
+// Returns:
+// - An inventory.InventoryState with the stored elements.
+// - An error if any query fails.
+func (a SQLClient) GetInventoryState(accountNames []string, clusterID string, expenses []inventory.Expense, clusterExpenses []inventory.ClusterExpense) (inventory.InventoryState, error) {
+	var state inventory.InventoryState
+
+	// Accounts are not part of the cluster scans scope
+	if clusterID == "" {
+		if err := a.db.Select(&state.Accounts, SelectScanStateAccountsQuery, pq.Array(accountNames)); err != nil {
+			return state, fmt.Errorf("failed to get accounts state: %w", err)
+		}
+	}
+
+	if err := a.db.Select(&state.Clusters, SelectScanStateClustersQuery, pq.Array(accountNames), clusterID); err != nil {
+		return state, fmt.Errorf("failed to get clusters state: %w", err)
+	}
+
+	var dbinstances []models.InstanceDB
+	if err := a.db.Select(&dbinstances, SelectScanStateInstancesQuery, pq.Array(accountNames), clusterID); err != nil {
+		return state, fmt.Errorf("failed to get instances state: %w", err)
+	}
+	state.Instances = removeEmptyTags(joinInstancesTags(dbinstances))
+
+	if err := a.db.Select(&state.Resources, SelectScanStateResourcesQuery, pq.Array(accountNames), clusterID); err != nil {
+		return state, fmt.Errorf("failed to get resources state: %w", err)
+	}
+	if err := a.joinResourcesTags(state.Resources); err != nil {
+		return state, fmt.Errorf("failed to get resources tags state: %w", err)
+	}
+
+	if len(expenses) > 0 {
+		instanceIDs := make([]string, 0, len(expenses))
+		since := expenses[0].Date
+		for _, expense := range expenses {
+			instanceIDs = append(instanceIDs, expense.InstanceID)
+			if expense.Date.Before(since) {
+				since = expense.Date
+			}
+		}
+		if err := a.db.Select(&state.Expenses, SelectScanStateExpensesQuery, pq.Array(instanceIDs), since); err != nil {
+			return state, fmt.Errorf("failed to get expenses state: %w", err)
+		}
+	}
+
+	if len(clusterExpenses) > 0 {
+		clusterIDs := make([]string, 0, len(clusterExpenses))
+		since := clusterExpenses[0].Date
+		for _, expense := range clusterExpenses {
+			clusterIDs = append(clusterIDs, expense.ClusterID)
+			if expense.Date.Before(since) {
+				since = expense.Date
+			}
+		}
+		if err := a.db.Select(&state.ClusterExpenses, SelectScanStateClusterExpensesQuery, pq.Array(clusterIDs), since); err != nil {
+			return state, fmt.Errorf("failed to get cluster expenses state: %w", err)
+		}
+	}
+
+	return state, nil
+}
+
+// WriteScanSession applies the changes of a scan session to the database in a
+// single transaction, and records the session with its change list, the
+// result of every scanned account and the state of the scanned clusters and
+// instances. The elements without changes only get their last scan timestamp
+// updated.
+//
+// Parameters:
+// - session: Scan session metadata. ID and Changes are ignored.
+// - diff: Differences between the stored inventory and the scan results.
+// - accountScans: Result of every account on the scan. Their IDs and SessionIDs are ignored.
+//
+// Returns:
+// - The ID of the new scan session.
+// - An error if the transaction fails.
+func (a SQLClient) WriteScanSession(session models.ScanSession, diff inventory.InventoryDiff, accountScans []models.AccountScan) (int64, error) {
+	tx, err := a.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			a.logger.Error("Failed to rollback WriteScanSession transaction", zap.Error(rbErr))
+		}
+	}()
+
+	if _, err := tx.Exec(SetChangeSourceQuery, ChangeSourceScan); err != nil {
+		return 0, fmt.Errorf("failed to set the change source: %w", err)
+	}
+
+	// Every scanned element shares the session timestamp. The inventory
+	// refresh relies on it for detecting the elements missing on the last
+	// successful scan of their accounts
+	for i := range diff.Accounts {
+		diff.Accounts[i].LastScanTimestamp = session.SessionTimestamp
+	}
+	for i := range diff.Clusters {
+		diff.Clusters[i].LastScanTimestamp = session.SessionTimestamp
+	}
+	for i := range diff.Instances {
+		diff.Instances[i].LastScanTimestamp = session.SessionTimestamp
+	}
+	for i := range diff.Resources {
+		diff.Resources[i].LastScanTimestamp = session.SessionTimestamp
+	}
+
+	// Writing added and updated elements
+	if err := namedExecInBatches(tx, InsertAccountsQuery, diff.Accounts); err != nil {
+		return 0, fmt.Errorf("failed to write accounts: %w", err)
+	}
+	if err := namedExecInBatches(tx, InsertClustersQuery, diff.Clusters); err != nil {
+		return 0, fmt.Errorf("failed to write clusters: %w", err)
+	}
+	if err := namedExecInBatches(tx, InsertInstancesQuery, diff.Instances); err != nil {
+		return 0, fmt.Errorf("failed to write instances: %w", err)
+	}
+
+	// Replacing the tags of the written instances, so removed tags don't remain on the DB
+	instanceIDs := make([]string, 0, len(diff.Instances))
+	var tags []inventory.Tag
+	for _, instance := range diff.Instances {
+		instanceIDs = append(instanceIDs, instance.ID)
+		for _, tag := range instance.Tags {
+			tag.InstanceID = instance.ID
+			tags = append(tags, tag)
+		}
+	}
+	if len(instanceIDs) > 0 {
+		if _, err := tx.Exec(DeleteInstancesTagsQuery, pq.Array(instanceIDs)); err != nil {
+			return 0, fmt.Errorf("failed to remove instances tags: %w", err)
+		}
+	}
+	if err := namedExecInBatches(tx, InsertTagsQuery, tags); err != nil {
+		return 0, fmt.Errorf("failed to write tags: %w", err)
+	}
+
+	if err := namedExecInBatches(tx, InsertResourcesQuery, diff.Resources); err != nil {
+		return 0, fmt.Errorf("failed to write resources: %w", err)
+	}
+	if err := writeResourcesTags(tx, diff.Resources); err != nil {
+		return 0, err
+	}
+	if err := namedExecInBatches(tx, InsertExpensesQuery, diff.Expenses); err != nil {
+		return 0, fmt.Errorf("failed to write expenses: %w", err)
+	}
+	if err := namedExecInBatches(tx, InsertClusterExpensesQuery, diff.ClusterExpenses); err != nil {
+		return 0, fmt.Errorf("failed to write cluster expenses: %w", err)
+	}
+
+	// Updating the last scan timestamp of the elements without changes
+	unchanged := []struct {
+		query string
+		ids   []string
+	}{
+		{UpdateAccountsScanTimestampQuery, diff.UnchangedAccounts},
+		{UpdateClustersScanTimestampQuery, diff.UnchangedClusters},
+		{UpdateInstancesScanTimestampQuery, diff.UnchangedInstances},
+		{UpdateResourcesScanTimestampQuery, diff.UnchangedResources},
+	}
+	for _, u := range unchanged {
+		if len(u.ids) == 0 {
+			continue
+		}
+		if _, err := tx.Exec(u.query, session.SessionTimestamp, pq.Array(u.ids)); err != nil {
+			return 0, fmt.Errorf("failed to update last scan timestamp: %w", err)
+		}
+	}
+
+	// Recording the scan session
+	session.ChangeSummary = diff.Summary()
+	rows, err := tx.NamedQuery(InsertScanSessionQuery, session)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert scan session: %w", err)
+	}
+	var sessionID int64
+	if rows.Next() {
+		err = rows.Scan(&sessionID)
+	} else {
+		err = fmt.Errorf("failed to retrieve inserted scan session ID")
+	}
+	if closeErr := rows.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	changes := make([]models.ScanSessionChangeDB, 0, len(diff.Changes))
+	for _, change := range diff.Changes {
+		changes = append(changes, models.ScanSessionChangeDB{
+			SessionID:   sessionID,
+			ElementType: change.ElementType,
+			ElementID:   change.ElementID,
+			Change:      change.Change,
+			Fields:      change.Fields,
+		})
+	}
+	if err := namedExecInBatches(tx, InsertScanSessionChangesQuery, changes); err != nil {
+		return 0, fmt.Errorf("failed to write scan session changes: %w", err)
+	}
+
+	for i := range accountScans {
+		accountScans[i].SessionID = sessionID
+	}
+	if err := namedExecInBatches(tx, InsertAccountScansQuery, accountScans); err != nil {
+		return 0, fmt.Errorf("failed to write account scans: %w", err)
+	}
+
+	// Recording the state of the scanned clusters and instances for point-in-time queries
+	clusterIDs := make([]string, 0, len(diff.Clusters)+len(diff.UnchangedClusters))
+	for _, cluster := range diff.Clusters {
+		clusterIDs = append(clusterIDs, cluster.ID)
+	}
+	clusterIDs = append(clusterIDs, diff.UnchangedClusters...)
+	if _, err := tx.Exec(InsertClustersHistoryQuery, sessionID, pq.Array(clusterIDs)); err != nil {
+		return 0, fmt.Errorf("failed to write clusters history: %w", err)
+	}
+	instanceIDs = append(instanceIDs, diff.UnchangedInstances...)
+	if _, err := tx.Exec(InsertInstancesHistoryQuery, sessionID, pq.Array(instanceIDs)); err != nil {
+		return 0, fmt.Errorf("failed to write instances history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return sessionID, nil
+}
+
+// GetScanSessions retrieves the most recent scan sessions, without their changes.
+//
+// Parameters:
+// - limit: Maximum number of scan sessions to retrieve.
+//
+// Returns:
+// - A slice of models.ScanSession objects, sorted from the newest.
+// - An error if the query fails.
+func (a SQLClient) GetScanSessions(limit int) ([]models.ScanSession, error) {
+	var sessions []models.ScanSession
+	if err := a.db.Select(&sessions, SelectScanSessionsQuery, limit); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// GetScanSessionByID retrieves a scan session and its changes.
+//
+// Parameters:
+// - sessionID: The ID of the scan session.
+//
+// Returns:
+// - A slice of models.ScanSession objects (usually one element).
+// - An error if the query fails.
+func (a SQLClient) GetScanSessionByID(sessionID int64) ([]models.ScanSession, error) {
+	var sessions []models.ScanSession
+	if err := a.db.Select(&sessions, SelectScanSessionByIDQuery, sessionID); err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return sessions, nil
+	}
+
+	var changes []models.ScanSessionChangeDB
+	if err := a.db.Select(&changes, SelectScanSessionChangesQuery, sessionID); err != nil {
+		return nil, err
+	}
+	sessions[0].Changes = make([]inventory.InventoryChange, 0, len(changes))
+	for _, change := range changes {
+		sessions[0].Changes = append(sessions[0].Changes, change.ToInventoryChange())
+	}
+
+	return sessions, nil
+}
+
+// namedExecInBatches runs a named query for every element of a slice, in
+// batches small enough for not exceeding the PostgreSQL parameters limit.
+// Empty slices are skipped, as they can't be used on batch inserts
+func namedExecInBatches[T any](tx *sqlx.Tx, query string, elements []T) error {
+	for batch := range slices.Chunk(elements, namedExecBatchSize) {
+		if _, err := tx.NamedExec(query, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetClustersAt retrieves every cluster as it was at a point in time, based on
+// the inventory history.
+//
+// Parameters:
+// - at: The point in time.
+//
+// Returns:
+// - A slice of inventory.Cluster objects with their last recorded state before the timestamp.
+// - An error if the query fails.
+func (a SQLClient) GetClustersAt(at time.Time) ([]inventory.Cluster, error) {
+	var clusters []inventory.Cluster
+	if err := a.db.Select(&clusters, SelectClustersAtQuery, at); err != nil {
+		return nil, err
+	}
+	return clusters, nil
+}
+
+// GetInstancesAt retrieves every instance as it was at a point in time, based
+// on the inventory history. The tags are not part of the history, so the
+// current tags are returned.
+//
+// Parameters:
+// - at: The point in time.
+//
+// Returns:
+// - A slice of inventory.Instance objects with their last recorded state before the timestamp.
+// - An error if the query fails.
+func (a SQLClient) GetInstancesAt(at time.Time) ([]inventory.Instance, error) {
+	var dbinstances []models.InstanceDB
+	if err := a.db.Select(&dbinstances, SelectInstancesAtQuery, at); err != nil {
+		return nil, err
+	}
+	return removeEmptyTags(joinInstancesTags(dbinstances)), nil
+}
+
+// GetClustersOverviewAt returns a summary of cluster statuses at a point in time.
+func (a SQLClient) GetClustersOverviewAt(at time.Time) (models.ClustersSummary, error) {
+	var clustersOverview models.ClustersSummary
+	if err := a.db.Get(&clustersOverview, SelectClustersOverviewAtQuery, at); err != nil {
+		return models.ClustersSummary{}, err
+	}
+	return clustersOverview, nil
+}
+
+// GetInstancesOverviewAt returns the number of instances at a point in time.
+func (a SQLClient) GetInstancesOverviewAt(at time.Time) (models.InstancesSummary, error) {
+	var instances models.InstancesSummary
+	if err := a.db.Get(&instances, SelectInstancesOverviewAtQuery, at); err != nil {
+		return models.InstancesSummary{}, err
+	}
+	return instances, nil
+}
+
+// GetProvidersOverviewAt returns the account and cluster counts of every cloud
+// provider at a point in time. Only the accounts with clusters are counted.
+func (a SQLClient) GetProvidersOverviewAt(at time.Time) (models.ProvidersSummary, error) {
+	var providerRows []providerOverviewRow
+	if err := a.db.Select(&providerRows, SelectProvidersOverviewAtQuery, at); err != nil {
+		return models.ProvidersSummary{}, err
+	}
+	return newProvidersSummary(providerRows), nil
+}
+
+// GetScannerLastScanTimestampAt returns the timestamp of the latest scan session before a point in time
+func (a SQLClient) GetScannerLastScanTimestampAt(at time.Time) (*time.Time, error) {
+	var lastScanTimestamp sql.NullTime
+	if err := a.db.Get(&lastScanTimestamp, SelectScannerLastScanTimestampAtQuery, at); err != nil {
+		return nil, err
+	}
+	if lastScanTimestamp.Valid {
+		return &lastScanTimestamp.Time, nil
+	}
+	return nil, nil
+}
+
+// removeEmptyTags removes the empty tag added by joinInstancesTags to the
+// instances without tags, when they're selected with a LEFT JOIN
+func removeEmptyTags(instances []inventory.Instance) []inventory.Instance {
+	for i := range instances {
+		instances[i].Tags = slices.DeleteFunc(instances[i].Tags, func(tag inventory.Tag) bool { return tag.Key == "" })
+	}
+	return instances
+}
+
+// writeResourcesTags replaces the tags of the written resources in the
+// transaction, so removed tags don't remain on the DB.
+//
+// Parameters:
+// - tx: The transaction writing the resources.
+// - resources: A slice of inventory.Resource objects already written on the transaction.
+//
+// Returns:
+// - An error if any query fails.
+func writeResourcesTags(tx *sqlx.Tx, resources []inventory.Resource) error {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	resourceIDs := make([]string, 0, len(resources))
+	var tags []inventory.Tag
+	for _, resource := range resources {
+		resourceIDs = append(resourceIDs, resource.ID)
+		for _, tag := range resource.Tags {
+			tag.InstanceID = resource.ID
+			tags = append(tags, tag)
+		}
+	}
+	if _, err := tx.Exec(DeleteResourcesTagsQuery, pq.Array(resourceIDs)); err != nil {
+		return fmt.Errorf("failed to remove resources tags: %w", err)
+	}
+	if err := namedExecInBatches(tx, InsertResourceTagsQuery, tags); err != nil {
+		return fmt.Errorf("failed to write resources tags: %w", err)
+	}
+	return nil
+}
+
+// joinResourcesTags reads the tags of the resources and adds them to every resource.
+//
+// Parameters:
+// - resources: A slice of inventory.Resource objects read from the DB.
+//
+// Returns:
+// - An error if the query fails.
+func (a SQLClient) joinResourcesTags(resources []inventory.Resource) error {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	resourceIndex := make(map[string]int, len(resources))
+	resourceIDs := make([]string, 0, len(resources))
+	for i, resource := range resources {
+		resourceIndex[resource.ID] = i
+		resourceIDs = append(resourceIDs, resource.ID)
+	}
+
+	var tags []inventory.Tag
+	if err := a.db.Select(&tags, SelectResourcesTagsQuery, pq.Array(resourceIDs)); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		i := resourceIndex[tag.InstanceID]
+		resources[i].Tags = append(resources[i].Tags, tag)
+	}
+	return nil
+}
+
 // joinInstancesTags maps an array of InstanceDB objects into a slice of inventory.Instance objects.
 //
 // Parameters:
@@ -1076,6 +1918,7 @@ func joinInstancesTags(dbinstances []models.InstanceDB) []inventory.Instance {
 			// TODO: Implement a method for setting this values OR include them on the builder method
 			instanceMap[dbinstance.ID].TotalCost = dbinstance.TotalCost
 			instanceMap[dbinstance.ID].DailyCost = dbinstance.DailyCost
+			instanceMap[dbinstance.ID].CostEstimated = dbinstance.CostEstimated
 		}
 	}
 
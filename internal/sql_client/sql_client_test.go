@@ -0,0 +1,124 @@
+package sqlclient
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// newTestSQLClient returns a SQLClient backed by a mocked DB. The mock
+// expectations are verified when the test finishes
+func newTestSQLClient(t *testing.T) (*SQLClient, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cannot create the DB mock: %v", err)
+	}
+	t.Cleanup(func() {
+		assert.Nil(t, mock.ExpectationsWereMet())
+		db.Close()
+	})
+
+	return NewSQLClientWithDB(sqlx.NewDb(db, "postgres"), zap.NewNop()), mock
+}
+
+// quoted returns the regular expression matching exactly the query
+func quoted(query string) string {
+	return regexp.QuoteMeta(query)
+}
+
+// TestWriteEstimatedExpenses verifies the estimations of the billed accounts are removed before writing the new ones in the same transaction
+func TestWriteEstimatedExpenses(t *testing.T) {
+	client, mock := newTestSQLClient(t)
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(quoted(DeleteBilledEstimatedExpensesQuery)).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(quoted("INSERT INTO expenses")).
+		WithArgs("i-0001", date, 1.5, true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.Nil(t, client.WriteEstimatedExpenses([]inventory.Expense{*inventory.NewEstimatedExpense("i-0001", 1.5, date)}))
+}
+
+// TestWriteEstimatedExpensesError verifies nothing is written when the billed estimations can't be removed
+func TestWriteEstimatedExpensesError(t *testing.T) {
+	client, mock := newTestSQLClient(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(quoted(DeleteBilledEstimatedExpensesQuery)).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	assert.NotNil(t, client.WriteEstimatedExpenses([]inventory.Expense{*inventory.NewEstimatedExpense("i-0001", 1.5, time.Now())}))
+}
+
+// TestUpdateClusterStatusByClusterID verifies the cluster and its instances are updated in a single transaction recorded as an action
+func TestUpdateClusterStatusByClusterID(t *testing.T) {
+	client, mock := newTestSQLClient(t)
+
+	mock.ExpectQuery(quoted(CheckStatusQuery)).WithArgs("Stopped").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectBegin()
+	mock.ExpectExec(quoted(SetChangeSourceQuery)).WithArgs(ChangeSourceAction).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(quoted(UpdateStatusClusterByClusterIDQuery)).WithArgs("Stopped", "ocp-a1b2c-account").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(quoted(UpdateStatusInstancesByClusterIDQuery)).WithArgs("Stopped", "ocp-a1b2c-account").WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	assert.Nil(t, client.UpdateClusterStatusByClusterID("Stopped", "ocp-a1b2c-account"))
+}
+
+// TestUpdateClusterStatusByClusterIDErrors verifies the unknown statuses are rejected before the transaction, and the transaction is rolled back when the cluster doesn't exist
+func TestUpdateClusterStatusByClusterIDErrors(t *testing.T) {
+	client, mock := newTestSQLClient(t)
+
+	mock.ExpectQuery(quoted(CheckStatusQuery)).WithArgs("Sleeping").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	assert.NotNil(t, client.UpdateClusterStatusByClusterID("Sleeping", "ocp-a1b2c-account"))
+
+	mock.ExpectQuery(quoted(CheckStatusQuery)).WithArgs("Stopped").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectBegin()
+	mock.ExpectExec(quoted(SetChangeSourceQuery)).WithArgs(ChangeSourceAction).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(quoted(UpdateStatusClusterByClusterIDQuery)).WithArgs("Stopped", "unknown").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.NotNil(t, client.UpdateClusterStatusByClusterID("Stopped", "unknown"))
+}
+
+// TestWriteResourcesTags verifies the tags of the written resources are replaced in the same transaction
+func TestWriteResourcesTags(t *testing.T) {
+	client, mock := newTestSQLClient(t)
+	volume := *inventory.NewResource("vol-0001", "data", inventory.VolumeResourceType, inventory.AWSProvider, "eu-west-1a", "in-use", "gp3", 100, "", []inventory.Tag{*inventory.NewTag("Owner", "team", "")}, time.Time{})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(quoted("INSERT INTO resources")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(quoted(DeleteResourcesTagsQuery)).WithArgs(pq.Array([]string{"vol-0001"})).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(quoted("INSERT INTO resource_tags")).WithArgs("Owner", "team", "vol-0001").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.Nil(t, client.WriteResources([]inventory.Resource{volume}))
+}
+
+// TestGetResourcesOnClusterTags verifies the resources are returned with their tags
+func TestGetResourcesOnClusterTags(t *testing.T) {
+	client, mock := newTestSQLClient(t)
+
+	mock.ExpectQuery(quoted(SelectResourcesOnClusterQuery)).WithArgs("ocp-a1b2c-account").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type"}).AddRow("vol-0001", "Volume").AddRow("vol-0002", "Volume"))
+	mock.ExpectQuery(quoted(SelectResourcesTagsQuery)).WithArgs(pq.Array([]string{"vol-0001", "vol-0002"})).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value", "instance_id"}).AddRow("Name", "data", "vol-0002").AddRow("Owner", "team", "vol-0002"))
+
+	resources, err := client.GetResourcesOnCluster("ocp-a1b2c-account")
+	assert.Nil(t, err)
+	if assert.Len(t, resources, 2) {
+		assert.Empty(t, resources[0].Tags)
+		assert.Equal(t, []inventory.Tag{*inventory.NewTag("Name", "data", "vol-0002"), *inventory.NewTag("Owner", "team", "vol-0002")}, resources[1].Tags)
+	}
+}
@@ -162,7 +162,8 @@ const (
 	// SelectLastExpensesQuery returns the last expense for every instance older
 	// than 1 day. This is used for obtaining the list of instances that need
 	// Billing information update because all the instances returned by this
-	// query doesn't have expenses for the current day
+	// query doesn't have expenses for the current day. The estimated expenses
+	// are ignored, as they must be replaced by the billing information
 	SelectLastExpensesQuery = `
 		SELECT
 				instances.id
@@ -174,6 +175,8 @@ const (
 						MAX(date) AS last_expense_date
 				FROM
 						expenses
+				WHERE
+						NOT estimated
 				GROUP BY
 						instance_id
 		) AS last_expenses
@@ -215,15 +218,220 @@ const (
 		INSERT INTO expenses (
 			instance_id,
 			date,
-			amount
+			amount,
+			estimated
 		) VALUES (
 			:instance_id,
 			:date,
-			:amount
+			:amount,
+			:estimated
 		) ON CONFLICT (instance_id, date) DO UPDATE SET
+			amount = EXCLUDED.amount,
+			estimated = EXCLUDED.estimated
+	`
+
+	// InsertEstimatedExpensesQuery inserts the estimated expenses of the
+	// instances. The expenses obtained from the billing information are never
+	// replaced by estimations
+	InsertEstimatedExpensesQuery = `
+		INSERT INTO expenses (
+			instance_id,
+			date,
+			amount,
+			estimated
+		) VALUES (
+			:instance_id,
+			:date,
+			:amount,
+			:estimated
+		) ON CONFLICT (instance_id, date) DO UPDATE SET
+			amount = EXCLUDED.amount
+		WHERE
+			expenses.estimated
+			AND expenses.amount <> EXCLUDED.amount
+	`
+
+	// DeleteBilledEstimatedExpensesQuery removes the estimated expenses of the
+	// accounts that already have billing information. The billing replaces the
+	// estimations of the same days, but the estimations of the days without
+	// billing (e.g. the last days, pending of billing) must be removed too
+	DeleteBilledEstimatedExpensesQuery = `
+		DELETE FROM expenses
+		USING instances, clusters
+		WHERE expenses.instance_id = instances.id
+			AND instances.cluster_id = clusters.id
+			AND expenses.estimated
+			AND EXISTS (
+				SELECT 1
+				FROM expenses billed_expenses
+				JOIN instances billed_instances ON billed_expenses.instance_id = billed_instances.id
+				JOIN clusters billed_clusters ON billed_instances.cluster_id = billed_clusters.id
+				WHERE billed_clusters.account_name = clusters.account_name
+					AND NOT billed_expenses.estimated
+			)
+	`
+
+	// SelectUnbilledInstancesSamplesQuery returns the states recorded by the
+	// scans since $1 of the instances whose accounts don't have billing
+	// information (no expenses but the estimated ones), including the last
+	// state of every instance before $1
+	SelectUnbilledInstancesSamplesQuery = `
+		WITH unbilled_instances AS (
+			SELECT instances.id
+			FROM instances
+			JOIN clusters ON instances.cluster_id = clusters.id
+			WHERE NOT EXISTS (
+				SELECT 1
+				FROM expenses
+				JOIN instances billed_instances ON expenses.instance_id = billed_instances.id
+				JOIN clusters billed_clusters ON billed_instances.cluster_id = billed_clusters.id
+				WHERE billed_clusters.account_name = clusters.account_name
+					AND NOT expenses.estimated
+			)
+		)
+		SELECT id, provider, instance_type, availability_zone, status, last_scan_timestamp
+		FROM instances_history
+		WHERE id IN (SELECT id FROM unbilled_instances)
+			AND last_scan_timestamp >= $1
+		UNION ALL
+		(
+			SELECT DISTINCT ON (id) id, provider, instance_type, availability_zone, status, last_scan_timestamp
+			FROM instances_history
+			WHERE id IN (SELECT id FROM unbilled_instances)
+				AND last_scan_timestamp < $1
+			ORDER BY id, last_scan_timestamp DESC
+		)
+	`
+
+	// InsertClusterExpensesQuery inserts a new cluster level expense of a service
+	InsertClusterExpensesQuery = `
+		INSERT INTO cluster_expenses (
+			cluster_id,
+			service,
+			date,
+			amount
+		) VALUES (
+			:cluster_id,
+			:service,
+			:date,
+			:amount
+		) ON CONFLICT (cluster_id, service, date) DO UPDATE SET
 			amount = EXCLUDED.amount
 	`
 
+	// SelectClusterExpensesQuery returns the cluster level expenses of a
+	// cluster. $2 and $3 optionally limit the dates to [$2, $3)
+	SelectClusterExpensesQuery = `
+		SELECT * FROM cluster_expenses
+		WHERE cluster_id = $1
+			AND ($2::DATE IS NULL OR date >= $2)
+			AND ($3::DATE IS NULL OR date < $3)
+		ORDER BY date, service
+	`
+
+	// SelectClusterServiceCostsQuery returns the cost of every service of a
+	// cluster. The days without cluster level expenses fall back to the
+	// expenses of its instances, reported as the 'Instances' service, like
+	// the cluster costs do. $2 and $3 optionally limit the dates to [$2, $3)
+	SelectClusterServiceCostsQuery = `
+		SELECT service, SUM(amount) AS amount FROM (
+			SELECT service, amount FROM cluster_expenses
+			WHERE cluster_id = $1
+				AND ($2::DATE IS NULL OR date >= $2)
+				AND ($3::DATE IS NULL OR date < $3)
+			UNION ALL
+			SELECT 'Instances' AS service, expenses.amount FROM expenses
+			JOIN instances ON instances.id = expenses.instance_id
+			WHERE instances.cluster_id = $1
+				AND ($2::DATE IS NULL OR expenses.date >= $2)
+				AND ($3::DATE IS NULL OR expenses.date < $3)
+				AND NOT EXISTS (
+					SELECT 1 FROM cluster_expenses
+					WHERE cluster_expenses.cluster_id = $1
+						AND cluster_expenses.date = expenses.date
+				)
+		) AS costs
+		GROUP BY service
+		ORDER BY amount DESC, service
+	`
+
+	// SelectClusterDailyCostsQuery returns the daily costs of a cluster in
+	// [$2, $3), as they're added up on the cluster costs
+	SelectClusterDailyCostsQuery = `
+		SELECT cost_date AS date, SUM(cost_amount) AS amount
+		FROM cluster_daily_costs($1)
+		WHERE cost_date >= $2 AND cost_date < $3
+		GROUP BY cost_date
+		ORDER BY cost_date
+	`
+
+	// SelectAccountDailyCostsQuery returns the daily costs of the active
+	// clusters of an account in [$2, $3). Like cluster_daily_costs, the days
+	// with cluster level expenses use them and the rest fall back to the
+	// instances expenses, but the expenses of every cluster are read at once.
+	// The Terminated and Missing clusters are not projected
+	SelectAccountDailyCostsQuery = `
+		WITH cluster_days AS (
+			SELECT cluster_expenses.cluster_id, cluster_expenses.date, SUM(cluster_expenses.amount) AS amount
+			FROM cluster_expenses
+			JOIN clusters ON clusters.id = cluster_expenses.cluster_id
+			WHERE clusters.account_name = $1
+				AND clusters.status NOT IN ('Terminated', 'Missing')
+				AND cluster_expenses.date >= $2 AND cluster_expenses.date < $3
+			GROUP BY cluster_expenses.cluster_id, cluster_expenses.date
+		)
+		SELECT date, SUM(amount) AS amount FROM (
+			SELECT date, amount FROM cluster_days
+			UNION ALL
+			SELECT expenses.date, expenses.amount FROM expenses
+			JOIN instances ON instances.id = expenses.instance_id
+			JOIN clusters ON clusters.id = instances.cluster_id
+			WHERE clusters.account_name = $1
+				AND clusters.status NOT IN ('Terminated', 'Missing')
+				AND expenses.date >= $2 AND expenses.date < $3
+				AND NOT EXISTS (
+					SELECT 1 FROM cluster_days
+					WHERE cluster_days.cluster_id = instances.cluster_id
+						AND cluster_days.date = expenses.date
+				)
+		) AS costs
+		GROUP BY date
+		ORDER BY date
+	`
+
+	// SelectInventoryDailyCostsQuery returns the daily costs of every active
+	// cluster in the inventory in [$1, $2). Like cluster_daily_costs, the days
+	// with cluster level expenses use them and the rest fall back to the
+	// instances expenses, but the expenses of every cluster are read at once
+	// instead of evaluating the function per cluster. The Terminated and
+	// Missing clusters are not projected
+	SelectInventoryDailyCostsQuery = `
+		WITH cluster_days AS (
+			SELECT cluster_expenses.cluster_id, cluster_expenses.date, SUM(cluster_expenses.amount) AS amount
+			FROM cluster_expenses
+			JOIN clusters ON clusters.id = cluster_expenses.cluster_id
+			WHERE clusters.status NOT IN ('Terminated', 'Missing')
+				AND cluster_expenses.date >= $1 AND cluster_expenses.date < $2
+			GROUP BY cluster_expenses.cluster_id, cluster_expenses.date
+		)
+		SELECT date, SUM(amount) AS amount FROM (
+			SELECT date, amount FROM cluster_days
+			UNION ALL
+			SELECT expenses.date, expenses.amount FROM expenses
+			JOIN instances ON instances.id = expenses.instance_id
+			JOIN clusters ON clusters.id = instances.cluster_id
+			WHERE clusters.status NOT IN ('Terminated', 'Missing')
+				AND expenses.date >= $1 AND expenses.date < $2
+				AND NOT EXISTS (
+					SELECT 1 FROM cluster_days
+					WHERE cluster_days.cluster_id = instances.cluster_id
+						AND cluster_days.date = expenses.date
+				)
+		) AS costs
+		GROUP BY date
+		ORDER BY date
+	`
+
 	// SelectInstancesQuery returns every instance in the inventory ordered by ID
 	SelectInstancesQuery = `
 		SELECT * FROM instances
@@ -255,6 +463,7 @@ const (
 		SELECT 
 			COUNT(CASE WHEN status = 'Running' THEN 1 END) AS running,
 			COUNT(CASE WHEN status = 'Stopped' THEN 1 END) AS stopped,
+			COUNT(CASE WHEN status = 'Missing' THEN 1 END) AS missing,
 			COUNT(CASE WHEN status = 'Terminated' THEN 1 END) AS archived
 		FROM clusters;
 	`
@@ -517,6 +726,91 @@ const (
 			value = EXCLUDED.value
 	`
 
+	// InsertResourceTagsQuery inserts into a new tag for a non-compute resource.
+	// The tags of the resources keep the resource ID on their InstanceID field
+	InsertResourceTagsQuery = `
+		INSERT INTO resource_tags (
+			key,
+			value,
+			resource_id
+		) VALUES (
+			:key,
+			:value,
+			:instance_id
+		) ON CONFLICT (key, resource_id) DO UPDATE SET
+			value = EXCLUDED.value
+	`
+
+	// SelectResourcesTagsQuery returns every tag of a set of non-compute resources
+	SelectResourcesTagsQuery = `
+		SELECT
+			key,
+			value,
+			resource_id AS instance_id
+		FROM resource_tags
+		WHERE resource_id = ANY($1)
+		ORDER BY resource_id, key
+	`
+
+	// SelectResourcesQuery returns every non-compute resource in the inventory ordered by ID
+	SelectResourcesQuery = `
+		SELECT * FROM resources
+		ORDER BY id
+	`
+
+	// SelectResourcesOnClusterQuery returns every non-compute resource belonging to a cluster
+	SelectResourcesOnClusterQuery = `
+		SELECT * FROM resources
+		WHERE cluster_id = $1
+		ORDER BY type, id
+	`
+
+	// SelectOrphanResourcesQuery returns every non-compute resource that remains on the cloud account after its cluster was terminated
+	SelectOrphanResourcesQuery = `
+		SELECT resources.* FROM resources
+		JOIN clusters ON resources.cluster_id = clusters.id
+		WHERE clusters.status = 'Terminated'
+		ORDER BY resources.cluster_id, resources.type, resources.id
+	`
+
+	// InsertResourcesQuery inserts into a new non-compute resource in its table
+	InsertResourcesQuery = `
+		INSERT INTO resources (
+			id,
+			name,
+			type,
+			provider,
+			region,
+			status,
+			class,
+			size,
+			cluster_id,
+			instance_id,
+			last_scan_timestamp,
+			creation_timestamp
+		) VALUES (
+			:id,
+			:name,
+			:type,
+			:provider,
+			:region,
+			:status,
+			:class,
+			:size,
+			:cluster_id,
+			:instance_id,
+			:last_scan_timestamp,
+			:creation_timestamp
+		) ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			status = EXCLUDED.status,
+			class = EXCLUDED.class,
+			size = EXCLUDED.size,
+			cluster_id = EXCLUDED.cluster_id,
+			instance_id = EXCLUDED.instance_id,
+			last_scan_timestamp = EXCLUDED.last_scan_timestamp
+	`
+
 	// DeleteInstanceQuery removes an instance by its ID
 	DeleteInstanceQuery = `DELETE FROM instances WHERE id=$1`
 
@@ -529,10 +823,16 @@ const (
 	// DeleteTagsQuery removes a Tag by its key and instance reference
 	DeleteTagsQuery = `DELETE FROM tags WHERE instance_id=$1`
 
-	// Run 'check_terminated_instances()' SQL function
-	UpdateTerminatedInstancesQuery = `SELECT check_terminated_instances()`
-	// Run 'check_terminated_clusters()' SQL function
-	UpdateTerminatedClustersQuery = `SELECT check_terminated_clusters()`
+	// Run 'check_missing_instances()' SQL function
+	UpdateMissingInstancesQuery = `SELECT check_missing_instances()`
+	// Run 'check_missing_clusters()' SQL function
+	UpdateMissingClustersQuery = `SELECT check_missing_clusters()`
+	// Run 'check_terminated_instances()' SQL function. $1 is the grace period in hours
+	UpdateTerminatedInstancesQuery = `SELECT check_terminated_instances(make_interval(hours => $1))`
+	// Run 'check_terminated_clusters()' SQL function. $1 is the grace period in hours
+	UpdateTerminatedClustersQuery = `SELECT check_terminated_clusters(make_interval(hours => $1))`
+	// Run 'check_deleted_resources()' SQL function. $1 is the grace period in hours
+	DeleteOutdatedResourcesQuery = `SELECT check_deleted_resources(make_interval(hours => $1))`
 
 	// UpdateInstanceStatus updates the status of a  set of instances based on their clusterID
 	UpdateStatusClusterByClusterIDQuery = `UPDATE clusters SET status=$1 WHERE id=$2`
@@ -544,4 +844,407 @@ const (
 	CheckStatusQuery = `SELECT EXISTS (SELECT 1 FROM status WHERE value=$1)`
 	// SelectScannerLastScanTimestamp returns the latest scan timestamp across all accounts
 	SelectScannerLastScanTimestamp = `SELECT MAX(last_scan_timestamp) as last_scan_timestamp FROM accounts;`
+
+	// SelectScanStateAccountsQuery returns the accounts in the scope of a scan session
+	SelectScanStateAccountsQuery = `
+		SELECT * FROM accounts
+		WHERE name = ANY($1)
+	`
+
+	// SelectScanStateClustersQuery returns the clusters in the scope of a scan
+	// session. $2 limits the scope to a single cluster when it's not empty
+	SelectScanStateClustersQuery = `
+		SELECT * FROM clusters
+		WHERE account_name = ANY($1)
+			AND ($2::TEXT = '' OR id = $2)
+	`
+
+	// SelectScanStateInstancesQuery returns the instances and their tags in the
+	// scope of a scan session. Instances without tags are returned with an empty tag
+	SelectScanStateInstancesQuery = `
+		SELECT
+			instances.*,
+			COALESCE(tags.key, '') AS key,
+			COALESCE(tags.value, '') AS value,
+			instances.id AS instance_id
+		FROM instances
+		JOIN clusters ON instances.cluster_id = clusters.id
+		LEFT JOIN tags ON instances.id = tags.instance_id
+		WHERE clusters.account_name = ANY($1)
+			AND ($2::TEXT = '' OR clusters.id = $2)
+	`
+
+	// SelectScanStateResourcesQuery returns the non-compute resources in the
+	// scope of a scan session. Resources of terminated clusters are managed by
+	// the orphans detection
+	SelectScanStateResourcesQuery = `
+		SELECT resources.* FROM resources
+		JOIN clusters ON resources.cluster_id = clusters.id
+		WHERE clusters.account_name = ANY($1)
+			AND ($2::TEXT = '' OR clusters.id = $2)
+			AND clusters.status != 'Terminated'
+	`
+
+	// SelectScanStateExpensesQuery returns the expenses of a set of instances since a date
+	SelectScanStateExpensesQuery = `
+		SELECT * FROM expenses
+		WHERE instance_id = ANY($1)
+			AND date >= $2
+	`
+
+	// SelectScanStateClusterExpensesQuery returns the cluster level expenses of a set of clusters since a date
+	SelectScanStateClusterExpensesQuery = `
+		SELECT * FROM cluster_expenses
+		WHERE cluster_id = ANY($1)
+			AND date >= $2
+	`
+
+	// UpdateAccountsScanTimestampQuery updates the last scan timestamp of the accounts without changes
+	UpdateAccountsScanTimestampQuery = `
+		UPDATE accounts
+		SET last_scan_timestamp = $1
+		WHERE name = ANY($2)
+	`
+
+	// UpdateClustersScanTimestampQuery updates the last scan timestamp and the age of the clusters without changes
+	UpdateClustersScanTimestampQuery = `
+		UPDATE clusters
+		SET
+			last_scan_timestamp = $1,
+			age = GREATEST(FLOOR(EXTRACT(EPOCH FROM ($1 - creation_timestamp)) / 86400), 1)
+		WHERE id = ANY($2)
+	`
+
+	// UpdateInstancesScanTimestampQuery updates the last scan timestamp and the age of the instances without changes
+	UpdateInstancesScanTimestampQuery = `
+		UPDATE instances
+		SET
+			last_scan_timestamp = $1,
+			age = GREATEST(FLOOR(EXTRACT(EPOCH FROM ($1 - creation_timestamp)) / 86400), 1)
+		WHERE id = ANY($2)
+	`
+
+	// UpdateResourcesScanTimestampQuery updates the last scan timestamp of the non-compute resources without changes
+	UpdateResourcesScanTimestampQuery = `
+		UPDATE resources
+		SET last_scan_timestamp = $1
+		WHERE id = ANY($2)
+	`
+
+	// DeleteInstancesTagsQuery removes every tag of a set of instances
+	DeleteInstancesTagsQuery = `DELETE FROM tags WHERE instance_id = ANY($1)`
+
+	// DeleteResourcesTagsQuery removes every tag of a set of non-compute resources
+	DeleteResourcesTagsQuery = `DELETE FROM resource_tags WHERE resource_id = ANY($1)`
+
+	// DeleteResourcesQuery removes a list of resources by their IDs
+	DeleteResourcesQuery = `DELETE FROM resources WHERE id = ANY($1)`
+
+	// InsertScanSessionQuery inserts a new scan session and returns its ID
+	InsertScanSessionQuery = `
+		INSERT INTO scan_sessions (
+			session_timestamp,
+			triggered_by,
+			account_name,
+			cluster_id,
+			added,
+			updated,
+			disappeared,
+			unchanged
+		) VALUES (
+			:session_timestamp,
+			:triggered_by,
+			:account_name,
+			:cluster_id,
+			:added,
+			:updated,
+			:disappeared,
+			:unchanged
+		) RETURNING id
+	`
+
+	// InsertScanSessionChangesQuery inserts the changes of a scan session
+	InsertScanSessionChangesQuery = `
+		INSERT INTO scan_session_changes (
+			session_id,
+			element_type,
+			element_id,
+			change,
+			fields
+		) VALUES (
+			:session_id,
+			:element_type,
+			:element_id,
+			:change,
+			:fields
+		)
+	`
+
+	// SelectScanSessionsQuery returns the most recent scan sessions
+	SelectScanSessionsQuery = `
+		SELECT * FROM scan_sessions
+		ORDER BY session_timestamp DESC, id DESC
+		LIMIT $1
+	`
+
+	// SelectScanSessionByIDQuery returns a scan session by its ID
+	SelectScanSessionByIDQuery = `
+		SELECT * FROM scan_sessions
+		WHERE id = $1
+	`
+
+	// SelectScanSessionChangesQuery returns the changes of a scan session
+	SelectScanSessionChangesQuery = `
+		SELECT * FROM scan_session_changes
+		WHERE session_id = $1
+		ORDER BY element_type, change, element_id
+	`
+
+	// InsertAccountScansQuery inserts the result of the accounts scanned on a scan session
+	InsertAccountScansQuery = `
+		INSERT INTO account_scans (
+			session_id,
+			account_name,
+			provider,
+			start_timestamp,
+			end_timestamp,
+			status,
+			scanned_regions,
+			failed_regions,
+			errors,
+			cluster_count,
+			instance_count,
+			resource_count,
+			throttled_requests
+		) VALUES (
+			:session_id,
+			:account_name,
+			:provider,
+			:start_timestamp,
+			:end_timestamp,
+			:status,
+			:scanned_regions,
+			:failed_regions,
+			:errors,
+			:cluster_count,
+			:instance_count,
+			:resource_count,
+			:throttled_requests
+		)
+	`
+
+	// SelectAccountScansQuery returns the latest scans of an account, from the newest
+	SelectAccountScansQuery = `
+		SELECT * FROM account_scans
+		WHERE account_name = $1
+		ORDER BY start_timestamp DESC
+		LIMIT $2
+	`
+
+	// SelectLatestAccountScansQuery returns the last scan of every account. If
+	// $1 is not NULL, only the scans started before that timestamp are considered
+	SelectLatestAccountScansQuery = `
+		SELECT DISTINCT ON (account_name) * FROM account_scans
+		WHERE $1::TIMESTAMP WITH TIME ZONE IS NULL OR start_timestamp <= $1
+		ORDER BY account_name, start_timestamp DESC
+	`
+
+	// clustersHistoryColumns are the columns of the clusters table kept on its history
+	clustersHistoryColumns = `
+			id,
+			name,
+			infra_id,
+			provider,
+			status,
+			region,
+			account_name,
+			console_link,
+			instance_count,
+			last_scan_timestamp,
+			creation_timestamp,
+			age,
+			owner,
+			total_cost,
+			last_15_days_cost,
+			last_month_cost,
+			current_month_so_far_cost`
+
+	// instancesHistoryColumns are the columns of the instances table kept on its history
+	instancesHistoryColumns = `
+			id,
+			name,
+			provider,
+			instance_type,
+			availability_zone,
+			status,
+			cluster_id,
+			last_scan_timestamp,
+			creation_timestamp,
+			age,
+			daily_cost,
+			total_cost`
+
+	// InsertClustersHistoryQuery records the current state of a set of clusters
+	// on a scan session. $1 is the session ID
+	InsertClustersHistoryQuery = `
+		INSERT INTO clusters_history (session_id,` + clustersHistoryColumns + `
+		)
+		SELECT $1::BIGINT,` + clustersHistoryColumns + `
+		FROM clusters
+		WHERE id = ANY($2)
+	`
+
+	// InsertInstancesHistoryQuery records the current state of a set of
+	// instances on a scan session. $1 is the session ID
+	InsertInstancesHistoryQuery = `
+		INSERT INTO instances_history (session_id,` + instancesHistoryColumns + `
+		)
+		SELECT $1::BIGINT,` + instancesHistoryColumns + `
+		FROM instances
+		WHERE id = ANY($2)
+	`
+
+	// InsertRefreshedClustersHistoryQuery records the clusters marked as
+	// Missing or Terminated by the inventory refresh, whose last recorded state
+	// was a different one or that have no recorded state
+	InsertRefreshedClustersHistoryQuery = `
+		INSERT INTO clusters_history (` + clustersHistoryColumns + `
+		)
+		SELECT` + clustersHistoryColumns + `
+		FROM clusters
+		WHERE status IN ('Missing', 'Terminated')
+			AND (
+				SELECT h.status FROM clusters_history h
+				WHERE h.id = clusters.id
+				ORDER BY h.recorded_at DESC
+				LIMIT 1
+			) IS DISTINCT FROM clusters.status
+	`
+
+	// InsertRefreshedInstancesHistoryQuery records the instances marked as
+	// Missing or Terminated by the inventory refresh, whose last recorded state
+	// was a different one or that have no recorded state
+	InsertRefreshedInstancesHistoryQuery = `
+		INSERT INTO instances_history (` + instancesHistoryColumns + `
+		)
+		SELECT` + instancesHistoryColumns + `
+		FROM instances
+		WHERE status IN ('Missing', 'Terminated')
+			AND (
+				SELECT h.status FROM instances_history h
+				WHERE h.id = instances.id
+				ORDER BY h.recorded_at DESC
+				LIMIT 1
+			) IS DISTINCT FROM instances.status
+	`
+
+	// clustersAtCTE selects the last recorded state of every cluster at the
+	// timestamp given by $1
+	clustersAtCTE = `
+		WITH clusters_at AS (
+			SELECT DISTINCT ON (id)` + clustersHistoryColumns + `
+			FROM clusters_history
+			WHERE recorded_at <= $1
+			ORDER BY id, recorded_at DESC
+		)
+	`
+
+	// instancesAtCTE selects the last recorded state of every instance at the
+	// timestamp given by $1
+	instancesAtCTE = `
+		WITH instances_at AS (
+			SELECT DISTINCT ON (id)` + instancesHistoryColumns + `
+			FROM instances_history
+			WHERE recorded_at <= $1
+			ORDER BY id, recorded_at DESC
+		)
+	`
+
+	// SelectClustersAtQuery returns every cluster as it was at a timestamp, ordered by Name
+	SelectClustersAtQuery = clustersAtCTE + `
+		SELECT * FROM clusters_at
+		ORDER BY name
+	`
+
+	// SelectInstancesAtQuery returns every instance as it was at a timestamp,
+	// with its current tags. Instances without tags are returned with an empty tag
+	SelectInstancesAtQuery = instancesAtCTE + `
+		SELECT
+			instances_at.*,
+			COALESCE(tags.key, '') AS key,
+			COALESCE(tags.value, '') AS value,
+			instances_at.id AS instance_id
+		FROM instances_at
+		LEFT JOIN tags ON instances_at.id = tags.instance_id
+		ORDER BY name
+	`
+
+	// SelectClustersOverviewAtQuery returns the number of clusters grouped by status at a timestamp
+	SelectClustersOverviewAtQuery = clustersAtCTE + `
+		SELECT
+			COUNT(CASE WHEN status = 'Running' THEN 1 END) AS running,
+			COUNT(CASE WHEN status = 'Stopped' THEN 1 END) AS stopped,
+			COUNT(CASE WHEN status = 'Missing' THEN 1 END) AS missing,
+			COUNT(CASE WHEN status = 'Terminated' THEN 1 END) AS archived
+		FROM clusters_at
+	`
+
+	// SelectInstancesOverviewAtQuery returns the total count of instances at a timestamp
+	SelectInstancesOverviewAtQuery = instancesAtCTE + `
+		SELECT COUNT(*) AS count FROM instances_at
+	`
+
+	// SelectProvidersOverviewAtQuery returns the account and cluster counts of
+	// every cloud provider at a timestamp. Only the accounts with recorded
+	// clusters are counted, and Terminated clusters are not counted
+	SelectProvidersOverviewAtQuery = clustersAtCTE + `
+		SELECT
+			provider,
+			COUNT(DISTINCT account_name) AS account_count,
+			COUNT(DISTINCT CASE WHEN status != 'Terminated' THEN id END) AS cluster_count
+		FROM clusters_at
+		WHERE provider != 'UNKNOWN'
+		GROUP BY provider
+		ORDER BY provider
+	`
+
+	// SelectScannerLastScanTimestampAtQuery returns the latest scan session timestamp before a timestamp
+	SelectScannerLastScanTimestampAtQuery = `
+		SELECT MAX(session_timestamp) AS last_scan_timestamp FROM scan_sessions
+		WHERE session_timestamp <= $1
+	`
+
+	// SetChangeSourceQuery sets the source of the cluster state transitions
+	// recorded during the current transaction
+	SetChangeSourceQuery = `SELECT set_config('cluster_iq.change_source', $1, true)`
+
+	// SelectClusterTimelineQuery returns the recorded state transitions of a
+	// cluster merged with the actions executed on it (audit logs), ordered by time
+	SelectClusterTimelineQuery = `
+		SELECT
+			transition_timestamp AS event_timestamp,
+			'Transition' AS category,
+			transition AS event_type,
+			source,
+			old_value,
+			new_value,
+			NULL::TEXT AS result,
+			NULL::TEXT AS description
+		FROM cluster_transitions
+		WHERE cluster_id = $1
+		UNION ALL
+		SELECT
+			event_timestamp,
+			CASE WHEN description LIKE 'ScheduledAction(%' THEN 'ScheduledAction' ELSE 'Action' END AS category,
+			action_name AS event_type,
+			triggered_by AS source,
+			NULL::TEXT AS old_value,
+			NULL::TEXT AS new_value,
+			result,
+			description
+		FROM audit_logs
+		WHERE resource_id = $1
+			AND resource_type = 'cluster'
+		ORDER BY event_timestamp, category
+	`
 )
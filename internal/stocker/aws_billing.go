@@ -1,16 +1,24 @@
 package stocker
 
 import (
-	"strconv"
+	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	cp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/costexplorer"
 	"go.uber.org/zap"
 )
 
+const (
+	// Number of days of expenses obtained on every scan. Cost Explorer only
+	// keeps the resource level costs of the last 14 days
+	billingPeriodDays = 14
+	// Value of the cluster tag on the resources owned by an OpenShift cluster
+	clusterOwnedTagValue = "owned"
+)
+
 // AWSBillingStocker object to obtain costs and expenses from AWS Cost Explorer API
 type AWSBillingStocker struct {
 	// Account to scan on this stocker
@@ -21,28 +29,30 @@ type AWSBillingStocker struct {
 	conn *cp.AWSConnection
 	// List of instances to obtain its expenses
 	Instances []inventory.Instance
+	// Cost allocation tag whose value is the InfraID or the name of the
+	// cluster owning each resource. When empty, the costs of every cluster
+	// are obtained with a query filtered by its own cluster tag
+	clusterCostTag string
+	// Cache limiting the queries per cluster. When nil, every cluster is queried
+	clusterCostsCache *ClusterCostsCache
 }
 
 // NewAWSBillingStocker create and returns a pointer to a new AWSBillingStocker instance
-func NewAWSBillingStocker(account *inventory.Account, logger *zap.Logger, instances []inventory.Instance) *AWSBillingStocker {
-	// Check if there are instances to get billing information
-	if len(instances) == 0 {
-		logger.Error("No instances to get billing information")
-		return nil
-	}
-
+func NewAWSBillingStocker(account *inventory.Account, role cp.AWSAssumeRoleConfig, logger *zap.Logger, instances []inventory.Instance, clusterCostTag string, clusterCostsCache *ClusterCostsCache) *AWSBillingStocker {
 	// Leaving the region empty forces to the AWSConnection to use the default region until a new one is configured
-	conn, err := cp.NewAWSConnection(account.GetUser(), account.GetPassword(), "", cp.WithCostExplorer())
+	conn, err := cp.NewAWSConnection(account.GetUser(), account.GetPassword(), role, "", cp.WithCostExplorer())
 	if err != nil {
 		logger.Error("Error creating a new AWSBillingStocker", zap.String("account", account.Name), zap.Error(err))
 		return nil
 	}
 
 	return &AWSBillingStocker{
-		Account:   account,
-		logger:    logger,
-		Instances: instances,
-		conn:      conn,
+		Account:           account,
+		logger:            logger,
+		Instances:         instances,
+		conn:              conn,
+		clusterCostTag:    clusterCostTag,
+		clusterCostsCache: clusterCostsCache,
 	}
 }
 
@@ -52,107 +62,244 @@ func (s *AWSBillingStocker) Connect() error {
 	return nil
 }
 
-// MakeStock implements the Stocker interface. It starts the Stocker main
-// process getting the expenses of the instances stored in the Stocker object
+// MakeStock implements the Stocker interface. It gets the daily costs of the
+// EC2 instances and the costs by service of the clusters of the account
 func (s *AWSBillingStocker) MakeStock() error {
-	for i := range s.Account.Clusters {
-		cluster := s.Account.Clusters[i]
-		for j := range cluster.Instances {
-			instance := &cluster.Instances[j]
-			for _, targetInstance := range s.Instances {
-				if targetInstance.ID == instance.ID {
-					err := s.getInstanceExpenses(instance)
-					if err != nil {
-						s.logger.Error("Error querying billing info for an instance",
-							zap.String("account", s.Account.Name),
-							zap.String("instance_id", instance.ID),
-							zap.String("reason", err.Error()),
-						)
-						// Continue to the next region even if an error occurs
-						continue
-					}
-					break
-				} else {
-					continue
-				}
-			}
+	return errors.Join(s.makeInstancesStock(), s.makeClustersStock())
+}
+
+// makeInstancesStock gets the daily costs of every EC2 instance of the
+// account with a single paginated Cost Explorer query, and distributes them
+// into the expenses of the instances stored in the Stocker object
+func (s *AWSBillingStocker) makeInstancesStock() error {
+	if len(s.Instances) == 0 {
+		s.logger.Debug("No instances to get billing information", zap.String("account", s.Account.Name))
+		return nil
+	}
+
+	targetInstances := make(map[string]bool, len(s.Instances))
+	for _, instance := range s.Instances {
+		targetInstances[instance.ID] = true
+	}
+
+	// The period ends today (excluded) and starts billingPeriodDays before
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -billingPeriodDays)
+
+	s.logger.Debug("Getting expenses for account instances",
+		zap.String("account", s.Account.Name),
+		zap.Int("instances", len(targetInstances)),
+		zap.Time("start_date", startDate),
+		zap.Time("end_date", endDate),
+	)
+
+	costs, err := s.conn.CostExplorer.GetEC2ResourcesDailyCosts(startDate, endDate)
+	if err != nil {
+		s.logger.Error("Error querying billing info for the account instances",
+			zap.String("account", s.Account.Name),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	var targetCosts []cp.DailyCost
+	instancesWithCosts := make(map[string]bool)
+	for _, cost := range costs {
+		if targetInstances[cost.Group] {
+			targetCosts = append(targetCosts, cost)
+			instancesWithCosts[cost.Group] = true
 		}
 	}
+	expenses := addInstancesExpenses(s.Account, targetCosts)
+
+	s.logger.Debug("Finished getting expenses for account instances",
+		zap.String("account", s.Account.Name),
+		zap.Int("instances_with_costs", len(instancesWithCosts)),
+		zap.Int("expenses", expenses),
+	)
 
 	return nil
 }
 
-// getInstanceExpenses gets from the AWS CostExplorer API the expenses of a given Instance.
-func (s *AWSBillingStocker) getInstanceExpenses(instance *inventory.Instance) error {
-	// Logic for Setting the period to fetch the Expenses within
-	// End date is equivalent to today's date
-	startDate := time.Now().AddDate(0, 0, -14).Format("2006-01-02")
-	// Start date is equivalent to today's date
-	endDate := time.Now().Format("2006-01-02")
+// makeClustersStock gets the daily costs by service of the resources of
+// every scanned cluster. This includes the costs of the instances and of the
+// rest of the cluster resources (volumes, load balancers, NAT gateways, data
+// transfer...). The costs are obtained since the first day of the previous
+// month. With a cluster cost tag, the costs of all the clusters are obtained
+// with a single query grouped by that tag; otherwise, with a query per
+// cluster filtered by its own tag, limited by the cluster costs cache
+func (s *AWSBillingStocker) makeClustersStock() error {
+	endDate := time.Now()
+	startDate := previousMonthStart(endDate)
 
-	s.logger.Debug("Getting expenses for instance",
-		zap.String("account", s.Account.Name),
-		zap.String("instance_id", instance.ID),
-		zap.String("start_date", startDate),
-		zap.String("end_date", endDate),
-	)
+	if s.clusterCostTag != "" {
+		return s.makeTaggedClustersStock(startDate, endDate)
+	}
+	return s.makeOwnedClustersStock(startDate, endDate)
+}
 
-	// Prepare the AWS Query input
-	input := &costexplorer.GetCostAndUsageWithResourcesInput{
-		TimePeriod: &costexplorer.DateInterval{
-			Start: aws.String(startDate),
-			End:   aws.String(endDate),
-		},
-		Granularity: aws.String("DAILY"),
-		Filter: &costexplorer.Expression{
-			Dimensions: &costexplorer.DimensionValues{
-				Key:    aws.String("RESOURCE_ID"),
-				Values: []*string{aws.String(instance.ID)},
-			},
-		},
-		Metrics: []*string{aws.String("UnblendedCost")},
-	}
-
-	// Fetch the Costs from AWS API
-	result, err := s.conn.CostExplorer.GetCostAndUsageWithResources(input)
+// makeTaggedClustersStock gets the daily costs by service of every value of
+// the cluster cost tag with a single paginated Cost Explorer query, and
+// distributes them into the expenses of the clusters whose InfraID or name is
+// the tag value. The costs of the tag values without a scanned cluster are
+// discarded
+func (s *AWSBillingStocker) makeTaggedClustersStock(startDate time.Time, endDate time.Time) error {
+	costs, err := s.conn.CostExplorer.GetTagValuesServicesDailyCosts(s.clusterCostTag, startDate, endDate)
 	if err != nil {
-		s.logger.Error("Error getting cost and usage with resources",
+		s.logger.Error("Error querying billing info for the account clusters",
 			zap.String("account", s.Account.Name),
-			zap.String("instance_id", instance.ID),
-			zap.Error(err))
+			zap.String("tag", s.clusterCostTag),
+			zap.Error(err),
+		)
 		return err
 	}
 
-	// for each cost add it to the instance Expenses
-	for _, resultByTime := range result.ResultsByTime {
-		if resultByTime.Total != nil {
-			if singleCost, ok := resultByTime.Total["UnblendedCost"]; ok {
-				// Getting Expense ammount as float64
-				amount, err := strconv.ParseFloat(*singleCost.Amount, 64)
-				if err != nil {
-					s.logger.Error("Error parsing cost amount",
-						zap.String("account", s.Account.Name),
-						zap.Float64("amount", amount),
-						zap.Error(err))
-					return err
-				}
+	expenses := addClustersExpenses(s.Account, costs)
 
-				// Getting Expense Date as Time
-				expenseDate, err := time.Parse(time.RFC3339, *resultByTime.TimePeriod.Start)
-				if err != nil {
-					s.logger.Error("Error parsing start date",
-						zap.String("account", s.Account.Name),
-						zap.String("start", *resultByTime.TimePeriod.Start),
-						zap.Error(err))
-					return err
-				}
+	s.logger.Debug("Finished getting expenses for account clusters",
+		zap.String("account", s.Account.Name),
+		zap.String("tag", s.clusterCostTag),
+		zap.Int("clusters", len(s.Account.Clusters)),
+		zap.Int("expenses", expenses),
+	)
+
+	return nil
+}
+
+// makeOwnedClustersStock gets the daily costs by service of every resource
+// tagged as owned by each scanned cluster (kubernetes.io/cluster/<infraID>=owned),
+// with a Cost Explorer query per cluster. Every query is billed, so the
+// clusters not selected by the cluster costs cache reuse their cached costs.
+// The clusters without InfraID are skipped, and the errors of a cluster don't
+// stop the rest
+func (s *AWSBillingStocker) makeOwnedClustersStock(startDate time.Time, endDate time.Time) error {
+	var clusterIDs []string
+	for id, cluster := range s.Account.Clusters {
+		if cluster.InfraID != "" {
+			clusterIDs = append(clusterIDs, id)
+		}
+	}
+	slices.Sort(clusterIDs)
+
+	toQuery := clusterIDs
+	if s.clusterCostsCache != nil {
+		toQuery = s.clusterCostsCache.clustersToQuery(clusterIDs, endDate)
+	}
+
+	var errs []error
+	expenses := 0
+	for _, id := range clusterIDs {
+		cluster := s.Account.Clusters[id]
+
+		var costs []cp.DailyCost
+		if slices.Contains(toQuery, id) {
+			tagKey := inventory.ClusterTagKey + cluster.InfraID
+			var err error
+			costs, err = s.conn.CostExplorer.GetTaggedServicesDailyCosts(tagKey, clusterOwnedTagValue, startDate, endDate)
+			if err != nil {
+				s.logger.Error("Error querying billing info for the cluster",
+					zap.String("account", s.Account.Name),
+					zap.String("cluster_id", cluster.ID),
+					zap.Error(err),
+				)
+				errs = append(errs, fmt.Errorf("cluster %s: %w", cluster.ID, err))
+				continue
+			}
+			if s.clusterCostsCache != nil {
+				s.clusterCostsCache.set(id, costs, endDate)
+			}
+		} else {
+			cached, ok := s.clusterCostsCache.get(id)
+			if !ok {
+				s.logger.Debug("Cluster costs postponed to the next scans",
+					zap.String("account", s.Account.Name),
+					zap.String("cluster_id", cluster.ID),
+				)
+				continue
+			}
+			costs = cached
+		}
 
-				instance.Expenses = append(instance.Expenses, *inventory.NewExpense(instance.ID, amount, expenseDate))
+		for _, cost := range costs {
+			if expense := inventory.NewClusterExpense(cluster.ID, cost.Group, cost.Amount, cost.Date); expense != nil {
+				cluster.AddExpense(*expense)
+				expenses++
 			}
 		}
 	}
 
-	return nil
+	s.logger.Debug("Finished getting expenses for account clusters",
+		zap.String("account", s.Account.Name),
+		zap.Int("clusters", len(s.Account.Clusters)),
+		zap.Int("queried_clusters", len(toQuery)),
+		zap.Int("expenses", expenses),
+	)
+
+	return errors.Join(errs...)
+}
+
+// addInstancesExpenses adds the daily costs to the expenses of the account
+// instances with the same ID. The negative daily costs (days where the
+// credits or refunds exceed the usage) are discarded. It returns the number
+// of added expenses
+func addInstancesExpenses(account *inventory.Account, costs []cp.DailyCost) int {
+	costsByInstance := make(map[string][]cp.DailyCost)
+	for _, cost := range costs {
+		costsByInstance[cost.Group] = append(costsByInstance[cost.Group], cost)
+	}
+
+	expenses := 0
+	for _, cluster := range account.Clusters {
+		for i := range cluster.Instances {
+			instance := &cluster.Instances[i]
+			for _, cost := range costsByInstance[instance.ID] {
+				if expense := inventory.NewExpense(instance.ID, cost.Amount, cost.Date); expense != nil {
+					instance.Expenses = append(instance.Expenses, *expense)
+					expenses++
+				}
+			}
+		}
+	}
+
+	return expenses
+}
+
+// addClustersExpenses adds the daily costs by service of every tag value to
+// the expenses of the account cluster whose InfraID or name is the tag value.
+// The InfraID takes precedence over the name, and the negative daily costs
+// are discarded. It returns the number of added expenses
+func addClustersExpenses(account *inventory.Account, costs []cp.TaggedDailyCost) int {
+	clustersByTag := make(map[string]*inventory.Cluster, len(account.Clusters))
+	for _, cluster := range account.Clusters {
+		if _, ok := clustersByTag[cluster.Name]; !ok {
+			clustersByTag[cluster.Name] = cluster
+		}
+	}
+	for _, cluster := range account.Clusters {
+		if cluster.InfraID != "" {
+			clustersByTag[cluster.InfraID] = cluster
+		}
+	}
+
+	expenses := 0
+	for _, cost := range costs {
+		cluster, ok := clustersByTag[cost.TagValue]
+		if !ok {
+			continue
+		}
+		if expense := inventory.NewClusterExpense(cluster.ID, cost.Group, cost.Amount, cost.Date); expense != nil {
+			cluster.AddExpense(*expense)
+			expenses++
+		}
+	}
+
+	return expenses
+}
+
+// previousMonthStart returns the first day (UTC) of the month before the specified date
+func previousMonthStart(date time.Time) time.Time {
+	date = date.UTC()
+	return time.Date(date.Year(), date.Month()-1, 1, 0, 0, 0, 0, time.UTC)
 }
 
 // PrintStock prints the stock (account) of the AWSBillingStocker as a string
@@ -164,3 +311,8 @@ func (s AWSBillingStocker) PrintStock() {
 func (s AWSBillingStocker) GetResults() inventory.Account {
 	return *s.Account
 }
+
+// GetThrottledRequests returns the number of AWS API requests throttled while making stock
+func (s *AWSBillingStocker) GetThrottledRequests() int64 {
+	return s.conn.GetThrottledRequests()
+}
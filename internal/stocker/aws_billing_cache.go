@@ -0,0 +1,77 @@
+package stocker
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	cp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
+)
+
+// ClusterCostsCache limits the Cost Explorer queries made per cluster when
+// the accounts don't have a cluster cost tag. Every cluster is queried at
+// most once per TTL, and at most maxQueries clusters are queried per account
+// on every scan. The rest of clusters reuse their last costs, if any
+type ClusterCostsCache struct {
+	ttl        time.Duration
+	maxQueries int
+	mutex      sync.Mutex
+	// Costs of every cluster by ID
+	entries map[string]clusterCostsEntry
+}
+
+// clusterCostsEntry contains the costs of a cluster and when they were obtained
+type clusterCostsEntry struct {
+	costs     []cp.DailyCost
+	timestamp time.Time
+}
+
+// NewClusterCostsCache returns a ClusterCostsCache keeping the costs during
+// ttl. maxQueries is the maximum number of clusters queried per account on
+// every scan, where zero disables the limit
+func NewClusterCostsCache(ttl time.Duration, maxQueries int) *ClusterCostsCache {
+	return &ClusterCostsCache{
+		ttl:        ttl,
+		maxQueries: maxQueries,
+		entries:    make(map[string]clusterCostsEntry),
+	}
+}
+
+// clustersToQuery returns the clusters whose costs must be obtained on the
+// current scan: the ones without costs or with expired costs, up to
+// maxQueries. The clusters are rotated every day, so the scans without a
+// running cache (e.g. one-shot scans) end up querying every cluster
+func (c *ClusterCostsCache) clustersToQuery(clusterIDs []string, now time.Time) []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var expired []string
+	for _, id := range clusterIDs {
+		if entry, ok := c.entries[id]; !ok || now.Sub(entry.timestamp) >= c.ttl {
+			expired = append(expired, id)
+		}
+	}
+	if c.maxQueries <= 0 || len(expired) <= c.maxQueries {
+		return expired
+	}
+
+	slices.Sort(expired)
+	days := int(now.Unix() / int64((24 * time.Hour).Seconds()))
+	offset := (days * c.maxQueries) % len(expired)
+	return slices.Concat(expired[offset:], expired[:offset])[:c.maxQueries]
+}
+
+// get returns the last costs obtained for the cluster
+func (c *ClusterCostsCache) get(clusterID string) ([]cp.DailyCost, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.entries[clusterID]
+	return entry.costs, ok
+}
+
+// set stores the costs obtained for the cluster
+func (c *ClusterCostsCache) set(clusterID string, costs []cp.DailyCost, now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries[clusterID] = clusterCostsEntry{costs: costs, timestamp: now}
+}
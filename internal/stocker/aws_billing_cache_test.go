@@ -0,0 +1,41 @@
+package stocker
+
+import (
+	"testing"
+	"time"
+
+	cp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestClusterCostsCacheClustersToQuery verifies only the clusters without fresh costs are queried, up to the maximum queries, rotating them every day
+func TestClusterCostsCacheClustersToQuery(t *testing.T) {
+	now := time.Date(2024, 5, 20, 10, 0, 0, 0, time.UTC)
+	cache := NewClusterCostsCache(24*time.Hour, 2)
+	clusterIDs := []string{"a", "b", "c", "d", "e"}
+
+	cache.set("a", []cp.DailyCost{{Group: "Amazon Simple Storage Service", Amount: 1}}, now.Add(-time.Hour))
+	cache.set("b", nil, now.Add(-24*time.Hour))
+
+	today := cache.clustersToQuery(clusterIDs, now)
+	assert.Len(t, today, 2)
+	assert.NotContains(t, today, "a")
+
+	// Every expired cluster is queried over the next days
+	queried := map[string]bool{}
+	for day := 0; day < 4; day++ {
+		for _, id := range cache.clustersToQuery(clusterIDs, now.AddDate(0, 0, day)) {
+			queried[id] = true
+		}
+	}
+	assert.Len(t, queried, 5)
+
+	costs, ok := cache.get("a")
+	assert.True(t, ok)
+	assert.Len(t, costs, 1)
+	_, ok = cache.get("c")
+	assert.False(t, ok)
+
+	unlimited := NewClusterCostsCache(24*time.Hour, 0)
+	assert.Equal(t, clusterIDs, unlimited.clustersToQuery(clusterIDs, now))
+}
@@ -0,0 +1,152 @@
+package stocker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	cp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// newCostExplorerTestStocker returns a billing stocker of the account whose
+// Cost Explorer requests are served by handler. It records the body of every
+// GetCostAndUsage request
+func newCostExplorerTestStocker(t *testing.T, account *inventory.Account, clusterCostTag string, handler func(body map[string]any) string) (*AWSBillingStocker, *[]map[string]any) {
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if r.Header.Get("X-Amz-Target") != "AWSInsightsIndexService.GetCostAndUsage" || json.NewDecoder(r.Body).Decode(&body) != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		requests = append(requests, body)
+
+		response := handler(body)
+		if response == "" {
+			w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type": "ValidationException", "message": "invalid request"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	sess, err := session.NewSession(aws.NewConfig().
+		WithCredentials(credentials.NewStaticCredentials("AKIAEXAMPLE", "secret", "")).
+		WithRegion(cp.DefaultAWSRegion).
+		WithEndpoint(server.URL).
+		WithMaxRetries(0))
+	assert.Nil(t, err)
+
+	stocker := &AWSBillingStocker{
+		Account:        account,
+		logger:         zap.NewNop(),
+		conn:           &cp.AWSConnection{CostExplorer: cp.NewAWSCostExplorerConnection(sess)},
+		clusterCostTag: clusterCostTag,
+	}
+	return stocker, &requests
+}
+
+// newClustersTestAccount returns an account with a cluster of every name and InfraID pair
+func newClustersTestAccount(t *testing.T, clusters ...[2]string) (*inventory.Account, []*inventory.Cluster) {
+	account := inventory.NewAccount("123456789012", "aws-account", inventory.AWSProvider, "user", "password")
+	var result []*inventory.Cluster
+	for _, c := range clusters {
+		cluster := inventory.NewCluster(c[0], c[1], inventory.AWSProvider, "us-east-1", account.Name, "", "")
+		assert.Nil(t, account.AddCluster(cluster))
+		result = append(result, cluster)
+	}
+	return account, result
+}
+
+// TestAWSBillingStockerTaggedClusters verifies the costs of every cluster are obtained with a single query grouped by the cluster cost tag, matching the InfraID or the name of the clusters
+func TestAWSBillingStockerTaggedClusters(t *testing.T) {
+	account, clusters := newClustersTestAccount(t, [2]string{"alpha", "alpha-abcde"}, [2]string{"beta", "beta-fghij"}, [2]string{"gamma", ""})
+	stocker, requests := newCostExplorerTestStocker(t, account, "cluster-name", func(map[string]any) string {
+		return `{"ResultsByTime": [{"TimePeriod": {"Start": "2024-05-01", "End": "2024-05-02"}, "Groups": [
+			{"Keys": ["cluster-name$alpha-abcde", "Amazon Elastic Compute Cloud - Compute"], "Metrics": {"UnblendedCost": {"Amount": "10.5", "Unit": "USD"}}},
+			{"Keys": ["cluster-name$alpha-abcde", "Tax"], "Metrics": {"UnblendedCost": {"Amount": "-1", "Unit": "USD"}}},
+			{"Keys": ["cluster-name$beta", "Amazon Simple Storage Service"], "Metrics": {"UnblendedCost": {"Amount": "0.75", "Unit": "USD"}}},
+			{"Keys": ["cluster-name$deleted-klmno", "Amazon Elastic Load Balancing"], "Metrics": {"UnblendedCost": {"Amount": "3", "Unit": "USD"}}},
+			{"Keys": ["cluster-name$", "Amazon Route 53"], "Metrics": {"UnblendedCost": {"Amount": "0.5", "Unit": "USD"}}}
+		]}]}`
+	})
+
+	assert.Nil(t, stocker.MakeStock())
+	assert.Len(t, *requests, 1)
+
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []inventory.ClusterExpense{
+		{ClusterID: clusters[0].ID, Service: "Amazon Elastic Compute Cloud - Compute", Amount: 10.5, Date: date},
+	}, clusters[0].Expenses)
+	assert.Equal(t, []inventory.ClusterExpense{
+		{ClusterID: clusters[1].ID, Service: "Amazon Simple Storage Service", Amount: 0.75, Date: date},
+	}, clusters[1].Expenses)
+	assert.Empty(t, clusters[2].Expenses)
+}
+
+// TestAWSBillingStockerOwnedClusters verifies that without a cluster cost tag every cluster with InfraID is queried by its own tag, and the errors of a cluster don't stop the rest
+func TestAWSBillingStockerOwnedClusters(t *testing.T) {
+	account, clusters := newClustersTestAccount(t, [2]string{"alpha", "alpha-abcde"}, [2]string{"beta", "beta-fghij"}, [2]string{"gamma", ""})
+	stocker, requests := newCostExplorerTestStocker(t, account, "", func(body map[string]any) string {
+		filter, _ := json.Marshal(body["Filter"])
+		if strings.Contains(string(filter), "beta-fghij") {
+			return ""
+		}
+		return `{"ResultsByTime": [{"TimePeriod": {"Start": "2024-05-01", "End": "2024-05-02"}, "Groups": [
+			{"Keys": ["Amazon Elastic Compute Cloud - Compute"], "Metrics": {"UnblendedCost": {"Amount": "10.5", "Unit": "USD"}}}
+		]}]}`
+	})
+
+	err := stocker.MakeStock()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), clusters[1].ID)
+	assert.Len(t, *requests, 2)
+
+	var filters []any
+	for _, request := range *requests {
+		filters = append(filters, request["Filter"])
+	}
+	assert.Contains(t, filters, map[string]any{"Tags": map[string]any{"Key": "kubernetes.io/cluster/alpha-abcde", "Values": []any{"owned"}}})
+	assert.Equal(t, []inventory.ClusterExpense{
+		{ClusterID: clusters[0].ID, Service: "Amazon Elastic Compute Cloud - Compute", Amount: 10.5, Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+	}, clusters[0].Expenses)
+	assert.Empty(t, clusters[1].Expenses)
+	assert.Empty(t, clusters[2].Expenses)
+}
+
+// TestAWSBillingStockerOwnedClustersCache verifies the clusters not queried on a scan reuse their cached costs, and the clusters without cached costs are postponed
+func TestAWSBillingStockerOwnedClustersCache(t *testing.T) {
+	account, clusters := newClustersTestAccount(t, [2]string{"alpha", "alpha-abcde"}, [2]string{"beta", "beta-fghij"})
+	stocker, requests := newCostExplorerTestStocker(t, account, "", func(map[string]any) string {
+		return `{"ResultsByTime": [{"TimePeriod": {"Start": "2024-05-01", "End": "2024-05-02"}, "Groups": [
+			{"Keys": ["Amazon Elastic Compute Cloud - Compute"], "Metrics": {"UnblendedCost": {"Amount": "10.5", "Unit": "USD"}}}
+		]}]}`
+	})
+	stocker.clusterCostsCache = NewClusterCostsCache(24*time.Hour, 1)
+
+	// Only one cluster is queried, and the other one has no cached costs yet
+	assert.Nil(t, stocker.makeClustersStock())
+	assert.Len(t, *requests, 1)
+	assert.Equal(t, 1, len(clusters[0].Expenses)+len(clusters[1].Expenses))
+
+	// The queried cluster reuses its cached costs while the other one is queried
+	for _, cluster := range clusters {
+		cluster.Expenses = nil
+	}
+	assert.Nil(t, stocker.makeClustersStock())
+	assert.Len(t, *requests, 2)
+	assert.Len(t, clusters[0].Expenses, 1)
+	assert.Len(t, clusters[1].Expenses, 1)
+}
@@ -0,0 +1,268 @@
+package stocker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	cp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"go.uber.org/zap"
+)
+
+// AWSCURBillingStocker object to obtain the expenses of the instances from
+// AWS Cost and Usage Report (CUR) files. It's an alternative to the Cost
+// Explorer API for the accounts exporting CUR, as it's not limited to the
+// last 14 days and it doesn't require the resource level data to be enabled
+type AWSCURBillingStocker struct {
+	// Account to scan on this stocker
+	Account *inventory.Account
+	// Stocker Logger
+	logger *zap.Logger
+	// Local path (file or directory) or S3 URL (s3://<bucket>/<prefix>) of the CUR files
+	curPath string
+	// AWS connection for downloading the CUR files. Nil for local paths
+	conn *cp.AWSConnection
+}
+
+// NewAWSCURBillingStocker create and returns a pointer to a new
+// AWSCURBillingStocker instance. The S3 bucket is accessed with the account
+// credentials on the specified region (the default one if it's empty)
+func NewAWSCURBillingStocker(account *inventory.Account, role cp.AWSAssumeRoleConfig, curPath string, region string, logger *zap.Logger) (*AWSCURBillingStocker, error) {
+	stocker := &AWSCURBillingStocker{
+		Account: account,
+		logger:  logger,
+		curPath: curPath,
+	}
+
+	if _, _, ok := cp.ParseS3URL(curPath); ok {
+		conn, err := cp.NewAWSConnection(account.GetUser(), account.GetPassword(), role, region, cp.WithS3())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AWS connection: %w", err)
+		}
+		stocker.conn = conn
+	}
+
+	return stocker, nil
+}
+
+// MakeStock implements the Stocker interface. It reads the CUR files of the
+// latest assembly of every billing period since the first day of the previous
+// month, and adds the daily costs of the account instances to their expenses.
+// The assemblies are found through the CUR manifests. When the configured
+// path has no manifests, every CUR file is read. The files that can't be read
+// are reported, but they don't stop the rest
+func (s *AWSCURBillingStocker) MakeStock() error {
+	targetInstances := make(map[string]bool)
+	for _, cluster := range s.Account.Clusters {
+		for _, instance := range cluster.Instances {
+			targetInstances[instance.ID] = true
+		}
+	}
+	if len(targetInstances) == 0 {
+		s.logger.Debug("No instances to get billing information", zap.String("account", s.Account.Name))
+		return nil
+	}
+
+	startDate := previousMonthStart(time.Now())
+	report := cp.NewCURReport(startDate, func(resourceID string) bool { return targetInstances[resourceID] })
+
+	s.logger.Debug("Reading CUR files for account instances",
+		zap.String("account", s.Account.Name),
+		zap.String("cur_path", s.curPath),
+		zap.Int("instances", len(targetInstances)),
+		zap.Time("start_date", startDate),
+	)
+
+	var files int
+	var err error
+	if bucket, prefix, ok := cp.ParseS3URL(s.curPath); ok {
+		files, err = s.readS3Files(report, bucket, prefix, startDate)
+	} else {
+		files, err = s.readLocalFiles(report, startDate)
+	}
+
+	expenses := addInstancesExpenses(s.Account, report.DailyCosts())
+
+	s.logger.Debug("Finished reading CUR files for account instances",
+		zap.String("account", s.Account.Name),
+		zap.Int("files", files),
+		zap.Int("expenses", expenses),
+	)
+
+	return err
+}
+
+// readLocalFiles reads the CUR files of a local file or directory, which is
+// a copy of the CUR bucket. It returns the number of files read
+func (s *AWSCURBillingStocker) readLocalFiles(report *cp.CURReport, since time.Time) (int, error) {
+	var errs []error
+	var manifests, curFiles []string
+	err := filepath.WalkDir(s.curPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+		case cp.IsCURManifest(entry.Name()):
+			manifests = append(manifests, filePath)
+		case cp.IsCURFile(entry.Name()):
+			curFiles = append(curFiles, filePath)
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("Error listing CUR files on %s: %w", s.curPath, err))
+	}
+
+	if len(manifests) > 0 {
+		curFiles = nil
+		for _, manifestPath := range manifests {
+			manifest, err := readLocalCURManifest(manifestPath)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if manifest.IsAssemblyCopy(filepath.ToSlash(manifestPath)) || !manifest.EndsAfter(since) {
+				continue
+			}
+			for _, key := range manifest.Keys() {
+				curFiles = append(curFiles, localCURFile(manifestPath, key))
+			}
+		}
+	}
+
+	files := 0
+	for _, filePath := range curFiles {
+		if err := report.ReadFile(filePath); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		files++
+	}
+
+	return files, errors.Join(errs...)
+}
+
+// readLocalCURManifest reads a local CUR manifest
+func readLocalCURManifest(manifestPath string) (*cp.CURManifest, error) {
+	file, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	manifest, err := cp.ReadCURManifest(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", manifestPath, err)
+	}
+	return manifest, nil
+}
+
+// localCURFile returns the local path of a CUR file from its S3 key. The key
+// is resolved from the directory of the manifest, as the local copy of the
+// bucket can start on any level of its prefix
+func localCURFile(manifestPath string, key string) string {
+	dir := filepath.Dir(manifestPath)
+	_, relativePath, ok := strings.Cut("/"+key, "/"+filepath.Base(dir)+"/")
+	if !ok {
+		relativePath = path.Base(key)
+	}
+	return filepath.Join(dir, filepath.FromSlash(relativePath))
+}
+
+// readS3Files downloads and reads the CUR files of an S3 bucket prefix. Every
+// file is downloaded into a temporary file, which is removed once it's read.
+// It returns the number of files read
+func (s *AWSCURBillingStocker) readS3Files(report *cp.CURReport, bucket string, prefix string, since time.Time) (int, error) {
+	keys, err := s.conn.S3.ListObjectKeys(bucket, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	var manifests, curKeys []string
+	for _, key := range keys {
+		switch {
+		case cp.IsCURManifest(key):
+			manifests = append(manifests, key)
+		case cp.IsCURFile(key):
+			curKeys = append(curKeys, key)
+		}
+	}
+
+	if len(manifests) > 0 {
+		curKeys = nil
+		for _, manifestKey := range manifests {
+			var content bytes.Buffer
+			if err := s.conn.S3.DownloadObject(bucket, manifestKey, &content); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			manifest, err := cp.ReadCURManifest(&content)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", manifestKey, err))
+				continue
+			}
+			if manifest.IsAssemblyCopy(manifestKey) || !manifest.EndsAfter(since) {
+				continue
+			}
+			curKeys = append(curKeys, manifest.Keys()...)
+		}
+	}
+
+	files := 0
+	for _, key := range curKeys {
+		if err := s.readS3File(report, bucket, key); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		files++
+	}
+
+	return files, errors.Join(errs...)
+}
+
+// readS3File downloads and reads a CUR file of an S3 bucket
+func (s *AWSCURBillingStocker) readS3File(report *cp.CURReport, bucket string, key string) error {
+	// Keeping the object name, as the file format is chosen by its extension
+	file, err := os.CreateTemp("", "cluster-iq-cur-*-"+path.Base(key))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	err = s.conn.S3.DownloadObject(bucket, key, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return report.ReadFile(file.Name())
+}
+
+// PrintStock prints the stock (account) of the AWSCURBillingStocker as a string
+func (s AWSCURBillingStocker) PrintStock() {
+	s.Account.PrintAccount()
+}
+
+// GetResults returns the account configured for this stocker
+func (s AWSCURBillingStocker) GetResults() inventory.Account {
+	return *s.Account
+}
+
+// GetThrottledRequests returns the number of AWS API requests throttled while making stock
+func (s *AWSCURBillingStocker) GetThrottledRequests() int64 {
+	if s.conn == nil {
+		return 0
+	}
+	return s.conn.GetThrottledRequests()
+}
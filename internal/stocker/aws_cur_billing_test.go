@@ -0,0 +1,189 @@
+package stocker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	cp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// newCURTestAccount returns an account with a single cluster and instance for the CUR billing stockers
+func newCURTestAccount(t *testing.T) (*inventory.Account, *inventory.Cluster) {
+	account := inventory.NewAccount("123456789012", "aws-account", inventory.AWSProvider, "user", "password")
+	cluster := inventory.NewCluster("cluster", "cluster-abcde", inventory.AWSProvider, "us-east-1", account.Name, "", "")
+	assert.Nil(t, cluster.AddInstance(*inventory.NewInstance("i-0001", "master-0", inventory.AWSProvider, "m5.xlarge", "us-east-1a", inventory.Running, cluster.ID, nil, time.Now())))
+	assert.Nil(t, account.AddCluster(cluster))
+	return account, cluster
+}
+
+// curBillingPeriod returns the CUR billing period directory name and the manifest dates of the month of the date
+func curBillingPeriod(date time.Time) (string, string, string) {
+	start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	return start.Format("20060102") + "-" + end.Format("20060102"), start.Format("20060102T150405.000Z"), end.Format("20060102T150405.000Z")
+}
+
+// newCURManifest returns a legacy CUR manifest of the billing period of the date
+func newCURManifest(date time.Time, assemblyID string, reportKeys ...string) string {
+	_, start, end := curBillingPeriod(date)
+	keys, _ := json.Marshal(reportKeys)
+	return fmt.Sprintf(`{"assemblyId":%q,"billingPeriod":{"start":%q,"end":%q},"reportKeys":%s}`, assemblyID, start, end, keys)
+}
+
+// newCURAssemblies returns the CUR objects (key and content) of a report
+// with two assemblies on the current billing period and an old billing
+// period, which can't be read. The latest assembly includes credits netting
+// a day to zero and another one below zero
+func newCURAssemblies() map[string]string {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	period, _, _ := curBillingPeriod(today)
+	oldDate := today.AddDate(0, -3, 0)
+	oldPeriod, _, _ := curBillingPeriod(oldDate)
+	header := "lineItem/UsageStartDate,lineItem/ResourceId,lineItem/UnblendedCost\n"
+	line := func(date time.Time, cost string) string {
+		return date.Format(time.RFC3339) + ",i-0001," + cost + "\n"
+	}
+
+	return map[string]string{
+		"cur/report/" + period + "/report-Manifest.json":       newCURManifest(today, "asm-2", "cur/report/"+period+"/asm-2/report-1.csv"),
+		"cur/report/" + period + "/asm-1/report-Manifest.json": newCURManifest(today, "asm-1", "cur/report/"+period+"/asm-1/report-1.csv"),
+		"cur/report/" + period + "/asm-1/report-1.csv":         header + line(today, "5"),
+		"cur/report/" + period + "/asm-2/report-Manifest.json": newCURManifest(today, "asm-2", "cur/report/"+period+"/asm-2/report-1.csv"),
+		"cur/report/" + period + "/asm-2/report-1.csv":         header + line(today, "1.5") + line(today.Add(time.Hour), "0.5") + line(today.AddDate(0, 0, -1), "0.1") + line(today.AddDate(0, 0, -1), "0.2") + line(today.AddDate(0, 0, -1), "-0.3") + line(today.AddDate(0, 0, -2), "1") + line(today.AddDate(0, 0, -2), "-3"),
+		"cur/report/" + oldPeriod + "/report-Manifest.json":    newCURManifest(oldDate, "asm-0", "cur/report/"+oldPeriod+"/asm-0/report-1.csv"),
+		"cur/report/" + oldPeriod + "/asm-0/report-1.csv":      "not a CUR file",
+	}
+}
+
+// assertCURAssembliesExpenses verifies the expenses come only from the latest assembly, without negative costs
+func assertCURAssembliesExpenses(t *testing.T, account *inventory.Account, cluster *inventory.Cluster) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	assert.Equal(t, []inventory.Expense{
+		*inventory.NewExpense("i-0001", 0, today.AddDate(0, 0, -1)),
+		*inventory.NewExpense("i-0001", 2, today),
+	}, account.Clusters[cluster.ID].Instances[0].Expenses)
+}
+
+// TestAWSCURBillingStockerLocalPath verifies the expenses of the account instances are obtained from the local CUR files
+func TestAWSCURBillingStockerLocalPath(t *testing.T) {
+	account, cluster := newCURTestAccount(t)
+
+	// The CUR files are organized by billing period. Other files are ignored
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	period, _, _ := curBillingPeriod(today)
+	periodDir := filepath.Join(t.TempDir(), "report", period)
+	assert.Nil(t, os.MkdirAll(periodDir, 0o700))
+	csv := fmt.Sprintf("lineItem/UsageStartDate,lineItem/ResourceId,lineItem/UnblendedCost\n%s,i-0001,1.5\n%s,i-0001,0.5\n%s,i-0002,3\n",
+		today.Format(time.RFC3339), today.Add(time.Hour).Format(time.RFC3339), today.Format(time.RFC3339))
+	assert.Nil(t, os.WriteFile(filepath.Join(periodDir, "report-1.csv"), []byte(csv), 0o600))
+	assert.Nil(t, os.WriteFile(filepath.Join(periodDir, "report-Manifest.json"), []byte(newCURManifest(today, "", "cur/report/"+period+"/report-1.csv")), 0o600))
+	assert.Nil(t, os.WriteFile(filepath.Join(periodDir, "notes.txt"), []byte("other file"), 0o600))
+
+	stocker, err := NewAWSCURBillingStocker(account, cp.AWSAssumeRoleConfig{}, filepath.Dir(periodDir), "", zap.NewNop())
+	assert.Nil(t, err)
+	assert.Nil(t, stocker.MakeStock())
+
+	instance := account.Clusters[cluster.ID].Instances[0]
+	assert.Equal(t, []inventory.Expense{*inventory.NewExpense("i-0001", 2, today)}, instance.Expenses)
+	assert.Equal(t, int64(0), stocker.GetThrottledRequests())
+}
+
+// TestAWSCURBillingStockerLocalAssemblies verifies only the latest assembly of the recent billing periods is read from the local CUR files
+func TestAWSCURBillingStockerLocalAssemblies(t *testing.T) {
+	account, cluster := newCURTestAccount(t)
+
+	dir := t.TempDir()
+	for key, content := range newCURAssemblies() {
+		filePath := filepath.Join(dir, filepath.FromSlash(key))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0o700))
+		assert.Nil(t, os.WriteFile(filePath, []byte(content), 0o600))
+	}
+
+	// The local copy starts on the report directory
+	stocker, err := NewAWSCURBillingStocker(account, cp.AWSAssumeRoleConfig{}, filepath.Join(dir, "cur", "report"), "", zap.NewNop())
+	assert.Nil(t, err)
+	assert.Nil(t, stocker.MakeStock())
+	assertCURAssembliesExpenses(t, account, cluster)
+}
+
+// TestAWSCURBillingStockerS3Assemblies verifies only the manifests and the latest assembly of the recent billing periods are downloaded from S3
+func TestAWSCURBillingStockerS3Assemblies(t *testing.T) {
+	account, cluster := newCURTestAccount(t)
+	objects := newCURAssemblies()
+
+	var downloaded []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/cur-bucket/")
+		if r.URL.Path == "/cur-bucket/" || r.URL.Path == "/cur-bucket" {
+			fmt.Fprint(w, `<ListBucketResult><IsTruncated>false</IsTruncated>`)
+			for key := range objects {
+				fmt.Fprintf(w, `<Contents><Key>%s</Key></Contents>`, key)
+			}
+			fmt.Fprint(w, `</ListBucketResult>`)
+			return
+		}
+		content, ok := objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		downloaded = append(downloaded, key)
+		fmt.Fprint(w, content)
+	}))
+	defer server.Close()
+
+	sess, err := session.NewSession(aws.NewConfig().
+		WithCredentials(credentials.NewStaticCredentials("AKIAEXAMPLE", "secret", "")).
+		WithRegion(cp.DefaultAWSRegion).
+		WithEndpoint(server.URL).
+		WithS3ForcePathStyle(true).
+		WithMaxRetries(0))
+	assert.Nil(t, err)
+
+	stocker := &AWSCURBillingStocker{
+		Account: account,
+		logger:  zap.NewNop(),
+		curPath: "s3://cur-bucket/cur/",
+		conn:    &cp.AWSConnection{S3: cp.NewAWSS3Connection(sess)},
+	}
+	assert.Nil(t, stocker.MakeStock())
+	assertCURAssembliesExpenses(t, account, cluster)
+
+	period, _, _ := curBillingPeriod(time.Now().UTC())
+	assert.Contains(t, downloaded, "cur/report/"+period+"/asm-2/report-1.csv")
+	assert.NotContains(t, downloaded, "cur/report/"+period+"/asm-1/report-1.csv")
+	for _, key := range downloaded {
+		assert.True(t, cp.IsCURManifest(key) || strings.Contains(key, "/asm-2/"), key)
+	}
+}
+
+// TestAWSCURBillingStockerInvalidFile verifies the unreadable files are reported without discarding the rest
+func TestAWSCURBillingStockerInvalidFile(t *testing.T) {
+	account := inventory.NewAccount("123456789012", "aws-account", inventory.AWSProvider, "user", "password")
+	cluster := inventory.NewCluster("cluster", "cluster-abcde", inventory.AWSProvider, "us-east-1", account.Name, "", "")
+	assert.Nil(t, cluster.AddInstance(*inventory.NewInstance("i-0001", "master-0", inventory.AWSProvider, "m5.xlarge", "us-east-1a", inventory.Running, cluster.ID, nil, time.Now())))
+	assert.Nil(t, account.AddCluster(cluster))
+
+	dir := t.TempDir()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "report-1.csv"), []byte("lineItem/UsageStartDate,lineItem/ResourceId,lineItem/UnblendedCost\n"+today.Format(time.RFC3339)+",i-0001,1\n"), 0o600))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "report-2.parquet"), []byte("not a parquet file"), 0o600))
+
+	stocker, err := NewAWSCURBillingStocker(account, cp.AWSAssumeRoleConfig{}, dir, "", zap.NewNop())
+	assert.Nil(t, err)
+	assert.NotNil(t, stocker.MakeStock())
+	assert.Len(t, account.Clusters[cluster.ID].Instances[0].Expenses, 1)
+}
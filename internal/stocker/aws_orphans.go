@@ -0,0 +1,143 @@
+package stocker
+
+import (
+	"fmt"
+	"time"
+
+	cp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"go.uber.org/zap"
+)
+
+// Resource types that can remain on the account after a cluster was removed
+var orphanRegionalResourceTypes = []string{
+	cp.TaggingLoadBalancerType,
+	cp.TaggingVolumeType,
+	cp.TaggingSecurityGroupType,
+}
+
+// AWSOrphanStocker object to look for the resources that remain on an AWS
+// account after its cluster was terminated. The resources are found by the
+// cluster tag that openshift-installer applies to every cluster resource
+type AWSOrphanStocker struct {
+	// Account to scan on this stocker
+	Account *inventory.Account
+	// Stocker Logger
+	logger *zap.Logger
+	// AWS connection interface
+	conn *cp.AWSConnection
+	// List of terminated clusters to look for orphaned resources
+	Clusters []inventory.Cluster
+	// Orphaned resources found on the last MakeStock execution
+	Orphans []inventory.Resource
+	// IDs of the clusters checked correctly on the last MakeStock execution
+	CheckedClusters []string
+}
+
+// NewAWSOrphanStocker create and returns a pointer to a new AWSOrphanStocker instance
+func NewAWSOrphanStocker(account *inventory.Account, role cp.AWSAssumeRoleConfig, logger *zap.Logger, clusters []inventory.Cluster) (*AWSOrphanStocker, error) {
+	// Leaving the region empty forces to the AWSConnection to use the default region until a new one is configured
+	conn, err := cp.NewAWSConnection(account.GetUser(), account.GetPassword(), role, "", cp.WithTagging())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS connection: %w", err)
+	}
+
+	return &AWSOrphanStocker{
+		Account:  account,
+		logger:   logger,
+		conn:     conn,
+		Clusters: clusters,
+	}, nil
+}
+
+// MakeStock implements the Stocker interface. It looks for the resources
+// still tagged as part of every terminated cluster of the stocker
+func (s *AWSOrphanStocker) MakeStock() error {
+	s.Orphans = make([]inventory.Resource, 0)
+	s.CheckedClusters = make([]string, 0, len(s.Clusters))
+
+	for _, cluster := range s.Clusters {
+		if !s.Account.IsRegionEnabled(cluster.Region) {
+			continue
+		}
+
+		orphans, err := s.getClusterOrphans(cluster)
+		if err != nil {
+			s.logger.Error("Error looking for orphaned resources",
+				zap.String("account", s.Account.Name),
+				zap.String("cluster_id", cluster.ID),
+				zap.Error(err),
+			)
+			// Continue to the next cluster even if an error occurs
+			continue
+		}
+
+		if len(orphans) > 0 {
+			s.logger.Warn("Orphaned resources found for terminated cluster",
+				zap.String("account", s.Account.Name),
+				zap.String("cluster_id", cluster.ID),
+				zap.Int("orphans", len(orphans)),
+			)
+		}
+		s.Orphans = append(s.Orphans, orphans...)
+		s.CheckedClusters = append(s.CheckedClusters, cluster.ID)
+	}
+
+	return nil
+}
+
+// getClusterOrphans returns the Load Balancers, Volumes and Security Groups
+// on the cluster region, and the Hosted Zones tagged with the cluster tag
+func (s *AWSOrphanStocker) getClusterOrphans(cluster inventory.Cluster) ([]inventory.Resource, error) {
+	tagKey := inventory.ClusterTagKey + cluster.Name + "-" + cluster.InfraID
+
+	if err := s.conn.SetRegion(cluster.Region); err != nil {
+		return nil, err
+	}
+	orphans, err := s.conn.Tagging.GetResourcesByTagKey(tagKey, orphanRegionalResourceTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Route53 is a global service that can only be queried from its own region
+	if err := s.conn.SetRegion(cp.TaggingGlobalRegion); err != nil {
+		return nil, err
+	}
+	zones, err := s.conn.Tagging.GetResourcesByTagKey(tagKey, []string{cp.TaggingHostedZoneType})
+	if err != nil {
+		return nil, err
+	}
+	orphans = append(orphans, zones...)
+
+	// Linking the orphans to the terminated cluster. They're stamped as
+	// scanned, so the inventory refresh keeps them while they're found
+	now := time.Now()
+	for i := range orphans {
+		orphans[i].ClusterID = cluster.ID
+		orphans[i].LastScanTimestamp = now
+	}
+
+	return orphans, nil
+}
+
+// GetOrphans returns the orphaned resources found by the stocker
+func (s AWSOrphanStocker) GetOrphans() []inventory.Resource {
+	return s.Orphans
+}
+
+// GetCheckedClusters returns the IDs of the clusters checked by the stocker
+func (s AWSOrphanStocker) GetCheckedClusters() []string {
+	return s.CheckedClusters
+}
+
+// PrintStock prints the orphaned resources found by the stocker
+func (s AWSOrphanStocker) PrintStock() {
+	for _, orphan := range s.Orphans {
+		fmt.Printf("Orphan: %s (%s) Cluster: %s Region: %s\n", orphan.ID, orphan.Type, orphan.ClusterID, orphan.Region)
+	}
+}
+
+// GetResults returns the Account scanned on this stocker
+func (s AWSOrphanStocker) GetResults() inventory.Account {
+	return *s.Account
+}
@@ -0,0 +1,122 @@
+package stocker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeAWSTransport serves every AWS request with the handler, whatever the
+// endpoint of its region
+type fakeAWSTransport struct {
+	handler http.Handler
+}
+
+func (f fakeAWSTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	f.handler.ServeHTTP(recorder, r)
+	return recorder.Result(), nil
+}
+
+// useFakeAWSTransport sends the requests of the AWS connections to the
+// handler during the test. The connections create a new session on every
+// region change, so the requests are intercepted on the default HTTP client
+// used by the sessions
+func useFakeAWSTransport(t *testing.T, handler http.Handler) {
+	// A custom CA bundle can only be loaded into the default transport
+	t.Setenv("AWS_CA_BUNDLE", "")
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = fakeAWSTransport{handler: handler}
+	t.Cleanup(func() { http.DefaultClient.Transport = transport })
+}
+
+// taggingRequest is a GetResources request received by the fake Tagging API
+type taggingRequest struct {
+	region        string
+	tagKey        string
+	resourceTypes []string
+}
+
+// fakeOrphansTaggingAPI returns the ARNs tagged with every tag key on every
+// region. The tag keys without ARNs fail
+type fakeOrphansTaggingAPI struct {
+	arns     map[string]map[string][]string
+	requests []taggingRequest
+}
+
+func (f *fakeOrphansTaggingAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		TagFilters          []struct{ Key string }
+		ResourceTypeFilters []string
+	}
+	if r.Header.Get("X-Amz-Target") != "ResourceGroupsTaggingAPI_20170126.GetResources" || json.NewDecoder(r.Body).Decode(&body) != nil || len(body.TagFilters) != 1 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	// Regional endpoints look like tagging.<region>.amazonaws.com
+	region := strings.Split(r.URL.Host, ".")[1]
+	tagKey := body.TagFilters[0].Key
+	f.requests = append(f.requests, taggingRequest{region: region, tagKey: tagKey, resourceTypes: body.ResourceTypeFilters})
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	arns, ok := f.arns[tagKey]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type": "InvalidParameterException", "message": "invalid request"}`))
+		return
+	}
+
+	mappings := []map[string]any{}
+	for _, arn := range arns[region] {
+		mappings = append(mappings, map[string]any{"ResourceARN": arn, "Tags": []any{map[string]any{"Key": tagKey, "Value": "owned"}}})
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"ResourceTagMappingList": mappings})
+}
+
+// TestAWSOrphanStocker verifies the resources tagged as part of every terminated cluster are looked for on its region and the Hosted Zones on the global region, skipping the clusters of disabled regions and keeping the rest of clusters when one fails
+func TestAWSOrphanStocker(t *testing.T) {
+	account := inventory.NewAccount("123456789012", "aws-account", inventory.AWSProvider, "AKIAEXAMPLE", "secret")
+	account.SetRegionFilter(nil, []string{"ap-south-1"})
+	alpha := *inventory.NewCluster("alpha", "abcde", inventory.AWSProvider, "eu-west-1", account.Name, "", "")
+	beta := *inventory.NewCluster("beta", "fghij", inventory.AWSProvider, "eu-west-1", account.Name, "", "")
+	gamma := *inventory.NewCluster("gamma", "klmno", inventory.AWSProvider, "ap-south-1", account.Name, "", "")
+
+	api := &fakeOrphansTaggingAPI{arns: map[string]map[string][]string{
+		inventory.ClusterTagKey + "alpha-abcde": {
+			"eu-west-1": {"arn:aws:ec2:eu-west-1:123456789012:volume/vol-0001"},
+			"us-east-1": {"arn:aws:route53:::hostedzone/Z0001"},
+		},
+	}}
+	useFakeAWSTransport(t, api)
+
+	stocker, err := NewAWSOrphanStocker(account, cp.AWSAssumeRoleConfig{}, zap.NewNop(), []inventory.Cluster{alpha, beta, gamma})
+	assert.Nil(t, err)
+	assert.Nil(t, stocker.MakeStock())
+
+	assert.Equal(t, []string{alpha.ID}, stocker.GetCheckedClusters())
+	orphans := stocker.GetOrphans()
+	if assert.Len(t, orphans, 2) {
+		assert.Equal(t, "vol-0001", orphans[0].ID)
+		assert.Equal(t, inventory.VolumeResourceType, orphans[0].Type)
+		assert.Equal(t, "Z0001", orphans[1].ID)
+		assert.Equal(t, inventory.HostedZoneResourceType, orphans[1].Type)
+		for _, orphan := range orphans {
+			assert.Equal(t, alpha.ID, orphan.ClusterID)
+			assert.False(t, orphan.LastScanTimestamp.IsZero())
+		}
+	}
+
+	assert.Equal(t, []taggingRequest{
+		{region: "eu-west-1", tagKey: inventory.ClusterTagKey + "alpha-abcde", resourceTypes: orphanRegionalResourceTypes},
+		{region: cp.TaggingGlobalRegion, tagKey: inventory.ClusterTagKey + "alpha-abcde", resourceTypes: []string{cp.TaggingHostedZoneType}},
+		{region: "eu-west-1", tagKey: inventory.ClusterTagKey + "beta-fghij", resourceTypes: orphanRegionalResourceTypes},
+	}, api.requests)
+}
@@ -1,7 +1,10 @@
 package stocker
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"sync"
 
 	cp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
@@ -17,14 +20,23 @@ const (
 type AWSStocker struct {
 	Account                  *inventory.Account // Account to be scanned by the AWSStocker
 	skipNoOpenShiftInstances bool               // Flag for skipping the scanned instances that doesn't belong to any Openshift cluster or Single Node Openshift
+	regionWorkers            int                // Max number of regions scanned concurrently
 	logger                   *zap.Logger        // Stocker Logger
 	conn                     *cp.AWSConnection  // AWS Connection for the stocker
+	mutex                    sync.Mutex         // Protects the Account clusters while the regional results are merged
+	regionsReport            RegionsReport      // Regions scanned on the last MakeStock run
 }
 
-// NewAWSStocker create and returns a pointer to a new AWSStocker instance
-func NewAWSStocker(account *inventory.Account, skipNoOpenShiftInstances bool, logger *zap.Logger) (*AWSStocker, error) {
+// NewAWSStocker create and returns a pointer to a new AWSStocker instance.
+// regionWorkers sets the number of regions scanned concurrently (the scanner
+// config CIQ_SCANNER_REGION_WORKERS), and it must be at least 1
+func NewAWSStocker(account *inventory.Account, role cp.AWSAssumeRoleConfig, skipNoOpenShiftInstances bool, regionWorkers int, logger *zap.Logger) (*AWSStocker, error) {
+	if regionWorkers < 1 {
+		return nil, fmt.Errorf("invalid number of region workers: %d", regionWorkers)
+	}
+
 	// Leaving the region empty forces to the AWSConnection to use the default region until a new one is configured
-	conn, err := cp.NewAWSConnection(account.GetUser(), account.GetPassword(), "", cp.WithEC2(), cp.WithRoute53(), cp.WithSTS())
+	conn, err := cp.NewAWSConnection(account.GetUser(), account.GetPassword(), role, "", cp.WithEC2(), cp.WithRoute53(), cp.WithSTS())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AWS connection: %w", err)
 	}
@@ -37,6 +49,7 @@ func NewAWSStocker(account *inventory.Account, skipNoOpenShiftInstances bool, lo
 	return &AWSStocker{
 		Account:                  account,
 		skipNoOpenShiftInstances: skipNoOpenShiftInstances,
+		regionWorkers:            regionWorkers,
 		logger:                   logger,
 		conn:                     conn,
 	}, nil
@@ -54,33 +67,96 @@ func (s *AWSStocker) MakeStock() error {
 		return err
 	}
 
-	// This loop cannot be parallelize because the AWSStocker object has only one "conn" and it depends on the configured region
+	// The failed regions don't stop the scan, but the Account is reported as
+	// partially scanned, so the clusters of those regions are not evaluated
+	regionsErr := s.scanRegions(s.filterRegions(regions))
+
+	// Lookup Openshift console URL. If the lookup fails, the Account is
+	// reported as failed instead of storing its clusters without console links
+	if err := s.FindOpenshiftConsoleURLs(); err != nil {
+		return errors.Join(regionsErr, fmt.Errorf("console links lookup failed: %w", err))
+	}
+
+	return regionsErr
+}
+
+// filterRegions returns the regions enabled on the Account
+func (s *AWSStocker) filterRegions(regions []string) []string {
+	var enabledRegions []string
 	for _, region := range regions {
-		err := s.processRegion(region)
-		if err != nil {
-			s.logger.Error("Error processing region",
-				zap.String("region", region),
-				zap.Error(err),
-			)
-			// Continue to the next region even if an error occurs
+		if !s.Account.IsRegionEnabled(region) {
+			s.logger.Debug("Skipping disabled region", zap.String("account", s.Account.Name), zap.String("region", region))
 			continue
 		}
+		enabledRegions = append(enabledRegions, region)
 	}
+	return enabledRegions
+}
 
-	// Lookup Openshift console URL
-	if err := s.FindOpenshiftConsoleURLs(); err != nil {
-		return err
+// scanRegions scans the regions concurrently. Every worker uses its own
+// regional connection, and the results are merged into the Account once the
+// region is completely scanned. It returns the errors of the failed regions
+func (s *AWSStocker) scanRegions(regions []string) error {
+	var wg sync.WaitGroup
+	var reportMutex sync.Mutex
+	var errs []error
+	s.regionsReport = RegionsReport{}
+	regionsChan := make(chan string)
+
+	workers := min(s.regionWorkers, len(regions))
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for region := range regionsChan {
+				if err := s.processRegion(region); err != nil {
+					s.logger.Error("Error processing region",
+						zap.String("account", s.Account.Name),
+						zap.String("region", region),
+						zap.Error(err),
+					)
+					reportMutex.Lock()
+					errs = append(errs, &RegionError{Region: region, Err: err})
+					s.regionsReport.Failed = append(s.regionsReport.Failed, region)
+					reportMutex.Unlock()
+					// Continue to the next region even if an error occurs
+					continue
+				}
+				reportMutex.Lock()
+				s.regionsReport.Scanned = append(s.regionsReport.Scanned, region)
+				reportMutex.Unlock()
+			}
+		}()
+	}
+
+	for _, region := range regions {
+		regionsChan <- region
 	}
+	close(regionsChan)
 
-	return nil
+	wg.Wait()
+	// Sorting the regions, as the workers finish them in any order
+	slices.Sort(s.regionsReport.Scanned)
+	slices.Sort(s.regionsReport.Failed)
+	return errors.Join(errs...)
 }
 
 // PrintStock Prints the Account Stock
-func (s AWSStocker) PrintStock() {
+func (s *AWSStocker) PrintStock() {
 	s.Account.PrintAccount()
 }
 
 // GetResults Returns the Account was scanned on this stocker
-func (s AWSStocker) GetResults() inventory.Account {
+func (s *AWSStocker) GetResults() inventory.Account {
 	return *s.Account
 }
+
+// GetRegionsReport returns the regions scanned correctly and the failed ones
+func (s *AWSStocker) GetRegionsReport() RegionsReport {
+	return s.regionsReport
+}
+
+// GetThrottledRequests returns the number of AWS API requests throttled while making stock
+func (s *AWSStocker) GetThrottledRequests() int64 {
+	return s.conn.GetThrottledRequests()
+}
@@ -3,69 +3,108 @@ package stocker
 import (
 	"fmt"
 
+	cp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
 	"go.uber.org/zap"
 )
 
-// TODO: processRegion gets from EC2 API the list of the instances running for the specified region, and runs its processing to group them by clusterID
+// processRegion gets from EC2 API the list of the instances and resources of the specified region using a dedicated regional connection, and merges them into the Account grouped by clusterID
 func (s *AWSStocker) processRegion(region string) error {
-	if err := s.conn.SetRegion(region); err != nil {
-		return err
+	conn, err := s.conn.NewRegionalConnection(region)
+	if err != nil {
+		return fmt.Errorf("couldn't create AWS connection for region %s: %w", region, err)
+	}
+	s.logger.Info("Scraping region", zap.String("account", s.Account.Name), zap.String("region", region))
+
+	instances, err := conn.EC2.GetInstances()
+	if err != nil {
+		return fmt.Errorf("couldn't retrieve EC2 instances in region %s: %w", region, err)
 	}
-	s.logger.Info("Scraping region", zap.String("account", s.Account.Name), zap.String("region", s.conn.GetRegion()))
 
-	instances, err := s.conn.EC2.GetInstances()
+	resources, err := getRegionResources(conn.EC2)
 	if err != nil {
-		return fmt.Errorf("couldn't retrieve EC2 instances in region %s: %w", s.conn.GetRegion(), err)
+		return fmt.Errorf("couldn't retrieve resources in region %s: %w", region, err)
 	}
 
-	// convert instances from ec2 to inventory.Instance
-	s.processInstances(instances)
+	// The Account is shared by every region worker
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Grouping the instances by cluster. The clusters found on several regions keep the region of their first instance
+	processInstances(s.Account, instances, inventory.AWSProvider, func(inventory.Instance) string { return region }, s.skipNoOpenShiftInstances, s.logger)
+
+	// Non-compute resources are processed after the instances, so they can be attributed to the already known clusters
+	s.processResources(resources)
 
 	return nil
 }
 
-// processInstances gets every AWS EC2 instance, parse it, a
-func (s *AWSStocker) processInstances(instances []inventory.Instance) {
-	// Getting Instances metadata
-	for i, instance := range instances {
+// getRegionResources gets the EBS volumes, EBS snapshots and Elastic IPs of the region configured on the EC2 connection.
+// Any error fails the whole region, so the stored resources of the region are kept instead of being reported as disappeared
+func getRegionResources(ec2Conn *cp.AWSEC2Connection) ([]inventory.Resource, error) {
+	var resources []inventory.Resource
+	getters := []struct {
+		resourceType inventory.ResourceType
+		getResources func() ([]inventory.Resource, error)
+	}{
+		{inventory.VolumeResourceType, ec2Conn.GetVolumes},
+		{inventory.SnapshotResourceType, ec2Conn.GetSnapshots},
+		{inventory.ElasticIPResourceType, ec2Conn.GetElasticIPs},
+	}
+
+	for _, getter := range getters {
+		r, err := getter.getResources()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", getter.resourceType, err)
+		}
+		resources = append(resources, r...)
+	}
 
-		// Generating ClusterID for this instance based on its properties
-		clusterName := inventory.GetClusterNameFromTags(instance.Tags)
-		if s.skipNoOpenShiftInstances && clusterName == inventory.UnknownClusterNameCode {
-			s.logger.Debug("Skipping instance because it's not associated to any cluster",
+	return resources, nil
+}
+
+// processResources attributes every resource to its cluster based on the
+// cluster tag. Untagged resources attached to a cluster instance belong to
+// the cluster of that instance. Resources without ID or that can't be
+// attributed to any scanned cluster are skipped
+func (s *AWSStocker) processResources(resources []inventory.Resource) {
+	// Index of the instances on the account for looking up the attached resources
+	instanceClusters := make(map[string]string)
+	for clusterID, cluster := range s.Account.Clusters {
+		for _, instance := range cluster.Instances {
+			instanceClusters[instance.ID] = clusterID
+		}
+	}
+
+	for _, resource := range resources {
+		if resource.ID == "" {
+			s.logger.Debug("Skipping resource without ID",
 				zap.String("account", s.Account.Name),
-				zap.String("instance_id", instance.ID),
-				zap.String("region", instance.AvailabilityZone))
+				zap.String("resource_type", string(resource.Type)))
 			continue
 		}
-		infraID := inventory.GetInfraIDFromTags(instance.Tags)
-		clusterID, err := inventory.GenerateClusterID(
-			clusterName,
-			infraID,
-			s.Account.Name,
-		)
-		if err != nil {
-			s.logger.Error("Error obtaining ClusterID for a new instance add", zap.String("account", s.Account.Name), zap.Error(err))
+
+		clusterID := instanceClusters[resource.InstanceID]
+
+		clusterName := inventory.GetClusterNameFromTags(resource.Tags)
+		if clusterName != inventory.UnknownClusterNameCode {
+			id, err := inventory.GenerateClusterID(clusterName, inventory.GetInfraIDFromTags(resource.Tags), s.Account.Name)
+			if err != nil {
+				s.logger.Error("Error obtaining ClusterID for a resource", zap.String("account", s.Account.Name), zap.Error(err))
+				continue
+			}
+			clusterID = id
 		}
 
-		instances[i].ClusterID = clusterID
-
-		// Checking if the cluster of the instance already exists on the inventory
-		if !s.Account.IsClusterOnAccount(clusterID) {
-			cluster := inventory.NewCluster(
-				clusterName,
-				infraID,
-				inventory.AWSProvider,
-				s.conn.GetRegion(),
-				s.Account.Name,
-				unknownConsoleLinkCode,
-				inventory.GetOwnerFromTags(instances[i].Tags),
-			)
-			s.Account.AddCluster(cluster)
+		if clusterID == "" || !s.Account.IsClusterOnAccount(clusterID) {
+			s.logger.Debug("Skipping resource because it's not associated to any scanned cluster",
+				zap.String("account", s.Account.Name),
+				zap.String("resource_id", resource.ID),
+				zap.String("resource_type", string(resource.Type)))
+			continue
 		}
 
-		// Adding the instance to the Cluster
-		s.Account.Clusters[clusterID].AddInstance(instances[i])
+		resource.ClusterID = clusterID
+		s.Account.Clusters[clusterID].AddResource(resource)
 	}
 }
@@ -0,0 +1,39 @@
+package stocker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// TestProcessResources verifies the resources are attributed by cluster tag or attached instance, skipping the resources without ID or cluster
+func TestProcessResources(t *testing.T) {
+	clusterTags := []inventory.Tag{*inventory.NewTag(inventory.ClusterTagKey+"ocp-a1b2c", "owned", "")}
+	account := inventory.NewAccount("123456789012", "aws-account", inventory.AWSProvider, "user", "password")
+	cluster := inventory.NewCluster(inventory.GetClusterNameFromTags(clusterTags), inventory.GetInfraIDFromTags(clusterTags), inventory.AWSProvider, "us-east-1", account.Name, "", "")
+	assert.Nil(t, cluster.AddInstance(*inventory.NewInstance("i-0001", "master-0", inventory.AWSProvider, "m5.xlarge", "us-east-1a", inventory.Running, cluster.ID, clusterTags, time.Now())))
+	assert.Nil(t, account.AddCluster(cluster))
+
+	s := &AWSStocker{Account: account, logger: zap.NewNop()}
+	s.processResources([]inventory.Resource{
+		// Tagged as part of the cluster
+		*inventory.NewResource("vol-0001", "", inventory.VolumeResourceType, inventory.AWSProvider, "us-east-1a", "available", "gp3", 120, "", clusterTags, time.Now()),
+		// Untagged, attached to a cluster instance
+		*inventory.NewResource("vol-0002", "", inventory.VolumeResourceType, inventory.AWSProvider, "us-east-1a", "in-use", "gp3", 120, "i-0001", nil, time.Now()),
+		// Not related to any scanned cluster
+		*inventory.NewResource("vol-0003", "", inventory.VolumeResourceType, inventory.AWSProvider, "us-east-1a", "in-use", "gp3", 120, "i-9999", nil, time.Now()),
+		*inventory.NewResource("snap-0001", "", inventory.SnapshotResourceType, inventory.AWSProvider, "us-east-1", "completed", "standard", 120, "", []inventory.Tag{*inventory.NewTag(inventory.ClusterTagKey+"other-x1y2z", "owned", "")}, time.Now()),
+		// Without ID
+		*inventory.NewResource("", "203.0.113.12", inventory.ElasticIPResourceType, inventory.AWSProvider, "us-east-1", "associated", "standard", 0, "i-0001", nil, time.Now()),
+	})
+
+	resources := account.Clusters[cluster.ID].Resources
+	assert.Len(t, resources, 2)
+	for _, resource := range resources {
+		assert.Contains(t, []string{"vol-0001", "vol-0002"}, resource.ID)
+		assert.Equal(t, cluster.ID, resource.ClusterID)
+	}
+}
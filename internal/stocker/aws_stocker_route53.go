@@ -4,6 +4,7 @@ import (
 	"strings"
 	"time"
 
+	cp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/route53"
@@ -34,10 +35,51 @@ func searchConsoleURLinDNSRecords(records []*route53.ResourceRecordSet, cluster
 	return nil
 }
 
+// hostedZoneRecordsCache keeps the records of the hosted zones fetched while
+// looking for the console URLs, so every zone is listed at most once per scan
+// even if it's shared by several clusters. Failed zones are cached too
+type hostedZoneRecordsCache struct {
+	conn    *cp.AWSRoute53Connection
+	records map[string][]*route53.ResourceRecordSet
+	errs    map[string]error
+}
+
+// newHostedZoneRecordsCache returns an empty cache for the specified Route53 connection
+func newHostedZoneRecordsCache(conn *cp.AWSRoute53Connection) *hostedZoneRecordsCache {
+	return &hostedZoneRecordsCache{
+		conn:    conn,
+		records: make(map[string][]*route53.ResourceRecordSet),
+		errs:    make(map[string]error),
+	}
+}
+
+// get returns the records of a hosted zone, fetching them only the first time
+func (c *hostedZoneRecordsCache) get(hostedZoneID string) ([]*route53.ResourceRecordSet, error) {
+	if err, ok := c.errs[hostedZoneID]; ok {
+		return nil, err
+	}
+	if records, ok := c.records[hostedZoneID]; ok {
+		return records, nil
+	}
+
+	records, err := c.conn.GetHostedZoneRecords(hostedZoneID)
+	if err != nil {
+		c.errs[hostedZoneID] = err
+		return nil, err
+	}
+	c.records[hostedZoneID] = records
+
+	return records, nil
+}
+
 // GetConsoleLinkOfCluster returns the corresponding ConsoleLink for a given cluster
-func (s *AWSStocker) getConsoleLinkOfCluster(cluster *inventory.Cluster, hostedZone *route53.HostedZone) string {
-	records, err := s.conn.Route53.GetHostedZoneRecords(*hostedZone.Id)
+func (s *AWSStocker) getConsoleLinkOfCluster(cluster *inventory.Cluster, hostedZone *route53.HostedZone, cache *hostedZoneRecordsCache) string {
+	records, err := cache.get(aws.StringValue(hostedZone.Id))
 	if err != nil {
+		s.logger.Warn("Failed to get hosted zone records",
+			zap.String("account", s.Account.Name),
+			zap.String("hosted_zone_id", aws.StringValue(hostedZone.Id)),
+			zap.Error(err))
 		return unknownConsoleLinkCode
 	}
 
@@ -49,24 +91,29 @@ func (s *AWSStocker) getConsoleLinkOfCluster(cluster *inventory.Cluster, hostedZ
 	return unknownConsoleLinkCode
 }
 
-// FindOpenshiftConsoleURLs iterates every Cluster and every Route53 HostedZone for looking for the corresponding URLs for the OCP console
+// FindOpenshiftConsoleURLs iterates every Cluster and every Route53 HostedZone for looking for the corresponding URLs for the OCP console.
+// The hosted zones and their tags are listed once, and the records of every zone are fetched at most once
 func (s *AWSStocker) FindOpenshiftConsoleURLs() error {
 	start := time.Now()
 	hostedZones, err := s.conn.Route53.GetZonesWithTags()
 	if err != nil {
 		return err
 	}
+
+	cache := newHostedZoneRecordsCache(s.conn.Route53)
 	for i, cluster := range s.Account.Clusters {
 		for _, hostedZone := range hostedZones {
 			// Checking if the current hosted zone belongs to the current cluster
 			if s.conn.Route53.ZoneBelongsToCluster(cluster, hostedZone) {
 				s.logger.Debug("Found Hosted Zone for Cluster", zap.String("account", s.Account.Name), zap.String("hosted_zone_id", *hostedZone.Zone.Name), zap.String("cluster_id", cluster.ID))
 
-				s.Account.Clusters[i].ConsoleLink = s.getConsoleLinkOfCluster(cluster, hostedZone.Zone)
+				s.Account.Clusters[i].ConsoleLink = s.getConsoleLinkOfCluster(cluster, hostedZone.Zone, cache)
 			}
 		}
 	}
 	s.logger.Debug("Finished finding OpenShift console URLs",
+		zap.Int("hosted_zones", len(hostedZones)),
+		zap.Int("fetched_hosted_zones", len(cache.records)+len(cache.errs)),
 		zap.Duration("duration", time.Since(start)))
 	return nil
 }
@@ -0,0 +1,185 @@
+package stocker
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/aws"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeEC2API serves the EC2 operations used for scanning the regions. Every
+// region returns its instances after throttling its first request, and the
+// regions without instances fail
+type fakeEC2API struct {
+	// Instance XML items of every region
+	instances map[string]string
+	// Action failing on every region
+	failedActions map[string]string
+	mutex         sync.Mutex
+	throttled     map[string]bool
+}
+
+func (f *fakeEC2API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	// Regional endpoints look like ec2.<region>.amazonaws.com
+	region := strings.Split(r.URL.Host, ".")[1]
+	action := r.Form.Get("Action")
+
+	f.mutex.Lock()
+	throttled := f.throttled[region]
+	f.throttled[region] = true
+	f.mutex.Unlock()
+	if !throttled {
+		writeEC2Error(w, http.StatusBadRequest, "RequestLimitExceeded")
+		return
+	}
+
+	instances, ok := f.instances[region]
+	if !ok || f.failedActions[region] == action {
+		writeEC2Error(w, http.StatusForbidden, "UnauthorizedOperation")
+		return
+	}
+
+	switch action {
+	case "DescribeInstances":
+		fmt.Fprintf(w, `<DescribeInstancesResponse><reservationSet><item><instancesSet>%s</instancesSet></item></reservationSet></DescribeInstancesResponse>`, instances)
+	case "DescribeVolumes":
+		fmt.Fprint(w, `<DescribeVolumesResponse><volumeSet></volumeSet></DescribeVolumesResponse>`)
+	case "DescribeSnapshots":
+		fmt.Fprint(w, `<DescribeSnapshotsResponse><snapshotSet></snapshotSet></DescribeSnapshotsResponse>`)
+	case "DescribeAddresses":
+		fmt.Fprint(w, `<DescribeAddressesResponse><addressesSet></addressesSet></DescribeAddressesResponse>`)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// writeEC2Error writes an EC2 API error response
+func writeEC2Error(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<Response><Errors><Error><Code>%s</Code><Message>%s</Message></Error></Errors><RequestID>fake</RequestID></Response>`, code, code)
+}
+
+// ec2InstanceItem returns the XML item of a running instance with the specified tag key
+func ec2InstanceItem(id string, availabilityZone string, tagKey string) string {
+	return fmt.Sprintf(`<item>
+		<instanceId>%s</instanceId>
+		<instanceType>m5.xlarge</instanceType>
+		<placement><availabilityZone>%s</availabilityZone></placement>
+		<instanceState><code>16</code><name>running</name></instanceState>
+		<tagSet><item><key>%s</key><value>owned</value></item></tagSet>
+	</item>`, id, availabilityZone, tagKey)
+}
+
+// clusterIDOfTag returns the ID of the cluster of the account identified by the cluster tag key
+func clusterIDOfTag(t *testing.T, tagKey string, accountName string) string {
+	tags := []inventory.Tag{*inventory.NewTag(tagKey, "owned", "")}
+	id, err := inventory.GenerateClusterID(inventory.GetClusterNameFromTags(tags), inventory.GetInfraIDFromTags(tags), accountName)
+	assert.Nil(t, err)
+	return id
+}
+
+// useFastAWSRetries makes the throttled requests be retried without waiting during the test
+func useFastAWSRetries(t *testing.T) {
+	policy := cp.GetAWSRetryPolicy()
+	assert.Nil(t, cp.SetAWSRetryPolicy(cp.AWSRetryPolicy{MaxRetries: 2, MinDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+	t.Cleanup(func() { _ = cp.SetAWSRetryPolicy(policy) })
+}
+
+// newTestAWSStocker returns an AWSStocker with an EC2 connection, scanning the specified number of regions concurrently
+func newTestAWSStocker(t *testing.T, regionWorkers int) *AWSStocker {
+	conn, err := cp.NewAWSConnection("AKIAEXAMPLE", "secret", cp.AWSAssumeRoleConfig{}, "", cp.WithEC2())
+	assert.Nil(t, err)
+
+	return &AWSStocker{
+		Account:                  inventory.NewAccount("123456789012", "aws-account", inventory.AWSProvider, "AKIAEXAMPLE", "secret"),
+		skipNoOpenShiftInstances: true,
+		regionWorkers:            regionWorkers,
+		logger:                   zap.NewNop(),
+		conn:                     conn,
+	}
+}
+
+// TestScanRegions verifies the regions are scanned concurrently on their own connections, merging the clusters spanning several regions, sorting the regions report and returning the failed regions as RegionErrors
+func TestScanRegions(t *testing.T) {
+	useFastAWSRetries(t)
+	multiRegionTag := inventory.ClusterTagKey + "ocp-a1b2c"
+	singleRegionTag := inventory.ClusterTagKey + "dev-x1y2z"
+	regions := []string{"us-east-1", "eu-west-1", "ap-south-1", "eu-central-1"}
+
+	for _, regionWorkers := range []int{1, 2, len(regions) + 3} {
+		t.Run(fmt.Sprintf("workers-%d", regionWorkers), func(t *testing.T) {
+			useFakeAWSTransport(t, &fakeEC2API{
+				instances: map[string]string{
+					"us-east-1":    ec2InstanceItem("i-0001", "us-east-1a", multiRegionTag),
+					"eu-west-1":    ec2InstanceItem("i-0002", "eu-west-1a", multiRegionTag) + ec2InstanceItem("i-0003", "eu-west-1b", multiRegionTag),
+					"eu-central-1": ec2InstanceItem("i-0004", "eu-central-1a", singleRegionTag),
+				},
+				throttled: make(map[string]bool),
+			})
+			s := newTestAWSStocker(t, regionWorkers)
+
+			err := s.scanRegions(regions)
+			assert.NotNil(t, err)
+			assert.True(t, OnlyRegionErrors(err))
+			var regionErr *RegionError
+			if assert.ErrorAs(t, err, &regionErr) {
+				assert.Equal(t, "ap-south-1", regionErr.Region)
+			}
+
+			assert.Equal(t, RegionsReport{Scanned: []string{"eu-central-1", "eu-west-1", "us-east-1"}, Failed: []string{"ap-south-1"}}, s.GetRegionsReport())
+
+			multiRegionID := clusterIDOfTag(t, multiRegionTag, s.Account.Name)
+			singleRegionID := clusterIDOfTag(t, singleRegionTag, s.Account.Name)
+			assert.Len(t, s.Account.Clusters, 2)
+			if assert.Contains(t, s.Account.Clusters, multiRegionID) {
+				assert.Len(t, s.Account.Clusters[multiRegionID].Instances, 3)
+			}
+			if assert.Contains(t, s.Account.Clusters, singleRegionID) {
+				assert.Len(t, s.Account.Clusters[singleRegionID].Instances, 1)
+			}
+
+			// The first request of every region was throttled on its own regional connection
+			assert.Equal(t, int64(len(regions)), s.GetThrottledRequests())
+		})
+	}
+}
+
+// TestScanRegionsResourcesError verifies a region fails when its resources can't be retrieved, so its stored resources are not reported as disappeared
+func TestScanRegionsResourcesError(t *testing.T) {
+	useFastAWSRetries(t)
+	tagKey := inventory.ClusterTagKey + "ocp-a1b2c"
+	useFakeAWSTransport(t, &fakeEC2API{
+		instances: map[string]string{
+			"us-east-1": ec2InstanceItem("i-0001", "us-east-1a", tagKey),
+			"eu-west-1": ec2InstanceItem("i-0002", "eu-west-1a", tagKey),
+		},
+		failedActions: map[string]string{"eu-west-1": "DescribeSnapshots"},
+		throttled:     make(map[string]bool),
+	})
+	s := newTestAWSStocker(t, 2)
+
+	err := s.scanRegions([]string{"us-east-1", "eu-west-1"})
+	assert.True(t, OnlyRegionErrors(err))
+	var regionErr *RegionError
+	if assert.ErrorAs(t, err, &regionErr) {
+		assert.Equal(t, "eu-west-1", regionErr.Region)
+	}
+	assert.Equal(t, RegionsReport{Scanned: []string{"us-east-1"}, Failed: []string{"eu-west-1"}}, s.GetRegionsReport())
+
+	// The instances of the failed region are not merged into the Account
+	clusterID := clusterIDOfTag(t, tagKey, s.Account.Name)
+	if assert.Contains(t, s.Account.Clusters, clusterID) {
+		assert.Len(t, s.Account.Clusters[clusterID].Instances, 1)
+	}
+}
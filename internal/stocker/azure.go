@@ -1,37 +1,125 @@
 package stocker
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 
+	cpazure "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/azure"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
 	"go.uber.org/zap"
 )
 
 // AzureStocker object to make stock on Azure
 type AzureStocker struct {
-	region  string
-	Account inventory.Account
-	logger  *zap.Logger
+	Account                  *inventory.Account       // Account to be scanned by the AzureStocker
+	skipNoOpenShiftInstances bool                     // Flag for skipping the scanned instances that doesn't belong to any Openshift cluster or Single Node Openshift
+	logger                   *zap.Logger              // Stocker Logger
+	conn                     *cpazure.AzureConnection // Azure Connection for the stocker
+	regionsReport            RegionsReport            // Subscriptions scanned on the last MakeStock run
 }
 
-// NewAzureStocker create and returns a pointer to a new AzureStocker instance
-func NewAzureStocker(account inventory.Account, logger *zap.Logger) *AzureStocker {
-	st := AzureStocker{region: "default", logger: logger}
-	st.Account = *inventory.NewAccount(account.ID, account.Name, inventory.AzureProvider, account.GetUser(), account.GetPassword())
-	return &st
+// NewAzureStocker create and returns a pointer to a new AzureStocker instance.
+// The account user and password are the Service Principal ClientID and ClientSecret
+func NewAzureStocker(account *inventory.Account, tenantID string, skipNoOpenShiftInstances bool, logger *zap.Logger) (*AzureStocker, error) {
+	conn, err := cpazure.NewAzureConnection(tenantID, account.GetUser(), account.GetPassword())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure connection: %w", err)
+	}
+
+	return NewAzureStockerWithConnection(account, conn, skipNoOpenShiftInstances, logger), nil
+}
+
+// NewAzureStockerWithConnection create and returns a pointer to a new AzureStocker instance based on an existing AzureConnection
+func NewAzureStockerWithConnection(account *inventory.Account, conn *cpazure.AzureConnection, skipNoOpenShiftInstances bool, logger *zap.Logger) *AzureStocker {
+	return &AzureStocker{
+		Account:                  account,
+		skipNoOpenShiftInstances: skipNoOpenShiftInstances,
+		logger:                   logger,
+		conn:                     conn,
+	}
+}
+
+// MakeStock Implements the interface Stocker for triggering the entire process of making stock about an Azure account
+func (s *AzureStocker) MakeStock() error {
+	subscriptions, err := s.conn.GetSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	// Using the SubscriptionID as AccountID when the Service Principal has
+	// access to a single subscription. Otherwise, the TenantID identifies the account
+	if s.Account.ID == "" {
+		if len(subscriptions) == 1 {
+			s.Account.ID = subscriptions[0]
+		} else {
+			s.Account.ID = s.conn.GetTenantID()
+		}
+	}
+
+	// The failed subscriptions don't stop the scan, but the Account is
+	// reported as incompletely scanned, so its missing clusters are not evaluated
+	var errs []error
+	s.regionsReport = RegionsReport{}
+	for _, subscription := range subscriptions {
+		err := s.processSubscription(subscription)
+		if err != nil {
+			s.logger.Error("Error processing subscription",
+				zap.String("account", s.Account.Name),
+				zap.String("subscription", subscription),
+				zap.Error(err),
+			)
+			errs = append(errs, err)
+			s.regionsReport.Failed = append(s.regionsReport.Failed, subscription)
+			// Continue to the next subscription even if an error occurs
+			continue
+		}
+		s.regionsReport.Scanned = append(s.regionsReport.Scanned, subscription)
+	}
+
+	return errors.Join(errs...)
 }
 
-// MakeStock Scans Azure cloud accounts
-func (s AzureStocker) MakeStock() error {
-	return fmt.Errorf("AzureStocker.MakeStock not implemented")
+// processSubscription gets the list of VMs of the specified subscription, and runs its processing to group them by clusterID
+func (s *AzureStocker) processSubscription(subscription string) error {
+	s.logger.Info("Scraping subscription", zap.String("account", s.Account.Name), zap.String("subscription", subscription))
+
+	instances, err := s.conn.GetInstances(subscription)
+	if err != nil {
+		return fmt.Errorf("couldn't retrieve virtual machines in subscription %s: %w", subscription, err)
+	}
+
+	// Skipping the instances of the disabled locations
+	instances = slices.DeleteFunc(instances, func(instance inventory.Instance) bool {
+		location := cpazure.GetLocationFromAvailabilityZone(instance.AvailabilityZone)
+		if s.Account.IsRegionEnabled(location) {
+			return false
+		}
+		s.logger.Debug("Skipping instance of a disabled location",
+			zap.String("account", s.Account.Name),
+			zap.String("instance_id", instance.ID),
+			zap.String("region", location))
+		return true
+	})
+
+	processInstances(s.Account, instances, inventory.AzureProvider, func(instance inventory.Instance) string {
+		return cpazure.GetLocationFromAvailabilityZone(instance.AvailabilityZone)
+	}, s.skipNoOpenShiftInstances, s.logger)
+
+	return nil
 }
 
-// PrintStock prints by stdout the account object belongs to this stocker
+// PrintStock Prints the Account Stock
 func (s AzureStocker) PrintStock() {
 	s.Account.PrintAccount()
 }
 
-// GetResults resturns the scanned results on this stocker instance
+// GetResults Returns the Account was scanned on this stocker
 func (s AzureStocker) GetResults() inventory.Account {
-	return s.Account
+	return *s.Account
+}
+
+// GetRegionsReport returns the subscriptions scanned correctly and the failed ones
+func (s AzureStocker) GetRegionsReport() RegionsReport {
+	return s.regionsReport
 }
@@ -0,0 +1,126 @@
+package stocker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	cpazure "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/azure"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeAzureClient implements cpazure.AzureAPIClient returning static VMs per subscription
+type fakeAzureClient struct {
+	vms map[string][]*armcompute.VirtualMachine
+}
+
+func (f *fakeAzureClient) ListSubscriptions(_ context.Context) ([]string, error) {
+	var subscriptions []string
+	for sub := range f.vms {
+		subscriptions = append(subscriptions, sub)
+	}
+	return subscriptions, nil
+}
+
+func (f *fakeAzureClient) ListVirtualMachines(_ context.Context, subscriptionID string) ([]*armcompute.VirtualMachine, error) {
+	return f.vms[subscriptionID], nil
+}
+
+func (f *fakeAzureClient) StartVirtualMachine(_ context.Context, _ string) error {
+	return nil
+}
+
+func (f *fakeAzureClient) DeallocateVirtualMachine(_ context.Context, _ string) error {
+	return nil
+}
+
+// newFakeVM returns an Azure VM with the specified properties
+func newFakeVM(id string, name string, location string, zone string, size armcompute.VirtualMachineSizeTypes, powerState string, tags map[string]*string) *armcompute.VirtualMachine {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	code := "PowerState/" + powerState
+	vm := &armcompute.VirtualMachine{
+		Name:     &name,
+		Location: &location,
+		Tags:     tags,
+		Properties: &armcompute.VirtualMachineProperties{
+			VMID:            &id,
+			TimeCreated:     &created,
+			HardwareProfile: &armcompute.HardwareProfile{VMSize: &size},
+			InstanceView: &armcompute.VirtualMachineInstanceView{
+				Statuses: []*armcompute.InstanceViewStatus{{Code: &code}},
+			},
+		},
+	}
+	if zone != "" {
+		vm.Zones = []*string{&zone}
+	}
+	return vm
+}
+
+// TestAzureStockerMakeStock tests the VMs are grouped by cluster using the Azure cluster tags
+func TestAzureStockerMakeStock(t *testing.T) {
+	owned := "owned"
+	client := &fakeAzureClient{
+		vms: map[string][]*armcompute.VirtualMachine{
+			"sub-1": {
+				newFakeVM("vm-1", "ocp-a1b2c-master-0", "eastus", "1", armcompute.VirtualMachineSizeTypesStandardD8SV3, "running",
+					map[string]*string{"kubernetes.io-cluster-ocp-a1b2c": &owned}),
+				newFakeVM("vm-2", "ocp-a1b2c-worker-0", "eastus", "2", armcompute.VirtualMachineSizeTypesStandardD4SV3, "deallocated",
+					map[string]*string{"kubernetes.io-cluster-ocp-a1b2c": &owned}),
+				newFakeVM("vm-3", "standalone", "westeurope", "", armcompute.VirtualMachineSizeTypesStandardB1S, "running", nil),
+			},
+		},
+	}
+
+	account := inventory.NewAccount("", "azure-account", inventory.AzureProvider, "client", "secret")
+	conn := cpazure.NewAzureConnectionWithClient("tenant", client)
+	st := NewAzureStockerWithConnection(account, conn, true, zap.NewNop())
+
+	err := st.MakeStock()
+	assert.Nil(t, err)
+
+	result := st.GetResults()
+	assert.Equal(t, "sub-1", result.ID)
+	assert.Len(t, result.Clusters, 1)
+
+	cluster, ok := result.Clusters["ocp-a1b2c-azure-account"]
+	assert.True(t, ok)
+	assert.Equal(t, inventory.CloudProvider(inventory.AzureProvider), cluster.Provider)
+	assert.Equal(t, "eastus", cluster.Region)
+	assert.Equal(t, "a1b2c", cluster.InfraID)
+	assert.Len(t, cluster.Instances, 2)
+
+	instances := make(map[string]inventory.Instance)
+	for _, instance := range cluster.Instances {
+		instances[instance.ID] = instance
+	}
+	assert.Equal(t, inventory.Running, instances["vm-1"].Status)
+	assert.Equal(t, "eastus-1", instances["vm-1"].AvailabilityZone)
+	assert.Equal(t, "Standard_D8s_v3", instances["vm-1"].InstanceType)
+	assert.Equal(t, inventory.Stopped, instances["vm-2"].Status)
+}
+
+// TestAzureStockerMultipleSubscriptions tests the TenantID is used as AccountID when several subscriptions are scanned
+func TestAzureStockerMultipleSubscriptions(t *testing.T) {
+	owned := "owned"
+	client := &fakeAzureClient{
+		vms: map[string][]*armcompute.VirtualMachine{
+			"sub-1": {newFakeVM("vm-1", "a-abcde-master-0", "eastus", "", armcompute.VirtualMachineSizeTypesStandardD8SV3, "running",
+				map[string]*string{"kubernetes.io_cluster.a-abcde": &owned})},
+			"sub-2": {newFakeVM("vm-2", "b-fghij-master-0", "westus", "", armcompute.VirtualMachineSizeTypesStandardD8SV3, "stopped",
+				map[string]*string{"kubernetes.io-cluster-b-fghij": &owned})},
+		},
+	}
+
+	account := inventory.NewAccount("", "azure-account", inventory.AzureProvider, "client", "secret")
+	st := NewAzureStockerWithConnection(account, cpazure.NewAzureConnectionWithClient("tenant", client), true, zap.NewNop())
+
+	assert.Nil(t, st.MakeStock())
+	result := st.GetResults()
+	assert.Equal(t, "tenant", result.ID)
+	assert.Len(t, result.Clusters, 2)
+	assert.Equal(t, "westus", result.Clusters["b-fghij-azure-account"].Region)
+}
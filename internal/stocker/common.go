@@ -1,6 +1,9 @@
 package stocker
 
-import "github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+import (
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"go.uber.org/zap"
+)
 
 // postNewInstance posts into the API, the new instances obtained after scanning
 func postNewInstance(instances []inventory.Instance) error {
@@ -16,3 +19,49 @@ func postNewCluster(clusters []inventory.Cluster) error {
 func postNewAccount(accounts []inventory.Account) error {
 	return nil
 }
+
+// processInstances groups the scanned instances of an account by cluster,
+// adding the clusters not found yet to the account. The region of every new
+// cluster is obtained from its first instance with the region function. The
+// instances without cluster are skipped when skipNoOpenShiftInstances is set
+func processInstances(account *inventory.Account, instances []inventory.Instance, provider inventory.CloudProvider, region func(inventory.Instance) string, skipNoOpenShiftInstances bool, logger *zap.Logger) {
+	for i, instance := range instances {
+		// Generating ClusterID for this instance based on its properties
+		clusterName := inventory.GetClusterNameFromTags(instance.Tags)
+		if skipNoOpenShiftInstances && clusterName == inventory.UnknownClusterNameCode {
+			logger.Debug("Skipping instance because it's not associated to any cluster",
+				zap.String("account", account.Name),
+				zap.String("instance_id", instance.ID),
+				zap.String("region", instance.AvailabilityZone))
+			continue
+		}
+		infraID := inventory.GetInfraIDFromTags(instance.Tags)
+		clusterID, err := inventory.GenerateClusterID(
+			clusterName,
+			infraID,
+			account.Name,
+		)
+		if err != nil {
+			logger.Error("Error obtaining ClusterID for a new instance add", zap.String("account", account.Name), zap.Error(err))
+		}
+
+		instances[i].ClusterID = clusterID
+
+		// Checking if the cluster of the instance already exists on the inventory
+		if !account.IsClusterOnAccount(clusterID) {
+			cluster := inventory.NewCluster(
+				clusterName,
+				infraID,
+				provider,
+				region(instance),
+				account.Name,
+				unknownConsoleLinkCode,
+				inventory.GetOwnerFromTags(instances[i].Tags),
+			)
+			account.AddCluster(cluster)
+		}
+
+		// Adding the instance to the Cluster
+		account.Clusters[clusterID].AddInstance(instances[i])
+	}
+}
@@ -0,0 +1,47 @@
+package stocker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// TestProcessInstances verifies the instances are grouped by cluster, the new clusters get the region of their first instance, and the instances without cluster are skipped only when requested
+func TestProcessInstances(t *testing.T) {
+	newInstance := func(id string, zone string, tagKey string) inventory.Instance {
+		tags := []inventory.Tag{*inventory.NewTag(tagKey, "owned", id)}
+		return *inventory.NewInstance(id, id, inventory.GCPProvider, "n2-standard-4", zone, inventory.Running, "", tags, time.Time{})
+	}
+	tagKey := inventory.ClusterTagKey + "ocp-a1b2c"
+	region := func(instance inventory.Instance) string {
+		return instance.AvailabilityZone[:len(instance.AvailabilityZone)-2]
+	}
+
+	for _, skip := range []bool{true, false} {
+		account := inventory.NewAccount("project", "gcp-account", inventory.GCPProvider, "project", "")
+		instances := []inventory.Instance{
+			newInstance("vm-1", "europe-west1-b", tagKey),
+			newInstance("vm-2", "europe-west4-a", tagKey),
+			newInstance("vm-3", "europe-west1-c", "Name"),
+		}
+
+		processInstances(account, instances, inventory.GCPProvider, region, skip, zap.NewNop())
+
+		clusterID := clusterIDOfTag(t, tagKey, account.Name)
+		if assert.Contains(t, account.Clusters, clusterID) {
+			cluster := account.Clusters[clusterID]
+			assert.Equal(t, inventory.CloudProvider(inventory.GCPProvider), cluster.Provider)
+			assert.Equal(t, "europe-west1", cluster.Region)
+			assert.Len(t, cluster.Instances, 2)
+		}
+		assert.Equal(t, clusterID, instances[0].ClusterID)
+		if skip {
+			assert.Len(t, account.Clusters, 1)
+		} else {
+			assert.Len(t, account.Clusters, 2)
+		}
+	}
+}
@@ -1,37 +1,142 @@
 package stocker
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	cpgcp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/gcp"
 	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
 	"go.uber.org/zap"
 )
 
 // GCPStocker object to make stock on GCP
 type GCPStocker struct {
-	region  string
-	Account inventory.Account
-	logger  *zap.Logger
+	Account                  *inventory.Account   // Account to be scanned by the GCPStocker
+	skipNoOpenShiftInstances bool                 // Flag for skipping the scanned instances that doesn't belong to any Openshift cluster or Single Node Openshift
+	logger                   *zap.Logger          // Stocker Logger
+	conn                     *cpgcp.GCPConnection // GCP Connection for the stocker
+	regionsReport            RegionsReport        // Zones scanned on the last MakeStock run
 }
 
-// NewGCPStocker create and returns a pointer to a new GCPStocker instance
-func NewGCPStocker(account inventory.Account, logger *zap.Logger) *GCPStocker {
-	st := GCPStocker{region: "default", logger: logger}
-	st.Account = *inventory.NewAccount(account.ID, account.Name, inventory.GCPProvider, account.GetUser(), account.GetPassword())
-	return &st
+// NewGCPStocker create and returns a pointer to a new GCPStocker instance.
+// The account user is the GCP ProjectID and the password is the path to the
+// Service Account key file
+func NewGCPStocker(account *inventory.Account, skipNoOpenShiftInstances bool, logger *zap.Logger) (*GCPStocker, error) {
+	conn, err := cpgcp.NewGCPConnection(account.GetUser(), account.GetPassword())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCP connection: %w", err)
+	}
+
+	return NewGCPStockerWithConnection(account, conn, skipNoOpenShiftInstances, logger), nil
+}
+
+// NewGCPStockerWithConnection create and returns a pointer to a new GCPStocker instance based on an existing GCPConnection
+func NewGCPStockerWithConnection(account *inventory.Account, conn *cpgcp.GCPConnection, skipNoOpenShiftInstances bool, logger *zap.Logger) *GCPStocker {
+	// The GCP ProjectID identifies the account
+	if account.ID == "" {
+		account.ID = conn.GetProjectID()
+	}
+
+	return &GCPStocker{
+		Account:                  account,
+		skipNoOpenShiftInstances: skipNoOpenShiftInstances,
+		logger:                   logger,
+		conn:                     conn,
+	}
+}
+
+// MakeStock Implements the interface Stocker for triggering the entire process of making stock about a GCP project
+func (s *GCPStocker) MakeStock() error {
+	zones, err := s.conn.GetZones()
+	if err != nil {
+		return err
+	}
+
+	// The failed zones don't stop the scan, but the Account is reported as
+	// incompletely scanned, so its missing clusters are not evaluated
+	var errs []error
+	s.regionsReport = RegionsReport{}
+	for _, zone := range zones {
+		if !s.Account.IsRegionEnabled(cpgcp.GetRegionFromZone(zone)) {
+			s.logger.Debug("Skipping zone of a disabled region", zap.String("account", s.Account.Name), zap.String("zone", zone))
+			continue
+		}
+
+		err := s.processZone(zone)
+		if err != nil {
+			s.logger.Error("Error processing zone",
+				zap.String("account", s.Account.Name),
+				zap.String("zone", zone),
+				zap.Error(err),
+			)
+			errs = append(errs, err)
+			s.regionsReport.Failed = append(s.regionsReport.Failed, zone)
+			// Continue to the next zone even if an error occurs
+			continue
+		}
+		s.regionsReport.Scanned = append(s.regionsReport.Scanned, zone)
+	}
+
+	// Lookup Openshift console URL
+	if err := s.FindOpenshiftConsoleURLs(); err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
 }
 
-// MakeStock Scans GCP cloud accounts
-func (s GCPStocker) MakeStock() error {
-	return fmt.Errorf("GCPStocker.MakeStock not implemented")
+// processZone gets the list of Compute Engine instances of the specified zone, and runs its processing to group them by clusterID
+func (s *GCPStocker) processZone(zone string) error {
+	s.logger.Debug("Scraping zone", zap.String("account", s.Account.Name), zap.String("zone", zone))
+
+	instances, err := s.conn.GetInstances(zone)
+	if err != nil {
+		return fmt.Errorf("couldn't retrieve Compute Engine instances in zone %s: %w", zone, err)
+	}
+
+	processInstances(s.Account, instances, inventory.GCPProvider, func(instance inventory.Instance) string {
+		return cpgcp.GetRegionFromZone(instance.AvailabilityZone)
+	}, s.skipNoOpenShiftInstances, s.logger)
+
+	return nil
 }
 
-// PrintStock prints by stdout the account object belongs to this stocker
+// FindOpenshiftConsoleURLs iterates every Cluster and every Cloud DNS managed zone for looking for the corresponding URLs for the OCP console
+func (s *GCPStocker) FindOpenshiftConsoleURLs() error {
+	start := time.Now()
+	managedZones, err := s.conn.GetManagedZones()
+	if err != nil {
+		return err
+	}
+	for i, cluster := range s.Account.Clusters {
+		for _, zone := range managedZones {
+			// Checking if the current managed zone belongs to the current cluster
+			if cpgcp.ManagedZoneBelongsToCluster(cluster, zone) {
+				s.logger.Debug("Found DNS Managed Zone for Cluster", zap.String("account", s.Account.Name), zap.String("managed_zone", zone.Name), zap.String("cluster_id", cluster.ID))
+
+				// The cluster zone DNS name is the cluster domain: "<cluster_name>.<base_domain>."
+				s.Account.Clusters[i].ConsoleLink = *generateConsoleLink(strings.TrimSuffix(zone.DnsName, "."))
+			}
+		}
+	}
+	s.logger.Debug("Finished finding OpenShift console URLs",
+		zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+// PrintStock Prints the Account Stock
 func (s GCPStocker) PrintStock() {
 	s.Account.PrintAccount()
 }
 
-// GetResults resturns the scanned results on this stocker instance
+// GetResults Returns the Account was scanned on this stocker
 func (s GCPStocker) GetResults() inventory.Account {
-	return s.Account
+	return *s.Account
+}
+
+// GetRegionsReport returns the zones scanned correctly and the failed ones
+func (s GCPStocker) GetRegionsReport() RegionsReport {
+	return s.regionsReport
 }
@@ -0,0 +1,137 @@
+package stocker
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	cpgcp "github.com/RHEcosystemAppEng/cluster-iq/internal/cloud_providers/gcp"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	compute "google.golang.org/api/compute/v1"
+	dns "google.golang.org/api/dns/v1"
+)
+
+// fakeGCPClient implements cpgcp.GCPAPIClient returning static instances per zone
+type fakeGCPClient struct {
+	instances    map[string][]*compute.Instance
+	managedZones []*dns.ManagedZone
+	failingZones []string
+}
+
+func (f *fakeGCPClient) ListZones(_ context.Context, _ string) ([]string, error) {
+	var zones []string
+	for zone := range f.instances {
+		zones = append(zones, zone)
+	}
+	return append(zones, f.failingZones...), nil
+}
+
+func (f *fakeGCPClient) ListInstances(_ context.Context, _ string, zone string) ([]*compute.Instance, error) {
+	if slices.Contains(f.failingZones, zone) {
+		return nil, errors.New("zone unavailable")
+	}
+	return f.instances[zone], nil
+}
+
+func (f *fakeGCPClient) ListManagedZones(_ context.Context, _ string) ([]*dns.ManagedZone, error) {
+	return f.managedZones, nil
+}
+
+func (f *fakeGCPClient) StartInstance(_ context.Context, _ string, _ string, _ string) error {
+	return nil
+}
+
+func (f *fakeGCPClient) StopInstance(_ context.Context, _ string, _ string, _ string) error {
+	return nil
+}
+
+func (f *fakeGCPClient) ResumeInstance(_ context.Context, _ string, _ string, _ string) error {
+	return nil
+}
+
+// TestGCPStockerMakeStock tests the instances are grouped by cluster using the GCP cluster labels
+func TestGCPStockerMakeStock(t *testing.T) {
+	zoneURL := "https://www.googleapis.com/compute/v1/projects/my-project/zones/us-central1-a"
+	labels := map[string]string{"kubernetes-io-cluster-ocp-a1b2c": "owned"}
+	client := &fakeGCPClient{
+		instances: map[string][]*compute.Instance{
+			"us-central1-a": {
+				{Id: 1001, Name: "ocp-a1b2c-master-0", Zone: zoneURL, MachineType: zoneURL + "/machineTypes/n2-standard-4", Status: "RUNNING", Labels: labels, CreationTimestamp: "2024-01-01T00:00:00.000-07:00"},
+				{Id: 1002, Name: "ocp-a1b2c-worker-0", Zone: zoneURL, MachineType: zoneURL + "/machineTypes/n2-standard-4", Status: "TERMINATED", Labels: labels},
+				{Id: 1003, Name: "standalone", Zone: zoneURL, MachineType: zoneURL + "/machineTypes/e2-micro", Status: "RUNNING"},
+			},
+		},
+		managedZones: []*dns.ManagedZone{
+			{Name: "ocp-a1b2c-private-zone", DnsName: "ocp.example.com.", Labels: labels},
+			{Name: "other", DnsName: "example.com."},
+		},
+	}
+
+	account := inventory.NewAccount("", "gcp-account", inventory.GCPProvider, "my-project", "")
+	st := NewGCPStockerWithConnection(account, cpgcp.NewGCPConnectionWithClient("my-project", client), true, zap.NewNop())
+
+	assert.Nil(t, st.MakeStock())
+
+	result := st.GetResults()
+	assert.Equal(t, "my-project", result.ID)
+	assert.Len(t, result.Clusters, 1)
+
+	cluster, ok := result.Clusters["ocp-a1b2c-gcp-account"]
+	assert.True(t, ok)
+	assert.Equal(t, "us-central1", cluster.Region)
+	assert.Equal(t, "https://console-openshift-console.apps.ocp.example.com", cluster.ConsoleLink)
+	assert.Len(t, cluster.Instances, 2)
+
+	instances := make(map[string]inventory.Instance)
+	for _, instance := range cluster.Instances {
+		instances[instance.ID] = instance
+	}
+	assert.Equal(t, inventory.Running, instances["1001"].Status)
+	assert.Equal(t, "n2-standard-4", instances["1001"].InstanceType)
+	assert.Equal(t, "us-central1-a", instances["1001"].AvailabilityZone)
+	assert.False(t, instances["1001"].CreationTimestamp.IsZero())
+	assert.Equal(t, inventory.Stopped, instances["1002"].Status)
+}
+
+// TestGCPStockerMakeStockFailedZone verifies the scanned zones are kept, but the account is reported as incompletely scanned when a zone fails
+func TestGCPStockerMakeStockFailedZone(t *testing.T) {
+	zoneURL := "https://www.googleapis.com/compute/v1/projects/my-project/zones/us-central1-a"
+	labels := map[string]string{"kubernetes-io-cluster-ocp-a1b2c": "owned"}
+	client := &fakeGCPClient{
+		instances: map[string][]*compute.Instance{
+			"us-central1-a": {
+				{Id: 1001, Name: "ocp-a1b2c-master-0", Zone: zoneURL, MachineType: zoneURL + "/machineTypes/n2-standard-4", Status: "RUNNING", Labels: labels},
+			},
+		},
+		failingZones: []string{"europe-west1-b"},
+	}
+
+	account := inventory.NewAccount("", "gcp-account", inventory.GCPProvider, "my-project", "")
+	st := NewGCPStockerWithConnection(account, cpgcp.NewGCPConnectionWithClient("my-project", client), true, zap.NewNop())
+
+	err := st.MakeStock()
+	assert.ErrorContains(t, err, "europe-west1-b")
+	assert.Len(t, st.GetResults().Clusters, 1)
+	assert.Equal(t, RegionsReport{Scanned: []string{"us-central1-a"}, Failed: []string{"europe-west1-b"}}, st.GetRegionsReport())
+}
+
+// TestGCPStockerMakeStockInvalidCreationTimestamp verifies the zones with invalid instance creation timestamps are reported as failed
+func TestGCPStockerMakeStockInvalidCreationTimestamp(t *testing.T) {
+	zoneURL := "https://www.googleapis.com/compute/v1/projects/my-project/zones/us-central1-a"
+	client := &fakeGCPClient{
+		instances: map[string][]*compute.Instance{
+			"us-central1-a": {
+				{Id: 1001, Name: "ocp-a1b2c-master-0", Zone: zoneURL, MachineType: zoneURL + "/machineTypes/n2-standard-4", Status: "RUNNING", CreationTimestamp: "yesterday"},
+			},
+		},
+	}
+
+	account := inventory.NewAccount("", "gcp-account", inventory.GCPProvider, "my-project", "")
+	st := NewGCPStockerWithConnection(account, cpgcp.NewGCPConnectionWithClient("my-project", client), false, zap.NewNop())
+
+	assert.ErrorContains(t, st.MakeStock(), "invalid creation timestamp")
+	assert.Equal(t, []string{"us-central1-a"}, st.GetRegionsReport().Failed)
+}
@@ -1,6 +1,10 @@
 package stocker
 
-import "github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+import (
+	"fmt"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+)
 
 // Stocker interface
 type Stocker interface {
@@ -8,3 +12,54 @@ type Stocker interface {
 	PrintStock()
 	GetResults() inventory.Account
 }
+
+// RegionsReport describes the regions scanned by a Stocker. GCP Stockers
+// report zones, and Azure Stockers report subscriptions
+type RegionsReport struct {
+	Scanned []string
+	Failed  []string
+}
+
+// RegionError is the error of a region that couldn't be scanned. The Stockers
+// only return it for the regions whose elements are identified by the region
+// of their clusters, so the rest of regions can be used
+type RegionError struct {
+	Region string
+	Err    error
+}
+
+func (e *RegionError) Error() string {
+	return fmt.Sprintf("region %s: %v", e.Region, e.Err)
+}
+
+func (e *RegionError) Unwrap() error {
+	return e.Err
+}
+
+// OnlyRegionErrors returns true if err is a RegionError or a join of them,
+// so the failure only affects the failed regions
+func OnlyRegionErrors(err error) bool {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			if !OnlyRegionErrors(e) {
+				return false
+			}
+		}
+		return len(joined.Unwrap()) > 0
+	}
+
+	_, ok := err.(*RegionError)
+	return ok
+}
+
+// RegionsReporter is implemented by the Stockers that scan the regions of an
+// account, for reporting which regions were scanned correctly
+type RegionsReporter interface {
+	GetRegionsReport() RegionsReport
+}
+
+// ThrottlingReporter is implemented by the Stockers whose cloud provider
+// throttles the API requests, for reporting how many requests were throttled
+type ThrottlingReporter interface {
+	GetThrottledRequests() int64
+}
@@ -0,0 +1,20 @@
+package stocker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOnlyRegionErrors verifies only the region errors, alone or joined, are reported as region failures
+func TestOnlyRegionErrors(t *testing.T) {
+	regionErr := &RegionError{Region: "us-east-1", Err: errors.New("timeout")}
+	assert.Equal(t, "region us-east-1: timeout", regionErr.Error())
+
+	assert.True(t, OnlyRegionErrors(regionErr))
+	assert.True(t, OnlyRegionErrors(errors.Join(regionErr, &RegionError{Region: "eu-west-1", Err: errors.New("denied")})))
+	assert.False(t, OnlyRegionErrors(errors.Join(regionErr, errors.New("console links lookup failed"))))
+	assert.False(t, OnlyRegionErrors(errors.New("can't list the regions")))
+	assert.False(t, OnlyRegionErrors(errors.Join()))
+}
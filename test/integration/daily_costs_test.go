@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/forecast"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	sqlclient "github.com/RHEcosystemAppEng/cluster-iq/internal/sql_client"
+	"github.com/stretchr/testify/assert"
+)
+
+// perClusterInventoryDailyCostsQuery adds up the daily costs of every active
+// cluster evaluating cluster_daily_costs per cluster, which the inventory
+// daily costs must match
+const perClusterInventoryDailyCostsQuery = `
+	SELECT daily_costs.cost_date AS date, SUM(daily_costs.cost_amount) AS amount
+	FROM clusters
+	CROSS JOIN LATERAL cluster_daily_costs(clusters.id) AS daily_costs
+	WHERE clusters.status NOT IN ('Terminated', 'Missing')
+		AND daily_costs.cost_date >= $1 AND daily_costs.cost_date < $2
+	GROUP BY daily_costs.cost_date
+	ORDER BY daily_costs.cost_date
+`
+
+// TestDailyCosts verifies the days with cluster level expenses use them, the rest of days fall back to the instances expenses, and the account and inventory daily costs match the per cluster ones
+func TestDailyCosts(t *testing.T) {
+	client, db := newTestSQLClient(t)
+	account, cluster := newTestAccount(t, client, "i-1", "i-2")
+	day1 := time.Date(2001, 2, 3, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	day3 := day2.AddDate(0, 0, 1)
+
+	assert.Nil(t, client.WriteExpenses([]inventory.Expense{
+		*inventory.NewExpense(cluster.Instances[0].ID, 3, day1),
+		*inventory.NewExpense(cluster.Instances[0].ID, 4, day2),
+		*inventory.NewExpense(cluster.Instances[1].ID, 1, day2),
+	}))
+	_, err := db.NamedExec(sqlclient.InsertClusterExpensesQuery, []inventory.ClusterExpense{
+		*inventory.NewClusterExpense(cluster.ID, "Amazon Elastic Compute Cloud - Compute", 10, day1),
+		*inventory.NewClusterExpense(cluster.ID, "Amazon Simple Storage Service", 2, day1),
+	})
+	assert.Nil(t, err)
+
+	expected := []forecast.DailyCost{{Date: day1, Amount: 12}, {Date: day2, Amount: 5}}
+
+	costs, err := client.GetClusterDailyCosts(cluster.ID, day1, day3)
+	assert.Nil(t, err)
+	assertDailyCosts(t, expected, costs)
+
+	costs, err = client.GetAccountDailyCosts(account.Name, day1, day3)
+	assert.Nil(t, err)
+	assertDailyCosts(t, expected, costs)
+
+	costs, err = client.GetInventoryDailyCosts(day1, day3)
+	assert.Nil(t, err)
+	var perClusterCosts []forecast.DailyCost
+	assert.Nil(t, db.Select(&perClusterCosts, perClusterInventoryDailyCostsQuery, day1, day3))
+	assertDailyCosts(t, perClusterCosts, costs)
+}
+
+// TestDailyCostsInactiveClusters verifies the account daily costs don't include the Terminated and Missing clusters, while their own daily costs are kept
+func TestDailyCostsInactiveClusters(t *testing.T) {
+	client, db := newTestSQLClient(t)
+	account, cluster := newTestAccount(t, client, "i-1")
+	day := time.Date(2001, 2, 3, 0, 0, 0, 0, time.UTC)
+	assert.Nil(t, client.WriteExpenses([]inventory.Expense{*inventory.NewExpense(cluster.Instances[0].ID, 3, day)}))
+
+	for _, status := range []inventory.InstanceStatus{inventory.Terminated, inventory.Missing} {
+		_, err := db.Exec("UPDATE clusters SET status = $1 WHERE id = $2", status, cluster.ID)
+		assert.Nil(t, err)
+
+		costs, err := client.GetAccountDailyCosts(account.Name, day, day.AddDate(0, 0, 1))
+		assert.Nil(t, err)
+		assert.Empty(t, costs, status)
+
+		costs, err = client.GetClusterDailyCosts(cluster.ID, day, day.AddDate(0, 0, 1))
+		assert.Nil(t, err)
+		assertDailyCosts(t, []forecast.DailyCost{{Date: day, Amount: 3}}, costs)
+	}
+}
+
+// assertDailyCosts checks the dates and amounts of the daily costs, ignoring the time zone of the dates
+func assertDailyCosts(t *testing.T, expected []forecast.DailyCost, costs []forecast.DailyCost) {
+	t.Helper()
+
+	if !assert.Len(t, costs, len(expected)) {
+		return
+	}
+	for i := range expected {
+		assert.True(t, expected[i].Date.Equal(costs[i].Date), "date %d: expected %s, got %s", i, expected[i].Date, costs[i].Date)
+		assert.InDelta(t, expected[i].Amount, costs[i].Amount, 0.001)
+	}
+}
@@ -0,0 +1,78 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	sqlclient "github.com/RHEcosystemAppEng/cluster-iq/internal/sql_client"
+	"github.com/stretchr/testify/assert"
+)
+
+// insertClusterHistoryQuery records a state of a cluster with explicit last scan and recording timestamps
+const insertClusterHistoryQuery = `
+	INSERT INTO clusters_history (id, name, account_name, provider, status, last_scan_timestamp, recorded_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+// findCluster returns the cluster with the ID, or nil if it's not in the list
+func findCluster(clusters []inventory.Cluster, id string) *inventory.Cluster {
+	for i := range clusters {
+		if clusters[i].ID == id {
+			return &clusters[i]
+		}
+	}
+	return nil
+}
+
+// TestClustersAtRecordedState verifies the point-in-time queries place the refreshed states when they were recorded, not on the last scan timestamp they keep
+func TestClustersAtRecordedState(t *testing.T) {
+	client, db := newTestSQLClient(t)
+	account, cluster := newTestAccount(t, client)
+	t.Cleanup(func() { _, _ = db.Exec("DELETE FROM clusters_history WHERE id = $1", cluster.ID) })
+
+	scan := time.Date(2001, 2, 3, 10, 0, 0, 0, time.UTC)
+	refresh := scan.Add(48 * time.Hour)
+	for _, state := range []struct {
+		status     inventory.InstanceStatus
+		recordedAt time.Time
+	}{{inventory.Running, scan}, {inventory.Missing, refresh}} {
+		_, err := db.Exec(insertClusterHistoryQuery, cluster.ID, cluster.Name, account.Name, cluster.Provider, state.status, scan, state.recordedAt)
+		assert.Nil(t, err)
+	}
+
+	clusters, err := client.GetClustersAt(refresh.Add(-time.Hour))
+	assert.Nil(t, err)
+	if found := findCluster(clusters, cluster.ID); assert.NotNil(t, found) {
+		assert.Equal(t, inventory.Running, found.Status)
+	}
+
+	clusters, err = client.GetClustersAt(refresh)
+	assert.Nil(t, err)
+	if found := findCluster(clusters, cluster.ID); assert.NotNil(t, found) {
+		assert.Equal(t, inventory.Missing, found.Status)
+	}
+
+	clusters, err = client.GetClustersAt(scan.Add(-time.Hour))
+	assert.Nil(t, err)
+	assert.Nil(t, findCluster(clusters, cluster.ID))
+}
+
+// TestRefreshedClustersHistoryWithoutState verifies the refresh records the clusters without any recorded state, and only once
+func TestRefreshedClustersHistoryWithoutState(t *testing.T) {
+	client, db := newTestSQLClient(t)
+	_, cluster := newTestAccount(t, client)
+	t.Cleanup(func() { _, _ = db.Exec("DELETE FROM clusters_history WHERE id = $1", cluster.ID) })
+
+	_, err := db.Exec("UPDATE clusters SET status = 'Missing' WHERE id = $1", cluster.ID)
+	assert.Nil(t, err)
+
+	for range 2 {
+		_, err = db.Exec(sqlclient.InsertRefreshedClustersHistoryQuery)
+		assert.Nil(t, err)
+	}
+
+	var statuses []string
+	assert.Nil(t, db.Select(&statuses, "SELECT status FROM clusters_history WHERE id = $1", cluster.ID))
+	assert.Equal(t, []string{"Missing"}, statuses)
+}
@@ -0,0 +1,54 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWriteOrphanResources verifies the orphaned resources of the Terminated clusters are written and listed with their tags, and the resolved ones are removed
+func TestWriteOrphanResources(t *testing.T) {
+	client, db := newTestSQLClient(t)
+	_, cluster := newTestAccount(t, client)
+	_, err := db.Exec("UPDATE clusters SET status = 'Terminated' WHERE id = $1", cluster.ID)
+	assert.Nil(t, err)
+
+	newOrphan := func(id string, resourceType inventory.ResourceType) inventory.Resource {
+		orphan := *inventory.NewResource(cluster.ID+"-"+id, id, resourceType, inventory.AWSProvider, "eu-west-1", "", "", 0, "", nil, time.Time{})
+		orphan.ClusterID = cluster.ID
+		orphan.LastScanTimestamp = time.Now()
+		return orphan
+	}
+	volume := newOrphan("vol-1", inventory.VolumeResourceType)
+	volume.Tags = []inventory.Tag{*inventory.NewTag("Name", "data", volume.ID)}
+	securityGroup := newOrphan("sg-1", inventory.SecurityGroupResourceType)
+
+	assert.Nil(t, client.WriteOrphanResources([]inventory.Resource{volume, securityGroup}, nil))
+	orphans, err := client.GetOrphanResources()
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{volume.ID, securityGroup.ID}, clusterOrphanIDs(orphans, cluster.ID))
+	for _, orphan := range orphans {
+		if orphan.ID == volume.ID {
+			assert.Equal(t, volume.Tags, orphan.Tags)
+		}
+	}
+
+	// The security group is not found anymore
+	assert.Nil(t, client.WriteOrphanResources([]inventory.Resource{volume}, []string{securityGroup.ID}))
+	orphans, err = client.GetOrphanResources()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{volume.ID}, clusterOrphanIDs(orphans, cluster.ID))
+}
+
+// clusterOrphanIDs returns the IDs of the orphaned resources of the cluster
+func clusterOrphanIDs(orphans []inventory.Resource, clusterID string) []string {
+	var ids []string
+	for _, orphan := range orphans {
+		if orphan.ClusterID == clusterID {
+			ids = append(ids, orphan.ID)
+		}
+	}
+	return ids
+}
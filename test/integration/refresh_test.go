@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	sqlclient "github.com/RHEcosystemAppEng/cluster-iq/internal/sql_client"
+	"github.com/stretchr/testify/assert"
+)
+
+// gracePeriodHours is the grace period used by the refresh tests
+const gracePeriodHours = 24
+
+// TestCheckTerminatedElements verifies the Missing clusters and instances are only marked as Terminated when their account kept being scanned during the grace period
+func TestCheckTerminatedElements(t *testing.T) {
+	client, db := newTestSQLClient(t)
+	account, cluster := newTestAccount(t, client, "i-old", "i-recent")
+	recent := inventory.NewCluster("recent", account.Name+"-recent", inventory.AWSProvider, "eu-west-1", account.Name, "", "")
+	assert.Nil(t, client.WriteClusters([]inventory.Cluster{*recent}))
+
+	now := time.Now()
+	old := now.Add(-2 * gracePeriodHours * time.Hour)
+	for _, update := range []struct {
+		query string
+		id    string
+		at    time.Time
+	}{
+		{"UPDATE clusters SET status = 'Missing', last_scan_timestamp = $2 WHERE id = $1", cluster.ID, old},
+		{"UPDATE clusters SET status = 'Missing', last_scan_timestamp = $2 WHERE id = $1", recent.ID, now.Add(-time.Hour)},
+		{"UPDATE instances SET status = 'Missing', last_scan_timestamp = $2 WHERE id = $1", cluster.Instances[0].ID, old},
+		{"UPDATE instances SET status = 'Missing', last_scan_timestamp = $2 WHERE id = $1", cluster.Instances[1].ID, now.Add(-time.Hour)},
+		{"UPDATE accounts SET last_scan_timestamp = $2 WHERE name = $1", account.Name, now},
+	} {
+		_, err := db.Exec(update.query, update.id, update.at)
+		assert.Nil(t, err)
+	}
+
+	for _, query := range []string{sqlclient.UpdateTerminatedInstancesQuery, sqlclient.UpdateTerminatedClustersQuery} {
+		_, err := db.Exec(query, gracePeriodHours)
+		assert.Nil(t, err)
+	}
+
+	assertStatus(t, db.Get, "clusters", cluster.ID, "Terminated")
+	assertStatus(t, db.Get, "clusters", recent.ID, "Missing")
+	assertStatus(t, db.Get, "instances", cluster.Instances[0].ID, "Terminated")
+	assertStatus(t, db.Get, "instances", cluster.Instances[1].ID, "Missing")
+}
+
+// TestCheckTerminatedElementsFailedAccount verifies the Missing clusters of an account not scanned successfully during the grace period are not terminated
+func TestCheckTerminatedElementsFailedAccount(t *testing.T) {
+	client, db := newTestSQLClient(t)
+	account, cluster := newTestAccount(t, client)
+
+	old := time.Now().Add(-2 * gracePeriodHours * time.Hour)
+	_, err := db.Exec("UPDATE clusters SET status = 'Missing', last_scan_timestamp = $2 WHERE id = $1", cluster.ID, old)
+	assert.Nil(t, err)
+	_, err = db.Exec("UPDATE accounts SET last_scan_timestamp = $2 WHERE name = $1", account.Name, old.Add(time.Hour))
+	assert.Nil(t, err)
+
+	_, err = db.Exec(sqlclient.UpdateTerminatedClustersQuery, gracePeriodHours)
+	assert.Nil(t, err)
+	assertStatus(t, db.Get, "clusters", cluster.ID, "Missing")
+}
+
+// TestCheckDeletedResources verifies the resources are removed once their account kept being scanned without finding them during the grace period
+func TestCheckDeletedResources(t *testing.T) {
+	client, db := newTestSQLClient(t)
+	account, cluster := newTestAccount(t, client)
+
+	now := time.Now()
+	var resources []inventory.Resource
+	for id, lastScan := range map[string]time.Time{
+		account.Name + "-vol-old":    now.Add(-2 * gracePeriodHours * time.Hour),
+		account.Name + "-vol-recent": now.Add(-time.Hour),
+	} {
+		resource := inventory.NewResource(id, id, inventory.VolumeResourceType, inventory.AWSProvider, "eu-west-1", "available", "gp3", 100, "", nil, now)
+		resource.ClusterID = cluster.ID
+		resource.LastScanTimestamp = lastScan
+		resources = append(resources, *resource)
+	}
+	assert.Nil(t, client.WriteResources(resources))
+	_, err := db.Exec("UPDATE accounts SET last_scan_timestamp = $2 WHERE name = $1", account.Name, now)
+	assert.Nil(t, err)
+
+	_, err = db.Exec(sqlclient.DeleteOutdatedResourcesQuery, gracePeriodHours)
+	assert.Nil(t, err)
+
+	var ids []string
+	assert.Nil(t, db.Select(&ids, "SELECT id FROM resources WHERE cluster_id = $1", cluster.ID))
+	assert.Equal(t, []string{account.Name + "-vol-recent"}, ids)
+}
+
+// assertStatus checks the status of a cluster or an instance on the DB
+func assertStatus(t *testing.T, get func(dest any, query string, args ...any) error, table string, id string, expected string) {
+	t.Helper()
+
+	var status string
+	assert.Nil(t, get(&status, "SELECT status FROM "+table+" WHERE id = $1", id))
+	assert.Equal(t, expected, status, "%s %s", table, id)
+}
@@ -0,0 +1,83 @@
+package integration
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWriteScanSession verifies the unchanged elements only get their last scan timestamp updated, the tags of the written instances are replaced, and the account scans and the history of the scanned clusters and instances are recorded
+func TestWriteScanSession(t *testing.T) {
+	client, db := newTestSQLClient(t)
+	account, cluster := newTestAccount(t, client, "i-1", "i-2")
+	updatedID, unchangedID := cluster.Instances[0].ID, cluster.Instances[1].ID
+
+	// Values not written by the scans, which must be kept on the unchanged elements
+	_, err := db.Exec("UPDATE instances SET total_cost = 7 WHERE id = $1", unchangedID)
+	assert.Nil(t, err)
+
+	current, err := client.GetInventoryState([]string{account.Name}, "", nil, nil)
+	assert.Nil(t, err)
+	scanned := current
+	scanned.Instances = slices.Clone(current.Instances)
+	for i := range scanned.Instances {
+		if scanned.Instances[i].ID == updatedID {
+			scanned.Instances[i].Tags = []inventory.Tag{*inventory.NewTag("Owner", "team", updatedID)}
+		}
+	}
+
+	diff := inventory.DiffInventory(current, scanned)
+	assert.Len(t, diff.Instances, 1)
+	assert.Equal(t, []string{account.Name}, diff.UnchangedAccounts)
+	assert.Equal(t, []string{cluster.ID}, diff.UnchangedClusters)
+	assert.Equal(t, []string{unchangedID}, diff.UnchangedInstances)
+
+	sessionTimestamp := time.Date(2001, 2, 3, 10, 0, 0, 0, time.UTC)
+	accountScans := []models.AccountScan{{
+		AccountName:    account.Name,
+		Provider:       inventory.AWSProvider,
+		StartTimestamp: sessionTimestamp.Add(-time.Minute),
+		EndTimestamp:   sessionTimestamp,
+		Status:         models.AccountScanSuccess,
+		ClusterCount:   1,
+		InstanceCount:  2,
+	}}
+	sessionID, err := client.WriteScanSession(models.ScanSession{SessionTimestamp: sessionTimestamp, TriggeredBy: "test"}, diff, accountScans)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _, _ = db.Exec("DELETE FROM scan_sessions WHERE id = $1", sessionID) })
+
+	// Unchanged elements
+	var lastScan time.Time
+	assert.Nil(t, db.Get(&lastScan, "SELECT last_scan_timestamp FROM clusters WHERE id = $1", cluster.ID))
+	assert.True(t, sessionTimestamp.Equal(lastScan))
+	assert.Nil(t, db.Get(&lastScan, "SELECT last_scan_timestamp FROM instances WHERE id = $1", unchangedID))
+	assert.True(t, sessionTimestamp.Equal(lastScan))
+	var totalCost float64
+	assert.Nil(t, db.Get(&totalCost, "SELECT total_cost FROM instances WHERE id = $1", unchangedID))
+	assert.Equal(t, 7.0, totalCost)
+
+	// Tags
+	var keys []string
+	assert.Nil(t, db.Select(&keys, "SELECT key FROM tags WHERE instance_id = $1", updatedID))
+	assert.Equal(t, []string{"Owner"}, keys)
+	assert.Nil(t, db.Select(&keys, "SELECT key FROM tags WHERE instance_id = $1", unchangedID))
+	assert.Equal(t, []string{"Name"}, keys)
+
+	// Account scans and history
+	scans, err := client.GetAccountScans(account.Name, 10)
+	assert.Nil(t, err)
+	if assert.Len(t, scans, 1) {
+		assert.Equal(t, sessionID, scans[0].SessionID)
+		assert.Equal(t, models.AccountScanSuccess, scans[0].Status)
+		assert.Equal(t, 2, scans[0].InstanceCount)
+	}
+	var ids []string
+	assert.Nil(t, db.Select(&ids, "SELECT id FROM clusters_history WHERE session_id = $1", sessionID))
+	assert.Equal(t, []string{cluster.ID}, ids)
+	assert.Nil(t, db.Select(&ids, "SELECT id FROM instances_history WHERE session_id = $1 ORDER BY id", sessionID))
+	assert.Equal(t, []string{updatedID, unchangedID}, ids)
+}
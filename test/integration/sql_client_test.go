@@ -0,0 +1,99 @@
+package integration
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RHEcosystemAppEng/cluster-iq/internal/inventory"
+	sqlclient "github.com/RHEcosystemAppEng/cluster-iq/internal/sql_client"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// TestDBURLEnv is the environment variable with the URL of the database
+// initialized with db/sql/init.sql. The SQL tests are skipped without it
+const TestDBURLEnv = "CIQ_TEST_DB_URL"
+
+// newTestSQLClient returns a SQLClient and a plain connection to the test
+// database for checking the stored values
+func newTestSQLClient(t *testing.T) (*sqlclient.SQLClient, *sqlx.DB) {
+	t.Helper()
+
+	dbURL := os.Getenv(TestDBURLEnv)
+	if dbURL == "" {
+		t.Skipf("%s is not set", TestDBURLEnv)
+	}
+
+	db, err := sqlx.Connect("postgres", dbURL)
+	if err != nil {
+		t.Fatalf("cannot connect to the test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return sqlclient.NewSQLClientWithDB(db, zap.NewNop()), db
+}
+
+// newTestAccount writes an AWS account with a cluster and the specified
+// instances. The account name is based on the test name, so every test has
+// its own elements on the shared database. The account and its elements are
+// removed when the test finishes
+func newTestAccount(t *testing.T, client *sqlclient.SQLClient, instanceIDs ...string) (*inventory.Account, *inventory.Cluster) {
+	t.Helper()
+
+	name := strings.ToLower(strings.ReplaceAll(t.Name(), "/", "-"))
+	account := inventory.NewAccount(name, name, inventory.AWSProvider, "", "")
+	account.LastScanTimestamp = time.Now()
+	cluster := inventory.NewCluster("cluster", name, inventory.AWSProvider, "eu-west-1", name, "", "")
+	cluster.LastScanTimestamp = time.Now()
+	for _, id := range instanceIDs {
+		id = name + "-" + id
+		tags := []inventory.Tag{*inventory.NewTag("Name", id, id)}
+		assert.Nil(t, cluster.AddInstance(*inventory.NewInstance(id, id, inventory.AWSProvider, "m5.xlarge", "eu-west-1a", inventory.Running, cluster.ID, tags, time.Now())))
+	}
+	assert.Nil(t, account.AddCluster(cluster))
+
+	t.Cleanup(func() { _ = client.DeleteAccount(name) })
+	assert.Nil(t, client.WriteAccounts([]inventory.Account{*account}))
+	assert.Nil(t, client.WriteClusters([]inventory.Cluster{*cluster}))
+	assert.Nil(t, client.WriteInstances(cluster.Instances))
+
+	return account, cluster
+}
+
+// TestEstimatedExpensesReplacedByBilling verifies the billing replaces the estimated expenses of the same day, and the rest of estimations of the account are removed on the next estimation
+func TestEstimatedExpensesReplacedByBilling(t *testing.T) {
+	client, db := newTestSQLClient(t)
+	_, cluster := newTestAccount(t, client, "i-1")
+	instanceID := cluster.Instances[0].ID
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	yesterday := today.AddDate(0, 0, -1)
+
+	// Account without billing information yet
+	assert.Nil(t, client.WriteEstimatedExpenses([]inventory.Expense{
+		*inventory.NewEstimatedExpense(instanceID, 5, yesterday),
+		*inventory.NewEstimatedExpense(instanceID, 5, today),
+	}))
+	var costEstimated bool
+	assert.Nil(t, db.Get(&costEstimated, "SELECT cost_estimated FROM instances WHERE id = $1", instanceID))
+	assert.True(t, costEstimated)
+
+	// The first billing covers only yesterday, and the estimations never replace it
+	assert.Nil(t, client.WriteExpenses([]inventory.Expense{*inventory.NewExpense(instanceID, 4, yesterday)}))
+	assert.Nil(t, client.WriteEstimatedExpenses([]inventory.Expense{*inventory.NewEstimatedExpense(instanceID, 9, yesterday)}))
+
+	expenses, err := client.GetExpensesByInstance(instanceID)
+	assert.Nil(t, err)
+	assert.Len(t, expenses, 1)
+	assert.Equal(t, 4.0, expenses[0].Amount)
+	assert.False(t, expenses[0].Estimated)
+
+	var totalCost float64
+	assert.Nil(t, db.Get(&totalCost, "SELECT total_cost FROM instances WHERE id = $1", instanceID))
+	assert.Equal(t, 4.0, totalCost)
+	assert.Nil(t, db.Get(&costEstimated, "SELECT cost_estimated FROM clusters WHERE id = $1", cluster.ID))
+	assert.False(t, costEstimated)
+}
@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	sqlclient "github.com/RHEcosystemAppEng/cluster-iq/internal/sql_client"
+	"github.com/stretchr/testify/assert"
+)
+
+// insertAuditLogQuery records an action executed on a cluster with an explicit timestamp
+const insertAuditLogQuery = `
+	INSERT INTO audit_logs (event_timestamp, triggered_by, action_name, resource_id, resource_type, result, description)
+	VALUES ($1, $2, $3, $4, 'cluster', 'Success', $5)
+`
+
+// TestClusterTimeline verifies the timeline merges the recorded transitions with the actions executed on the cluster, telling the scheduled actions apart from the manual ones
+func TestClusterTimeline(t *testing.T) {
+	client, db := newTestSQLClient(t)
+	_, cluster := newTestAccount(t, client, "i-1")
+	t.Cleanup(func() {
+		_, _ = db.Exec("DELETE FROM cluster_transitions WHERE cluster_id = $1", cluster.ID)
+		_, _ = db.Exec("DELETE FROM audit_logs WHERE resource_id = $1", cluster.ID)
+	})
+
+	assert.Nil(t, client.UpdateClusterStatusByClusterID("Stopped", cluster.ID))
+
+	// The actions are placed after the transitions, which are recorded with the current time
+	now := time.Now()
+	_, err := db.Exec(insertAuditLogQuery, now.Add(time.Hour), "user@example.com", "PowerOffCluster", cluster.ID, "Manual power off")
+	assert.Nil(t, err)
+	_, err = db.Exec(insertAuditLogQuery, now.Add(2*time.Hour), "agent", "PowerOnCluster", cluster.ID, "ScheduledAction(42)")
+	assert.Nil(t, err)
+
+	timeline, err := client.GetClusterTimeline(cluster.ID)
+	assert.Nil(t, err)
+	if !assert.Len(t, timeline, 4) {
+		return
+	}
+
+	assert.Equal(t, "Transition", timeline[0].Category)
+	assert.Equal(t, "FirstSeen", timeline[0].Type)
+
+	assert.Equal(t, "Transition", timeline[1].Category)
+	assert.Equal(t, "StatusChanged", timeline[1].Type)
+	assert.Equal(t, sqlclient.ChangeSourceAction, timeline[1].Source)
+	if assert.NotNil(t, timeline[1].OldValue) && assert.NotNil(t, timeline[1].NewValue) {
+		assert.Equal(t, "Running", *timeline[1].OldValue)
+		assert.Equal(t, "Stopped", *timeline[1].NewValue)
+	}
+
+	assert.Equal(t, "Action", timeline[2].Category)
+	assert.Equal(t, "PowerOffCluster", timeline[2].Type)
+	assert.Equal(t, "user@example.com", timeline[2].Source)
+	assert.Nil(t, timeline[2].OldValue)
+
+	assert.Equal(t, "ScheduledAction", timeline[3].Category)
+	assert.Equal(t, "PowerOnCluster", timeline[3].Type)
+	if assert.NotNil(t, timeline[3].Result) {
+		assert.Equal(t, "Success", *timeline[3].Result)
+	}
+}